@@ -0,0 +1,282 @@
+package bun
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/uptrace/bun/dialect"
+)
+
+const defaultBlobChunkSize = 1 << 20
+
+// Blob streams a binary column of a single row in chunks, which allows writing and reading
+// values that are too large to be kept in memory, for example, PostgreSQL bytea
+// or MySQL LONGBLOB columns:
+//
+//	file := &File{ID: 1}
+//	blob := bun.NewBlob(db, file, "data")
+//	_, err := blob.ReadFrom(ctx, f)
+//	_, err = blob.WriteTo(ctx, w)
+//
+// The row is selected with WherePK, so the model must have primary keys set.
+// Writing a blob executes an UPDATE for every chunk, so it should be done in a transaction.
+// Each UPDATE appends the chunk to the column value, which most databases do by
+// rewriting the whole value, so writing n bytes costs O(n²/ChunkSize) bytes of I/O.
+// Use a larger chunk size for large values or LargeObject with PostgreSQL.
+type Blob struct {
+	db        IDB
+	model     interface{}
+	column    string
+	chunkSize int
+}
+
+// NewBlob returns a Blob for the column of the row identified by the model primary keys.
+func NewBlob(db IDB, model interface{}, column string) *Blob {
+	return &Blob{
+		db:        db,
+		model:     model,
+		column:    column,
+		chunkSize: defaultBlobChunkSize,
+	}
+}
+
+// ChunkSize sets the number of bytes written or read by a single query. Default is 1MB.
+func (b *Blob) ChunkSize(n int) *Blob {
+	if n > 0 {
+		b.chunkSize = n
+	}
+	return b
+}
+
+// ReadFrom replaces the column value with the data read from r until io.EOF
+// and returns the number of bytes written.
+func (b *Blob) ReadFrom(ctx context.Context, r io.Reader) (int64, error) {
+	concat, err := b.concatExpr()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := b.db.NewUpdate().
+		Model(b.model).
+		Set("? = ?", Ident(b.column), []byte{}).
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return 0, sql.ErrNoRows
+	}
+
+	buf := make([]byte, b.chunkSize)
+	var written int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if _, err := b.db.NewUpdate().
+				Model(b.model).
+				Set("? = "+concat, Ident(b.column), Ident(b.column), buf[:n]).
+				WherePK().
+				Exec(ctx); err != nil {
+				return written, err
+			}
+			written += int64(n)
+		}
+
+		switch {
+		case err == nil:
+			continue
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			return written, nil
+		default:
+			return written, err
+		}
+	}
+}
+
+// WriteTo writes the column value to w and returns the number of bytes written.
+// A NULL value is treated as empty.
+func (b *Blob) WriteTo(ctx context.Context, w io.Writer) (int64, error) {
+	substr, err := b.substrExpr()
+	if err != nil {
+		return 0, err
+	}
+
+	var written int64
+	var chunk []byte
+	for {
+		chunk = chunk[:0]
+		if err := b.db.NewSelect().
+			Model(b.model).
+			ColumnExpr(substr, Ident(b.column), written+1, b.chunkSize).
+			WherePK().
+			Scan(ctx, &chunk); err != nil {
+			return written, err
+		}
+
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		if len(chunk) < b.chunkSize {
+			return written, nil
+		}
+	}
+}
+
+func (b *Blob) concatExpr() (string, error) {
+	switch name := b.db.Dialect().Name(); name {
+	case dialect.PG:
+		return "? || ?", nil
+	case dialect.SQLite:
+		// The || operator returns text in SQLite.
+		return "CAST(? || ? AS BLOB)", nil
+	case dialect.MySQL:
+		return "CONCAT(?, ?)", nil
+	case dialect.MSSQL:
+		return "? + ?", nil
+	default:
+		return "", fmt.Errorf("bun: Blob is not supported by %s", name)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// LargeObject streams a PostgreSQL large object, which is stored outside of the table
+// and referenced by its oid, so chunks are written in place without rewriting the value:
+//
+//	oid, err := bun.CreateLargeObject(ctx, db)
+//	lo := bun.NewLargeObject(db, oid)
+//	_, err = lo.ReadFrom(ctx, f)
+//	_, err = lo.WriteTo(ctx, w)
+//
+// The oid is usually stored in an oid column of the row that owns the object.
+// Large objects are not deleted with the row, see Unlink.
+type LargeObject struct {
+	db        IDB
+	oid       uint32
+	chunkSize int
+}
+
+// CreateLargeObject creates an empty PostgreSQL large object and returns its oid.
+func CreateLargeObject(ctx context.Context, db IDB) (uint32, error) {
+	if err := checkLargeObject(db); err != nil {
+		return 0, err
+	}
+	var oid uint32
+	if err := db.NewRaw("SELECT lo_create(0)").Scan(ctx, &oid); err != nil {
+		return 0, err
+	}
+	return oid, nil
+}
+
+// NewLargeObject returns a LargeObject for the PostgreSQL large object with the oid.
+func NewLargeObject(db IDB, oid uint32) *LargeObject {
+	return &LargeObject{
+		db:        db,
+		oid:       oid,
+		chunkSize: defaultBlobChunkSize,
+	}
+}
+
+// ChunkSize sets the number of bytes written or read by a single query. Default is 1MB.
+func (lo *LargeObject) ChunkSize(n int) *LargeObject {
+	if n > 0 {
+		lo.chunkSize = n
+	}
+	return lo
+}
+
+// ReadFrom writes the data read from r until io.EOF to the object starting
+// at offset 0 and returns the number of bytes written. Existing data after
+// the written bytes is kept, so use it with a new object.
+func (lo *LargeObject) ReadFrom(ctx context.Context, r io.Reader) (int64, error) {
+	if err := checkLargeObject(lo.db); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, lo.chunkSize)
+	var written int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if _, err := lo.db.NewRaw(
+				"SELECT lo_put(?, ?, ?)", lo.oid, written, buf[:n],
+			).Exec(ctx); err != nil {
+				return written, err
+			}
+			written += int64(n)
+		}
+
+		switch {
+		case err == nil:
+			continue
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			return written, nil
+		default:
+			return written, err
+		}
+	}
+}
+
+// WriteTo writes the object data to w and returns the number of bytes written.
+func (lo *LargeObject) WriteTo(ctx context.Context, w io.Writer) (int64, error) {
+	if err := checkLargeObject(lo.db); err != nil {
+		return 0, err
+	}
+
+	var written int64
+	var chunk []byte
+	for {
+		chunk = chunk[:0]
+		if err := lo.db.NewRaw(
+			"SELECT lo_get(?, ?, ?)", lo.oid, written, lo.chunkSize,
+		).Scan(ctx, &chunk); err != nil {
+			return written, err
+		}
+
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		if len(chunk) < lo.chunkSize {
+			return written, nil
+		}
+	}
+}
+
+// Unlink deletes the object.
+func (lo *LargeObject) Unlink(ctx context.Context) error {
+	if err := checkLargeObject(lo.db); err != nil {
+		return err
+	}
+	_, err := lo.db.NewRaw("SELECT lo_unlink(?)", lo.oid).Exec(ctx)
+	return err
+}
+
+func checkLargeObject(db IDB) error {
+	if name := db.Dialect().Name(); name != dialect.PG {
+		return fmt.Errorf("bun: LargeObject is not supported by %s", name)
+	}
+	return nil
+}
+
+func (b *Blob) substrExpr() (string, error) {
+	switch name := b.db.Dialect().Name(); name {
+	case dialect.PG:
+		return "substring(? from ? for ?)", nil
+	case dialect.SQLite:
+		return "substr(?, ?, ?)", nil
+	case dialect.MySQL, dialect.MSSQL:
+		return "SUBSTRING(?, ?, ?)", nil
+	default:
+		return "", fmt.Errorf("bun: Blob is not supported by %s", name)
+	}
+}
@@ -20,6 +20,9 @@ type (
 
 	BeforeScanRowHook = schema.BeforeScanRowHook
 	AfterScanRowHook  = schema.AfterScanRowHook
+
+	BeforeValidateHook = schema.BeforeValidateHook
+	ValidateHook       = schema.ValidateHook
 )
 
 func SafeQuery(query string, args ...interface{}) schema.QueryWithArgs {
@@ -83,6 +86,25 @@ func In(slice interface{}) schema.QueryAppender {
 	return schema.In(slice)
 }
 
+// Encrypted appends the value encrypted deterministically to compare it with
+// a column with the encrypted:deterministic tag option, see schema.Encrypted.
+func Encrypted(value interface{}) schema.QueryAppender {
+	return schema.Encrypted(value)
+}
+
 func NullZero(value interface{}) schema.QueryAppender {
 	return schema.NullZero(value)
 }
+
+// Named binds :name placeholders in the query to the fields of the struct
+// or the values of the map when it is the only arg of the query, for example:
+//
+//	db.NewRaw("SELECT * FROM users WHERE id IN (:ids) AND status = :status", bun.Named(map[string]interface{}{
+//		"ids":    []int64{1, 2, 3},
+//		"status": "active",
+//	}))
+//
+// Slice values are expanded to comma-separated lists. ? placeholders are not formatted.
+func Named(params interface{}) schema.NamedParams {
+	return schema.NewNamedParams(params)
+}
@@ -0,0 +1,448 @@
+// Command bungen generates column constants and reflection-free Append, Scan, and IsZero
+// functions for bun models. The generated functions are registered with
+// schema.RegisterGeneratedModel and are used instead of reflection when available.
+//
+// Usage:
+//
+//	//go:generate go run github.com/uptrace/bun/cmd/bungen $GOFILE
+//
+// By default bungen processes the structs in the file that embed bun.BaseModel.
+// Use -type to select the structs explicitly. The output is written to
+// file_bun.go (file_bun_test.go for test files) next to the input file.
+//
+// For a model User, bungen generates UserColumnEmail constants with column names and
+// a UserColumns variable with columns qualified with the table alias:
+//
+//	db.NewSelect().Model(&users).Where("? = ?", UserColumns.Email, email)
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/uptrace/bun/internal"
+	"github.com/uptrace/bun/internal/tagparser"
+)
+
+const header = "// Code generated by bungen. DO NOT EDIT.\n\n"
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bungen: ")
+
+	typeNames := flag.String("type", "", "comma-separated list of struct names; defaults to structs embedding bun.BaseModel")
+	output := flag.String("o", "", "output file name; defaults to file_bun.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: bungen [-type T1,T2] [-o output] file.go\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	filename := flag.Arg(0)
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+
+	src, err := generate(filename, types)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *output == "" {
+		*output = outputName(filename)
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func outputName(filename string) string {
+	base := strings.TrimSuffix(filename, ".go")
+	if strings.HasSuffix(base, "_test") {
+		return strings.TrimSuffix(base, "_test") + "_bun_test.go"
+	}
+	return base + "_bun.go"
+}
+
+// generate returns the generated source for the structs declared in the file.
+func generate(filename string, types []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	models, err := parseModels(file, types)
+	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no models found in %s", filepath.Base(filename))
+	}
+
+	g := &generator{pkg: file.Name.Name}
+	return g.generate(models)
+}
+
+type model struct {
+	name   string
+	fields []*field
+}
+
+type field struct {
+	goName string
+	column string
+	kind   string // Go type supported by generated functions or empty
+}
+
+func parseModels(file *ast.File, types []string) ([]*model, error) {
+	bunPkg := importName(file, "github.com/uptrace/bun")
+	schemaPkg := importName(file, "github.com/uptrace/bun/schema")
+	timePkg := importName(file, "time")
+
+	structs := make(map[string]*ast.StructType)
+	var names []string
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok || ts.TypeParams != nil {
+				continue
+			}
+			structs[ts.Name.Name] = st
+			if len(types) == 0 && embedsBaseModel(st, bunPkg, schemaPkg) {
+				names = append(names, ts.Name.Name)
+			}
+		}
+	}
+
+	if len(types) > 0 {
+		for _, name := range types {
+			name = strings.TrimSpace(name)
+			if _, ok := structs[name]; !ok {
+				return nil, fmt.Errorf("struct %s not found", name)
+			}
+			names = append(names, name)
+		}
+	}
+
+	models := make([]*model, 0, len(names))
+	for _, name := range names {
+		m := &model{name: name}
+		for _, f := range structs[name].Fields.List {
+			if len(f.Names) == 0 { // embedded
+				continue
+			}
+
+			tag := tagparser.Tag{}
+			tagstr := ""
+			if f.Tag != nil {
+				s, err := strconv.Unquote(f.Tag.Value)
+				if err != nil {
+					return nil, err
+				}
+				tagstr = reflect.StructTag(s).Get("bun")
+				tag = tagparser.Parse(tagstr)
+			}
+			if tagstr == "-" || tag.HasOption("rel") || tag.HasOption("m2m") {
+				continue
+			}
+
+			for _, ident := range f.Names {
+				if !ident.IsExported() {
+					continue
+				}
+				m.fields = append(m.fields, &field{
+					goName: ident.Name,
+					column: columnName(ident.Name, tag),
+					kind:   fieldKind(f.Type, tag, timePkg),
+				})
+			}
+		}
+		models = append(models, m)
+	}
+	return models, nil
+}
+
+func embedsBaseModel(st *ast.StructType, pkgs ...string) bool {
+	for _, f := range st.Fields.List {
+		if len(f.Names) != 0 {
+			continue
+		}
+		sel, ok := f.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "BaseModel" {
+			continue
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		for _, pkg := range pkgs {
+			if pkg != "" && x.Name == pkg {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// importName returns the name the file uses for the imported package or an empty string.
+func importName(file *ast.File, path string) string {
+	for _, imp := range file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p != path {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return path[strings.LastIndexByte(path, '/')+1:]
+	}
+	return ""
+}
+
+func columnName(goName string, tag tagparser.Tag) string {
+	name := internal.Underscore(goName)
+	if tag.Name != "" {
+		name = tag.Name
+	}
+	if s, ok := tag.Option("column"); ok {
+		name = s
+	}
+	return name
+}
+
+var skipOptions = []string{
+	"msgpack", "array", "hstore", "composite", "multirange", "discriminator",
+	"extras", "json_use_number", "json_omit_empty", "encrypted",
+}
+
+// fieldKind returns the Go type of the field if bungen can generate functions for it.
+func fieldKind(expr ast.Expr, tag tagparser.Tag, timePkg string) string {
+	for _, opt := range skipOptions {
+		if tag.HasOption(opt) {
+			return ""
+		}
+	}
+	if typ, ok := tag.Option("type"); ok {
+		typ = strings.ToLower(typ)
+		if typ == "json" || typ == "jsonb" || typ == "uuid" || strings.HasPrefix(typ, "vector") {
+			return ""
+		}
+	}
+
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		switch expr.Name {
+		case "bool", "string",
+			"int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64",
+			"float32", "float64":
+			return expr.Name
+		}
+	case *ast.ArrayType:
+		if ident, ok := expr.Elt.(*ast.Ident); ok && expr.Len == nil &&
+			(ident.Name == "byte" || ident.Name == "uint8") {
+			return "[]byte"
+		}
+	case *ast.SelectorExpr:
+		if x, ok := expr.X.(*ast.Ident); ok && timePkg != "" &&
+			x.Name == timePkg && expr.Sel.Name == "Time" {
+			return "time.Time"
+		}
+	}
+	return ""
+}
+
+type generator struct {
+	pkg string
+
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+func (g *generator) generate(models []*model) ([]byte, error) {
+	g.imports = map[string]bool{"github.com/uptrace/bun/schema": true}
+
+	var body bytes.Buffer
+	for _, m := range models {
+		g.writeColumns(&body, m)
+	}
+
+	body.WriteString("func init() {\n")
+	for _, m := range models {
+		g.writeRegister(&body, m)
+	}
+	body.WriteString("}\n")
+
+	g.buf.WriteString(header)
+	fmt.Fprintf(&g.buf, "package %s\n\n", g.pkg)
+	g.writeImports()
+	g.buf.Write(body.Bytes())
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w:\n%s", err, g.buf.String())
+	}
+	return src, nil
+}
+
+func (g *generator) writeImports() {
+	g.buf.WriteString("import (\n")
+	for _, path := range []string{"strconv"} {
+		if g.imports[path] {
+			fmt.Fprintf(&g.buf, "%q\n", path)
+		}
+	}
+	g.buf.WriteString("\n")
+	for _, path := range []string{"github.com/uptrace/bun/dialect", "github.com/uptrace/bun/schema"} {
+		if g.imports[path] {
+			fmt.Fprintf(&g.buf, "%q\n", path)
+		}
+	}
+	g.buf.WriteString(")\n\n")
+}
+
+func (g *generator) writeColumns(w *bytes.Buffer, m *model) {
+	if len(m.fields) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "// Column names of %s.\n", m.name)
+	w.WriteString("const (\n")
+	for _, f := range m.fields {
+		fmt.Fprintf(w, "%sColumn%s = %q\n", m.name, f.goName, f.column)
+	}
+	w.WriteString(")\n\n")
+
+	fmt.Fprintf(w, "// %sColumns are the columns of %s qualified with the table alias.\n", m.name, m.name)
+	fmt.Fprintf(w, "var %sColumns = struct {\n", m.name)
+	for _, f := range m.fields {
+		fmt.Fprintf(w, "%s schema.ModelColumn\n", f.goName)
+	}
+	w.WriteString("}{\n")
+	for _, f := range m.fields {
+		fmt.Fprintf(w, "%s: schema.NewModelColumn((*%s)(nil), %sColumn%s),\n", f.goName, m.name, m.name, f.goName)
+	}
+	w.WriteString("}\n\n")
+}
+
+func (g *generator) writeRegister(w *bytes.Buffer, m *model) {
+	fmt.Fprintf(w, "schema.RegisterGeneratedModel((*%s)(nil), map[string]schema.GeneratedField{\n", m.name)
+	for _, f := range m.fields {
+		if f.kind == "" {
+			continue
+		}
+		fv := fmt.Sprintf("model.(*%s).%s", m.name, f.goName)
+
+		fmt.Fprintf(w, "%q: {\n", f.goName)
+		fmt.Fprintf(w, "Append: func(fmter schema.Formatter, b []byte, model interface{}) []byte {\nreturn %s\n},\n",
+			g.appendExpr(f.kind, fv))
+		scanFunc, conv := scanFunc(f.kind)
+		fmt.Fprintf(w, `Scan: func(model interface{}, src interface{}) error {
+v, err := schema.%s(src)
+if err != nil {
+return err
+}
+%s = %s
+return nil
+},
+`, scanFunc, fv, conv)
+		fmt.Fprintf(w, "IsZero: func(model interface{}) bool {\nreturn %s\n},\n", isZeroExpr(f.kind, fv))
+		w.WriteString("},\n")
+	}
+	w.WriteString("})\n")
+}
+
+func (g *generator) appendExpr(kind, fv string) string {
+	switch kind {
+	case "bool":
+		return fmt.Sprintf("fmter.Dialect().AppendBool(b, %s)", fv)
+	case "string":
+		return fmt.Sprintf("fmter.Dialect().AppendString(b, %s)", fv)
+	case "int", "int8", "int16", "int32":
+		g.imports["strconv"] = true
+		return fmt.Sprintf("strconv.AppendInt(b, int64(%s), 10)", fv)
+	case "int64":
+		g.imports["strconv"] = true
+		return fmt.Sprintf("strconv.AppendInt(b, %s, 10)", fv)
+	case "uint", "uint8", "uint16":
+		g.imports["strconv"] = true
+		return fmt.Sprintf("strconv.AppendUint(b, uint64(%s), 10)", fv)
+	case "uint32":
+		return fmt.Sprintf("fmter.Dialect().AppendUint32(b, %s)", fv)
+	case "uint64":
+		return fmt.Sprintf("fmter.Dialect().AppendUint64(b, %s)", fv)
+	case "float32":
+		g.imports["github.com/uptrace/bun/dialect"] = true
+		return fmt.Sprintf("dialect.AppendFloat32(b, %s)", fv)
+	case "float64":
+		g.imports["github.com/uptrace/bun/dialect"] = true
+		return fmt.Sprintf("dialect.AppendFloat64(b, %s)", fv)
+	case "[]byte":
+		return fmt.Sprintf("fmter.Dialect().AppendBytes(b, %s)", fv)
+	case "time.Time":
+		return fmt.Sprintf("fmter.AppendTime(b, %s)", fv)
+	}
+	panic("not reached")
+}
+
+func scanFunc(kind string) (string, string) {
+	switch kind {
+	case "bool":
+		return "ScanBool", "v"
+	case "string":
+		return "ScanString", "v"
+	case "int64":
+		return "ScanInt64", "v"
+	case "int", "int8", "int16", "int32":
+		return "ScanInt64", kind + "(v)"
+	case "uint64":
+		return "ScanUint64", "v"
+	case "uint", "uint8", "uint16", "uint32":
+		return "ScanUint64", kind + "(v)"
+	case "float64":
+		return "ScanFloat64", "v"
+	case "float32":
+		return "ScanFloat64", "float32(v)"
+	case "[]byte":
+		return "ScanBytes", "v"
+	case "time.Time":
+		return "ScanTime", "v"
+	}
+	panic("not reached")
+}
+
+func isZeroExpr(kind, fv string) string {
+	switch kind {
+	case "bool":
+		return "!" + fv
+	case "string":
+		return fv + ` == ""`
+	case "[]byte":
+		return fv + " == nil"
+	case "time.Time":
+		return fv + ".IsZero()"
+	}
+	return fv + " == 0"
+}
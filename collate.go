@@ -0,0 +1,38 @@
+package bun
+
+import (
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// Collate returns an expression that compares or sorts the column with the collation,
+// for example, a case-insensitive ICU collation on Postgres:
+//
+//	q.Where("? = ?", bun.Collate("name", "und-u-ks-level2"), name)
+//	q.OrderExpr("? ASC", bun.Collate("name", "de-x-icu"))
+//
+// On MySQL, the collation must match the character set of the column, e.g. utf8mb4_bin.
+// Columns can use a collation by default with the collate tag option.
+func Collate(column, collation string) schema.QueryAppender {
+	return &collateExpr{column: column, collation: collation}
+}
+
+type collateExpr struct {
+	column    string
+	collation string
+}
+
+func (e *collateExpr) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	b = fmter.AppendIdent(b, e.column)
+	return appendCollation(fmter, b, e.collation), nil
+}
+
+// appendCollation appends the COLLATE clause. Postgres collation names are identifiers
+// that are often mixed-case or contain dashes, e.g. "C" or "de-x-icu", so they are quoted.
+func appendCollation(fmter schema.Formatter, b []byte, collation string) []byte {
+	b = append(b, " COLLATE "...)
+	if fmter.Dialect().Name() == dialect.PG {
+		return fmter.AppendIdent(b, collation)
+	}
+	return append(b, collation...)
+}
@@ -0,0 +1,66 @@
+package bun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// WithContextArg registers the named arg, e.g. ?tenant_id, that is resolved from the
+// context every time a query is executed, so conditions such as row scoping can be added
+// consistently without passing values to every query, for example:
+//
+//	db := bun.NewDB(sqldb, dialect, bun.WithContextArg("tenant_id",
+//		func(ctx context.Context) (interface{}, bool) {
+//			id, ok := ctx.Value(tenantKey{}).(int64)
+//			return id, ok
+//		}))
+//
+//	db.NewSelect().Model(&orders).Where("tenant_id = ?tenant_id").Scan(ctx)
+//
+// Queries that use the arg fail if the func returns false, so a missing value is never
+// silently ignored. Named args of the model take precedence.
+func WithContextArg(name string, fn func(ctx context.Context) (interface{}, bool)) DBOption {
+	return func(db *DB) {
+		db.contextArgs = append(db.contextArgs, contextArg{name: name, fn: fn})
+	}
+}
+
+type contextArg struct {
+	name string
+	fn   func(ctx context.Context) (interface{}, bool)
+}
+
+// formatter returns the formatter that resolves the args registered with WithContextArg
+// from the context.
+func (db *DB) formatter(ctx context.Context) schema.Formatter {
+	if len(db.contextArgs) == 0 {
+		return db.fmter
+	}
+	return db.fmter.WithArg(&contextArgs{ctx: ctx, args: db.contextArgs})
+}
+
+type contextArgs struct {
+	ctx  context.Context
+	args []contextArg
+}
+
+var _ schema.NamedArgAppender = (*contextArgs)(nil)
+
+func (a *contextArgs) AppendNamedArg(
+	fmter schema.Formatter, b []byte, name string,
+) ([]byte, bool) {
+	for _, arg := range a.args {
+		if arg.name != name {
+			continue
+		}
+		value, ok := arg.fn(a.ctx)
+		if !ok {
+			return dialect.AppendError(b, fmt.Errorf("bun: context arg ?%s is not set", name)), true
+		}
+		return fmter.AppendArg(b, value), true
+	}
+	return b, false
+}
@@ -0,0 +1,134 @@
+package bun
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/uptrace/bun/internal"
+)
+
+// Cursor iterates over the rows returned by a query one row at a time,
+// which allows processing large results without loading them into memory.
+//
+// Only columns selected by the query are scanned, so has-many and m2m relations are not loaded.
+type Cursor struct {
+	ctx       context.Context
+	db        *DB
+	rows      *sql.Rows
+	scanFlags internal.Flag
+}
+
+// Cursor executes the query and returns a cursor over the resulting rows.
+// The caller must close the cursor.
+func (q *SelectQuery) Cursor(ctx context.Context) (*Cursor, error) {
+	rows, err := q.Rows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{
+		ctx:       ctx,
+		db:        q.db,
+		rows:      rows,
+		scanFlags: q.scanFlags,
+	}, nil
+}
+
+// Next prepares the next row for Scan. It returns false when there are no more rows
+// or an error occurred; use Err to tell the two apart.
+func (c *Cursor) Next() bool {
+	return c.rows.Next()
+}
+
+// Scan scans the current row into dest, for example, a struct pointer or a list of values.
+// The scan options of the query, e.g. StrictScan, apply to struct destinations.
+func (c *Cursor) Scan(dest ...interface{}) error {
+	if len(dest) == 0 {
+		return errors.New("bun: Cursor.Scan requires a destination")
+	}
+
+	model, err := newModel(c.db, dest)
+	if err != nil {
+		return err
+	}
+	if c.scanFlags != 0 {
+		if tm, ok := model.(TableModel); ok {
+			tm.setScanFlags(c.scanFlags)
+		}
+	}
+
+	rs, ok := model.(rowScanner)
+	if !ok {
+		return fmt.Errorf("bun: %T does not support ScanRow", model)
+	}
+	return rs.ScanRow(c.ctx, c.rows)
+}
+
+// Err returns the error, if any, that was encountered during iteration.
+func (c *Cursor) Err() error {
+	return c.rows.Err()
+}
+
+func (c *Cursor) Close() error {
+	return c.rows.Close()
+}
+
+// ForEach executes the query and calls fn for each row scanned into a new T.
+// Iteration stops at the first error returned by fn.
+func ForEach[T any](ctx context.Context, q *SelectQuery, fn func(model *T) error) error {
+	cursor, err := q.Cursor(ctx)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	for cursor.Next() {
+		model := new(T)
+		if err := cursor.Scan(model); err != nil {
+			return err
+		}
+		if err := fn(model); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// ScanChan executes the query and sends each row, scanned into a new struct,
+// to ch, which must be a chan *T or chan<- *T. It blocks while the channel is full
+// and returns ctx.Err() when the context is done. ScanChan does not close the channel.
+func (q *SelectQuery) ScanChan(ctx context.Context, ch interface{}) error {
+	chv := reflect.ValueOf(ch)
+	if chv.Kind() != reflect.Chan || chv.Type().ChanDir()&reflect.SendDir == 0 {
+		return fmt.Errorf("bun: ScanChan(unsupported %T) (expected chan *struct)", ch)
+	}
+	elemType := chv.Type().Elem()
+	if elemType.Kind() != reflect.Ptr || elemType.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bun: ScanChan(unsupported %T) (expected chan *struct)", ch)
+	}
+
+	cursor, err := q.Cursor(ctx)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: chv},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	}
+	for cursor.Next() {
+		model := reflect.New(elemType.Elem())
+		if err := cursor.Scan(model.Interface()); err != nil {
+			return err
+		}
+
+		cases[0].Send = model
+		if chosen, _, _ := reflect.Select(cases); chosen == 1 {
+			return ctx.Err()
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,190 @@
+package bun
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// DateTrunc returns an expression that truncates the time column to the unit, which is one of
+// "second", "minute", "hour", "day", "week", "month", "quarter", or "year", for example:
+//
+//	db.NewSelect().
+//		ColumnExpr("? AS day, count(*)", bun.DateTrunc("day", "created_at")).
+//		GroupExpr("day")
+//
+// It uses date_trunc on Postgres, DATE_FORMAT on MySQL, strftime on SQLite, and DATETRUNC
+// on SQL Server 2022. Weeks start on Monday. On SQLite, the result is a text in the
+// "2006-01-02 15:04:05" format, which can be scanned into time.Time.
+func DateTrunc(unit, column string) schema.QueryAppender {
+	return &dateTrunc{unit: unit, column: column}
+}
+
+type dateTrunc struct {
+	unit   string
+	column string
+}
+
+var (
+	mysqlDateTruncFormats = map[string]string{
+		"second": "%Y-%m-%d %H:%i:%s",
+		"minute": "%Y-%m-%d %H:%i:00",
+		"hour":   "%Y-%m-%d %H:00:00",
+		"day":    "%Y-%m-%d 00:00:00",
+		"month":  "%Y-%m-01 00:00:00",
+		"year":   "%Y-01-01 00:00:00",
+	}
+	sqliteDateTruncFormats = map[string]string{
+		"second": "%Y-%m-%d %H:%M:%S",
+		"minute": "%Y-%m-%d %H:%M:00",
+		"hour":   "%Y-%m-%d %H:00:00",
+		"day":    "%Y-%m-%d 00:00:00",
+		"month":  "%Y-%m-01 00:00:00",
+		"year":   "%Y-01-01 00:00:00",
+	}
+)
+
+func (d *dateTrunc) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	switch d.unit {
+	case "second", "minute", "hour", "day", "week", "month", "quarter", "year":
+	default:
+		return nil, fmt.Errorf("bun: DateTrunc(unsupported unit %q)", d.unit)
+	}
+
+	switch fmter.Dialect().Name() {
+	case dialect.PG:
+		b = append(b, "date_trunc('"...)
+		b = append(b, d.unit...)
+		b = append(b, "', "...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, ')')
+		return b, nil
+	case dialect.MySQL:
+		return d.appendMySQL(fmter, b), nil
+	case dialect.SQLite:
+		return d.appendSQLite(fmter, b), nil
+	case dialect.MSSQL:
+		unit := d.unit
+		if unit == "week" {
+			unit = "iso_week"
+		}
+		b = append(b, "DATETRUNC("...)
+		b = append(b, unit...)
+		b = append(b, ", "...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, ')')
+		return b, nil
+	default:
+		return nil, fmt.Errorf("bun: DateTrunc is not supported by %s", fmter.Dialect().Name())
+	}
+}
+
+func (d *dateTrunc) appendMySQL(fmter schema.Formatter, b []byte) []byte {
+	b = append(b, "CAST("...)
+	switch d.unit {
+	case "week":
+		b = append(b, "DATE_SUB(DATE("...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, "), INTERVAL WEEKDAY("...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, ") DAY)"...)
+	case "quarter":
+		b = append(b, "MAKEDATE(YEAR("...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, "), 1) + INTERVAL (QUARTER("...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, ") - 1) QUARTER"...)
+	default:
+		b = append(b, "DATE_FORMAT("...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, ", "...)
+		b = fmter.Dialect().AppendString(b, mysqlDateTruncFormats[d.unit])
+		b = append(b, ')')
+	}
+	b = append(b, " AS DATETIME)"...)
+	return b
+}
+
+func (d *dateTrunc) appendSQLite(fmter schema.Formatter, b []byte) []byte {
+	switch d.unit {
+	case "week":
+		b = append(b, "strftime('%Y-%m-%d 00:00:00', "...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, ", 'weekday 0', '-6 days')"...)
+	case "quarter":
+		b = append(b, "strftime('%Y-', "...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, ") || printf('%02d', (strftime('%m', "...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, ") - 1) / 3 * 3 + 1) || '-01 00:00:00'"...)
+	default:
+		b = append(b, "strftime("...)
+		b = fmter.Dialect().AppendString(b, sqliteDateTruncFormats[d.unit])
+		b = append(b, ", "...)
+		b = fmter.AppendIdent(b, d.column)
+		b = append(b, ')')
+	}
+	return b
+}
+
+// TimeBucket returns an expression that rounds the time column down to a multiple
+// of the interval since the Unix epoch, e.g. 15-minute buckets:
+//
+//	bun.TimeBucket(15*time.Minute, "created_at")
+//
+// The interval is rounded down to whole seconds. Like DateTrunc, the result is a text
+// on SQLite.
+func TimeBucket(interval time.Duration, column string) schema.QueryAppender {
+	return &timeBucket{seconds: int64(interval / time.Second), column: column}
+}
+
+type timeBucket struct {
+	seconds int64
+	column  string
+}
+
+func (t *timeBucket) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if t.seconds <= 0 {
+		return nil, errors.New("bun: TimeBucket requires an interval of at least one second")
+	}
+
+	switch fmter.Dialect().Name() {
+	case dialect.PG:
+		b = append(b, "to_timestamp(floor(extract(epoch FROM "...)
+		b = fmter.AppendIdent(b, t.column)
+		b = append(b, ") / "...)
+		b = strconv.AppendInt(b, t.seconds, 10)
+		b = append(b, ") * "...)
+		b = strconv.AppendInt(b, t.seconds, 10)
+		b = append(b, ')')
+	case dialect.MySQL:
+		b = append(b, "FROM_UNIXTIME(FLOOR(UNIX_TIMESTAMP("...)
+		b = fmter.AppendIdent(b, t.column)
+		b = append(b, ") / "...)
+		b = strconv.AppendInt(b, t.seconds, 10)
+		b = append(b, ") * "...)
+		b = strconv.AppendInt(b, t.seconds, 10)
+		b = append(b, ')')
+	case dialect.SQLite:
+		b = append(b, "datetime(CAST(strftime('%s', "...)
+		b = fmter.AppendIdent(b, t.column)
+		b = append(b, ") AS INTEGER) / "...)
+		b = strconv.AppendInt(b, t.seconds, 10)
+		b = append(b, " * "...)
+		b = strconv.AppendInt(b, t.seconds, 10)
+		b = append(b, ", 'unixepoch')"...)
+	case dialect.MSSQL:
+		b = append(b, "DATE_BUCKET(second, "...)
+		b = strconv.AppendInt(b, t.seconds, 10)
+		b = append(b, ", "...)
+		b = fmter.AppendIdent(b, t.column)
+		b = append(b, ", CAST('1970-01-01' AS DATETIME2))"...)
+	default:
+		return nil, fmt.Errorf("bun: TimeBucket is not supported by %s", fmter.Dialect().Name())
+	}
+	return b, nil
+}
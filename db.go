@@ -9,19 +9,36 @@ import (
 	"reflect"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/uptrace/bun/dialect/feature"
+	"github.com/uptrace/bun/extra/bunjson"
 	"github.com/uptrace/bun/internal"
 	"github.com/uptrace/bun/schema"
 )
 
 const (
 	discardUnknownColumns internal.Flag = 1 << iota
+	strictScan
+	strictNullScan
 )
 
+// DBStats contains the counters of the queries executed by the DB, see also DB.Stats.
 type DBStats struct {
 	Queries uint32
 	Errors  uint32
+
+	Selects uint32
+	Inserts uint32
+	Updates uint32
+	Deletes uint32
+	// OtherQueries counts the rest of the queries, e.g. raw queries,
+	// DDL, and transaction statements.
+	OtherQueries uint32
+
+	TxBegins    uint32
+	TxCommits   uint32
+	TxRollbacks uint32
 }
 
 type DBOption func(db *DB)
@@ -32,13 +49,125 @@ func WithDiscardUnknownColumns() DBOption {
 	}
 }
 
+// WithStrictScan makes scanning into models fail when a selected column
+// has no destination field instead of silently discarding the value.
+// It takes precedence over WithDiscardUnknownColumns. Columns starting
+// with an underscore are still ignored.
+func WithStrictScan() DBOption {
+	return func(db *DB) {
+		db.flags = db.flags.Set(strictScan)
+	}
+}
+
+// WithStrictNullScan makes scanning into models fail when a NULL value
+// is scanned into a field with the notnull tag option.
+func WithStrictNullScan() DBOption {
+	return func(db *DB) {
+		db.flags = db.flags.Set(strictNullScan)
+	}
+}
+
+// WithTimeLocation converts time.Time values to the location before appending them
+// to queries and after scanning them into models, e.g. WithTimeLocation(time.UTC).
+func WithTimeLocation(loc *time.Location) DBOption {
+	return func(db *DB) {
+		db.timeConfig().Location = loc
+	}
+}
+
+// WithTimePrecision truncates time.Time values to the precision before appending them
+// to queries and after scanning them into models, e.g. WithTimePrecision(time.Microsecond).
+func WithTimePrecision(d time.Duration) DBOption {
+	return func(db *DB) {
+		db.timeConfig().Precision = d
+	}
+}
+
+// WithTimestampType sets the column type that CreateTableQuery uses for time.Time
+// fields without an explicit type, for example, to use "TIMESTAMP" instead of
+// "TIMESTAMPTZ" with PostgreSQL.
+func WithTimestampType(sqlType string) DBOption {
+	return func(db *DB) {
+		db.timeConfig().SQLType = sqlType
+	}
+}
+
+// WithJSONProvider sets the JSON encoder and decoder used for JSON fields
+// instead of the global bunjson provider, e.g. to use a faster JSON library.
+func WithJSONProvider(p bunjson.Provider) DBOption {
+	return func(db *DB) {
+		db.fmter = db.fmter.WithJSONProvider(p)
+	}
+}
+
+// WithEncryptor sets the encryptor for fields with the encrypted tag option, e.g.
+//
+//	bun.WithEncryptor(schema.NewAESGCMEncryptor(schema.StaticKey("v1", key)))
+func WithEncryptor(e schema.Encryptor) DBOption {
+	return func(db *DB) {
+		db.fmter = db.fmter.WithEncryptor(e)
+	}
+}
+
+// WithSQLType sets the SQL type of fields with the Go type of the value,
+// e.g. WithSQLType((*Email)(nil), "citext"). The type is registered with the dialect
+// tables rather than with the DB, which has two consequences:
+//   - it applies to all DBs that share the dialect, so use a separate dialect
+//     instance, e.g. pgdialect.New(), for each DB with different types;
+//   - tables that the dialect has already built keep their SQL types, so the type
+//     must be registered before the models that use it are used with any DB
+//     that shares the dialect.
+func WithSQLType(value interface{}, sqlType string) DBOption {
+	return func(db *DB) {
+		db.dialect.Tables().RegisterSQLType(value, sqlType)
+	}
+}
+
+// WithRelationChunkSize limits the number of base models that are loaded with a single
+// has-many or many-to-many relation query. Larger sets of base models are split into
+// chunks and each chunk is selected with a separate query, so the IN list does not exceed
+// database limits on the number of query parameters or the query size.
+func WithRelationChunkSize(size int) DBOption {
+	return func(db *DB) {
+		db.relationChunkSize = size
+	}
+}
+
+// WithRelationParallelism runs up to n has-many and many-to-many relation queries
+// of a select query concurrently instead of one by one, which reduces the latency of
+// queries with several relations. Queries that use a transaction or a connection
+// always select relations one by one.
+func WithRelationParallelism(n int) DBOption {
+	return func(db *DB) {
+		db.relationParallelism = n
+	}
+}
+
 type DB struct {
 	*sql.DB
 
 	dialect  schema.Dialect
 	features feature.Feature
 
-	queryHooks []QueryHook
+	queryHooks  []QueryHook
+	modelHooks  *modelHooks
+	txRetry     *TxRetryPolicy
+	stmtCache   *stmtCache
+	queryCache  *queryCache
+	entityCache *entityCache
+	queryRetry  *QueryRetryPolicy
+	queryErrors *QueryErrorOptions
+	sqlErrors   bool
+
+	queryTimeout        time.Duration
+	relationChunkSize   int
+	relationParallelism int
+
+	contextArgs []contextArg
+	sessionVars []sessionVar
+	scopes      map[reflect.Type][]schema.QueryWithArgs
+
+	columnGroups map[reflect.Type]map[string][]string
 
 	fmter schema.Formatter
 	flags internal.Flag
@@ -54,6 +183,8 @@ func NewDB(sqldb *sql.DB, dialect schema.Dialect, opts ...DBOption) *DB {
 		dialect:  dialect,
 		features: dialect.Features(),
 		fmter:    schema.NewFormatter(dialect),
+
+		modelHooks: new(modelHooks),
 	}
 
 	for _, opt := range opts {
@@ -63,6 +194,15 @@ func NewDB(sqldb *sql.DB, dialect schema.Dialect, opts ...DBOption) *DB {
 	return db
 }
 
+func (db *DB) timeConfig() *schema.TimeConfig {
+	cfg := db.fmter.TimeConfig()
+	if cfg == nil {
+		cfg = new(schema.TimeConfig)
+		db.fmter = db.fmter.WithTimeConfig(cfg)
+	}
+	return cfg
+}
+
 func (db *DB) String() string {
 	var b strings.Builder
 	b.WriteString("DB<dialect=")
@@ -75,6 +215,16 @@ func (db *DB) DBStats() DBStats {
 	return DBStats{
 		Queries: atomic.LoadUint32(&db.stats.Queries),
 		Errors:  atomic.LoadUint32(&db.stats.Errors),
+
+		Selects:      atomic.LoadUint32(&db.stats.Selects),
+		Inserts:      atomic.LoadUint32(&db.stats.Inserts),
+		Updates:      atomic.LoadUint32(&db.stats.Updates),
+		Deletes:      atomic.LoadUint32(&db.stats.Deletes),
+		OtherQueries: atomic.LoadUint32(&db.stats.OtherQueries),
+
+		TxBegins:    atomic.LoadUint32(&db.stats.TxBegins),
+		TxCommits:   atomic.LoadUint32(&db.stats.TxCommits),
+		TxRollbacks: atomic.LoadUint32(&db.stats.TxRollbacks),
 	}
 }
 
@@ -201,6 +351,13 @@ func (db *DB) RegisterModel(models ...interface{}) {
 	db.dialect.Tables().Register(models...)
 }
 
+// ValidateModels builds the tables for the models and their relations and returns
+// every invalid struct tag and relation as a single error, so misconfigured models are
+// detected at startup or in tests instead of panicking on the first query.
+func (db *DB) ValidateModels(models ...interface{}) error {
+	return db.dialect.Tables().Validate(models...)
+}
+
 func (db *DB) clone() *DB {
 	clone := *db
 
@@ -243,10 +400,15 @@ func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
 func (db *DB) ExecContext(
 	ctx context.Context, query string, args ...interface{},
 ) (sql.Result, error) {
-	formattedQuery := db.format(query, args)
+	if tx, ok := TxFromContext(ctx); ok && tx.db.DB == db.DB {
+		return tx.ExecContext(ctx, query, args...)
+	}
+
+	formattedQuery := db.format(ctx, query, args)
 	ctx, event := db.beforeQuery(ctx, nil, query, args, formattedQuery, nil)
 	res, err := db.DB.ExecContext(ctx, formattedQuery)
-	db.afterQuery(ctx, event, res, err)
+	err = db.afterQuery(ctx, event, res, err)
+	db.invalidateStmtCache(sqlOperation(formattedQuery))
 	return res, err
 }
 
@@ -257,11 +419,20 @@ func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
 func (db *DB) QueryContext(
 	ctx context.Context, query string, args ...interface{},
 ) (*sql.Rows, error) {
-	formattedQuery := db.format(query, args)
+	if tx, ok := TxFromContext(ctx); ok && tx.db.DB == db.DB {
+		return tx.QueryContext(ctx, query, args...)
+	}
+
+	formattedQuery := db.format(ctx, query, args)
 	ctx, event := db.beforeQuery(ctx, nil, query, args, formattedQuery, nil)
 	rows, err := db.DB.QueryContext(ctx, formattedQuery)
-	db.afterQuery(ctx, event, nil, err)
-	return rows, err
+	if err = db.afterQuery(ctx, event, nil, err); err != nil {
+		if rows != nil {
+			_ = rows.Close()
+		}
+		return nil, err
+	}
+	return rows, nil
 }
 
 func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
@@ -269,15 +440,19 @@ func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
 }
 
 func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	formattedQuery := db.format(query, args)
+	if tx, ok := TxFromContext(ctx); ok && tx.db.DB == db.DB {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+
+	formattedQuery := db.format(ctx, query, args)
 	ctx, event := db.beforeQuery(ctx, nil, query, args, formattedQuery, nil)
 	row := db.DB.QueryRowContext(ctx, formattedQuery)
 	db.afterQuery(ctx, event, nil, row.Err())
 	return row
 }
 
-func (db *DB) format(query string, args []interface{}) string {
-	return db.fmter.FormatQuery(query, args...)
+func (db *DB) format(ctx context.Context, query string, args []interface{}) string {
+	return db.formatter(ctx).FormatQuery(query, args...)
 }
 
 //------------------------------------------------------------------------------
@@ -301,25 +476,31 @@ func (db *DB) Conn(ctx context.Context) (Conn, error) {
 func (c Conn) ExecContext(
 	ctx context.Context, query string, args ...interface{},
 ) (sql.Result, error) {
-	formattedQuery := c.db.format(query, args)
+	formattedQuery := c.db.format(ctx, query, args)
 	ctx, event := c.db.beforeQuery(ctx, nil, query, args, formattedQuery, nil)
 	res, err := c.Conn.ExecContext(ctx, formattedQuery)
-	c.db.afterQuery(ctx, event, res, err)
+	err = c.db.afterQuery(ctx, event, res, err)
+	c.db.invalidateStmtCache(sqlOperation(formattedQuery))
 	return res, err
 }
 
 func (c Conn) QueryContext(
 	ctx context.Context, query string, args ...interface{},
 ) (*sql.Rows, error) {
-	formattedQuery := c.db.format(query, args)
+	formattedQuery := c.db.format(ctx, query, args)
 	ctx, event := c.db.beforeQuery(ctx, nil, query, args, formattedQuery, nil)
 	rows, err := c.Conn.QueryContext(ctx, formattedQuery)
-	c.db.afterQuery(ctx, event, nil, err)
-	return rows, err
+	if err = c.db.afterQuery(ctx, event, nil, err); err != nil {
+		if rows != nil {
+			_ = rows.Close()
+		}
+		return nil, err
+	}
+	return rows, nil
 }
 
 func (c Conn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	formattedQuery := c.db.format(query, args)
+	formattedQuery := c.db.format(ctx, query, args)
 	ctx, event := c.db.beforeQuery(ctx, nil, query, args, formattedQuery, nil)
 	row := c.Conn.QueryRowContext(ctx, formattedQuery)
 	c.db.afterQuery(ctx, event, nil, row.Err())
@@ -386,43 +567,41 @@ func (c Conn) NewDropColumn() *DropColumnQuery {
 	return NewDropColumnQuery(c.db).Conn(c)
 }
 
-// RunInTx runs the function in a transaction. If the function returns an error,
-// the transaction is rolled back. Otherwise, the transaction is committed.
+// RunInTx runs the function in a transaction like DB.RunInTx.
 func (c Conn) RunInTx(
 	ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context, tx Tx) error,
 ) error {
-	tx, err := c.BeginTx(ctx, opts)
-	if err != nil {
-		return err
+	if tx, ok := TxFromContext(ctx); ok && tx.db.DB == c.db.DB {
+		return tx.RunInTx(ctx, opts, fn)
 	}
 
-	var done bool
-
-	defer func() {
-		if !done {
-			_ = tx.Rollback()
-		}
-	}()
-
-	if err := fn(ctx, tx); err != nil {
+	tx, err := c.BeginTx(ctx, opts)
+	if err != nil {
 		return err
 	}
-
-	done = true
-	return tx.Commit()
+	return runInTx(ctx, tx, fn)
 }
 
 func (c Conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
 	ctx, event := c.db.beforeQuery(ctx, nil, "BEGIN", nil, "BEGIN", nil)
 	tx, err := c.Conn.BeginTx(ctx, opts)
-	c.db.afterQuery(ctx, event, nil, err)
-	if err != nil {
+	if err = c.db.afterQuery(ctx, event, nil, err); err != nil {
+		if tx != nil {
+			_ = tx.Rollback()
+		}
+		return Tx{}, err
+	}
+	atomic.AddUint32(&c.db.stats.TxBegins, 1)
+	if err := c.db.setSessionVars(ctx, tx); err != nil {
+		_ = tx.Rollback()
 		return Tx{}, err
 	}
 	return Tx{
-		ctx: ctx,
-		db:  c.db,
-		Tx:  tx,
+		ctx:       ctx,
+		db:        c.db,
+		opts:      opts,
+		Tx:        tx,
+		callbacks: new(txCallbacks),
 	}, nil
 }
 
@@ -451,19 +630,69 @@ type Tx struct {
 	db  *DB
 	// name is the name of a savepoint
 	name string
+	// opts are the options the transaction was started with
+	opts *sql.TxOptions
 	*sql.Tx
+
+	callbacks *txCallbacks
 }
 
 // RunInTx runs the function in a transaction. If the function returns an error,
 // the transaction is rolled back. Otherwise, the transaction is committed.
+//
+// RunInTx can be nested: when the context passed to the function is used to call RunInTx
+// again, the inner function runs in a savepoint of the same transaction and an error
+// only rolls back to the savepoint. The nested call returns an error if its non-nil opts
+// differ from the options of the transaction, see Tx.RunInTx.
 func (db *DB) RunInTx(
 	ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context, tx Tx) error,
 ) error {
+	if tx, ok := TxFromContext(ctx); ok && tx.db.DB == db.DB {
+		return tx.RunInTx(ctx, opts, fn)
+	}
+
 	tx, err := db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
+	return runInTx(ctx, tx, fn)
+}
+
+type txCtxKey struct{}
+
+// ContextWithTx returns a copy of the context with the transaction. Queries created with
+// DB.NewSelect, DB.NewInsert, etc. and executed with the context, as well as
+// DB.ExecContext, DB.QueryContext, and DB.QueryRowContext, use the transaction
+// instead of the DB, for example:
+//
+//	ctx = bun.ContextWithTx(ctx, tx)
+//	// Executed in tx.
+//	err := db.NewSelect().Model(&users).Scan(ctx)
+//
+// RunInTx adds the transaction to the context passed to the function.
+func ContextWithTx(ctx context.Context, tx Tx) context.Context {
+	return context.WithValue(ctx, txCtxKey{}, tx)
+}
 
+// TxFromContext returns the transaction added to the context with ContextWithTx.
+func TxFromContext(ctx context.Context) (Tx, bool) {
+	tx, ok := ctx.Value(txCtxKey{}).(Tx)
+	return tx, ok
+}
+
+// FromContext returns the transaction from the context if it was started by this DB
+// and the DB otherwise, which allows writing functions that work both with and
+// without a transaction.
+func (db *DB) FromContext(ctx context.Context) IDB {
+	if tx, ok := TxFromContext(ctx); ok && tx.db.DB == db.DB {
+		return tx
+	}
+	return db
+}
+
+// runInTx runs the function and commits the transaction or savepoint,
+// or rolls it back if the function returns an error or panics.
+func runInTx(ctx context.Context, tx Tx, fn func(ctx context.Context, tx Tx) error) error {
 	var done bool
 
 	defer func() {
@@ -472,7 +701,7 @@ func (db *DB) RunInTx(
 		}
 	}()
 
-	if err := fn(ctx, tx); err != nil {
+	if err := fn(ContextWithTx(ctx, tx), tx); err != nil {
 		return err
 	}
 
@@ -487,14 +716,23 @@ func (db *DB) Begin() (Tx, error) {
 func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
 	ctx, event := db.beforeQuery(ctx, nil, "BEGIN", nil, "BEGIN", nil)
 	tx, err := db.DB.BeginTx(ctx, opts)
-	db.afterQuery(ctx, event, nil, err)
-	if err != nil {
+	if err = db.afterQuery(ctx, event, nil, err); err != nil {
+		if tx != nil {
+			_ = tx.Rollback()
+		}
+		return Tx{}, err
+	}
+	atomic.AddUint32(&db.stats.TxBegins, 1)
+	if err := db.setSessionVars(ctx, tx); err != nil {
+		_ = tx.Rollback()
 		return Tx{}, err
 	}
 	return Tx{
-		ctx: ctx,
-		db:  db,
-		Tx:  tx,
+		ctx:       ctx,
+		db:        db,
+		opts:      opts,
+		Tx:        tx,
+		callbacks: new(txCallbacks),
 	}, nil
 }
 
@@ -508,16 +746,27 @@ func (tx Tx) Commit() error {
 func (tx Tx) commitTX() error {
 	ctx, event := tx.db.beforeQuery(tx.ctx, nil, "COMMIT", nil, "COMMIT", nil)
 	err := tx.Tx.Commit()
-	tx.db.afterQuery(ctx, event, nil, err)
+	committed := err == nil
+	err = tx.db.afterQuery(ctx, event, nil, err)
+	if committed {
+		atomic.AddUint32(&tx.db.stats.TxCommits, 1)
+		tx.callbacks.commit(tx.ctx)
+	} else {
+		tx.callbacks.rollback(tx.ctx)
+	}
 	return err
 }
 
 func (tx Tx) commitSP() error {
 	if tx.Dialect().Features().Has(feature.MSSavepoint) {
+		tx.callbacks.release()
 		return nil
 	}
 	query := "RELEASE SAVEPOINT " + tx.name
 	_, err := tx.ExecContext(tx.ctx, query)
+	if err == nil {
+		tx.callbacks.release()
+	}
 	return err
 }
 
@@ -531,7 +780,12 @@ func (tx Tx) Rollback() error {
 func (tx Tx) rollbackTX() error {
 	ctx, event := tx.db.beforeQuery(tx.ctx, nil, "ROLLBACK", nil, "ROLLBACK", nil)
 	err := tx.Tx.Rollback()
-	tx.db.afterQuery(ctx, event, nil, err)
+	if err == nil {
+		atomic.AddUint32(&tx.db.stats.TxRollbacks, 1)
+	}
+	err = tx.db.afterQuery(ctx, event, nil, err)
+	// The callbacks are taken on commit, so they only run once.
+	tx.callbacks.rollback(tx.ctx)
 	return err
 }
 
@@ -541,9 +795,32 @@ func (tx Tx) rollbackSP() error {
 		query = "ROLLBACK TRANSACTION " + tx.name
 	}
 	_, err := tx.ExecContext(tx.ctx, query)
+	if err == nil {
+		tx.callbacks.rollback(tx.ctx)
+	}
 	return err
 }
 
+// OnCommit registers a function that is called after the transaction is committed,
+// for example, to invalidate a cache or publish an event. Functions registered
+// in a savepoint are discarded when the savepoint is rolled back.
+func (tx Tx) OnCommit(fn func(ctx context.Context)) {
+	tx.mustCallbacks().onCommit(fn)
+}
+
+// OnRollback registers a function that is called after the transaction or the savepoint
+// is rolled back.
+func (tx Tx) OnRollback(fn func(ctx context.Context)) {
+	tx.mustCallbacks().onRollback(fn)
+}
+
+func (tx Tx) mustCallbacks() *txCallbacks {
+	if tx.callbacks == nil {
+		panic("bun: the transaction was not started with BeginTx or RunInTx")
+	}
+	return tx.callbacks
+}
+
 func (tx Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
 	return tx.ExecContext(context.TODO(), query, args...)
 }
@@ -551,10 +828,11 @@ func (tx Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
 func (tx Tx) ExecContext(
 	ctx context.Context, query string, args ...interface{},
 ) (sql.Result, error) {
-	formattedQuery := tx.db.format(query, args)
+	formattedQuery := tx.db.format(ctx, query, args)
 	ctx, event := tx.db.beforeQuery(ctx, nil, query, args, formattedQuery, nil)
 	res, err := tx.Tx.ExecContext(ctx, formattedQuery)
-	tx.db.afterQuery(ctx, event, res, err)
+	err = tx.db.afterQuery(ctx, event, res, err)
+	tx.invalidateStmtCache(sqlOperation(formattedQuery))
 	return res, err
 }
 
@@ -565,11 +843,16 @@ func (tx Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
 func (tx Tx) QueryContext(
 	ctx context.Context, query string, args ...interface{},
 ) (*sql.Rows, error) {
-	formattedQuery := tx.db.format(query, args)
+	formattedQuery := tx.db.format(ctx, query, args)
 	ctx, event := tx.db.beforeQuery(ctx, nil, query, args, formattedQuery, nil)
 	rows, err := tx.Tx.QueryContext(ctx, formattedQuery)
-	tx.db.afterQuery(ctx, event, nil, err)
-	return rows, err
+	if err = tx.db.afterQuery(ctx, event, nil, err); err != nil {
+		if rows != nil {
+			_ = rows.Close()
+		}
+		return nil, err
+	}
+	return rows, nil
 }
 
 func (tx Tx) QueryRow(query string, args ...interface{}) *sql.Row {
@@ -577,7 +860,7 @@ func (tx Tx) QueryRow(query string, args ...interface{}) *sql.Row {
 }
 
 func (tx Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	formattedQuery := tx.db.format(query, args)
+	formattedQuery := tx.db.format(ctx, query, args)
 	ctx, event := tx.db.beforeQuery(ctx, nil, query, args, formattedQuery, nil)
 	row := tx.Tx.QueryRowContext(ctx, formattedQuery)
 	tx.db.afterQuery(ctx, event, nil, row.Err())
@@ -609,35 +892,39 @@ func (tx Tx) BeginTx(ctx context.Context, _ *sql.TxOptions) (Tx, error) {
 		return Tx{}, err
 	}
 	return Tx{
-		ctx:  ctx,
-		db:   tx.db,
-		Tx:   tx.Tx,
-		name: qName,
+		ctx:       ctx,
+		db:        tx.db,
+		name:      qName,
+		opts:      tx.opts,
+		Tx:        tx.Tx,
+		callbacks: &txCallbacks{parent: tx.callbacks},
 	}, nil
 }
 
+// RunInTx runs the function in a savepoint. If the function returns an error,
+// the transaction is rolled back to the savepoint. Otherwise, the savepoint is released.
+//
+// A savepoint can't change the isolation level or the read-only mode of the transaction,
+// so RunInTx returns an error if non-nil opts differ from the transaction options.
 func (tx Tx) RunInTx(
-	ctx context.Context, _ *sql.TxOptions, fn func(ctx context.Context, tx Tx) error,
+	ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context, tx Tx) error,
 ) error {
-	sp, err := tx.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	var done bool
-
-	defer func() {
-		if !done {
-			_ = sp.Rollback()
+	if opts != nil {
+		var txOpts sql.TxOptions
+		if tx.opts != nil {
+			txOpts = *tx.opts
 		}
-	}()
+		if *opts != txOpts {
+			return fmt.Errorf("bun: nested RunInTx can't change the transaction options "+
+				"(isolation level %s, read-only %t)", txOpts.Isolation, txOpts.ReadOnly)
+		}
+	}
 
-	if err := fn(ctx, sp); err != nil {
+	sp, err := tx.BeginTx(ctx, nil)
+	if err != nil {
 		return err
 	}
-
-	done = true
-	return sp.Commit()
+	return runInTx(ctx, sp, fn)
 }
 
 func (tx Tx) Dialect() schema.Dialect {
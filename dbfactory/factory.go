@@ -0,0 +1,194 @@
+package dbfactory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// Factory creates and inserts models for tests using per-model definitions.
+// Belongs-to relations that are not set are created automatically,
+// so creating an Order also creates the User it belongs to.
+type Factory struct {
+	db bun.IDB
+
+	mu   sync.Mutex
+	defs map[reflect.Type]reflect.Value
+	seqs map[reflect.Type]int
+}
+
+func New(db bun.IDB) *Factory {
+	return &Factory{
+		db:   db,
+		defs: make(map[reflect.Type]reflect.Value),
+		seqs: make(map[reflect.Type]int),
+	}
+}
+
+// Define registers a definition for the model. The fn must have the signature
+// func(model *T, seq int) where T is the model type; seq starts at 1 and is
+// incremented for each created model, which helps to generate unique values.
+func (f *Factory) Define(model interface{}, fn interface{}) *Factory {
+	typ := reflect.TypeOf(model)
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("dbfactory: got %T, wanted a struct pointer", model))
+	}
+
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+	if fnType.Kind() != reflect.Func ||
+		fnType.NumIn() != 2 ||
+		fnType.In(0) != typ ||
+		fnType.In(1).Kind() != reflect.Int ||
+		fnType.NumOut() != 0 {
+		panic(fmt.Errorf("dbfactory: got %s, wanted func(%s, int)", fnType, typ))
+	}
+
+	f.mu.Lock()
+	f.defs[typ.Elem()] = fnValue
+	f.mu.Unlock()
+
+	return f
+}
+
+// Create builds the model from its definition, creates missing belongs-to relations,
+// and inserts the model. Non-zero fields of the model take precedence over the definition.
+//
+// The model must be a struct pointer, e.g. &User{}, or a pointer to a slice of structs
+// or struct pointers, e.g. &users. For slices, a model is created for each slice element.
+func (f *Factory) Create(ctx context.Context, model interface{}) error {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("dbfactory: got %T, wanted a pointer", model)
+	}
+	v = v.Elem()
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return f.create(ctx, v)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					elem.Set(reflect.New(elem.Type().Elem()))
+				}
+				elem = elem.Elem()
+			}
+			if err := f.create(ctx, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("dbfactory: got %T, wanted a struct or slice pointer", model)
+	}
+}
+
+// CreateMany is like Create, but first resizes the slice to n elements.
+func (f *Factory) CreateMany(ctx context.Context, slice interface{}, n int) error {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dbfactory: got %T, wanted a slice pointer", slice)
+	}
+	v = v.Elem()
+
+	if v.Len() < n {
+		v.Set(reflect.AppendSlice(v, reflect.MakeSlice(v.Type(), n-v.Len(), n-v.Len())))
+	} else {
+		v.SetLen(n)
+	}
+	return f.Create(ctx, slice)
+}
+
+func (f *Factory) create(ctx context.Context, strct reflect.Value) error {
+	table := f.db.Dialect().Tables().Get(strct.Type())
+
+	built := reflect.New(strct.Type())
+	if fn, seq, ok := f.next(strct.Type()); ok {
+		fn.Call([]reflect.Value{built, reflect.ValueOf(seq)})
+	}
+	mergeNonZero(built.Elem(), strct)
+	strct.Set(built.Elem())
+
+	for _, rel := range table.Relations {
+		if rel.Type != schema.BelongsToRelation {
+			continue
+		}
+		if err := f.createBelongsTo(ctx, strct, rel); err != nil {
+			return err
+		}
+	}
+
+	_, err := f.db.NewInsert().Model(strct.Addr().Interface()).Exec(ctx)
+	return err
+}
+
+func (f *Factory) createBelongsTo(ctx context.Context, strct reflect.Value, rel *schema.Relation) error {
+	for _, fk := range rel.BasePKs {
+		if !fk.HasZeroValue(strct) {
+			return nil
+		}
+	}
+
+	relValue := rel.Field.Value(strct)
+	if relValue.Kind() == reflect.Ptr {
+		if relValue.IsNil() {
+			relValue.Set(reflect.New(relValue.Type().Elem()))
+		}
+		relValue = relValue.Elem()
+	}
+
+	if hasZeroPK(rel.JoinTable, relValue) {
+		if err := f.create(ctx, relValue); err != nil {
+			return err
+		}
+	}
+
+	for i, fk := range rel.BasePKs {
+		pk := rel.JoinPKs[i]
+		fk.Value(strct).Set(pk.Value(relValue))
+	}
+	return nil
+}
+
+func (f *Factory) next(typ reflect.Type) (reflect.Value, int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, ok := f.defs[typ]
+	if !ok {
+		return reflect.Value{}, 0, false
+	}
+	f.seqs[typ]++
+	return fn, f.seqs[typ], true
+}
+
+func hasZeroPK(table *schema.Table, strct reflect.Value) bool {
+	if len(table.PKs) == 0 {
+		return true
+	}
+	for _, pk := range table.PKs {
+		if !pk.HasZeroValue(strct) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeNonZero copies non-zero exported fields from src to dest.
+func mergeNonZero(dest, src reflect.Value) {
+	typ := src.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		if !typ.Field(i).IsExported() {
+			continue
+		}
+		if fv := src.Field(i); !fv.IsZero() {
+			dest.Field(i).Set(fv)
+		}
+	}
+}
@@ -190,6 +190,13 @@ func (f *Fixture) addRow(ctx context.Context, table *schema.Table, row row) erro
 			continue
 		}
 
+		if rel := referencingRelation(table, key); rel != nil {
+			if err := f.decodeRelation(strct, rel, &value); err != nil {
+				return fmt.Errorf("dbfixture: decoding %s failed: %w", key, err)
+			}
+			continue
+		}
+
 		field, err := table.Field(key)
 		if err != nil {
 			return err
@@ -272,6 +279,43 @@ func (f *Fixture) decodeField(strct reflect.Value, field *schema.Field, value *y
 	return value.Decode(iface)
 }
 
+// decodeRelation fills the foreign key columns from the primary keys of a row
+// loaded earlier, for example, `author: pk10` or `author: john` where john is the _id of the row.
+func (f *Fixture) decodeRelation(strct reflect.Value, rel *schema.Relation, value *yaml.Node) error {
+	var rowID string
+	if err := value.Decode(&rowID); err != nil {
+		return err
+	}
+
+	rows, ok := f.modelRows[rel.JoinTable.TypeName]
+	if !ok {
+		return fmt.Errorf("unknown model=%q", rel.JoinTable.TypeName)
+	}
+	row, ok := rows[rowID]
+	if !ok {
+		return fmt.Errorf("can't find row=%q for model=%q", rowID, rel.JoinTable.TypeName)
+	}
+
+	joinStrct := reflect.ValueOf(row).Elem()
+	for i, basePK := range rel.BasePKs {
+		joinPK := rel.JoinPKs[i]
+		if err := scanFieldValue(strct, basePK, joinPK.Value(joinStrct).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// referencingRelation returns the relation named name that stores foreign keys in the table.
+func referencingRelation(table *schema.Table, name string) *schema.Relation {
+	for _, rel := range table.Relations {
+		if rel.Field.Name == name && rel.References() {
+			return rel
+		}
+	}
+	return nil
+}
+
 func (f *Fixture) dropTable(ctx context.Context, table *schema.Table) error {
 	if _, ok := f.seenTables[table.Name]; ok {
 		return nil
@@ -1,3 +1,14 @@
+// Package feature defines the features that dialects may support, so applications and
+// extensions can build portable queries, for example:
+//
+//	if db.Dialect().Features().Has(feature.Returning) {
+//		q = q.Returning("id")
+//	}
+//
+// Features are bit flags and can be combined. Has reports whether any of the features
+// is supported, e.g. Has(feature.InsertOnConflict|feature.InsertOnDuplicateKey)
+// reports whether the dialect supports upserts. Some features depend on the server
+// version and are only enabled after the dialect is initialized by bun.NewDB.
 package feature
 
 import "github.com/uptrace/bun/internal"
@@ -5,33 +16,101 @@ import "github.com/uptrace/bun/internal"
 type Feature = internal.Flag
 
 const (
-	CTE Feature = 1 << iota
-	WithValues
-	Returning
-	InsertReturning
-	Output // mssql
-	DefaultPlaceholder
-	DoubleColonCast
-	ValuesRow
-	UpdateMultiTable
-	InsertTableAlias
-	UpdateTableAlias
-	DeleteTableAlias
-	AutoIncrement
-	Identity
-	TableCascade
-	TableIdentity
-	TableTruncate
-	InsertOnConflict     // INSERT ... ON CONFLICT
-	InsertOnDuplicateKey // INSERT ... ON DUPLICATE KEY
-	InsertIgnore         // INSERT IGNORE ...
-	TableNotExists
-	OffsetFetch
-	SelectExists
-	UpdateFromTable
-	MSSavepoint
-	GeneratedIdentity
-	CompositeIn      // ... WHERE (A,B) IN ((N, NN), (N, NN)...)
-	UpdateOrderLimit // UPDATE ... ORDER BY ... LIMIT ...
-	DeleteOrderLimit // DELETE ... ORDER BY ... LIMIT ...
+	CTE                  Feature = 1 << iota // WITH ... AS (...)
+	WithValues                               // WITH ... AS (VALUES ...)
+	Returning                                // INSERT/UPDATE/DELETE ... RETURNING
+	InsertReturning                          // INSERT ... RETURNING
+	Output                                   // INSERT/UPDATE/DELETE ... OUTPUT, e.g. MSSQL
+	DefaultPlaceholder                       // DEFAULT in VALUES lists
+	DoubleColonCast                          // value::type casts
+	ValuesRow                                // VALUES ROW(...)
+	UpdateMultiTable                         // UPDATE t1, t2 SET ...
+	InsertTableAlias                         // INSERT INTO table AS alias
+	UpdateTableAlias                         // UPDATE table AS alias
+	DeleteTableAlias                         // DELETE FROM table AS alias
+	AutoIncrement                            // AUTO_INCREMENT or AUTOINCREMENT columns
+	Identity                                 // IDENTITY columns, e.g. MSSQL
+	TableCascade                             // DROP/TRUNCATE TABLE ... CASCADE
+	TableIdentity                            // TRUNCATE TABLE ... RESTART IDENTITY
+	TableTruncate                            // TRUNCATE TABLE instead of DELETE FROM
+	InsertOnConflict                         // INSERT ... ON CONFLICT
+	InsertOnDuplicateKey                     // INSERT ... ON DUPLICATE KEY
+	InsertIgnore                             // INSERT IGNORE ...
+	TableNotExists                           // CREATE TABLE IF NOT EXISTS
+	OffsetFetch                              // OFFSET ... ROWS FETCH NEXT ... ROWS ONLY
+	SelectExists                             // SELECT EXISTS (...)
+	UpdateFromTable                          // UPDATE ... FROM table
+	MSSavepoint                              // SAVE TRANSACTION instead of SAVEPOINT
+	GeneratedIdentity                        // GENERATED BY DEFAULT AS IDENTITY
+	CompositeIn                              // ... WHERE (A,B) IN ((N, NN), (N, NN)...)
+	UpdateOrderLimit                         // UPDATE ... ORDER BY ... LIMIT ...
+	DeleteOrderLimit                         // DELETE ... ORDER BY ... LIMIT ...
+	DeleteReturning                          // DELETE ... RETURNING without UPDATE ... RETURNING, e.g. MariaDB
+	InsertValuesAlias                        // INSERT ... VALUES (...) AS new ON DUPLICATE KEY UPDATE
+	Sequence                                 // CREATE SEQUENCE
+	Lateral                                  // JOIN LATERAL (...)
+	Array                                    // array columns and operators, e.g. PostgreSQL
+	OnConflictConstraint                     // INSERT ... ON CONFLICT ON CONSTRAINT name
+	Regexp                                   // regular expression matching, e.g. ~ or REGEXP
+	ValuesTable                              // FROM (VALUES ...) AS t (column, ...)
+	SystemVersioning                         // system-versioned tables and FOR SYSTEM_TIME
+	DeferrableUnique                         // UNIQUE (...) DEFERRABLE INITIALLY DEFERRED
+	NullsNotDistinct                         // UNIQUE NULLS NOT DISTINCT (...), e.g. PostgreSQL 15
+	ExclusionConstraint                      // EXCLUDE USING gist (...)
 )
+
+var names = []string{
+	"CTE",
+	"WithValues",
+	"Returning",
+	"InsertReturning",
+	"Output",
+	"DefaultPlaceholder",
+	"DoubleColonCast",
+	"ValuesRow",
+	"UpdateMultiTable",
+	"InsertTableAlias",
+	"UpdateTableAlias",
+	"DeleteTableAlias",
+	"AutoIncrement",
+	"Identity",
+	"TableCascade",
+	"TableIdentity",
+	"TableTruncate",
+	"InsertOnConflict",
+	"InsertOnDuplicateKey",
+	"InsertIgnore",
+	"TableNotExists",
+	"OffsetFetch",
+	"SelectExists",
+	"UpdateFromTable",
+	"MSSavepoint",
+	"GeneratedIdentity",
+	"CompositeIn",
+	"UpdateOrderLimit",
+	"DeleteOrderLimit",
+	"DeleteReturning",
+	"InsertValuesAlias",
+	"Sequence",
+	"Lateral",
+	"Array",
+	"OnConflictConstraint",
+	"Regexp",
+	"ValuesTable",
+	"SystemVersioning",
+	"DeferrableUnique",
+	"NullsNotDistinct",
+	"ExclusionConstraint",
+}
+
+// Names returns the names of the features, e.g. []string{"CTE", "Returning"},
+// which is useful to log the capabilities of a dialect.
+func Names(f Feature) []string {
+	var ss []string
+	for i, name := range names {
+		if f.Has(1 << uint(i)) {
+			ss = append(ss, name)
+		}
+	}
+	return ss
+}
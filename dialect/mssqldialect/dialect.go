@@ -23,6 +23,7 @@ const (
 	bitType       = "BIT"
 	nvarcharType  = "NVARCHAR(MAX)"
 	varbinaryType = "VARBINARY(MAX)"
+	uuidType      = "UNIQUEIDENTIFIER"
 )
 
 func init() {
@@ -48,7 +49,10 @@ func New() *Dialect {
 		feature.Output |
 		feature.OffsetFetch |
 		feature.UpdateFromTable |
-		feature.MSSavepoint
+		feature.MSSavepoint |
+		feature.Sequence |
+		feature.ValuesTable |
+		feature.SystemVersioning
 	return d
 }
 
@@ -83,8 +87,19 @@ func (d *Dialect) Tables() *schema.Tables {
 	return d.tables
 }
 
+// RegisterSQLType sets the SQL type of fields with the Go type of the value,
+// e.g. d.RegisterSQLType((*Email)(nil), "citext"). It must be called before
+// the models are used.
+func (d *Dialect) RegisterSQLType(value interface{}, sqlType string) {
+	d.tables.RegisterSQLType(value, sqlType)
+}
+
 func (d *Dialect) OnTable(table *schema.Table) {
 	for _, field := range table.FieldMap {
+		if field.DiscoveredSQLType == sqltype.UUID ||
+			strings.EqualFold(field.UserSQLType, sqltype.UUID) {
+			field.Scan = uuidScanner(field.Scan)
+		}
 		field.DiscoveredSQLType = sqlType(field)
 		if strings.ToUpper(field.UserSQLType) == sqltype.JSON {
 			field.UserSQLType = nvarcharType
@@ -96,6 +111,11 @@ func (d *Dialect) IdentQuote() byte {
 	return '"'
 }
 
+// MaxIdentLen implements schema.IdentLimiter.
+func (d *Dialect) MaxIdentLen() int {
+	return 128
+}
+
 func (*Dialect) AppendTime(b []byte, tm time.Time) []byte {
 	b = append(b, '\'')
 	b = tm.AppendFormat(b, "2006-01-02 15:04:05.999")
@@ -147,10 +167,12 @@ func sqlType(field *schema.Field) string {
 		return datetimeType
 	case sqltype.Boolean:
 		return bitType
-	case sqltype.JSON:
+	case sqltype.JSON, sqltype.Text:
 		return nvarcharType
 	case sqltype.Blob:
 		return varbinaryType
+	case sqltype.UUID:
+		return uuidType
 	}
 	return field.DiscoveredSQLType
 }
@@ -9,3 +9,25 @@ import (
 func scanner(typ reflect.Type) schema.ScannerFunc {
 	return schema.Scanner(typ)
 }
+
+// uuidScanner wraps the scanner of a UUID field, because SQL Server returns
+// UNIQUEIDENTIFIER values with the first three groups in little-endian order.
+func uuidScanner(scan schema.ScannerFunc) schema.ScannerFunc {
+	return func(dest reflect.Value, src interface{}) error {
+		if b, ok := src.([]byte); ok && len(b) == 16 {
+			src = swapUUIDBytes(b)
+		}
+		return scan(dest, src)
+	}
+}
+
+// swapUUIDBytes converts a UUID between the SQL Server mixed-endian
+// and the big-endian byte order.
+func swapUUIDBytes(b []byte) []byte {
+	uuid := make([]byte, 16)
+	copy(uuid, b)
+	uuid[0], uuid[1], uuid[2], uuid[3] = b[3], b[2], b[1], b[0]
+	uuid[4], uuid[5] = b[5], b[4]
+	uuid[6], uuid[7] = b[7], b[6]
+	return uuid
+}
@@ -35,11 +35,19 @@ type Dialect struct {
 	tables   *schema.Tables
 	features feature.Feature
 	loc      *time.Location
+
+	binaryUUID bool
+	mariaDB    bool
+
+	autoIncStep     int64
+	autoIncLockMode int
 }
 
 func New(opts ...DialectOption) *Dialect {
 	d := new(Dialect)
 	d.tables = schema.NewTables(d)
+	d.autoIncStep = 1
+	d.autoIncLockMode = -1
 	d.features = feature.AutoIncrement |
 		feature.DefaultPlaceholder |
 		feature.UpdateMultiTable |
@@ -51,7 +59,8 @@ func New(opts ...DialectOption) *Dialect {
 		feature.SelectExists |
 		feature.CompositeIn |
 		feature.UpdateOrderLimit |
-		feature.DeleteOrderLimit
+		feature.DeleteOrderLimit |
+		feature.Regexp
 
 	for _, opt := range opts {
 		opt(d)
@@ -70,28 +79,83 @@ func WithTimeLocation(loc string) DialectOption {
 	}
 }
 
+// WithBinaryUUID stores UUIDs in BINARY(16) columns instead of CHAR(36).
+// The 16 raw bytes are written and scanned for [16]byte UUID types,
+// e.g. github.com/google/uuid.UUID, even when they implement driver.Valuer.
+func WithBinaryUUID() DialectOption {
+	return func(d *Dialect) {
+		d.binaryUUID = true
+	}
+}
+
+// Init discovers the server version and how the server generates auto-increment IDs
+// using the same query. Servers without InnoDB, which have no innodb_autoinc_lock_mode,
+// need a second query that only selects the version.
 func (d *Dialect) Init(db *sql.DB) {
 	var version string
-	if err := db.QueryRow("SELECT version()").Scan(&version); err != nil {
+	var step, lockMode int64
+	err := db.QueryRow("SELECT version(), @@auto_increment_increment, @@innodb_autoinc_lock_mode").
+		Scan(&version, &step, &lockMode)
+	if err == nil {
+		d.initAutoIncrement(step, lockMode)
+	} else if err := db.QueryRow("SELECT version()").Scan(&version); err != nil {
 		log.Printf("can't discover MySQL version: %s", err)
 		return
 	}
 
 	if strings.Contains(version, "MariaDB") {
-		version = semver.MajorMinor("v" + cleanupVersion(version))
-		if semver.Compare(version, "v10.5.0") >= 0 {
-			d.features |= feature.InsertReturning
-		}
+		d.initMariaDB(semver.MajorMinor("v" + cleanupVersion(version)))
 		return
 	}
 
 	version = "v" + cleanupVersion(version)
 	if semver.Compare(version, "v8.0") >= 0 {
-		d.features |= feature.CTE | feature.WithValues
+		d.features |= feature.CTE
+	}
+	if semver.Compare(version, "v8.0.14") >= 0 {
+		d.features |= feature.Lateral
 	}
 	if semver.Compare(version, "v8.0.16") >= 0 {
 		d.features |= feature.DeleteTableAlias
 	}
+	if semver.Compare(version, "v8.0.19") >= 0 {
+		// The VALUES statement was added in MySQL 8.0.19.
+		d.features |= feature.WithValues | feature.InsertValuesAlias
+	}
+}
+
+// initMariaDB enables the features that MariaDB supports unlike MySQL. MariaDB does not
+// support row aliases in INSERT ... ON DUPLICATE KEY UPDATE, so VALUES() is used instead.
+func (d *Dialect) initMariaDB(version string) {
+	d.mariaDB = true
+	d.features |= feature.DeleteReturning
+	if semver.Compare(version, "v10.3.0") >= 0 {
+		d.features |= feature.Sequence | feature.SystemVersioning
+	}
+	if semver.Compare(version, "v10.5.0") >= 0 {
+		d.features |= feature.InsertReturning
+	}
+}
+
+// initAutoIncrement sets how the server generates auto-increment IDs,
+// which are used to compute the IDs of rows inserted by a single query.
+func (d *Dialect) initAutoIncrement(step, lockMode int64) {
+	if step > 0 {
+		d.autoIncStep = step
+	}
+	d.autoIncLockMode = int(lockMode)
+}
+
+// AutoIncrementStep returns the auto_increment_increment discovered by Init and reports
+// whether the IDs of rows inserted by a single query are consecutive, which is not
+// guaranteed with innodb_autoinc_lock_mode = 2 (interleaved, the default since MySQL 8.0).
+func (d *Dialect) AutoIncrementStep() (step int64, consecutive bool) {
+	return d.autoIncStep, d.autoIncLockMode != 2
+}
+
+// IsMariaDB reports whether Init detected a MariaDB server.
+func (d *Dialect) IsMariaDB() bool {
+	return d.mariaDB
 }
 
 func cleanupVersion(s string) string {
@@ -113,9 +177,21 @@ func (d *Dialect) Tables() *schema.Tables {
 	return d.tables
 }
 
+// RegisterSQLType sets the SQL type of fields with the Go type of the value,
+// e.g. d.RegisterSQLType((*Email)(nil), "citext"). It must be called before
+// the models are used.
+func (d *Dialect) RegisterSQLType(value interface{}, sqlType string) {
+	d.tables.RegisterSQLType(value, sqlType)
+}
+
 func (d *Dialect) OnTable(table *schema.Table) {
 	for _, field := range table.FieldMap {
-		field.DiscoveredSQLType = sqlType(field)
+		isUUID := field.DiscoveredSQLType == sqltype.UUID ||
+			strings.EqualFold(field.UserSQLType, sqltype.UUID)
+		field.DiscoveredSQLType = d.sqlType(field)
+		if isUUID {
+			d.onUUIDField(field)
+		}
 	}
 }
 
@@ -123,6 +199,11 @@ func (d *Dialect) IdentQuote() byte {
 	return '`'
 }
 
+// MaxIdentLen implements schema.IdentLimiter. MySQL limits identifiers to 64 characters.
+func (d *Dialect) MaxIdentLen() int {
+	return 64
+}
+
 func (d *Dialect) AppendTime(b []byte, tm time.Time) []byte {
 	b = append(b, '\'')
 	if d.loc != nil {
@@ -200,6 +281,13 @@ func (*Dialect) AppendJSON(b, jsonb []byte) []byte {
 	return b
 }
 
+func (d *Dialect) AppendUUID(b []byte, uuid [16]byte) []byte {
+	if d.binaryUUID {
+		return d.AppendBytes(b, uuid[:])
+	}
+	return schema.AppendUUID(b, uuid)
+}
+
 func (d *Dialect) DefaultVarcharLen() int {
 	return 255
 }
@@ -208,9 +296,12 @@ func (d *Dialect) AppendSequence(b []byte, _ *schema.Table, _ *schema.Field) []b
 	return append(b, " AUTO_INCREMENT"...)
 }
 
-func sqlType(field *schema.Field) string {
-	if field.DiscoveredSQLType == sqltype.Timestamp {
+func (d *Dialect) sqlType(field *schema.Field) string {
+	switch field.DiscoveredSQLType {
+	case sqltype.Timestamp:
 		return datetimeType
+	case sqltype.UUID:
+		return d.uuidType()
 	}
 	return field.DiscoveredSQLType
 }
@@ -0,0 +1,69 @@
+package mysqldialect
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/uptrace/bun/dialect/sqltype"
+	"github.com/uptrace/bun/schema"
+)
+
+// onUUIDField configures a field that stores UUIDs. MySQL has no UUID type, so the
+// column is CHAR(36) or, with WithBinaryUUID, BINARY(16).
+func (d *Dialect) onUUIDField(field *schema.Field) {
+	if field.CreateTableSQLType == "" || strings.EqualFold(field.CreateTableSQLType, sqltype.UUID) {
+		field.CreateTableSQLType = d.uuidType()
+	}
+
+	if !d.binaryUUID || !isUUIDArray(field.IndirectType) {
+		return
+	}
+
+	// Keep the value from being bound with driver.Valuer, which returns a string.
+	if field.UserSQLType == "" {
+		field.UserSQLType = sqltype.UUID
+	}
+
+	appendUUID := func(fmter schema.Formatter, b []byte, v reflect.Value) []byte {
+		var uuid [16]byte
+		reflect.Copy(reflect.ValueOf(uuid[:]), v)
+		return d.AppendUUID(b, uuid)
+	}
+	if field.IsPtr {
+		field.Append = schema.PtrAppender(appendUUID)
+	} else {
+		field.Append = appendUUID
+	}
+	field.Scan = binaryUUIDScanner(field.Scan)
+}
+
+func (d *Dialect) uuidType() string {
+	if d.binaryUUID {
+		return "BINARY(16)"
+	}
+	return "CHAR(36)"
+}
+
+// binaryUUIDScanner wraps the scanner of a UUID field to copy the 16 bytes of
+// a BINARY(16) column into the array.
+func binaryUUIDScanner(scan schema.ScannerFunc) schema.ScannerFunc {
+	return func(dest reflect.Value, src interface{}) error {
+		b, ok := src.([]byte)
+		if !ok || len(b) != 16 {
+			return scan(dest, src)
+		}
+
+		if dest.Kind() == reflect.Ptr {
+			if dest.IsNil() {
+				dest.Set(reflect.New(dest.Type().Elem()))
+			}
+			dest = dest.Elem()
+		}
+		reflect.Copy(dest, reflect.ValueOf(b))
+		return nil
+	}
+}
+
+func isUUIDArray(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Array && typ.Len() == 16 && typ.Elem().Kind() == reflect.Uint8
+}
@@ -58,6 +58,13 @@ func (d *Dialect) Tables() *schema.Tables {
 	return d.tables
 }
 
+// RegisterSQLType sets the SQL type of fields with the Go type of the value,
+// e.g. d.RegisterSQLType((*Email)(nil), "citext"). It must be called before
+// the models are used.
+func (d *Dialect) RegisterSQLType(value interface{}, sqlType string) {
+	d.tables.RegisterSQLType(value, sqlType)
+}
+
 func (d *Dialect) OnTable(table *schema.Table) {
 	for _, field := range table.FieldMap {
 		d.onField(field)
@@ -72,6 +79,11 @@ func (d *Dialect) IdentQuote() byte {
 	return '"'
 }
 
+// MaxIdentLen implements schema.IdentLimiter.
+func (d *Dialect) MaxIdentLen() int {
+	return 128
+}
+
 func (*Dialect) AppendBytes(b, bs []byte) []byte {
 	if bs == nil {
 		return dialect.AppendNull(b)
@@ -91,7 +103,7 @@ func (d *Dialect) DefaultVarcharLen() int {
 }
 
 func (d *Dialect) AppendSequence(b []byte, table *schema.Table, field *schema.Field) []byte {
-	return append(b, " GENERATED BY DEFAULT AS IDENTITY"...)
+	return field.AppendIdentity(b)
 }
 
 func fieldSQLType(field *schema.Field) string {
@@ -102,6 +114,11 @@ func fieldSQLType(field *schema.Field) string {
 		return sqltype.Integer
 	case sqltype.Boolean:
 		return "number(1,0)"
+	case sqltype.UUID:
+		return "VARCHAR2(36)"
+	case sqltype.Text:
+		// CLOB columns can't be compared with =.
+		return "VARCHAR2(4000)"
 	default:
 		return field.DiscoveredSQLType
 	}
@@ -141,20 +141,63 @@ func (d *Dialect) arrayAppender(typ reflect.Type) schema.AppenderFunc {
 }
 
 func (d *Dialect) arrayElemAppender(typ reflect.Type) schema.AppenderFunc {
+	if fn, _, ok := schema.LookupCodec(typ); ok && fn != nil {
+		return arrayAppendLiteral(fn)
+	}
+	if typ.Implements(rangeValueType) {
+		// Ranges in multiranges are not quoted, e.g. {[1,2),[3,4)}.
+		return arrayAppendRangeValue
+	}
 	if typ.Implements(driverValuerType) {
 		return arrayAppendDriverValue
 	}
 	switch typ.Kind() {
 	case reflect.String:
 		return arrayAppendStringValue
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array:
 		if typ.Elem().Kind() == reflect.Uint8 {
-			return arrayAppendBytesValue
+			if typ.Kind() == reflect.Slice {
+				return arrayAppendBytesValue
+			}
+			break
 		}
+		return d.subarrayAppender(typ)
 	}
 	return schema.Appender(d, typ)
 }
 
+// subarrayAppender appends a nested slice as a dimension of a multidimensional array,
+// e.g. [][]int64{{1, 2}, {3, 4}} as {{1,2},{3,4}}.
+func (d *Dialect) subarrayAppender(typ reflect.Type) schema.AppenderFunc {
+	appendElem := d.arrayElemAppender(typ.Elem())
+	return func(fmter schema.Formatter, b []byte, v reflect.Value) []byte {
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return append(b, "NULL"...)
+		}
+
+		b = append(b, '{')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendElem(fmter, b, v.Index(i))
+		}
+		b = append(b, '}')
+		return b
+	}
+}
+
+// arrayAppendLiteral converts an SQL literal produced by the appender to an array element.
+func arrayAppendLiteral(fn schema.AppenderFunc) schema.AppenderFunc {
+	return func(fmter schema.Formatter, b []byte, v reflect.Value) []byte {
+		text, ok := unquoteLiteral(fn(fmter, nil, v))
+		if !ok {
+			return append(b, "NULL"...)
+		}
+		return arrayAppendString(b, internal.String(text))
+	}
+}
+
 func arrayAppend(fmter schema.Formatter, b []byte, v interface{}) []byte {
 	switch v := v.(type) {
 	case int64:
@@ -194,6 +237,14 @@ func arrayAppendDriverValue(fmter schema.Formatter, b []byte, v reflect.Value) [
 	return arrayAppend(fmter, b, iface)
 }
 
+func arrayAppendRangeValue(fmter schema.Formatter, b []byte, v reflect.Value) []byte {
+	bb, err := v.Interface().(rangeValue).appendRange(b)
+	if err != nil {
+		return dialect.AppendError(b, err)
+	}
+	return bb
+}
+
 func appendStringSliceValue(fmter schema.Formatter, b []byte, v reflect.Value) []byte {
 	ss := v.Convert(sliceStringType).Interface().([]string)
 	return appendStringSlice(b, ss)
@@ -376,7 +427,13 @@ func arrayScanner(typ reflect.Type) schema.ScannerFunc {
 		}
 	}
 
-	scanElem := schema.Scanner(elemType)
+	var scanElem schema.ScannerFunc
+	if isSubarray(elemType) {
+		scanElem = arrayScanner(elemType)
+	} else {
+		scanElem = schema.Scanner(elemType)
+	}
+
 	return func(dest reflect.Value, src interface{}) error {
 		dest = reflect.Indirect(dest)
 		if !dest.CanSet() {
@@ -422,6 +479,18 @@ func arrayScanner(typ reflect.Type) schema.ScannerFunc {
 	}
 }
 
+// isSubarray reports whether the array element is itself an array,
+// i.e. the array is multidimensional.
+func isSubarray(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Slice:
+		return typ.Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return typ.Elem().Kind() != reflect.Uint8 && !typ.Implements(driverValuerType)
+	}
+	return false
+}
+
 func scanStringSliceValue(dest reflect.Value, src interface{}) error {
 	dest = reflect.Indirect(dest)
 	if !dest.CanSet() {
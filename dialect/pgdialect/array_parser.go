@@ -65,6 +65,18 @@ func (p *arrayParser) readNext() error {
 
 		p.elem = b
 		return nil
+	case '{':
+		sub, err := p.readSubarray()
+		if err != nil {
+			return err
+		}
+
+		if p.p.Peek() == ',' {
+			p.p.Advance()
+		}
+
+		p.elem = sub
+		return nil
 	case '[', '(':
 		rng, err := p.p.ReadRange(ch)
 		if err != nil {
@@ -91,3 +103,33 @@ func (p *arrayParser) readNext() error {
 		return nil
 	}
 }
+
+// readSubarray reads a nested array of a multidimensional array, e.g. {1,2} in {{1,2},{3,4}}.
+func (p *arrayParser) readSubarray() ([]byte, error) {
+	b := []byte{'{'}
+	depth := 1
+	var quoted bool
+
+	for p.p.Valid() {
+		c := p.p.Read()
+		b = append(b, c)
+
+		switch {
+		case quoted && c == '\\':
+			if p.p.Valid() {
+				b = append(b, p.p.Read())
+			}
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == '{':
+			depth++
+		case !quoted && c == '}':
+			depth--
+			if depth == 0 {
+				return b, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("pgdialect: can't parse array: unterminated sub-array %q", b)
+}
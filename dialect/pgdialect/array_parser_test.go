@@ -24,6 +24,8 @@ func TestArrayParser(t *testing.T) {
 		{`{"1","2"}`, []string{"1", "2"}},
 		{`{"{1}","{2}"}`, []string{"{1}", "{2}"}},
 		{`{[1,2),[3,4)}`, []string{"[1,2)", "[3,4)"}},
+		{`{{1,2},{3,4}}`, []string{"{1,2}", "{3,4}"}},
+		{`{{"a,}","b"},{NULL,"c"}}`, []string{`{"a,}","b"}`, `{NULL,"c"}`}},
 	}
 
 	for i, test := range tests {
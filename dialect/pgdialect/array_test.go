@@ -0,0 +1,76 @@
+package pgdialect
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun/schema"
+)
+
+func TestMultidimArray(t *testing.T) {
+	fmter := schema.NewFormatter(pgDialect)
+
+	ints := [][]int64{{1, 2}, {3, 4}}
+	b := pgDialect.arrayAppender(reflect.TypeOf(ints))(fmter, nil, reflect.ValueOf(ints))
+	require.Equal(t, `'{{1,2},{3,4}}'`, string(b))
+
+	strs := [][]string{{"a,}", "b"}, {"c", "d'e"}}
+	b = pgDialect.arrayAppender(reflect.TypeOf(strs))(fmter, nil, reflect.ValueOf(strs))
+	require.Equal(t, `'{{"a,}","b"},{"c","d''e"}}'`, string(b))
+
+	var gotInts [][]int64
+	err := arrayScanner(reflect.TypeOf(&gotInts))(reflect.ValueOf(&gotInts), []byte(`{{1,2},{3,4}}`))
+	require.NoError(t, err)
+	require.Equal(t, ints, gotInts)
+
+	var gotStrs [][]string
+	err = arrayScanner(reflect.TypeOf(&gotStrs))(reflect.ValueOf(&gotStrs), []byte(`{{"a,}",b},{c,"d'e"}}`))
+	require.NoError(t, err)
+	require.Equal(t, strs, gotStrs)
+
+	require.Equal(t, "BIGINT[][]", arraySQLType(reflect.TypeOf(ints)))
+}
+
+type arrayCodecColor struct {
+	name string
+}
+
+func TestArrayCodecElem(t *testing.T) {
+	typ := reflect.TypeOf(arrayCodecColor{})
+	schema.RegisterCodec(typ, func(fmter schema.Formatter, b []byte, v reflect.Value) []byte {
+		return fmter.Dialect().AppendString(b, strings.ToUpper(v.Interface().(arrayCodecColor).name))
+	}, func(dest reflect.Value, src interface{}) error {
+		if src == nil {
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		}
+		dest.Set(reflect.ValueOf(arrayCodecColor{name: strings.ToLower(string(src.([]byte)))}))
+		return nil
+	})
+
+	fmter := schema.NewFormatter(pgDialect)
+	colors := []arrayCodecColor{{name: "red"}, {name: "it's"}}
+
+	b := pgDialect.arrayAppender(reflect.TypeOf(colors))(fmter, nil, reflect.ValueOf(colors))
+	require.Equal(t, `'{"RED","IT''S"}'`, string(b))
+
+	var got []arrayCodecColor
+	err := arrayScanner(reflect.TypeOf(&got))(reflect.ValueOf(&got), []byte(`{RED,"IT'S"}`))
+	require.NoError(t, err)
+	require.Equal(t, colors, got)
+}
+
+type arrayJSONModel struct {
+	Array []string          `bun:",array"`
+	Attrs map[string]string `bun:",hstore"`
+	Data  map[string]string
+}
+
+func TestArrayFieldIsNotJSON(t *testing.T) {
+	table := pgDialect.Tables().Get(reflect.TypeOf(arrayJSONModel{}))
+	require.False(t, table.FieldMap["array"].JSON)
+	require.False(t, table.FieldMap["attrs"].JSON)
+	require.True(t, table.FieldMap["data"].JSON)
+}
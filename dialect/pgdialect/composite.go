@@ -0,0 +1,322 @@
+package pgdialect
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/internal"
+	"github.com/uptrace/bun/schema"
+)
+
+// compositeAppender returns an appender for a struct that is stored as a PostgreSQL
+// composite (row) value, for example:
+//
+//	Address Address `bun:",composite:address"`
+//
+// Struct fields are appended in the order of declaration and can be composites
+// or arrays themselves.
+func (d *Dialect) compositeAppender(typ reflect.Type) schema.AppenderFunc {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if fn := d.compositeAppender(typ.Elem()); fn != nil {
+			return schema.PtrAppender(fn)
+		}
+		return nil
+	case reflect.Struct:
+		// ok:
+	default:
+		return nil
+	}
+
+	return func(fmter schema.Formatter, b []byte, v reflect.Value) []byte {
+		text := d.appendComposite(fmter, nil, typ, v)
+		return fmter.Dialect().AppendString(b, internal.String(text))
+	}
+}
+
+// compositeArrayAppender returns an appender for a slice of composites, for example:
+//
+//	Addresses []Address `bun:",array,composite:address"`
+func (d *Dialect) compositeArrayAppender(typ reflect.Type) schema.AppenderFunc {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if fn := d.compositeArrayAppender(typ.Elem()); fn != nil {
+			return schema.PtrAppender(fn)
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		// ok:
+	default:
+		return nil
+	}
+
+	elemType := typ.Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return func(fmter schema.Formatter, b []byte, v reflect.Value) []byte {
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return dialect.AppendNull(b)
+		}
+
+		b = append(b, "'{"...)
+		var text []byte
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b = append(b, ',')
+			}
+
+			elem := v.Index(i)
+			if isPtr {
+				if elem.IsNil() {
+					b = append(b, "NULL"...)
+					continue
+				}
+				elem = elem.Elem()
+			}
+
+			text = d.appendComposite(fmter, text[:0], elemType, elem)
+			b = arrayAppendString(b, internal.String(text))
+		}
+		b = append(b, "}'"...)
+
+		return b
+	}
+}
+
+// appendComposite appends the struct in the composite text format, e.g. (1,"foo bar",).
+func (d *Dialect) appendComposite(
+	fmter schema.Formatter, b []byte, typ reflect.Type, strct reflect.Value,
+) []byte {
+	table := d.tables.Get(typ)
+
+	b = append(b, '(')
+	var lit []byte
+	for i, f := range table.Fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+
+		lit = f.AppendValue(fmter, lit[:0], strct)
+		if text, ok := unquoteLiteral(lit); ok {
+			b = appendCompositeElem(b, text)
+		}
+	}
+	b = append(b, ')')
+
+	return b
+}
+
+// unquoteLiteral converts an SQL literal produced by an appender to its text
+// representation. It returns false for NULL.
+func unquoteLiteral(lit []byte) ([]byte, bool) {
+	if bytes.Equal(lit, []byte("NULL")) {
+		return nil, false
+	}
+	if len(lit) < 2 || lit[0] != '\'' || lit[len(lit)-1] != '\'' {
+		return lit, true
+	}
+	return bytes.ReplaceAll(lit[1:len(lit)-1], []byte("''"), []byte("'")), true
+}
+
+func appendCompositeElem(b, text []byte) []byte {
+	if len(text) > 0 && bytes.IndexAny(text, "\"\\(), \t\n\r") == -1 {
+		return append(b, text...)
+	}
+
+	b = append(b, '"')
+	for _, c := range text {
+		switch c {
+		case '"':
+			b = append(b, '"', '"')
+		case '\\':
+			b = append(b, '\\', '\\')
+		default:
+			b = append(b, c)
+		}
+	}
+	b = append(b, '"')
+	return b
+}
+
+//------------------------------------------------------------------------------
+
+func (d *Dialect) compositeScanner(typ reflect.Type) schema.ScannerFunc {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if fn := d.compositeScanner(typ.Elem()); fn != nil {
+			return schema.PtrScanner(fn)
+		}
+		return nil
+	case reflect.Struct:
+		// ok:
+	default:
+		return nil
+	}
+
+	return func(dest reflect.Value, src interface{}) error {
+		if src == nil {
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		}
+
+		b, err := toBytes(src)
+		if err != nil {
+			return err
+		}
+		return d.scanComposite(d.tables.Get(typ), dest, b)
+	}
+}
+
+func (d *Dialect) compositeArrayScanner(typ reflect.Type) schema.ScannerFunc {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if fn := d.compositeArrayScanner(typ.Elem()); fn != nil {
+			return schema.PtrScanner(fn)
+		}
+		return nil
+	case reflect.Slice:
+		// ok:
+	default:
+		return nil
+	}
+
+	elemType := typ.Elem()
+	scanElem := d.compositeScanner(elemType)
+	if scanElem == nil {
+		return nil
+	}
+
+	return func(dest reflect.Value, src interface{}) error {
+		dest = reflect.Indirect(dest)
+		if !dest.CanSet() {
+			return fmt.Errorf("bun: Scan(non-settable %s)", dest.Type())
+		}
+
+		if src == nil {
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		}
+
+		b, err := toBytes(src)
+		if err != nil {
+			return err
+		}
+
+		slice := reflect.MakeSlice(dest.Type(), 0, 0)
+		p := newArrayParser(b)
+		for p.Next() {
+			elem := reflect.New(elemType).Elem()
+			if text := p.Elem(); text != nil {
+				if err := scanElem(elem, text); err != nil {
+					return err
+				}
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		if err := p.Err(); err != nil {
+			return err
+		}
+
+		dest.Set(slice)
+		return nil
+	}
+}
+
+func (d *Dialect) scanComposite(table *schema.Table, strct reflect.Value, b []byte) error {
+	elems, err := parseComposite(b)
+	if err != nil {
+		return err
+	}
+
+	for i, f := range table.Fields {
+		var src interface{}
+		if i < len(elems) && elems[i] != nil {
+			src = decodeCompositeElem(f, elems[i])
+		}
+		if err := f.ScanValue(strct, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeCompositeElem decodes bytea values that are hex-encoded in the text format.
+func decodeCompositeElem(f *schema.Field, elem []byte) []byte {
+	if f.IndirectType.Kind() != reflect.Slice ||
+		f.IndirectType.Elem().Kind() != reflect.Uint8 ||
+		!bytes.HasPrefix(elem, []byte(`\x`)) {
+		return elem
+	}
+
+	buf := make([]byte, hex.DecodedLen(len(elem)-2))
+	if _, err := hex.Decode(buf, elem[2:]); err != nil {
+		return elem
+	}
+	return buf
+}
+
+// parseComposite splits a composite value in the text format into elements.
+// NULL elements are returned as nil.
+func parseComposite(b []byte) ([][]byte, error) {
+	if len(b) < 2 || b[0] != '(' || b[len(b)-1] != ')' {
+		return nil, fmt.Errorf("pgdialect: can't parse composite: %q", b)
+	}
+	b = b[1 : len(b)-1]
+
+	var elems [][]byte
+	for {
+		var elem []byte
+		var quoted bool
+
+		i := 0
+		for i < len(b) && b[i] != ',' {
+			if b[i] != '"' {
+				elem = append(elem, b[i])
+				i++
+				continue
+			}
+
+			quoted = true
+			i++
+			for i < len(b) {
+				c := b[i]
+				if c == '"' {
+					if i+1 < len(b) && b[i+1] == '"' {
+						elem = append(elem, '"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				if c == '\\' && i+1 < len(b) {
+					elem = append(elem, b[i+1])
+					i += 2
+					continue
+				}
+				elem = append(elem, c)
+				i++
+			}
+		}
+
+		if elem == nil && quoted {
+			elem = []byte{}
+		}
+		elems = append(elems, elem)
+
+		if i >= len(b) {
+			break
+		}
+		b = b[i+1:]
+	}
+	return elems, nil
+}
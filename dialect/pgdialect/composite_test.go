@@ -0,0 +1,70 @@
+package pgdialect
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun/schema"
+)
+
+type compositePoint struct {
+	X int64
+	Y int64
+}
+
+type compositeAddress struct {
+	Street string
+	Zip    *string
+	Tags   []string        `bun:",array"`
+	Point  *compositePoint `bun:",composite:point"`
+}
+
+type compositeModel struct {
+	Address   compositeAddress    `bun:",composite:address"`
+	Addresses []*compositeAddress `bun:",array,composite:address"`
+}
+
+func TestComposite(t *testing.T) {
+	table := pgDialect.Tables().Get(reflect.TypeOf(compositeModel{}))
+	require.Equal(t, "address", table.FieldMap["address"].DiscoveredSQLType)
+	require.Equal(t, "address[]", table.FieldMap["addresses"].DiscoveredSQLType)
+
+	zip := "12345"
+	src := compositeModel{
+		Address: compositeAddress{
+			Street: `Main "St", 1`,
+			Zip:    &zip,
+			Tags:   []string{"a", "b c"},
+			Point:  &compositePoint{X: 1, Y: 2},
+		},
+		Addresses: []*compositeAddress{
+			{Street: "O'Hara"},
+			nil,
+		},
+	}
+
+	fmter := schema.NewFormatter(pgDialect)
+	strct := reflect.ValueOf(&src).Elem()
+
+	field := table.FieldMap["address"]
+	b := field.AppendValue(fmter, nil, strct)
+	require.Equal(t, `'("Main ""St"", 1",12345,"{""a"",""b c""}","(1,2)")'`, string(b))
+
+	field = table.FieldMap["addresses"]
+	b = field.AppendValue(fmter, nil, strct)
+	require.Equal(t, `'{"(O''Hara,,,)",NULL}'`, string(b))
+
+	var dest compositeModel
+	destStrct := reflect.ValueOf(&dest).Elem()
+
+	err := table.FieldMap["address"].ScanValue(destStrct,
+		[]byte(`("Main ""St"", 1",12345,"{a,""b c""}","(1,2)")`))
+	require.NoError(t, err)
+	require.Equal(t, src.Address, dest.Address)
+
+	err = table.FieldMap["addresses"].ScanValue(destStrct,
+		[]byte(`{"(O'Hara,,,)",NULL}`))
+	require.NoError(t, err)
+	require.Equal(t, src.Addresses, dest.Addresses)
+}
@@ -1,10 +1,12 @@
 package pgdialect
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect"
@@ -22,14 +24,18 @@ func init() {
 	}
 }
 
+type DialectOption func(d *Dialect)
+
 type Dialect struct {
 	schema.BaseDialect
 
 	tables   *schema.Tables
 	features feature.Feature
+
+	cockroachDB bool
 }
 
-func New() *Dialect {
+func New(opts ...DialectOption) *Dialect {
 	d := new(Dialect)
 	d.tables = schema.NewTables(d)
 	d.features = feature.CTE |
@@ -46,13 +52,94 @@ func New() *Dialect {
 		feature.TableTruncate |
 		feature.TableNotExists |
 		feature.InsertOnConflict |
+		feature.OnConflictConstraint |
 		feature.SelectExists |
 		feature.GeneratedIdentity |
-		feature.CompositeIn
+		feature.CompositeIn |
+		feature.Sequence |
+		feature.Lateral |
+		feature.Array |
+		feature.Regexp |
+		feature.ValuesTable |
+		feature.DeferrableUnique |
+		feature.NullsNotDistinct |
+		feature.ExclusionConstraint
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
 	return d
 }
 
-func (d *Dialect) Init(*sql.DB) {}
+// WithCockroachDB enables the CockroachDB flavor of the dialect without querying
+// the server version in Init. In this mode:
+//
+//   - autoincrement columns are created as BIGINT DEFAULT unique_rowid() instead of SERIAL;
+//   - UUID primary keys without a default use DEFAULT gen_random_uuid();
+//   - TRUNCATE does not use RESTART IDENTITY.
+//
+// Use SelectQuery.AsOfSystemTime for historical reads and bun.DB.RunInTxRetry to retry
+// transactions aborted by CockroachDB, which are classified by bun.IsRetryableTxError.
+func WithCockroachDB() DialectOption {
+	return func(d *Dialect) {
+		d.setCockroachDB()
+	}
+}
+
+func (d *Dialect) setCockroachDB() {
+	d.cockroachDB = true
+	d.features &^= feature.TableIdentity | feature.DeferrableUnique | feature.NullsNotDistinct |
+		feature.ExclusionConstraint
+}
+
+// IsCockroachDB reports whether the dialect is used with CockroachDB.
+func (d *Dialect) IsCockroachDB() bool {
+	return d.cockroachDB
+}
+
+// initTimeout bounds the version query in Init, so bun.NewDB does not block
+// when the server is unreachable or slow.
+const initTimeout = 3 * time.Second
+
+// Init detects CockroachDB by the server version and disables
+// UNIQUE NULLS NOT DISTINCT before PostgreSQL 15. The version query times out
+// after a few seconds. With WithCockroachDB, Init does not query the server.
+func (d *Dialect) Init(db *sql.DB) {
+	if d.cockroachDB {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), initTimeout)
+	defer cancel()
+
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT version()").Scan(&version); err != nil {
+		// The server may be unavailable yet; assume PostgreSQL.
+		return
+	}
+	if strings.Contains(version, "CockroachDB") {
+		d.setCockroachDB()
+		return
+	}
+	if major, ok := pgMajorVersion(version); ok && major < 15 {
+		d.features &^= feature.NullsNotDistinct
+	}
+}
+
+// pgMajorVersion parses the major version, e.g. 16 in "PostgreSQL 16.2 on x86_64-pc-linux-gnu".
+func pgMajorVersion(version string) (int, bool) {
+	fields := strings.Fields(version)
+	if len(fields) < 2 || fields[0] != "PostgreSQL" {
+		return 0, false
+	}
+	s, _, _ := strings.Cut(fields[1], ".")
+	major, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return major, true
+}
 
 func (d *Dialect) Name() dialect.Name {
 	return dialect.PG
@@ -66,6 +153,13 @@ func (d *Dialect) Tables() *schema.Tables {
 	return d.tables
 }
 
+// RegisterSQLType sets the SQL type of fields with the Go type of the value,
+// e.g. d.RegisterSQLType((*Email)(nil), "citext"). It must be called before
+// the models are used.
+func (d *Dialect) RegisterSQLType(value interface{}, sqlType string) {
+	d.tables.RegisterSQLType(value, sqlType)
+}
+
 func (d *Dialect) OnTable(table *schema.Table) {
 	for _, field := range table.FieldMap {
 		d.onField(field)
@@ -75,7 +169,9 @@ func (d *Dialect) OnTable(table *schema.Table) {
 func (d *Dialect) onField(field *schema.Field) {
 	field.DiscoveredSQLType = fieldSQLType(field)
 
-	if field.AutoIncrement && !field.Identity {
+	if d.cockroachDB {
+		d.onCockroachDBField(field)
+	} else if field.AutoIncrement && !field.Identity {
 		switch field.DiscoveredSQLType {
 		case sqltype.SmallInt:
 			field.CreateTableSQLType = pgTypeSmallSerial
@@ -86,15 +182,44 @@ func (d *Dialect) onField(field *schema.Field) {
 		}
 	}
 
+	if field.Tag.HasOption("composite") {
+		if field.Tag.HasOption("array") {
+			field.Append = d.compositeArrayAppender(field.StructField.Type)
+			field.Scan = d.compositeArrayScanner(field.StructField.Type)
+		} else {
+			field.Append = d.compositeAppender(field.StructField.Type)
+			field.Scan = d.compositeScanner(field.StructField.Type)
+		}
+		if field.Append != nil {
+			field.JSON = false
+			return
+		}
+		panic(fmt.Errorf("pgdialect: composite field %s must be a struct or a slice of structs, got %s",
+			field.GoName, field.StructField.Type))
+	}
+
+	if field.IndirectType == float32sType && isVectorSQLType(field.UserSQLType) {
+		field.Append = appendVectorValue
+		field.Scan = scanVectorValue
+		if field.IsPtr {
+			field.Append = schema.PtrAppender(field.Append)
+			field.Scan = schema.PtrScanner(field.Scan)
+		}
+		field.JSON = false
+		return
+	}
+
 	if field.Tag.HasOption("array") || strings.HasSuffix(field.UserSQLType, "[]") {
 		field.Append = d.arrayAppender(field.StructField.Type)
 		field.Scan = arrayScanner(field.StructField.Type)
+		field.JSON = false
 		return
 	}
 
 	if field.Tag.HasOption("multirange") {
 		field.Append = d.arrayAppender(field.StructField.Type)
 		field.Scan = arrayScanner(field.StructField.Type)
+		field.JSON = false
 		return
 	}
 
@@ -102,6 +227,24 @@ func (d *Dialect) onField(field *schema.Field) {
 	case sqltype.HSTORE:
 		field.Append = d.hstoreAppender(field.StructField.Type)
 		field.Scan = hstoreScanner(field.StructField.Type)
+		field.JSON = false
+	}
+}
+
+func (d *Dialect) onCockroachDBField(field *schema.Field) {
+	if field.UserSQLType != "" || field.SQLDefault != "" {
+		return
+	}
+
+	switch {
+	case field.AutoIncrement && !field.Identity:
+		switch field.DiscoveredSQLType {
+		case sqltype.SmallInt, sqltype.Integer, sqltype.BigInt:
+			field.CreateTableSQLType = sqltype.BigInt
+			field.SQLDefault = "unique_rowid()"
+		}
+	case field.IsPK && strings.EqualFold(field.DiscoveredSQLType, sqltype.UUID):
+		field.SQLDefault = "gen_random_uuid()"
 	}
 }
 
@@ -109,6 +252,12 @@ func (d *Dialect) IdentQuote() byte {
 	return '"'
 }
 
+// MaxIdentLen implements schema.IdentLimiter. Postgres truncates longer identifiers
+// to NAMEDATALEN-1 bytes.
+func (d *Dialect) MaxIdentLen() int {
+	return 63
+}
+
 func (d *Dialect) AppendUint32(b []byte, n uint32) []byte {
 	return strconv.AppendInt(b, int64(int32(n)), 10)
 }
@@ -117,6 +266,6 @@ func (d *Dialect) AppendUint64(b []byte, n uint64) []byte {
 	return strconv.AppendInt(b, int64(n), 10)
 }
 
-func (d *Dialect) AppendSequence(b []byte, _ *schema.Table, _ *schema.Field) []byte {
-	return append(b, " GENERATED BY DEFAULT AS IDENTITY"...)
+func (d *Dialect) AppendSequence(b []byte, _ *schema.Table, field *schema.Field) []byte {
+	return field.AppendIdentity(b)
 }
@@ -3,9 +3,12 @@ package pgdialect
 import (
 	"bytes"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/hex"
 	"fmt"
 	"io"
+	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/uptrace/bun/internal"
@@ -13,22 +16,43 @@ import (
 	"github.com/uptrace/bun/schema"
 )
 
+// MultiRange is a Postgres multirange, e.g. tstzmultirange, which requires the multirange tag option:
+//
+//	Value pgdialect.MultiRange[time.Time] `bun:",multirange,type:tstzmultirange"`
 type MultiRange[T any] []Range[T]
 
+// Range is a Postgres range of time.Time, int32, int64, or float64 values, which are stored
+// in tstzrange, int4range, int8range, and numrange columns by default. Use the type tag option
+// for other range types, e.g. daterange or tsrange:
+//
+//	type Booking struct {
+//		ID     int64                      `bun:",pk,autoincrement"`
+//		RoomID int64
+//		During pgdialect.Range[time.Time]
+//		Days   pgdialect.Range[time.Time] `bun:"type:daterange"`
+//	}
+//
+// Unbounded ranges, e.g. [2024-01-01,), have the RangeBoundUnbounded bound. Dates are
+// appended as timestamps in UTC, so use times at midnight in UTC for daterange.
 type Range[T any] struct {
 	Lower, Upper           T
 	LowerBound, UpperBound RangeBound
+
+	// Empty is true for the empty range, which does not contain any values.
+	Empty bool
 }
 
 type RangeBound byte
 
 const (
+	RangeBoundUnbounded      RangeBound = 0
 	RangeBoundInclusiveLeft  RangeBound = '['
 	RangeBoundInclusiveRight RangeBound = ']'
 	RangeBoundExclusiveLeft  RangeBound = '('
 	RangeBoundExclusiveRight RangeBound = ')'
 )
 
+// NewRange returns the [lower,upper) range, which is the canonical form of Postgres ranges.
 func NewRange[T any](lower, upper T) Range[T] {
 	return Range[T]{
 		Lower:      lower,
@@ -38,19 +62,117 @@ func NewRange[T any](lower, upper T) Range[T] {
 	}
 }
 
-var _ sql.Scanner = (*Range[any])(nil)
+// rangeValue is implemented by all ranges so they can be detected regardless of the value type.
+type rangeValue interface {
+	appendRange(b []byte) ([]byte, error)
+	rangeSQLType() string
+}
+
+var rangeValueType = reflect.TypeOf((*rangeValue)(nil)).Elem()
+
+var (
+	_ schema.QueryAppender = Range[time.Time]{}
+	_ driver.Valuer        = Range[time.Time]{}
+	_ sql.Scanner          = (*Range[time.Time])(nil)
+)
 
-func (r *Range[T]) Scan(anySrc any) (err error) {
-	src := anySrc.([]byte)
+func (r Range[T]) AppendQuery(_ schema.Formatter, b []byte) ([]byte, error) {
+	b = append(b, '\'')
+	b, err := r.appendRange(b)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, '\'')
+	return b, nil
+}
+
+func (r Range[T]) Value() (driver.Value, error) {
+	b, err := r.appendRange(nil)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// appendRange appends the range in the text format, e.g. ["2024-01-01 00:00:00+00:00",).
+func (r Range[T]) appendRange(b []byte) (_ []byte, err error) {
+	if r.Empty {
+		return append(b, "empty"...), nil
+	}
+
+	if r.LowerBound == RangeBoundUnbounded {
+		b = append(b, byte(RangeBoundExclusiveLeft))
+	} else {
+		b = append(b, byte(r.LowerBound))
+		if b, err = appendElem(b, r.Lower); err != nil {
+			return nil, err
+		}
+	}
+
+	b = append(b, ',')
+
+	if r.UpperBound == RangeBoundUnbounded {
+		b = append(b, byte(RangeBoundExclusiveRight))
+	} else {
+		if b, err = appendElem(b, r.Upper); err != nil {
+			return nil, err
+		}
+		b = append(b, byte(r.UpperBound))
+	}
+	return b, nil
+}
+
+func (r Range[T]) rangeSQLType() string {
+	switch any(r.Lower).(type) {
+	case time.Time:
+		return pgTypeTstzRange
+	case int32:
+		return pgTypeInt4Range
+	case int64:
+		return pgTypeInt8Range
+	case float64:
+		return pgTypeNumRange
+	default:
+		return ""
+	}
+}
+
+func (r *Range[T]) Scan(src any) error {
+	var b []byte
+	switch src := src.(type) {
+	case nil:
+		*r = Range[T]{}
+		return nil
+	case []byte:
+		b = src
+	case string:
+		b = []byte(src)
+	default:
+		return fmt.Errorf("pgdialect: can't scan %T into %T", src, r)
+	}
+	return r.scanRange(b)
+}
+
+func (r *Range[T]) scanRange(src []byte) (err error) {
+	*r = Range[T]{}
+
+	if bytes.Equal(src, []byte("empty")) {
+		r.Empty = true
+		return nil
+	}
 
 	if len(src) == 0 {
 		return io.ErrUnexpectedEOF
 	}
 	r.LowerBound = RangeBound(src[0])
+	if r.LowerBound != RangeBoundInclusiveLeft && r.LowerBound != RangeBoundExclusiveLeft {
+		return fmt.Errorf("pgdialect: invalid range lower bound %q", src[0])
+	}
 	src = src[1:]
 
-	src, err = scanElem(&r.Lower, src)
-	if err != nil {
+	if len(src) > 0 && src[0] == ',' {
+		r.LowerBound = RangeBoundUnbounded
+	} else if src, err = scanElem(&r.Lower, src, ','); err != nil {
 		return err
 	}
 
@@ -62,15 +184,23 @@ func (r *Range[T]) Scan(anySrc any) (err error) {
 	}
 	src = src[1:]
 
-	src, err = scanElem(&r.Upper, src)
-	if err != nil {
-		return err
+	unbounded := len(src) == 1
+	if !unbounded {
+		if src, err = scanElem(&r.Upper, src, 0); err != nil {
+			return err
+		}
 	}
 
 	if len(src) == 0 {
 		return io.ErrUnexpectedEOF
 	}
 	r.UpperBound = RangeBound(src[0])
+	if r.UpperBound != RangeBoundInclusiveRight && r.UpperBound != RangeBoundExclusiveRight {
+		return fmt.Errorf("pgdialect: invalid range upper bound %q", src[0])
+	}
+	if unbounded {
+		r.UpperBound = RangeBoundUnbounded
+	}
 	src = src[1:]
 
 	if len(src) > 0 {
@@ -79,47 +209,74 @@ func (r *Range[T]) Scan(anySrc any) (err error) {
 	return nil
 }
 
-var _ schema.QueryAppender = (*Range[any])(nil)
-
-func (r *Range[T]) AppendQuery(fmt schema.Formatter, buf []byte) ([]byte, error) {
-	buf = append(buf, byte(r.LowerBound))
-	buf = appendElem(buf, r.Lower)
-	buf = append(buf, ',')
-	buf = appendElem(buf, r.Upper)
-	buf = append(buf, byte(r.UpperBound))
-	return buf, nil
-}
-
-func appendElem(buf []byte, val any) []byte {
+func appendElem(b []byte, val any) ([]byte, error) {
 	switch val := val.(type) {
 	case time.Time:
-		buf = append(buf, '"')
-		buf = appendTime(buf, val)
-		buf = append(buf, '"')
-		return buf
+		b = append(b, '"')
+		b = appendTime(b, val)
+		b = append(b, '"')
+		return b, nil
+	case int32:
+		return strconv.AppendInt(b, int64(val), 10), nil
+	case int64:
+		return strconv.AppendInt(b, val, 10), nil
+	case float64:
+		return strconv.AppendFloat(b, val, 'f', -1, 64), nil
 	default:
-		panic(fmt.Errorf("unsupported range type: %T", val))
+		return nil, fmt.Errorf("pgdialect: unsupported range type: %T", val)
 	}
 }
 
-func scanElem(ptr any, src []byte) ([]byte, error) {
-	switch ptr := ptr.(type) {
-	case *time.Time:
-		src, str, err := readStringLiteral(src)
+// scanElem scans a quoted or unquoted range element, e.g. "2024-01-01 00:00:00+00" or 2024-01-01.
+// Unquoted elements end at the sep or, when sep is 0, before the last byte, which is the bound.
+func scanElem(ptr any, src []byte, sep byte) ([]byte, error) {
+	var str []byte
+	if len(src) > 0 && src[0] == '"' {
+		var err error
+		src, str, err = readStringLiteral(src)
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		i := len(src) - 1
+		if sep != 0 {
+			i = bytes.IndexByte(src, sep)
+		}
+		if i < 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		str, src = src[:i], src[i:]
+	}
 
+	switch ptr := ptr.(type) {
+	case *time.Time:
 		tm, err := internal.ParseTime(internal.String(str))
 		if err != nil {
 			return nil, err
 		}
 		*ptr = tm
-
-		return src, nil
+	case *int32:
+		n, err := strconv.ParseInt(internal.String(str), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		*ptr = int32(n)
+	case *int64:
+		n, err := strconv.ParseInt(internal.String(str), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		*ptr = n
+	case *float64:
+		f, err := strconv.ParseFloat(internal.String(str), 64)
+		if err != nil {
+			return nil, err
+		}
+		*ptr = f
 	default:
-		panic(fmt.Errorf("unsupported range type: %T", ptr))
+		return nil, fmt.Errorf("pgdialect: unsupported range type: %T", ptr)
 	}
+	return src, nil
 }
 
 func readStringLiteral(src []byte) ([]byte, []byte, error) {
@@ -0,0 +1,80 @@
+package pgdialect
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun/schema"
+)
+
+func TestRange(t *testing.T) {
+	fmter := schema.NewFormatter(pgDialect)
+
+	tm1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tm2 := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	b, err := NewRange(tm1, tm2).AppendQuery(fmter, nil)
+	require.NoError(t, err)
+	require.Equal(t, `'["2024-01-01 00:00:00+00:00","2024-01-05 00:00:00+00:00")'`, string(b))
+
+	b, err = Range[int64]{Lower: 1, LowerBound: RangeBoundInclusiveLeft}.AppendQuery(fmter, nil)
+	require.NoError(t, err)
+	require.Equal(t, `'[1,)'`, string(b))
+
+	b, err = Range[int32]{Empty: true}.AppendQuery(fmter, nil)
+	require.NoError(t, err)
+	require.Equal(t, `'empty'`, string(b))
+
+	_, err = NewRange("a", "b").AppendQuery(fmter, nil)
+	require.Error(t, err)
+
+	var tr Range[time.Time]
+	require.NoError(t, tr.Scan([]byte(`["2024-01-01 00:00:00+00","2024-01-05 00:00:00+00")`)))
+	require.True(t, tr.Lower.Equal(tm1))
+	require.True(t, tr.Upper.Equal(tm2))
+
+	require.NoError(t, tr.Scan("[2024-01-01,2024-01-05)"))
+	require.Equal(t, NewRange(tm1, tm2), tr)
+
+	var ir Range[int32]
+	require.NoError(t, ir.Scan("[1,5)"))
+	require.Equal(t, NewRange[int32](1, 5), ir)
+
+	require.NoError(t, ir.Scan("(,5]"))
+	require.Equal(t, Range[int32]{Upper: 5, UpperBound: RangeBoundInclusiveRight}, ir)
+
+	require.NoError(t, ir.Scan("[1,)"))
+	require.Equal(t, Range[int32]{Lower: 1, LowerBound: RangeBoundInclusiveLeft}, ir)
+
+	require.NoError(t, ir.Scan("empty"))
+	require.Equal(t, Range[int32]{Empty: true}, ir)
+
+	var nr Range[float64]
+	require.NoError(t, nr.Scan("[1.5,2.25]"))
+	require.Equal(t, Range[float64]{
+		Lower: 1.5, Upper: 2.25,
+		LowerBound: RangeBoundInclusiveLeft, UpperBound: RangeBoundInclusiveRight,
+	}, nr)
+
+	require.Error(t, ir.Scan("[1,5"))
+	require.Error(t, ir.Scan("[a,5)"))
+}
+
+func TestRangeSQLType(t *testing.T) {
+	require.Equal(t, "TSTZRANGE", sqlType(reflect.TypeOf(Range[time.Time]{})))
+	require.Equal(t, "INT4RANGE", sqlType(reflect.TypeOf(Range[int32]{})))
+	require.Equal(t, "INT8RANGE", sqlType(reflect.TypeOf(Range[int64]{})))
+	require.Equal(t, "NUMRANGE", sqlType(reflect.TypeOf(Range[float64]{})))
+}
+
+func TestMultiRange(t *testing.T) {
+	fmter := schema.NewFormatter(pgDialect)
+	appendMultiRange := pgDialect.arrayAppender(reflect.TypeOf(MultiRange[int64]{}))
+
+	v := MultiRange[int64]{NewRange[int64](1, 3), NewRange[int64](5, 7)}
+	b := appendMultiRange(fmter, nil, reflect.ValueOf(v))
+	require.Equal(t, `'{[1,3),[5,7)}'`, string(b))
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"net"
+	"net/netip"
 	"reflect"
 
 	"github.com/uptrace/bun/dialect/sqltype"
@@ -30,11 +31,20 @@ const (
 
 	// Binary Data Types
 	pgTypeBytea = "BYTEA" // binary string
+
+	// Range Types
+	pgTypeTstzRange = "TSTZRANGE" // Range of timestamps with a time zone
+	pgTypeInt4Range = "INT4RANGE" // Range of integers
+	pgTypeInt8Range = "INT8RANGE" // Range of bigints
+	pgTypeNumRange  = "NUMRANGE"  // Range of numerics
 )
 
 var (
 	ipType             = reflect.TypeOf((*net.IP)(nil)).Elem()
 	ipNetType          = reflect.TypeOf((*net.IPNet)(nil)).Elem()
+	netipAddrType      = reflect.TypeOf((*netip.Addr)(nil)).Elem()
+	netipPrefixType    = reflect.TypeOf((*netip.Prefix)(nil)).Elem()
+	hardwareAddrType   = reflect.TypeOf((*net.HardwareAddr)(nil)).Elem()
 	jsonRawMessageType = reflect.TypeOf((*json.RawMessage)(nil)).Elem()
 	nullStringType     = reflect.TypeOf((*sql.NullString)(nil)).Elem()
 )
@@ -49,20 +59,29 @@ func fieldSQLType(field *schema.Field) string {
 	}
 
 	if v, ok := field.Tag.Option("composite"); ok {
+		if field.Tag.HasOption("array") {
+			return v + "[]"
+		}
 		return v
 	}
 	if field.Tag.HasOption("hstore") {
 		return sqltype.HSTORE
 	}
+	if field.Tag.HasOption("discriminator") {
+		return sqltype.JSONB
+	}
 
 	if field.Tag.HasOption("array") {
 		switch field.IndirectType.Kind() {
 		case reflect.Slice, reflect.Array:
-			sqlType := sqlType(field.IndirectType.Elem())
-			return sqlType + "[]"
+			return arraySQLType(field.IndirectType)
 		}
 	}
 
+	if field.IndirectType == vectorType {
+		return pgTypeVector
+	}
+
 	if field.DiscoveredSQLType == sqltype.Blob {
 		return pgTypeBytea
 	}
@@ -70,18 +89,43 @@ func fieldSQLType(field *schema.Field) string {
 	return sqlType(field.IndirectType)
 }
 
+// arraySQLType returns the type of the array including all dimensions, e.g. BIGINT[][].
+func arraySQLType(typ reflect.Type) string {
+	elemType := typ.Elem()
+	if isSubarray(elemType) {
+		return arraySQLType(elemType) + "[]"
+	}
+	return sqlType(elemType) + "[]"
+}
+
 func sqlType(typ reflect.Type) string {
+	if elemType := schema.UnwrapNullType(typ); elemType != nil {
+		return sqlType(elemType)
+	}
+
 	switch typ {
 	case nullStringType: // typ.Kind() == reflect.Struct, test for exact match
 		return sqltype.VarChar
-	case ipType:
+	case ipType, netipAddrType:
 		return pgTypeInet
-	case ipNetType:
+	case ipNetType, netipPrefixType:
 		return pgTypeCidr
+	case hardwareAddrType:
+		return pgTypeMacaddr
 	case jsonRawMessageType:
 		return sqltype.JSONB
 	}
 
+	if schema.IsUUIDType(typ) {
+		return sqltype.UUID
+	}
+
+	if typ.Implements(rangeValueType) {
+		if sqlType := reflect.Zero(typ).Interface().(rangeValue).rangeSQLType(); sqlType != "" {
+			return sqlType
+		}
+	}
+
 	sqlType := schema.DiscoverSQLType(typ)
 	switch sqlType {
 	case sqltype.Timestamp:
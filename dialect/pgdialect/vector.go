@@ -0,0 +1,172 @@
+package pgdialect
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+const pgTypeVector = "vector"
+
+var (
+	vectorType   = reflect.TypeOf((*Vector)(nil)).Elem()
+	float32sType = reflect.TypeOf((*[]float32)(nil)).Elem()
+)
+
+// Vector is a pgvector embedding stored in a vector column, for example:
+//
+//	type Item struct {
+//		ID        int64
+//		Embedding pgdialect.Vector `bun:"type:vector(3)"`
+//	}
+//
+// Fields of type []float32 with the vector SQL type use the same encoding.
+type Vector []float32
+
+var (
+	_ schema.QueryAppender = Vector(nil)
+	_ driver.Valuer        = Vector(nil)
+	_ sql.Scanner          = (*Vector)(nil)
+)
+
+func (v Vector) AppendQuery(_ schema.Formatter, b []byte) ([]byte, error) {
+	if v == nil {
+		return dialect.AppendNull(b), nil
+	}
+	b = append(b, '\'')
+	b = appendVector(b, v)
+	b = append(b, '\'')
+	return b, nil
+}
+
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return string(appendVector(nil, v)), nil
+}
+
+func (v *Vector) Scan(src interface{}) error {
+	if src == nil {
+		*v = nil
+		return nil
+	}
+
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	default:
+		return fmt.Errorf("pgdialect: can't scan %T into Vector", src)
+	}
+
+	vec, err := parseVector(s)
+	if err != nil {
+		return err
+	}
+	*v = vec
+	return nil
+}
+
+// appendVector appends the vector in the text format, e.g. [1,2,3].
+func appendVector(b []byte, v []float32) []byte {
+	b = append(b, '[')
+	for i, f := range v {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendFloat(b, float64(f), 'f', -1, 32)
+	}
+	b = append(b, ']')
+	return b
+}
+
+func parseVector(s string) ([]float32, error) {
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("pgdialect: invalid vector: %q", s)
+	}
+
+	s = s[1 : len(s)-1]
+	if s == "" {
+		return []float32{}, nil
+	}
+
+	parts := strings.Split(s, ",")
+	vec := make([]float32, len(parts))
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("pgdialect: invalid vector: %w", err)
+		}
+		vec[i] = float32(f)
+	}
+	return vec, nil
+}
+
+func isVectorSQLType(sqlType string) bool {
+	return strings.HasPrefix(strings.ToLower(sqlType), pgTypeVector)
+}
+
+func appendVectorValue(fmter schema.Formatter, b []byte, v reflect.Value) []byte {
+	if v.IsNil() {
+		return dialect.AppendNull(b)
+	}
+	b, _ = Vector(v.Interface().([]float32)).AppendQuery(fmter, b)
+	return b
+}
+
+func scanVectorValue(dest reflect.Value, src interface{}) error {
+	var vec Vector
+	if err := vec.Scan(src); err != nil {
+		return err
+	}
+	dest.Set(reflect.ValueOf([]float32(vec)))
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// L2Distance returns the pgvector Euclidean distance between the column and the vector,
+// i.e. column <-> vector.
+func L2Distance(column string, v []float32) schema.QueryWithArgs {
+	return vectorDistance("<->", column, v)
+}
+
+// MaxInnerProduct returns the pgvector negative inner product of the column and the vector,
+// i.e. column <#> vector.
+func MaxInnerProduct(column string, v []float32) schema.QueryWithArgs {
+	return vectorDistance("<#>", column, v)
+}
+
+// CosineDistance returns the pgvector cosine distance between the column and the vector,
+// i.e. column <=> vector.
+func CosineDistance(column string, v []float32) schema.QueryWithArgs {
+	return vectorDistance("<=>", column, v)
+}
+
+func vectorDistance(op, column string, v []float32) schema.QueryWithArgs {
+	return schema.SafeQuery("? "+op+" ?", []interface{}{bun.Ident(column), Vector(v)})
+}
+
+// NearestNeighbors orders the query by the distance and limits the number of rows,
+// so an index on the vector column can be used, for example:
+//
+//	db.NewSelect().
+//		Model(&items).
+//		Apply(pgdialect.NearestNeighbors(pgdialect.L2Distance("embedding", vec), 5))
+func NearestNeighbors(
+	distance schema.QueryWithArgs, limit int,
+) func(*bun.SelectQuery) *bun.SelectQuery {
+	return func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.OrderExpr(distance.Query, distance.Args...).Limit(limit)
+	}
+}
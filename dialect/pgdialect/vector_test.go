@@ -0,0 +1,49 @@
+package pgdialect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun/schema"
+)
+
+func TestVector(t *testing.T) {
+	fmter := schema.NewFormatter(pgDialect)
+
+	b, err := Vector{1, 2.5, -3}.AppendQuery(fmter, nil)
+	require.NoError(t, err)
+	require.Equal(t, "'[1,2.5,-3]'", string(b))
+
+	b, err = Vector(nil).AppendQuery(fmter, nil)
+	require.NoError(t, err)
+	require.Equal(t, "NULL", string(b))
+
+	var v Vector
+	require.NoError(t, v.Scan([]byte("[1, 2.5,-3]")))
+	require.Equal(t, Vector{1, 2.5, -3}, v)
+
+	require.NoError(t, v.Scan("[]"))
+	require.Equal(t, Vector{}, v)
+
+	require.Error(t, v.Scan("1,2"))
+	require.Error(t, v.Scan("[a]"))
+}
+
+func TestVectorDistance(t *testing.T) {
+	fmter := schema.NewFormatter(pgDialect)
+
+	tests := []struct {
+		query    schema.QueryWithArgs
+		expected string
+	}{
+		{L2Distance("embedding", []float32{1, 2}), `"embedding" <-> '[1,2]'`},
+		{MaxInnerProduct("embedding", []float32{1, 2}), `"embedding" <#> '[1,2]'`},
+		{CosineDistance("embedding", []float32{1, 2}), `"embedding" <=> '[1,2]'`},
+	}
+	for _, test := range tests {
+		b, err := test.query.AppendQuery(fmter, nil)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, string(b))
+	}
+}
@@ -26,7 +26,17 @@ type Dialect struct {
 	features feature.Feature
 }
 
-func New() *Dialect {
+type DialectOption func(d *Dialect)
+
+// WithRegexp enables feature.Regexp, e.g. bun.Regexp. SQLite does not provide
+// the REGEXP function, so use it only with drivers that register it.
+func WithRegexp() DialectOption {
+	return func(d *Dialect) {
+		d.features |= feature.Regexp
+	}
+}
+
+func New(opts ...DialectOption) *Dialect {
 	d := new(Dialect)
 	d.tables = schema.NewTables(d)
 	d.features = feature.CTE |
@@ -41,6 +51,11 @@ func New() *Dialect {
 		feature.SelectExists |
 		feature.AutoIncrement |
 		feature.CompositeIn
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
 	return d
 }
 
@@ -58,6 +73,13 @@ func (d *Dialect) Tables() *schema.Tables {
 	return d.tables
 }
 
+// RegisterSQLType sets the SQL type of fields with the Go type of the value,
+// e.g. d.RegisterSQLType((*Email)(nil), "citext"). It must be called before
+// the models are used.
+func (d *Dialect) RegisterSQLType(value interface{}, sqlType string) {
+	d.tables.RegisterSQLType(value, sqlType)
+}
+
 func (d *Dialect) OnTable(table *schema.Table) {
 	for _, field := range table.FieldMap {
 		d.onField(field)
@@ -117,6 +139,8 @@ func fieldSQLType(field *schema.Field) string {
 		// INTEGER PRIMARY KEY is an alias for the ROWID.
 		// It is safe to convert all ints to INTEGER, because SQLite types don't have size.
 		return sqltype.Integer
+	case sqltype.UUID:
+		return "TEXT"
 	default:
 		return field.DiscoveredSQLType
 	}
@@ -8,9 +8,11 @@ const (
 	Real            = "REAL"
 	DoublePrecision = "DOUBLE PRECISION"
 	VarChar         = "VARCHAR"
+	Text            = "TEXT"
 	Blob            = "BLOB"
 	Timestamp       = "TIMESTAMP"
 	JSON            = "JSON"
 	JSONB           = "JSONB"
 	HSTORE          = "HSTORE"
+	UUID            = "UUID"
 )
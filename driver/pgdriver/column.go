@@ -19,6 +19,8 @@ const (
 	pgFloat4 = 700
 	pgFloat8 = 701
 
+	pgNumeric = 1700
+
 	pgText    = 25
 	pgVarchar = 1043
 	pgBytea   = 17
@@ -28,6 +30,38 @@ const (
 	pgTimestamptz = 1184
 )
 
+func columnTypeName(dataType int32) string {
+	switch dataType {
+	case pgBool:
+		return "BOOL"
+	case pgInt2:
+		return "INT2"
+	case pgInt4:
+		return "INT4"
+	case pgInt8:
+		return "INT8"
+	case pgFloat4:
+		return "FLOAT4"
+	case pgFloat8:
+		return "FLOAT8"
+	case pgNumeric:
+		return "NUMERIC"
+	case pgText:
+		return "TEXT"
+	case pgVarchar:
+		return "VARCHAR"
+	case pgBytea:
+		return "BYTEA"
+	case pgDate:
+		return "DATE"
+	case pgTimestamp:
+		return "TIMESTAMP"
+	case pgTimestamptz:
+		return "TIMESTAMPTZ"
+	}
+	return ""
+}
+
 func readColumnValue(rd *reader, dataType int32, dataLen int) (interface{}, error) {
 	if dataLen == -1 {
 		return nil, nil
@@ -384,6 +384,15 @@ func (r *rows) Columns() []string {
 	return r.rowDesc.names
 }
 
+var _ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	if r.rowDesc == nil {
+		return ""
+	}
+	return columnTypeName(r.rowDesc.types[index])
+}
+
 func (r *rows) Close() error {
 	if r.closed {
 		return nil
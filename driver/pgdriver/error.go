@@ -21,6 +21,11 @@ func (err Error) Field(k byte) string {
 	return err.m[k]
 }
 
+// SQLState returns the SQLSTATE error code, e.g. 40001 for serialization failures.
+func (err Error) SQLState() string {
+	return err.Field('C')
+}
+
 // IntegrityViolation reports whether the error is a part of
 // Integrity Constraint Violation class of errors.
 //
@@ -0,0 +1,98 @@
+package pgxdriver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// DB is a bun.DB that runs queries on a pgx pool. The query builder works as usual and
+// uses the pool through database/sql, while Query, CopyFrom, and Pool use the pool directly,
+// e.g. to scan rows using the binary protocol or to LISTEN for notifications.
+type DB struct {
+	*bun.DB
+	pool *pgxpool.Pool
+}
+
+func NewDB(pool *pgxpool.Pool, opts ...bun.DBOption) *DB {
+	return &DB{
+		DB:   bun.NewDB(stdlib.OpenDBFromPool(pool), pgdialect.New(), opts...),
+		pool: pool,
+	}
+}
+
+// Pool returns the pgx pool.
+func (db *DB) Pool() *pgxpool.Pool {
+	return db.pool
+}
+
+// Query formats the query built with bun and executes it on the pool, so the rows
+// can be scanned with pgx, for example:
+//
+//	rows, err := db.Query(ctx, db.NewSelect().Model((*User)(nil)).Column("id", "name"))
+//	users, err := pgx.CollectRows(rows, pgx.RowToStructByPos[UserRow])
+func (db *DB) Query(ctx context.Context, q schema.QueryAppender) (pgx.Rows, error) {
+	b, err := q.AppendQuery(db.Formatter(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return db.pool.Query(ctx, string(b))
+}
+
+// CopyFrom inserts the models, e.g. []User or []*User, using the COPY protocol
+// and returns the number of inserted rows. Autoincrement, identity, and generated
+// columns are skipped. Other columns are copied as is, so SQL defaults are not
+// applied to zero values, and zero values of nullzero fields are copied as NULL.
+func (db *DB) CopyFrom(ctx context.Context, models interface{}) (int64, error) {
+	slice := reflect.Indirect(reflect.ValueOf(models))
+	if slice.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("pgxdriver: CopyFrom requires a slice, got %T", models)
+	}
+
+	typ := slice.Type().Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return 0, fmt.Errorf("pgxdriver: CopyFrom requires a slice of structs, got %T", models)
+	}
+
+	table := db.Dialect().Tables().Get(typ)
+
+	fields := make([]*schema.Field, 0, len(table.Fields))
+	columns := make([]string, 0, len(table.Fields))
+	for _, f := range table.Fields {
+		if _, ok := f.GeneratedExpr(); ok || f.AutoIncrement || f.Identity {
+			continue
+		}
+		fields = append(fields, f)
+		columns = append(columns, f.Name)
+	}
+
+	rows := pgx.CopyFromSlice(slice.Len(), func(i int) ([]interface{}, error) {
+		strct := reflect.Indirect(slice.Index(i))
+		if !strct.IsValid() {
+			return nil, fmt.Errorf("pgxdriver: CopyFrom got a nil model at index %d", i)
+		}
+
+		values := make([]interface{}, len(fields))
+		for j, f := range fields {
+			if f.NullZero && f.HasZeroValue(strct) {
+				continue
+			}
+			values[j] = f.Value(strct).Interface()
+		}
+		return values, nil
+	})
+
+	return db.pool.CopyFrom(ctx, pgx.Identifier(strings.Split(table.Name, ".")), columns, rows)
+}
@@ -0,0 +1,555 @@
+package bun
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"database/sql"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun/schema"
+)
+
+// EntityCache stores the rows of models that opt in with the cache table tag option,
+// for example:
+//
+//	type User struct {
+//		bun.BaseModel `bun:"table:users,cache:5m"`
+//
+//		ID   int64 `bun:",pk,autoincrement"`
+//		Name string
+//	}
+//
+// Use NewLRUEntityCache for an in-process cache. Other stores, e.g. Redis, only need
+// a thin adapter:
+//
+//	type redisCache struct{ rdb *redis.Client }
+//
+//	func (c redisCache) Get(ctx context.Context, key string) ([]byte, error) {
+//		b, err := c.rdb.Get(ctx, key).Bytes()
+//		if err == redis.Nil {
+//			return nil, nil
+//		}
+//		return b, err
+//	}
+//
+//	func (c redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+//		return c.rdb.Set(ctx, key, value, ttl).Err()
+//	}
+//
+//	func (c redisCache) Delete(ctx context.Context, keys ...string) error {
+//		return c.rdb.Del(ctx, keys...).Err()
+//	}
+type EntityCache interface {
+	// Get returns the value of the key or nil if the key is not found.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores the value for the ttl. A zero ttl means the value does not expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the keys.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// WithEntityCache enables the second-level cache of the models that opt in with
+// the cache table tag option. Select queries that only look up a single row with WherePK,
+// e.g. db.NewSelect().Model(&User{ID: 1}).WherePK().Scan(ctx), are served from the cache,
+// which stores the rows for ttl unless the tag option sets a different TTL.
+//
+// Insert (on conflict or replace), update, delete, merge, truncate, and drop queries
+// of the same model remove the changed rows from the cache, or all rows of the table
+// when the rows can't be identified by the primary keys of the model. Changes made with
+// raw queries or by other applications are not tracked, so keep the TTL short.
+// Queries in transactions and on dedicated connections are not served from the cache,
+// and the rows changed in a transaction are removed again when it commits.
+// A lookup that races with a change can still cache the old row until it expires.
+func WithEntityCache(cache EntityCache, ttl time.Duration) DBOption {
+	return func(db *DB) {
+		if cache != nil {
+			db.entityCache = &entityCache{cache: cache, ttl: ttl}
+		}
+	}
+}
+
+type entityCache struct {
+	cache EntityCache
+	ttl   time.Duration
+}
+
+func (c *entityCache) genKey(table *schema.Table) string {
+	return "bun:" + table.Name + ":gen"
+}
+
+func (c *entityCache) rowKey(table *schema.Table, gen, pk string) string {
+	return "bun:" + table.Name + ":" + gen + ":" + pk
+}
+
+// gen returns the generation of the table, which is a part of row keys, so all rows
+// of the table are invalidated at once by changing the generation. With create,
+// a missing generation is created, otherwise an empty string is returned.
+func (c *entityCache) gen(ctx context.Context, table *schema.Table, create bool) (string, error) {
+	b, err := c.cache.Get(ctx, c.genKey(table))
+	if err != nil {
+		return "", err
+	}
+	if b != nil {
+		return string(b), nil
+	}
+	if !create {
+		return "", nil
+	}
+	return c.newGen(ctx, table)
+}
+
+func (c *entityCache) newGen(ctx context.Context, table *schema.Table) (string, error) {
+	gen := strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := c.cache.Set(ctx, c.genKey(table), []byte(gen), 0); err != nil {
+		return "", err
+	}
+	return gen, nil
+}
+
+// invalidate removes the rows with the primary keys from the cache
+// or all rows of the table when pks is nil.
+func (c *entityCache) invalidate(ctx context.Context, table *schema.Table, pks []string) error {
+	if pks == nil {
+		_, err := c.newGen(ctx, table)
+		return err
+	}
+
+	gen, err := c.gen(ctx, table, false)
+	if err != nil || gen == "" {
+		return err
+	}
+
+	keys := make([]string, len(pks))
+	for i, pk := range pks {
+		keys[i] = c.rowKey(table, gen, pk)
+	}
+	return c.cache.Delete(ctx, keys...)
+}
+
+func (c *entityCache) load(ctx context.Context, key string) (*entityCacheRow, error) {
+	b, err := c.cache.Get(ctx, key)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	row := new(entityCacheRow)
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func (c *entityCache) store(
+	ctx context.Context, table *schema.Table, key string, row *entityCacheRow,
+) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(row); err != nil {
+		return err
+	}
+	ttl := c.ttl
+	if table.EntityCacheTTL > 0 {
+		ttl = table.EntityCacheTTL
+	}
+	return c.cache.Set(ctx, key, buf.Bytes(), ttl)
+}
+
+// appendEntityPK appends the primary key values of the struct as a part of the row key.
+// It returns false if any of the values is zero.
+func appendEntityPK(fmter schema.Formatter, b []byte, table *schema.Table, strct reflect.Value) ([]byte, bool) {
+	for i, f := range table.PKs {
+		if f.HasZeroValue(strct) {
+			return nil, false
+		}
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = f.AppendValue(fmter, b, strct)
+	}
+	return b, true
+}
+
+//------------------------------------------------------------------------------
+
+// entityCacheRow is a cached row, which is scanned into the model like a row
+// returned by the database.
+type entityCacheRow struct {
+	Columns []string
+	Values  []entityCacheValue
+}
+
+const (
+	entityNull uint8 = iota
+	entityInt
+	entityFloat
+	entityBool
+	entityBytes
+	entityString
+	entityTime
+)
+
+// entityCacheValue is a driver value that can be encoded with gob without registering types.
+type entityCacheValue struct {
+	Kind  uint8
+	Int   int64
+	Float float64
+	Bytes []byte
+	Time  time.Time
+}
+
+// newEntityCacheValue copies the driver value and returns false
+// if the type of the value is not supported.
+func newEntityCacheValue(src interface{}) (entityCacheValue, bool) {
+	switch src := src.(type) {
+	case nil:
+		return entityCacheValue{Kind: entityNull}, true
+	case int64:
+		return entityCacheValue{Kind: entityInt, Int: src}, true
+	case float64:
+		return entityCacheValue{Kind: entityFloat, Float: src}, true
+	case bool:
+		v := entityCacheValue{Kind: entityBool}
+		if src {
+			v.Int = 1
+		}
+		return v, true
+	case []byte:
+		return entityCacheValue{Kind: entityBytes, Bytes: bytes.Clone(src)}, true
+	case string:
+		return entityCacheValue{Kind: entityString, Bytes: []byte(src)}, true
+	case time.Time:
+		return entityCacheValue{Kind: entityTime, Time: src}, true
+	default:
+		return entityCacheValue{}, false
+	}
+}
+
+func (v *entityCacheValue) value() interface{} {
+	switch v.Kind {
+	case entityInt:
+		return v.Int
+	case entityFloat:
+		return v.Float
+	case entityBool:
+		return v.Int != 0
+	case entityBytes:
+		if v.Bytes == nil {
+			return []byte{}
+		}
+		return v.Bytes
+	case entityString:
+		return string(v.Bytes)
+	case entityTime:
+		return v.Time
+	default:
+		return nil
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// entityCacheModel scans a row into the struct model and records the values
+// of the row, so the row can be cached.
+type entityCacheModel struct {
+	*structTableModel
+
+	row       entityCacheRow
+	cacheable bool
+}
+
+var _ TableModel = (*entityCacheModel)(nil)
+
+func (m *entityCacheModel) ScanRows(ctx context.Context, rows *sql.Rows) (int, error) {
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	m.row = entityCacheRow{
+		Columns: append([]string(nil), columns...),
+		Values:  make([]entityCacheValue, len(columns)),
+	}
+	m.cacheable = true
+
+	m.setColumns(columns)
+	if err := m.scanRow(ctx, rows, makeDest(m, len(columns))); err != nil {
+		return 0, err
+	}
+
+	n := 1
+	for rows.Next() {
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (m *entityCacheModel) Scan(src interface{}) error {
+	if i := m.scanIndex; i < len(m.row.Values) {
+		v, ok := newEntityCacheValue(src)
+		if !ok {
+			m.cacheable = false
+		}
+		m.row.Values[i] = v
+	}
+	return m.structTableModel.Scan(src)
+}
+
+// scanEntityCacheRow scans the cached row into the model.
+func (m *structTableModel) scanEntityCacheRow(ctx context.Context, row *entityCacheRow) error {
+	if len(row.Values) != len(row.Columns) {
+		return fmt.Errorf("bun: cached %s row is corrupted", m.table.TypeName)
+	}
+
+	m.setColumns(append([]string(nil), row.Columns...))
+
+	if err := m.BeforeScanRow(ctx); err != nil {
+		return err
+	}
+
+	m.scanIndex = 0
+	for i := range row.Values {
+		if err := m.Scan(row.Values[i].value()); err != nil {
+			return err
+		}
+	}
+
+	return m.AfterScanRow(ctx)
+}
+
+//------------------------------------------------------------------------------
+
+// entityCacheKey returns the cache key of the row when the query only looks up
+// a single row of a cached model by its primary key.
+func (q *SelectQuery) entityCacheKey(ctx context.Context, dest []interface{}) (string, bool) {
+	c := q.db.entityCache
+	if c == nil || q.table == nil || !q.table.EntityCache || len(dest) > 0 {
+		return "", false
+	}
+	if _, ok := q.conn.(*sql.DB); !ok {
+		return "", false
+	}
+	if _, ok := q.txFromContext(ctx); ok {
+		return "", false
+	}
+
+	model, ok := q.model.(*structTableModel)
+	if !ok || !model.strct.IsValid() || len(model.getJoins()) > 0 || !q.isWherePKOnly() {
+		return "", false
+	}
+
+	if len(q.with) > 0 || len(q.union) > 0 || len(q.tables) > 0 || len(q.columns) > 0 ||
+		q.modelTableName.Query != "" || len(q.distinctOn) > 0 || len(q.joins) > 0 ||
+		len(q.group) > 0 || len(q.having) > 0 || len(q.order) > 0 ||
+		q.limit != 0 || q.offset != 0 || q.flags != 0 ||
+		q.selFor.Query != "" || q.asOf.Query != "" || q.sysTime.Query != "" ||
+		q.use != nil || q.ignore != nil || q.force != nil ||
+		len(q.defaultScopes()) > 0 {
+		return "", false
+	}
+
+	pk, ok := appendEntityPK(q.db.Formatter(), nil, q.table, model.strct)
+	if !ok {
+		return "", false
+	}
+
+	gen, err := c.gen(ctx, q.table, true)
+	if err != nil {
+		return "", false
+	}
+	return c.rowKey(q.table, gen, string(pk)), true
+}
+
+// isWherePKOnly reports whether the only condition of the query is WherePK
+// with the primary keys of the table.
+func (q *whereBaseQuery) isWherePKOnly() bool {
+	return len(q.where) == 0 && q.isWherePK()
+}
+
+// isWherePK reports whether the query has WherePK with the primary keys of the table.
+func (q *whereBaseQuery) isWherePK() bool {
+	if q.table == nil || len(q.whereFields) == 0 || len(q.whereFields) != len(q.table.PKs) {
+		return false
+	}
+	for i, f := range q.whereFields {
+		if f != q.table.PKs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// scanEntityCache scans the cached row into the model and reports whether the row was found.
+// Cache errors are ignored and the row is selected from the database.
+func (q *SelectQuery) scanEntityCache(ctx context.Context, key string) (bool, error) {
+	row, err := q.db.entityCache.load(ctx, key)
+	if err != nil || row == nil {
+		return false, nil
+	}
+
+	model := q.model.(*structTableModel)
+	if q.scanFlags != 0 {
+		model.setScanFlags(q.scanFlags)
+	}
+	if err := model.scanEntityCacheRow(ctx, row); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// invalidateEntities removes the rows changed by the query from the entity cache.
+// With byPK, the rows are identified by the primary keys of the model,
+// otherwise all rows of the table are removed.
+func (q *baseQuery) invalidateEntities(ctx context.Context, byPK bool) error {
+	c := q.db.entityCache
+	if c == nil || q.table == nil || !q.table.EntityCache {
+		return nil
+	}
+
+	var pks []string
+	if byPK {
+		pks = q.entityPKs()
+	}
+	table := q.table
+
+	idb := q.idb
+	if tx, ok := q.txFromContext(ctx); ok {
+		idb = tx
+	}
+	if tx, ok := idb.(Tx); ok && tx.callbacks != nil {
+		// Remove the rows again in case they were cached before the transaction commits.
+		tx.OnCommit(func(ctx context.Context) {
+			_ = c.invalidate(ctx, table, pks)
+		})
+	}
+	return c.invalidate(ctx, table, pks)
+}
+
+// entityPKs returns the primary keys of the model rows or nil if any of them is zero.
+func (q *baseQuery) entityPKs() []string {
+	fmter := q.db.Formatter()
+	switch model := q.tableModel.(type) {
+	case *structTableModel:
+		if !model.strct.IsValid() {
+			return nil
+		}
+		pk, ok := appendEntityPK(fmter, nil, q.table, model.strct)
+		if !ok {
+			return nil
+		}
+		return []string{string(pk)}
+	case *sliceTableModel:
+		pks := make([]string, 0, model.slice.Len())
+		for i := 0; i < model.slice.Len(); i++ {
+			pk, ok := appendEntityPK(fmter, nil, q.table, indirect(model.slice.Index(i)))
+			if !ok {
+				return nil
+			}
+			pks = append(pks, string(pk))
+		}
+		return pks
+	default:
+		return nil
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// LRUEntityCache is an in-process EntityCache that keeps up to size least recently used keys.
+type LRUEntityCache struct {
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List // of *lruEntityCacheEntry, the most recently used first
+	items map[string]*list.Element
+}
+
+var _ EntityCache = (*LRUEntityCache)(nil)
+
+type lruEntityCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func NewLRUEntityCache(size int) *LRUEntityCache {
+	return &LRUEntityCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUEntityCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, nil
+	}
+
+	entry := el.Value.(*lruEntityCacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, nil
+	}
+
+	c.ll.MoveToFront(el)
+	return entry.value, nil
+}
+
+func (c *LRUEntityCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &lruEntityCacheEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	if el, ok := c.items[key]; ok {
+		el.Value = entry
+		c.ll.MoveToFront(el)
+		return nil
+	}
+
+	c.items[key] = c.ll.PushFront(entry)
+	for c.ll.Len() > c.size {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.items, el.Value.(*lruEntityCacheEntry).key)
+	}
+	return nil
+}
+
+func (c *LRUEntityCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.ll.Remove(el)
+			delete(c.items, key)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of cached keys, including expired keys that were not evicted yet.
+func (c *LRUEntityCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
@@ -0,0 +1,283 @@
+package bunaudit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// Entry is a row of the audit log. The table can be created with:
+//
+//	db.NewCreateTable().Model((*bunaudit.Entry)(nil)).Exec(ctx)
+type Entry struct {
+	bun.BaseModel `bun:"table:audit_log,alias:audit"`
+
+	ID         int64 `bun:",pk,autoincrement"`
+	Actor      string
+	Operation  string
+	TableName  string
+	PrimaryKey map[string]interface{}
+	OldValues  map[string]interface{}
+	NewValues  map[string]interface{}
+	CreatedAt  time.Time
+}
+
+type actorKey struct{}
+
+// ContextWithActor returns a copy of the context with the actor, e.g. the user id,
+// that is recorded in audit entries.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with ContextWithActor.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// QueryHook writes an audit entry for every row inserted, updated, or deleted
+// using the models configured with WithModels:
+//
+//	db.AddQueryHook(bunaudit.NewQueryHook(bunaudit.WithModels((*User)(nil))))
+//
+// Entries are written using the same connection as the query, so queries executed
+// in a transaction are audited in that transaction. Old values are loaded by primary keys
+// with a single query before updates and deletes, which means that queries without
+// a model value, e.g. updates with a WHERE clause, are audited with a single entry
+// without values. If the old values can't be loaded, no entries are written
+// and the query returns the error.
+type QueryHook struct {
+	models    map[reflect.Type]struct{}
+	tableName string
+	actor     func(ctx context.Context) string
+}
+
+var _ bun.QueryHook = (*QueryHook)(nil)
+
+func NewQueryHook(opts ...Option) *QueryHook {
+	h := &QueryHook{
+		models: make(map[reflect.Type]struct{}),
+		actor:  ActorFromContext,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+type (
+	oldValuesKey    struct{}
+	oldValuesErrKey struct{}
+)
+
+func (h *QueryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	q, table, ok := h.auditedQuery(event)
+	if !ok {
+		return ctx
+	}
+
+	switch q.(type) {
+	case *bun.UpdateQuery, *bun.DeleteQuery:
+	default:
+		return ctx
+	}
+
+	if event.Stash == nil {
+		event.Stash = make(map[interface{}]interface{})
+	}
+
+	oldValues, err := loadOldValues(ctx, queryDB(q), table, modelRows(q.GetModel()))
+	if err != nil {
+		event.Stash[oldValuesErrKey{}] = err
+		return ctx
+	}
+	event.Stash[oldValuesKey{}] = oldValues
+
+	return ctx
+}
+
+func (h *QueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	if event.Err != nil {
+		return
+	}
+
+	q, table, ok := h.auditedQuery(event)
+	if !ok {
+		return
+	}
+
+	if err, ok := event.Stash[oldValuesErrKey{}]; ok {
+		event.Err = err.(error)
+		return
+	}
+
+	var oldValues []map[string]interface{}
+	if v, ok := event.Stash[oldValuesKey{}]; ok {
+		oldValues = v.([]map[string]interface{})
+	}
+
+	now := time.Now()
+	newEntry := func() *Entry {
+		return &Entry{
+			Actor:     h.actor(ctx),
+			Operation: q.Operation(),
+			TableName: table.Name,
+			CreatedAt: now,
+		}
+	}
+
+	var entries []*Entry
+	rows := modelRows(q.GetModel())
+	for i, strct := range rows {
+		entry := newEntry()
+		entry.PrimaryKey = fieldValues(table.PKs, strct)
+		if i < len(oldValues) {
+			entry.OldValues = oldValues[i]
+		}
+		if _, ok := q.(*bun.DeleteQuery); !ok {
+			entry.NewValues = fieldValues(table.Fields, strct)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		entries = append(entries, newEntry())
+	}
+
+	iq := queryDB(q).NewInsert().Model(&entries)
+	if h.tableName != "" {
+		iq = iq.ModelTableExpr("?", bun.Ident(h.tableName))
+	}
+	if _, err := iq.Exec(ctx); err != nil {
+		event.Err = err
+	}
+}
+
+func (h *QueryHook) auditedQuery(event *bun.QueryEvent) (bun.Query, *schema.Table, bool) {
+	switch event.IQuery.(type) {
+	case *bun.InsertQuery, *bun.UpdateQuery, *bun.DeleteQuery:
+	default:
+		return nil, nil, false
+	}
+
+	tm, ok := event.IQuery.GetModel().(bun.TableModel)
+	if !ok {
+		return nil, nil, false
+	}
+
+	table := tm.Table()
+	if _, ok := h.models[table.Type]; !ok {
+		return nil, nil, false
+	}
+	return event.IQuery, table, true
+}
+
+// queryDB returns the connection used by the query, e.g. bun.Tx.
+func queryDB(q bun.Query) bun.IDB {
+	return q.(interface{ GetIDB() bun.IDB }).GetIDB()
+}
+
+// modelRows returns the structs of the model value.
+func modelRows(model bun.Model) []reflect.Value {
+	v := reflect.ValueOf(model.Value())
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return []reflect.Value{v}
+	case reflect.Slice, reflect.Array:
+		rows := make([]reflect.Value, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem := reflect.Indirect(v.Index(i))
+			if elem.IsValid() {
+				rows = append(rows, elem)
+			}
+		}
+		return rows
+	default:
+		return nil
+	}
+}
+
+// loadOldValues loads the current values of the rows using a single query.
+// Rows without a primary key or that don't exist have nil values.
+func loadOldValues(
+	ctx context.Context, db bun.IDB, table *schema.Table, rows []reflect.Value,
+) ([]map[string]interface{}, error) {
+	oldValues := make([]map[string]interface{}, len(rows))
+
+	olds := reflect.New(reflect.SliceOf(table.Type))
+	index := make(map[string][]int, len(rows))
+	for i, strct := range rows {
+		if hasZeroPK(table, strct) {
+			continue
+		}
+
+		key := pkKey(table, strct)
+		if _, ok := index[key]; !ok {
+			old := reflect.New(table.Type).Elem()
+			for _, pk := range table.PKs {
+				pk.Value(old).Set(pk.Value(strct))
+			}
+			olds.Elem().Set(reflect.Append(olds.Elem(), old))
+		}
+		index[key] = append(index[key], i)
+	}
+	if len(index) == 0 {
+		return oldValues, nil
+	}
+
+	q := db.NewSelect().Model(olds.Interface()).WherePK()
+	if table.SoftDeleteField != nil {
+		q = q.WhereAllWithDeleted()
+	}
+	if err := q.Scan(ctx); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	for i := 0; i < olds.Elem().Len(); i++ {
+		old := olds.Elem().Index(i)
+		values := fieldValues(table.Fields, old)
+		for _, j := range index[pkKey(table, old)] {
+			oldValues[j] = values
+		}
+	}
+	return oldValues, nil
+}
+
+func hasZeroPK(table *schema.Table, strct reflect.Value) bool {
+	for _, pk := range table.PKs {
+		if pk.HasZeroValue(strct) {
+			return true
+		}
+	}
+	return len(table.PKs) == 0
+}
+
+// pkKey returns a string that identifies the row by its primary keys.
+func pkKey(table *schema.Table, strct reflect.Value) string {
+	values := make([]interface{}, len(table.PKs))
+	for i, pk := range table.PKs {
+		values[i] = pk.Value(strct).Interface()
+	}
+	return fmt.Sprintf("%#v", values)
+}
+
+func fieldValues(fields []*schema.Field, strct reflect.Value) map[string]interface{} {
+	values := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		values[f.Name] = f.Value(strct).Interface()
+	}
+	return values
+}
@@ -0,0 +1,38 @@
+package bunaudit
+
+import (
+	"context"
+	"reflect"
+)
+
+type Option func(h *QueryHook)
+
+// WithModels configures the models that are audited, for example:
+//
+//	bunaudit.WithModels((*User)(nil), (*Order)(nil))
+func WithModels(models ...interface{}) Option {
+	return func(h *QueryHook) {
+		for _, model := range models {
+			typ := reflect.TypeOf(model)
+			for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+				typ = typ.Elem()
+			}
+			h.models[typ] = struct{}{}
+		}
+	}
+}
+
+// WithTableName configures the name of the table for audit entries. Default is audit_log.
+func WithTableName(name string) Option {
+	return func(h *QueryHook) {
+		h.tableName = name
+	}
+}
+
+// WithActorFunc configures the function that returns the actor from the context.
+// By default, the actor set with ContextWithActor is used.
+func WithActorFunc(fn func(ctx context.Context) string) Option {
+	return func(h *QueryHook) {
+		h.actor = fn
+	}
+}
@@ -7,11 +7,14 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
 )
 
 type Option func(*QueryHook)
@@ -39,6 +42,25 @@ func WithWriter(w io.Writer) Option {
 	}
 }
 
+// WithSlowQueryThreshold configures the hook to log queries that take longer than the threshold
+// together with the caller location, even when verbose mode is disabled.
+func WithSlowQueryThreshold(threshold time.Duration) Option {
+	return func(h *QueryHook) {
+		h.slowQueryThreshold = threshold
+	}
+}
+
+// WithExplain configures the hook to log the query plan of slow queries.
+// The plan is captured by re-running the query with EXPLAIN, which is supported for
+// PostgreSQL and SQLite. Only SELECT, INSERT, UPDATE, and DELETE queries are explained.
+// EXPLAIN uses a separate connection from the pool, so it does not see uncommitted changes
+// made by the transaction that executed the query.
+func WithExplain(on bool) Option {
+	return func(h *QueryHook) {
+		h.explain = on
+	}
+}
+
 // FromEnv configures the hook using the environment variable value.
 // For example, WithEnv("BUNDEBUG"):
 //   - BUNDEBUG=0 - disables the hook.
@@ -60,9 +82,11 @@ func FromEnv(keys ...string) Option {
 }
 
 type QueryHook struct {
-	enabled bool
-	verbose bool
-	writer  io.Writer
+	enabled            bool
+	verbose            bool
+	writer             io.Writer
+	slowQueryThreshold time.Duration
+	explain            bool
 }
 
 var _ bun.QueryHook = (*QueryHook)(nil)
@@ -89,16 +113,17 @@ func (h *QueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
 		return
 	}
 
-	if !h.verbose {
+	now := time.Now()
+	dur := now.Sub(event.StartTime)
+	slow := h.slowQueryThreshold > 0 && dur >= h.slowQueryThreshold
+
+	if !h.verbose && !slow {
 		switch event.Err {
 		case nil, sql.ErrNoRows, sql.ErrTxDone:
 			return
 		}
 	}
 
-	now := time.Now()
-	dur := now.Sub(event.StartTime)
-
 	args := []interface{}{
 		"[bun]",
 		now.Format(" 15:04:05.000 "),
@@ -115,7 +140,109 @@ func (h *QueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
 		)
 	}
 
+	if slow {
+		args = append(args, "\t", color.New(color.FgYellow).Sprint("slow query at "+caller()))
+	}
+
 	fmt.Fprintln(h.writer, args...)
+
+	if slow && h.explain {
+		if plan, err := explain(ctx, event); err != nil {
+			fmt.Fprintln(h.writer, "\tEXPLAIN failed:", err)
+		} else if plan != "" {
+			fmt.Fprintln(h.writer, plan)
+		}
+	}
+}
+
+// caller returns the location of the first caller outside of Bun.
+func caller() string {
+	const depth = 32
+	var pcs [depth]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		f, more := frames.Next()
+		if !isInternalFrame(f.Function) {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+func isInternalFrame(fn string) bool {
+	for _, prefix := range []string{
+		"github.com/uptrace/bun.",
+		"github.com/uptrace/bun/extra/",
+		"github.com/uptrace/bun/schema.",
+		"github.com/uptrace/bun/dialect/",
+		"github.com/uptrace/bun/driver/",
+		"database/sql.",
+	} {
+		if strings.HasPrefix(fn, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// explain returns the query plan of the query. The query is executed using database/sql
+// directly so it does not trigger query hooks.
+func explain(ctx context.Context, event *bun.QueryEvent) (string, error) {
+	if event.DB == nil {
+		return "", nil
+	}
+
+	switch event.Operation() {
+	case "SELECT", "INSERT", "UPDATE", "DELETE":
+	default:
+		return "", nil
+	}
+
+	var query string
+	switch event.DB.Dialect().Name() {
+	case dialect.PG:
+		query = "EXPLAIN " + event.Query
+	case dialect.SQLite:
+		query = "EXPLAIN QUERY PLAN " + event.Query
+	default:
+		return "", nil
+	}
+
+	rows, err := event.DB.DB.QueryContext(context.WithoutCancel(ctx), query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		// The plan is in the last column, e.g. "detail" in SQLite.
+		b.WriteString("\t")
+		b.WriteString(values[len(values)-1].String)
+		b.WriteByte('\n')
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
 }
 
 func formatOperation(event *bun.QueryEvent) string {
@@ -0,0 +1,101 @@
+package bungolden
+
+import (
+	"strings"
+)
+
+// clauses start a new line when they are not nested in parentheses. Longer clauses
+// go first so LEFT JOIN is not split into LEFT and JOIN.
+var clauses = []string{
+	"LEFT JOIN", "RIGHT JOIN", "FULL JOIN", "INNER JOIN", "CROSS JOIN", "JOIN",
+	"ON CONFLICT", "ON DUPLICATE KEY UPDATE",
+	"FROM", "WHERE", "GROUP BY", "HAVING", "WINDOW", "ORDER BY",
+	"LIMIT", "OFFSET", "FETCH", "FOR",
+	"SET", "VALUES", "OUTPUT", "RETURNING",
+	"UNION", "INTERSECT", "EXCEPT",
+}
+
+// Format returns the SQL of the query formatted for the dialect of the query DB.
+// Whitespace is collapsed and top-level clauses, e.g. FROM and WHERE, start on
+// new lines, so changes in golden files are easy to review.
+func Format(q Query) (string, error) {
+	b, err := q.AppendQuery(q.DB().Formatter(), nil)
+	if err != nil {
+		return "", err
+	}
+	return normalizeSQL(string(b)), nil
+}
+
+func normalizeSQL(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+
+	var depth int
+	var space bool
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			space = b.Len() > 0
+			continue
+		}
+
+		if depth == 0 && (i == 0 || !isIdentChar(query[i-1])) {
+			if clause, ok := matchClause(query[i:]); ok {
+				if b.Len() > 0 {
+					b.WriteByte('\n')
+				}
+				space = false
+				b.WriteString(query[i : i+len(clause)])
+				i += len(clause) - 1
+				continue
+			}
+		}
+
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+
+		switch c {
+		case '\'', '"', '`':
+			// Copy quoted strings and identifiers as is.
+			end := strings.IndexByte(query[i+1:], c)
+			if end == -1 {
+				b.WriteString(query[i:])
+				i = len(query)
+				continue
+			}
+			end += i + 1
+			b.WriteString(query[i : end+1])
+			i = end
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		b.WriteByte(c)
+	}
+
+	b.WriteByte('\n')
+	return b.String()
+}
+
+func matchClause(s string) (string, bool) {
+	for _, clause := range clauses {
+		if len(s) < len(clause) || !strings.EqualFold(s[:len(clause)], clause) {
+			continue
+		}
+		if len(s) > len(clause) && isIdentChar(s[len(clause)]) {
+			continue
+		}
+		return clause, true
+	}
+	return "", false
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
@@ -0,0 +1,16 @@
+module github.com/uptrace/bun/extra/bungolden
+
+go 1.22
+
+replace github.com/uptrace/bun => ../..
+
+require github.com/uptrace/bun v1.2.5
+
+require (
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/puzpuzpuz/xsync/v3 v3.4.0 // indirect
+	github.com/tmthrgd/go-hex v0.0.0-20190904060850-447a3041c3bc // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+)
@@ -0,0 +1,111 @@
+package bungolden
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// Query is a query built with bun, e.g. *bun.SelectQuery or *bun.InsertQuery.
+type Query interface {
+	schema.QueryAppender
+	DB() *bun.DB
+}
+
+type config struct {
+	dir    string
+	update bool
+}
+
+type Option func(c *config)
+
+// WithDir configures the directory for golden files. Default is testdata.
+func WithDir(dir string) Option {
+	return func(c *config) {
+		c.dir = dir
+	}
+}
+
+// WithUpdate makes Assert write golden files instead of comparing them.
+// Default is the value of the -update flag when the test package defines it,
+// otherwise BUNGOLDEN_UPDATE=1 in the environment.
+func WithUpdate(update bool) Option {
+	return func(c *config) {
+		c.update = update
+	}
+}
+
+// Assert formats the query with Format for the dialect of the query DB and compares it
+// with the golden file <dir>/<name>.<dialect>.sql, e.g. testdata/user_search.pg.sql:
+//
+//	func TestUserSearch(t *testing.T) {
+//		q := repo.SearchUsers(db.NewSelect(), filter)
+//		bungolden.Assert(t, "user_search", q)
+//	}
+//
+// Run the tests with BUNGOLDEN_UPDATE=1 to create or update golden files,
+// or with -update if the test package defines that flag.
+func Assert(t testing.TB, name string, q Query, opts ...Option) {
+	t.Helper()
+
+	c := &config{
+		dir:    "testdata",
+		update: updateFlag(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	got, err := Format(q)
+	if err != nil {
+		t.Fatalf("bungolden: %s: %s", name, err)
+		return
+	}
+
+	path := filepath.Join(c.dir, name+"."+q.DB().Dialect().Name().String()+".sql")
+
+	if c.update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("bungolden: %s", err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("bungolden: %s", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("bungolden: %s does not exist, run the test with BUNGOLDEN_UPDATE=1 to create it", path)
+			return
+		}
+		t.Fatalf("bungolden: %s", err)
+		return
+	}
+
+	if string(want) != got {
+		t.Errorf("bungolden: query does not match %s, run the test with BUNGOLDEN_UPDATE=1 to update it\n"+
+			"--- want:\n%s--- got:\n%s", path, want, got)
+	}
+}
+
+// updateFlag reports whether golden files should be updated. bungolden does not
+// register the -update flag itself, because it would conflict with test packages
+// that define it, so the flag is only looked up when Assert runs.
+func updateFlag() bool {
+	if f := flag.Lookup("update"); f != nil {
+		update, _ := strconv.ParseBool(f.Value.String())
+		return update
+	}
+	update, _ := strconv.ParseBool(os.Getenv("BUNGOLDEN_UPDATE"))
+	return update
+}
@@ -0,0 +1,289 @@
+package bunmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Mock is a database/sql driver that records the queries generated by bun and returns
+// canned results, so code that uses bun can be tested without a database:
+//
+//	mock := bunmock.New()
+//	db := bun.NewDB(mock.DB(), pgdialect.New())
+//
+//	mock.Expect(`SELECT "u"."id", "u"."name" FROM "users" AS "u" WHERE (id = 1)`).
+//		ReturnRows(bunmock.NewRows("id", "name").AddRow(1, "admin"))
+//
+// Without expectations, the mock records the queries and returns no rows, see Queries.
+// Once an expectation is added, every query must match the next expectation,
+// and other queries fail with an error.
+type Mock struct {
+	db *sql.DB
+
+	mu       sync.Mutex
+	strict   bool
+	expected []*Expectation
+	next     int
+	queries  []Query
+}
+
+// Query is a query received by the mock.
+type Query struct {
+	SQL  string
+	Args []interface{}
+}
+
+func New() *Mock {
+	m := new(Mock)
+	m.db = sql.OpenDB(&connector{mock: m})
+	return m
+}
+
+// DB returns the database that sends queries to the mock, e.g. for bun.NewDB.
+func (m *Mock) DB() *sql.DB {
+	return m.db
+}
+
+// Queries returns the queries received by the mock in order.
+func (m *Mock) Queries() []Query {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Query(nil), m.queries...)
+}
+
+// LastQuery returns the last query received by the mock.
+func (m *Mock) LastQuery() Query {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.queries) == 0 {
+		return Query{}
+	}
+	return m.queries[len(m.queries)-1]
+}
+
+// Reset removes the recorded queries and the expectations.
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.strict = false
+	m.expected = nil
+	m.next = 0
+	m.queries = nil
+}
+
+// Expect adds an expectation for the query. Queries are compared ignoring
+// the differences in whitespace.
+func (m *Mock) Expect(query string) *Expectation {
+	return m.expect(&Expectation{query: normalizeSQL(query)})
+}
+
+// ExpectRegexp adds an expectation for a query that matches the regular expression.
+func (m *Mock) ExpectRegexp(pattern string) *Expectation {
+	return m.expect(&Expectation{re: regexp.MustCompile(pattern)})
+}
+
+func (m *Mock) expect(e *Expectation) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.strict = true
+	m.expected = append(m.expected, e)
+	return e
+}
+
+// ExpectationsWereMet returns an error if some of the expected queries were not received.
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.next == len(m.expected) {
+		return nil
+	}
+	pending := make([]string, 0, len(m.expected)-m.next)
+	for _, e := range m.expected[m.next:] {
+		pending = append(pending, e.String())
+	}
+	return fmt.Errorf("bunmock: %d expected queries were not received:\n\t%s",
+		len(pending), strings.Join(pending, "\n\t"))
+}
+
+// handle records the query and returns the expectation that it matches,
+// or nil if the mock does not have expectations.
+func (m *Mock) handle(query string, args []driver.NamedValue) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q := Query{SQL: query}
+	if len(args) > 0 {
+		q.Args = make([]interface{}, len(args))
+		for i, arg := range args {
+			q.Args[i] = arg.Value
+		}
+	}
+	m.queries = append(m.queries, q)
+
+	if !m.strict {
+		return nil, nil
+	}
+	if m.next == len(m.expected) {
+		return nil, fmt.Errorf("bunmock: unexpected query:\n\t%s", query)
+	}
+
+	e := m.expected[m.next]
+	if err := e.match(q); err != nil {
+		return nil, err
+	}
+	m.next++
+
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e, nil
+}
+
+//------------------------------------------------------------------------------
+
+// Expectation is an expected query and its result.
+type Expectation struct {
+	query string
+	re    *regexp.Regexp
+	args  []interface{}
+
+	rows   *Rows
+	result driver.Result
+	err    error
+}
+
+// WithArgs makes the expectation check the args that are passed to the driver.
+// Bun formats args into the query, so only queries that are executed with
+// bun.DB.DB or a driver that supports placeholders have args.
+func (e *Expectation) WithArgs(args ...interface{}) *Expectation {
+	if args == nil {
+		args = []interface{}{}
+	}
+	e.args = args
+	return e
+}
+
+// ReturnRows makes the query return the rows.
+func (e *Expectation) ReturnRows(rows *Rows) *Expectation {
+	e.rows = rows
+	return e
+}
+
+// ReturnResult makes the query return the last insert id and the number of affected rows.
+func (e *Expectation) ReturnResult(lastInsertID, rowsAffected int64) *Expectation {
+	e.result = result{lastInsertID: lastInsertID, rowsAffected: rowsAffected}
+	return e
+}
+
+// ReturnError makes the query fail with the error.
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) String() string {
+	if e.re != nil {
+		return e.re.String()
+	}
+	return e.query
+}
+
+func (e *Expectation) match(q Query) error {
+	if e.re != nil {
+		if !e.re.MatchString(q.SQL) {
+			return fmt.Errorf("bunmock: query\n\t%s\ndoes not match the expected pattern\n\t%s",
+				q.SQL, e.re)
+		}
+	} else if normalizeSQL(q.SQL) != e.query {
+		return fmt.Errorf("bunmock: query\n\t%s\ndoes not match the expected query\n\t%s",
+			q.SQL, e.query)
+	}
+
+	if e.args == nil {
+		return nil
+	}
+	if len(q.Args) != len(e.args) {
+		return fmt.Errorf("bunmock: query has %d args, expected %d", len(q.Args), len(e.args))
+	}
+	for i, arg := range e.args {
+		want, err := driver.DefaultParameterConverter.ConvertValue(arg)
+		if err != nil {
+			return fmt.Errorf("bunmock: expected arg %d: %w", i, err)
+		}
+		got, err := driver.DefaultParameterConverter.ConvertValue(q.Args[i])
+		if err != nil {
+			got = q.Args[i]
+		}
+		if !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("bunmock: query arg %d is %#v, expected %#v", i, got, want)
+		}
+	}
+	return nil
+}
+
+func (e *Expectation) driverRows() (driver.Rows, error) {
+	if e == nil || e.rows == nil {
+		return &rows{rows: NewRows()}, nil
+	}
+	if e.rows.err != nil {
+		return nil, e.rows.err
+	}
+	return &rows{rows: e.rows}, nil
+}
+
+func (e *Expectation) driverResult() driver.Result {
+	switch {
+	case e == nil:
+		return result{}
+	case e.result != nil:
+		return e.result
+	case e.rows != nil:
+		return result{rowsAffected: int64(len(e.rows.values))}
+	default:
+		return result{}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// Rows are the canned rows returned by a query.
+type Rows struct {
+	columns []string
+	values  [][]driver.Value
+	err     error
+}
+
+func NewRows(columns ...string) *Rows {
+	return &Rows{columns: columns}
+}
+
+// AddRow adds a row with the values of the columns. Values are converted to
+// the types supported by database/sql drivers, e.g. int to int64.
+func (r *Rows) AddRow(values ...interface{}) *Rows {
+	if len(values) != len(r.columns) {
+		r.err = fmt.Errorf("bunmock: row has %d values, expected %d", len(values), len(r.columns))
+		return r
+	}
+
+	row := make([]driver.Value, len(values))
+	for i, v := range values {
+		dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			r.err = fmt.Errorf("bunmock: column %s: %w", r.columns[i], err)
+			return r
+		}
+		row[i] = dv
+	}
+	r.values = append(r.values, row)
+	return r
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
@@ -33,6 +33,15 @@ func WithFormattedQueries(format bool) Option {
 	}
 }
 
+// WithQuerySanitizer configures a function that is applied to the statement attribute
+// before it is added to the span, for example, to remove sensitive values
+// from formatted queries. See SanitizeQuery.
+func WithQuerySanitizer(fn func(query string) string) Option {
+	return func(h *QueryHook) {
+		h.sanitizeQuery = fn
+	}
+}
+
 // WithTracerProvider returns an Option to use the TracerProvider when
 // creating a Tracer.
 func WithTracerProvider(tp trace.TracerProvider) Option {
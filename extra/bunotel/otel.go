@@ -23,9 +23,11 @@ import (
 type QueryHook struct {
 	attrs          []attribute.KeyValue
 	formatQueries  bool
+	sanitizeQuery  func(query string) string
 	tracer         trace.Tracer
 	meter          metric.Meter
 	queryHistogram metric.Int64Histogram
+	inFlight       metric.Int64UpDownCounter
 }
 
 var _ bun.QueryHook = (*QueryHook)(nil)
@@ -46,6 +48,10 @@ func NewQueryHook(opts ...Option) *QueryHook {
 		metric.WithDescription("Timing of processed queries"),
 		metric.WithUnit("milliseconds"),
 	)
+	h.inFlight, _ = h.meter.Int64UpDownCounter(
+		"go.sql.queries_in_flight",
+		metric.WithDescription("Number of queries that are being executed"),
+	)
 	return h
 }
 
@@ -60,6 +66,7 @@ func (h *QueryHook) Init(db *bun.DB) {
 }
 
 func (h *QueryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	h.inFlight.Add(ctx, 1, metric.WithAttributes(h.inFlightLabels(event)...))
 	ctx, _ = h.tracer.Start(ctx, "", trace.WithSpanKind(trace.SpanKindClient))
 	return ctx
 }
@@ -77,6 +84,8 @@ func (h *QueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
 		}
 	}
 
+	h.inFlight.Add(ctx, -1, metric.WithAttributes(h.inFlightLabels(event)...))
+
 	dur := time.Since(event.StartTime)
 	h.queryHistogram.Record(ctx, dur.Milliseconds(), metric.WithAttributes(labels...))
 
@@ -121,6 +130,13 @@ func (h *QueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
 	span.SetAttributes(attrs...)
 }
 
+func (h *QueryHook) inFlightLabels(event *bun.QueryEvent) []attribute.KeyValue {
+	labels := make([]attribute.KeyValue, 0, len(h.attrs)+1)
+	labels = append(labels, h.attrs...)
+	labels = append(labels, semconv.DBOperationKey.String(event.Operation()))
+	return labels
+}
+
 func funcFileLine(pkg string) (string, string, int) {
 	const depth = 16
 	var pcs [depth]uintptr
@@ -159,6 +175,10 @@ func (h *QueryHook) eventQuery(event *bun.QueryEvent) string {
 		query = unformattedQuery(event)
 	}
 
+	if h.sanitizeQuery != nil {
+		query = h.sanitizeQuery(query)
+	}
+
 	if len(query) > hardQueryLimit {
 		query = query[:hardQueryLimit]
 	}
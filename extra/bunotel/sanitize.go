@@ -0,0 +1,15 @@
+package bunotel
+
+import "github.com/uptrace/bun/internal"
+
+// SanitizeQuery replaces string and numeric literals in the query with ?, so formatted
+// queries can be recorded without leaking values, for example:
+//
+//	SELECT * FROM users WHERE email = 'foo@example.com' AND id = 1
+//	SELECT * FROM users WHERE email = ? AND id = ?
+//
+// Quoted identifiers are left intact. Quotes in string literals can be escaped
+// by doubling them or, like in MySQL, with a backslash.
+func SanitizeQuery(query string) string {
+	return internal.SanitizeQuery(query)
+}
@@ -0,0 +1,43 @@
+package bunotel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "SELECT * FROM users WHERE email = 'foo@example.com' AND id = 1",
+			want:  "SELECT * FROM users WHERE email = ? AND id = ?",
+		},
+		{
+			query: "SELECT * FROM users WHERE name = 'O''Brien' AND age > 42.5",
+			want:  "SELECT * FROM users WHERE name = ? AND age > ?",
+		},
+		{
+			query: `SELECT * FROM users WHERE name = 'It\'s secret' AND id = 1`,
+			want:  "SELECT * FROM users WHERE name = ? AND id = ?",
+		},
+		{
+			query: `SELECT * FROM users WHERE name = 'secret\\' AND id = 1`,
+			want:  "SELECT * FROM users WHERE name = ? AND id = ?",
+		},
+		{
+			query: `SELECT "user's", ` + "`col1` FROM t1 WHERE x = 'a'",
+			want:  `SELECT "user's", ` + "`col1` FROM t1 WHERE x = ?",
+		},
+		{
+			query: "SELECT * FROM t WHERE name = 'unterminated",
+			want:  "SELECT * FROM t WHERE name = ?",
+		},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, SanitizeQuery(test.query), test.query)
+	}
+}
@@ -0,0 +1,43 @@
+package bunprom
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type Option func(h *QueryHook)
+
+// WithNamespace configures the namespace of the metrics, e.g. myapp_bun_query_duration_seconds.
+func WithNamespace(namespace string) Option {
+	return func(h *QueryHook) {
+		h.namespace = namespace
+	}
+}
+
+// WithDBName configures the db_name label of the connection pool metrics.
+// Default is "bun".
+func WithDBName(name string) Option {
+	return func(h *QueryHook) {
+		h.dbName = name
+	}
+}
+
+// WithRegisterer configures the registerer of the metrics.
+// Default is prometheus.DefaultRegisterer.
+func WithRegisterer(reg prometheus.Registerer) Option {
+	return func(h *QueryHook) {
+		h.registerer = reg
+	}
+}
+
+// WithBuckets configures the buckets of the query duration histogram.
+// Default is prometheus.DefBuckets.
+func WithBuckets(buckets []float64) Option {
+	return func(h *QueryHook) {
+		h.buckets = buckets
+	}
+}
+
+// WithConstLabels configures labels that are added to all metrics.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(h *QueryHook) {
+		h.constLabels = labels
+	}
+}
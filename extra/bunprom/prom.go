@@ -0,0 +1,115 @@
+package bunprom
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/uptrace/bun"
+)
+
+// QueryHook exports Prometheus metrics for executed queries:
+//
+//   - bun_query_duration_seconds - histogram of query durations;
+//   - bun_query_errors_total - number of failed queries;
+//
+// Both metrics are labeled with the operation, e.g. SELECT, and the table name.
+// The connection pool metrics from sql.DBStats are exported as go_sql_* metrics.
+type QueryHook struct {
+	namespace   string
+	dbName      string
+	registerer  prometheus.Registerer
+	buckets     []float64
+	constLabels prometheus.Labels
+
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+var _ bun.QueryHook = (*QueryHook)(nil)
+
+// NewQueryHook creates and registers the query metrics:
+//
+//	db.AddQueryHook(bunprom.NewQueryHook(bunprom.WithDBName("mydb")))
+func NewQueryHook(opts ...Option) *QueryHook {
+	h := &QueryHook{
+		dbName:     "bun",
+		registerer: prometheus.DefaultRegisterer,
+		buckets:    prometheus.DefBuckets,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	labels := []string{"operation", "table"}
+	h.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   h.namespace,
+		Subsystem:   "bun",
+		Name:        "query_duration_seconds",
+		Help:        "Duration of executed queries.",
+		Buckets:     h.buckets,
+		ConstLabels: h.constLabels,
+	}, labels)
+	h.errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   h.namespace,
+		Subsystem:   "bun",
+		Name:        "query_errors_total",
+		Help:        "Number of queries that returned an error.",
+		ConstLabels: h.constLabels,
+	}, labels)
+
+	h.duration = register(h.registerer, h.duration)
+	h.errors = register(h.registerer, h.errors)
+
+	return h
+}
+
+// Init registers the connection pool metrics of the db.
+func (h *QueryHook) Init(db *bun.DB) {
+	reg := h.registerer
+	if len(h.constLabels) > 0 {
+		reg = prometheus.WrapRegistererWith(h.constLabels, reg)
+	}
+	_ = register(reg, collectors.NewDBStatsCollector(db.DB, h.dbName))
+}
+
+func (h *QueryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *QueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	operation := event.Operation()
+	var table string
+	if event.IQuery != nil {
+		table = event.IQuery.GetTableName()
+	}
+
+	h.duration.WithLabelValues(operation, table).Observe(time.Since(event.StartTime).Seconds())
+
+	switch {
+	case event.Err == nil,
+		errors.Is(event.Err, sql.ErrNoRows),
+		errors.Is(event.Err, sql.ErrTxDone):
+		// ignore
+	default:
+		h.errors.WithLabelValues(operation, table).Inc()
+	}
+}
+
+// register registers the collector and returns the already registered collector
+// when the same metrics are registered twice, for example, by hooks of several databases.
+func register[T prometheus.Collector](reg prometheus.Registerer, c T) T {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+	}
+	return c
+}
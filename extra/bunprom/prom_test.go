@@ -0,0 +1,46 @@
+package bunprom
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun"
+)
+
+func TestAfterQuery(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h := NewQueryHook(WithRegisterer(reg), WithNamespace("test"))
+
+	events := []*bun.QueryEvent{
+		{Query: "SELECT 1", StartTime: time.Now()},
+		{Query: "SELECT 2", StartTime: time.Now(), Err: sql.ErrNoRows},
+		{Query: "SELECT 3", StartTime: time.Now(), Err: fmt.Errorf("select: %w", sql.ErrNoRows)},
+		{Query: "INSERT INTO t VALUES (1)", StartTime: time.Now(), Err: errors.New("boom")},
+	}
+	for _, event := range events {
+		h.AfterQuery(context.Background(), event)
+	}
+
+	require.Equal(t, 2, testutil.CollectAndCount(h.duration))
+
+	expected := `
+# HELP test_bun_query_errors_total Number of queries that returned an error.
+# TYPE test_bun_query_errors_total counter
+test_bun_query_errors_total{operation="INSERT",table=""} 1
+`
+	err := testutil.CollectAndCompare(h.errors, strings.NewReader(expected))
+	require.NoError(t, err)
+
+	// Registering the same metrics again reuses the existing collectors.
+	h2 := NewQueryHook(WithRegisterer(reg), WithNamespace("test"))
+	require.Same(t, h.duration, h2.duration, "duration histogram is not shared")
+}
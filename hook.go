@@ -23,7 +23,14 @@ type QueryEvent struct {
 
 	StartTime time.Time
 	Result    sql.Result
-	Err       error
+
+	// Err is the error returned by the query. AfterQuery hooks can set Err
+	// to make a successful query return the error, for example, when a query
+	// executed by the hook fails. The error of a failed query can't be replaced
+	// or cleared, and COMMIT and ROLLBACK ignore the errors set by hooks,
+	// because they can't be undone. QueryRowContext can't return the error,
+	// because sql.Row does not allow setting it.
+	Err error
 
 	Stash map[interface{}]interface{}
 }
@@ -52,6 +59,89 @@ type QueryHook interface {
 	AfterQuery(context.Context, *QueryEvent)
 }
 
+// QueryEventFilter reports whether a query hook should receive the event.
+type QueryEventFilter func(event *QueryEvent) bool
+
+// FilterQueryHook returns a hook that passes to the hook only events accepted by all filters,
+// for example:
+//
+//	db.AddQueryHook(bun.FilterQueryHook(hook,
+//		bun.OperationFilter("INSERT", "UPDATE", "DELETE"),
+//		bun.TableFilter("users"),
+//	))
+func FilterQueryHook(hook QueryHook, filters ...QueryEventFilter) QueryHook {
+	return &filteredQueryHook{
+		hook:    hook,
+		filters: filters,
+	}
+}
+
+// OperationFilter accepts events for queries with one of the operations, e.g. "SELECT".
+func OperationFilter(operations ...string) QueryEventFilter {
+	return func(event *QueryEvent) bool {
+		op := event.Operation()
+		for _, operation := range operations {
+			if strings.EqualFold(op, operation) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// TableFilter accepts events for queries with one of the table names.
+// Raw queries don't have a table name and are never accepted.
+func TableFilter(tables ...string) QueryEventFilter {
+	return func(event *QueryEvent) bool {
+		if event.IQuery == nil {
+			return false
+		}
+		name := event.IQuery.GetTableName()
+		for _, table := range tables {
+			if name == table {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type filteredQueryHook struct {
+	hook    QueryHook
+	filters []QueryEventFilter
+}
+
+var _ QueryHook = (*filteredQueryHook)(nil)
+
+func (h *filteredQueryHook) Init(db *DB) {
+	if initer, ok := h.hook.(queryHookIniter); ok {
+		initer.Init(db)
+	}
+}
+
+func (h *filteredQueryHook) accept(event *QueryEvent) bool {
+	for _, filter := range h.filters {
+		if !filter(event) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *filteredQueryHook) BeforeQuery(ctx context.Context, event *QueryEvent) context.Context {
+	if !h.accept(event) {
+		return ctx
+	}
+	return h.hook.BeforeQuery(ctx, event)
+}
+
+func (h *filteredQueryHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	if !h.accept(event) {
+		return
+	}
+	h.hook.AfterQuery(ctx, event)
+}
+
 func (db *DB) beforeQuery(
 	ctx context.Context,
 	iquery Query,
@@ -60,7 +150,10 @@ func (db *DB) beforeQuery(
 	query string,
 	model Model,
 ) (context.Context, *QueryEvent) {
-	atomic.AddUint32(&db.stats.Queries, 1)
+	db.stats.countQuery(iquery)
+	if q, ok := iquery.(interface{ bindTx(context.Context) }); ok {
+		q.bindTx(ctx)
+	}
 
 	if len(db.queryHooks) == 0 {
 		return ctx, nil
@@ -86,12 +179,14 @@ func (db *DB) beforeQuery(
 	return ctx, event
 }
 
+// afterQuery calls AfterQuery hooks and returns the query error. The driver error
+// is authoritative, so hooks can only fail a query that succeeded.
 func (db *DB) afterQuery(
 	ctx context.Context,
 	event *QueryEvent,
 	res sql.Result,
 	err error,
-) {
+) error {
 	switch err {
 	case nil, sql.ErrNoRows:
 		// nothing
@@ -100,13 +195,27 @@ func (db *DB) afterQuery(
 	}
 
 	if event == nil {
-		return
+		return err
 	}
 
 	event.Result = res
 	event.Err = err
 
 	db.afterQueryFromIndex(ctx, event, len(db.queryHooks)-1)
+	if err != nil || isTxEnd(event.Query) {
+		return err
+	}
+	return event.Err
+}
+
+// isTxEnd reports whether the query ends a transaction or a savepoint.
+func isTxEnd(query string) bool {
+	for _, prefix := range []string{"COMMIT", "ROLLBACK", "RELEASE SAVEPOINT"} {
+		if strings.HasPrefix(query, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 func (db *DB) afterQueryFromIndex(ctx context.Context, event *QueryEvent, hookIndex int) {
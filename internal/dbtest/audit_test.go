@@ -0,0 +1,99 @@
+package dbtest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/extra/bunaudit"
+)
+
+type AuditedAccount struct {
+	ID      int64 `bun:",pk,autoincrement"`
+	Name    string
+	Balance int64
+}
+
+func TestAudit(t *testing.T) {
+	testEachDB(t, testAudit)
+}
+
+func testAudit(t *testing.T, dbName string, db *bun.DB) {
+	var selects int
+	db.AddQueryHook(&queryHook{
+		beforeQuery: func(ctx context.Context, event *bun.QueryEvent) context.Context {
+			if event.Operation() == "SELECT" {
+				selects++
+			}
+			return ctx
+		},
+	})
+	db.AddQueryHook(bunaudit.NewQueryHook(bunaudit.WithModels((*AuditedAccount)(nil))))
+
+	mustResetModel(t, ctx, db, (*AuditedAccount)(nil), (*bunaudit.Entry)(nil))
+
+	ctx := bunaudit.ContextWithActor(ctx, "alice")
+
+	account := &AuditedAccount{Name: "savings", Balance: 100}
+	_, err := db.NewInsert().Model(account).Exec(ctx)
+	require.NoError(t, err)
+
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		account.Balance = 50
+		_, err := tx.NewUpdate().Model(account).WherePK().Exec(ctx)
+		return err
+	})
+	require.NoError(t, err)
+
+	_, err = db.NewDelete().Model(account).WherePK().Exec(ctx)
+	require.NoError(t, err)
+
+	var entries []bunaudit.Entry
+	err = db.NewSelect().Model(&entries).Order("id").Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	for _, entry := range entries {
+		require.Equal(t, "alice", entry.Actor)
+		require.Equal(t, "audited_accounts", entry.TableName)
+		require.EqualValues(t, account.ID, entry.PrimaryKey["id"])
+		require.False(t, entry.CreatedAt.IsZero())
+	}
+
+	require.Equal(t, "INSERT", entries[0].Operation)
+	require.Nil(t, entries[0].OldValues)
+	require.EqualValues(t, 100, entries[0].NewValues["balance"])
+
+	require.Equal(t, "UPDATE", entries[1].Operation)
+	require.EqualValues(t, 100, entries[1].OldValues["balance"])
+	require.EqualValues(t, 50, entries[1].NewValues["balance"])
+
+	require.Equal(t, "DELETE", entries[2].Operation)
+	require.EqualValues(t, 50, entries[2].OldValues["balance"])
+	require.Nil(t, entries[2].NewValues)
+
+	// Old values of several rows are loaded with a single query.
+	accounts := []*AuditedAccount{
+		{Name: "a", Balance: 1},
+		{Name: "b", Balance: 2},
+		{Name: "c", Balance: 3},
+	}
+	_, err = db.NewInsert().Model(&accounts).Exec(ctx)
+	require.NoError(t, err)
+
+	selects = 0
+	_, err = db.NewDelete().Model(&accounts).WherePK().Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, selects)
+
+	entries = nil
+	err = db.NewSelect().Model(&entries).Where("operation = ?", "DELETE").Order("id").Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	for i, account := range accounts {
+		require.EqualValues(t, account.ID, entries[i+1].PrimaryKey["id"])
+		require.EqualValues(t, account.Balance, entries[i+1].OldValues["balance"])
+	}
+}
@@ -48,6 +48,112 @@ func benchmarkSelectSlice(b *testing.B, db *bun.DB) {
 	})
 }
 
+type BenchWide struct {
+	ID                                 int64 `bun:",pk,autoincrement"`
+	S1, S2, S3, S4, S5, S6, S7, S8, S9 string
+	I1, I2, I3, I4, I5, I6, I7, I8, I9 int64
+	F1, F2, F3, F4, F5, F6, F7, F8, F9 float64
+	B1, B2, B3, B4, B5, B6, B7, B8, B9 bool
+	BenchTimestamps
+}
+
+type BenchTimestamps struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func BenchmarkSelectWideSlice(b *testing.B) {
+	benchEachDB(b, benchmarkSelectWideSlice)
+}
+
+func benchmarkSelectWideSlice(b *testing.B, db *bun.DB) {
+	mustResetModel(b, ctx, db, (*BenchWide)(nil))
+
+	rows := make([]BenchWide, 100)
+	for i := range rows {
+		rows[i] = BenchWide{
+			S1: gofakeit.Name(), S5: gofakeit.Email(), S9: gofakeit.City(),
+			I1: int64(i), I5: int64(i * 2), I9: int64(i * 3),
+			F1: float64(i) / 2, F5: float64(i) / 3, F9: float64(i) / 4,
+			B1: true, B5: i%2 == 0,
+			BenchTimestamps: BenchTimestamps{CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		}
+	}
+	_, err := db.NewInsert().Model(&rows).Exec(ctx)
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var models []BenchWide
+			err := db.NewSelect().Model(&models).Scan(ctx)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkSelectQueryBuild(b *testing.B) {
+	db := sqlite(b)
+
+	build := func() *bun.SelectQuery {
+		q := db.NewSelect().
+			Model((*Bench)(nil)).
+			Column("id", "name").
+			Where("id > ?", 1).
+			Where("name LIKE ?", "foo%").
+			Order("id").
+			Limit(10)
+		_ = q.String()
+		return q
+	}
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			build()
+		}
+	})
+
+	b.Run("release", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			build().Release()
+		}
+	})
+}
+
+func BenchmarkSelectQueryCache(b *testing.B) {
+	db := sqlite(b)
+
+	build := func(db *bun.DB, i int) {
+		q := db.NewSelect().
+			Model((*BenchWide)(nil)).
+			Where("i1 > ?", i).
+			Where("s1 LIKE ?", "foo%").
+			WhereOr("b1 = ?", true).
+			Order("id").
+			Limit(10)
+		_ = q.String()
+	}
+
+	b.Run("format", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			build(db, i)
+		}
+	})
+
+	b.Run("cache", func(b *testing.B) {
+		db := bun.NewDB(db.DB, db.Dialect(), bun.WithQueryCache(100))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			build(db, i)
+		}
+	})
+}
+
 func BenchmarkSelectError(b *testing.B) {
 	benchEachDB(b, benchmarkSelectError)
 }
@@ -0,0 +1,138 @@
+package dbtest_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+//go:generate go run github.com/uptrace/bun/cmd/bungen bungen_test.go
+
+type GenModel struct {
+	bun.BaseModel `bun:"table:gen_models"`
+
+	ID        int64 `bun:",pk,autoincrement"`
+	Name      string
+	Active    bool
+	Count     int32
+	Size      uint16
+	Score     float64
+	Ratio     float32
+	Data      []byte
+	Note      string `bun:"comment,nullzero"`
+	CreatedAt time.Time
+	Tags      []string
+}
+
+func TestBungenUpToDate(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	output := filepath.Join(t.TempDir(), "bungen_bun_test.go")
+	cmd := exec.Command("go", "run", "github.com/uptrace/bun/cmd/bungen", "-o", output, "bungen_test.go")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	got, err := os.ReadFile(output)
+	require.NoError(t, err)
+	want, err := os.ReadFile("bungen_bun_test.go")
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got), "run go generate to update bungen_bun_test.go")
+}
+
+func testBungenModel(t *testing.T, db *bun.DB) {
+	mustResetModel(t, ctx, db, (*GenModel)(nil))
+
+	model := &GenModel{
+		Name:      "hello",
+		Active:    true,
+		Count:     -42,
+		Size:      7,
+		Score:     1.5,
+		Ratio:     0.25,
+		Data:      []byte("data"),
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:      []string{"a", "b"},
+	}
+	_, err := db.NewInsert().Model(model).Exec(ctx)
+	require.NoError(t, err)
+	require.NotZero(t, model.ID)
+
+	var comment *string
+	err = db.NewSelect().Model((*GenModel)(nil)).
+		Column(GenModelColumnNote).
+		Where("? = ?", bun.Ident(GenModelColumnID), model.ID).
+		Scan(ctx, &comment)
+	require.NoError(t, err)
+	require.Nil(t, comment)
+
+	got := new(GenModel)
+	err = db.NewSelect().Model(got).Where("id = ?", model.ID).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, model.Name, got.Name)
+	require.Equal(t, model.Active, got.Active)
+	require.Equal(t, model.Count, got.Count)
+	require.Equal(t, model.Size, got.Size)
+	require.Equal(t, model.Score, got.Score)
+	require.Equal(t, model.Ratio, got.Ratio)
+	require.Equal(t, model.Data, got.Data)
+	require.Equal(t, "", got.Note)
+	require.True(t, model.CreatedAt.Equal(got.CreatedAt))
+	require.Equal(t, model.Tags, got.Tags)
+
+	got.Note = "note"
+	_, err = db.NewUpdate().Model(got).WherePK().Exec(ctx)
+	require.NoError(t, err)
+
+	var models []GenModel
+	err = db.NewSelect().Model(&models).Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	require.Equal(t, "note", models[0].Note)
+
+	q := db.NewSelect().Model(&models).
+		Column(GenModelColumns.ID.String()).
+		ColumnExpr("?", GenModelColumns.Note).
+		Where("? = ?", GenModelColumns.Name, "hello").
+		OrderExpr("? DESC", GenModelColumns.CreatedAt)
+	require.Contains(t, q.String(), `"gen_model"."comment"`)
+	require.Contains(t, q.String(), `WHERE ("gen_model"."name" = 'hello')`)
+	require.Contains(t, q.String(), `ORDER BY "gen_model"."created_at" DESC`)
+
+	type GenChild struct {
+		ID       int64 `bun:",pk"`
+		ParentID int64
+		Parent   *GenModel `bun:"rel:belongs-to"`
+	}
+
+	childQuery := db.NewSelect().Model((*GenChild)(nil)).
+		Relation("Parent").
+		Where("? = ?", GenModelColumns.Name, "hello")
+	require.Contains(t, childQuery.String(), `WHERE ("parent"."name" = 'hello')`)
+
+	models = nil
+	err = q.Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	require.Equal(t, model.ID, models[0].ID)
+	require.Equal(t, "note", models[0].Note)
+
+	_, err = db.NewUpdate().Model((*GenModel)(nil)).
+		Set("? = ?", GenModelColumns.Note.Name(), "updated").
+		Where("? = ?", GenModelColumns.ID, model.ID).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	err = db.NewSelect().Model((*GenModel)(nil)).
+		Where("? = ?", schema.NewModelColumn((*GenModel)(nil), "unknown"), 1).
+		Scan(ctx)
+	require.Error(t, err)
+}
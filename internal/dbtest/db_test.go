@@ -1,18 +1,27 @@
 package dbtest_test
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect"
@@ -24,9 +33,12 @@ import (
 	"github.com/uptrace/bun/driver/pgdriver"
 	"github.com/uptrace/bun/driver/sqliteshim"
 	"github.com/uptrace/bun/extra/bundebug"
+	"github.com/uptrace/bun/extra/bunjson"
+	"github.com/uptrace/bun/schema"
 
 	_ "github.com/denisenkom/go-mssqldb"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v4/stdlib"
 	"github.com/stretchr/testify/require"
 )
@@ -224,6 +236,20 @@ func funcName(x interface{}) string {
 	return s
 }
 
+func TestDialectFeatures(t *testing.T) {
+	require.Equal(t, []string{"CTE", "Returning", "Array"},
+		feature.Names(feature.CTE|feature.Returning|feature.Array))
+
+	pgFeatures := pgdialect.New().Features()
+	require.True(t, pgFeatures.Has(feature.Array))
+	require.True(t, pgFeatures.Has(feature.InsertOnConflict|feature.InsertOnDuplicateKey))
+
+	sqliteFeatures := sqlitedialect.New().Features()
+	require.False(t, sqliteFeatures.Has(feature.Array|feature.Lateral))
+	require.False(t, sqliteFeatures.Has(feature.Regexp))
+	require.True(t, sqlitedialect.New(sqlitedialect.WithRegexp()).Features().Has(feature.Regexp))
+}
+
 func TestDB(t *testing.T) {
 	type Test struct {
 		run func(t *testing.T, db *bun.DB)
@@ -235,6 +261,7 @@ func TestDB(t *testing.T) {
 		{testSelectScan},
 		{testSelectCount},
 		{testSelectMap},
+		{testSelectMapTypes},
 		{testSelectMapSlice},
 		{testSelectStruct},
 		{testSelectNestedStructValue},
@@ -250,6 +277,21 @@ func TestDB(t *testing.T) {
 		{testScanSingleRow},
 		{testScanSingleRowByRow},
 		{testScanRows},
+		{testCursor},
+		{testScanExtras},
+		{testStrictScan},
+		{testTimeConfig},
+		{testNetworkTypes},
+		{testUUID},
+		{testJSONProvider},
+		{testNull},
+		{testDiscriminator},
+		{testBlob},
+		{testLargeObject},
+		{testValidation},
+		{testUpdateChanges},
+		{testRegisterModelHook},
+		{testSkipHooks},
 		{testRunInTx},
 		{testJSONInterface},
 		{testJSONValuer},
@@ -277,6 +319,51 @@ func TestDB(t *testing.T) {
 		{testJSONMarshaler},
 		{testNilDriverValue},
 		{testRunInTxAndSavepoint},
+		{testNestedRunInTx},
+		{testRunInTxRetry},
+		{testTxCallbacks},
+		{testContextWithTx},
+		{testStmtCache},
+		{testQueryTimeout},
+		{testDeleteReturning},
+		{testRegisterSQLType},
+		{testSQLiteTableOptions},
+		{testGeneratedColumn},
+		{testBungenModel},
+		{testQueryRelease},
+		{testQueryCache},
+		{testQueryClone},
+		{testQueryToSQL},
+		{testQueryError},
+		{testSQLError},
+		{testPredicate},
+		{testPaginate},
+		{testNamedParams},
+		{testContextArg},
+		{testDefaultScope},
+		{testEncrypted},
+		{testLoader},
+		{testExport},
+		{testImportCSV},
+		{testLongJoinAlias},
+		{testRelationAlias},
+		{testTableFunc},
+		{testDateTrunc},
+		{testScanKVMap},
+		{testOnConflictConstraint},
+		{testExecExpectingRows},
+		{testRelationJoinType},
+		{testRegexp},
+		{testValuesTable},
+		{testSystemVersioning},
+		{testEntityCache},
+		{testStats},
+		{testBulkInsertIDs},
+		{testWhereIn},
+		{testColumnGroup},
+		{testScanProjection},
+		{testRelationUniqueKey},
+		{testCollate},
 		{testDriverValuerReturnsItself},
 		{testNoPanicWhenReturningNullColumns},
 	}
@@ -365,6 +452,27 @@ func testSelectMap(t *testing.T, db *bun.DB) {
 	}
 }
 
+func testSelectMapTypes(t *testing.T, db *bun.DB) {
+	var m map[string]interface{}
+	err := db.NewSelect().
+		ColumnExpr("CAST(1.5 AS DECIMAL(10, 2)) AS num").
+		ColumnExpr("CAST('hello' AS VARCHAR(10)) AS str").
+		Scan(ctx, &m)
+	require.NoError(t, err)
+
+	if db.Dialect().Name() == dialect.SQLite {
+		require.Equal(t, 1.5, m["num"])
+	} else {
+		require.Equal(t, "1.50", m["num"])
+	}
+	switch db.Dialect().Name() {
+	case dialect.MySQL:
+		require.Equal(t, []byte("hello"), m["str"])
+	default:
+		require.Equal(t, "hello", m["str"])
+	}
+}
+
 func testSelectMapSlice(t *testing.T, db *bun.DB) {
 	if !db.Dialect().Features().Has(feature.CTE) {
 		t.Skip()
@@ -675,1078 +783,4114 @@ func testScanRows(t *testing.T, db *bun.DB) {
 	require.Equal(t, []int{3, 2, 1}, nums)
 }
 
-func testRunInTx(t *testing.T, db *bun.DB) {
-	type Counter struct {
-		Count int64
+func testCursor(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64 `bun:",pk"`
+		Name string
 	}
 
-	mustResetModel(t, ctx, db, (*Counter)(nil))
+	mustResetModel(t, ctx, db, (*Model)(nil))
 
-	_, err := db.NewInsert().Model(&Counter{Count: 0}).Exec(ctx)
+	models := []Model{{ID: 1, Name: "one"}, {ID: 2, Name: "two"}, {ID: 3, Name: "three"}}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
 	require.NoError(t, err)
 
-	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		_, err := tx.NewUpdate().Model((*Counter)(nil)).
-			Set("count = count + 1").
-			Where("1 = 1").
-			Exec(ctx)
-		return err
+	cursor, err := db.NewSelect().Model((*Model)(nil)).Order("id").Cursor(ctx)
+	require.NoError(t, err)
+
+	var ids []int64
+	for cursor.Next() {
+		model := new(Model)
+		require.NoError(t, cursor.Scan(model))
+		ids = append(ids, model.ID)
+	}
+	require.NoError(t, cursor.Err())
+	require.NoError(t, cursor.Close())
+	require.Equal(t, []int64{1, 2, 3}, ids)
+
+	discardDB := bun.NewDB(db.DB, db.Dialect(), bun.WithDiscardUnknownColumns())
+	cursor, err = discardDB.NewSelect().Model((*Model)(nil)).ColumnExpr("*, 1 AS unknown").
+		StrictScan().Cursor(ctx)
+	require.NoError(t, err)
+	require.True(t, cursor.Next())
+	err = cursor.Scan(new(Model))
+	require.ErrorContains(t, err, `strict scan`)
+	require.NoError(t, cursor.Close())
+
+	var names []string
+	err = bun.ForEach(ctx, db.NewSelect().Model((*Model)(nil)).Order("id"), func(model *Model) error {
+		names = append(names, model.Name)
+		return nil
 	})
 	require.NoError(t, err)
+	require.Equal(t, []string{"one", "two", "three"}, names)
+
+	ch := make(chan *Model)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- db.NewSelect().Model((*Model)(nil)).Order("id").ScanChan(ctx, ch)
+		close(ch)
+	}()
+
+	ids = ids[:0]
+	for model := range ch {
+		ids = append(ids, model.ID)
+	}
+	require.NoError(t, <-errc)
+	require.Equal(t, []int64{1, 2, 3}, ids)
+}
 
-	var count int
-	err = db.NewSelect().Model((*Counter)(nil)).Scan(ctx, &count)
+func testScanExtras(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID     int64                  `bun:",pk"`
+		Extras map[string]interface{} `bun:",extras"`
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	_, err := db.NewInsert().Model(&Model{ID: 1}).Exec(ctx)
 	require.NoError(t, err)
-	require.Equal(t, 1, count)
 
-	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		if _, err := tx.NewUpdate().Model((*Counter)(nil)).
-			Set("count = count + 1").
-			Where("TRUE").
-			Exec(ctx); err != nil {
-			return err
-		}
-		return errors.New("rollback")
-	})
+	model := new(Model)
+	err = db.NewSelect().
+		Model(model).
+		ColumnExpr("id").
+		ColumnExpr("'hello' AS greeting").
+		ColumnExpr("NULL AS missing").
+		ColumnExpr("1 AS _helper").
+		Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), model.ID)
+	require.Len(t, model.Extras, 2)
+	require.Contains(t, model.Extras, "missing")
+	require.Nil(t, model.Extras["missing"])
+
+	switch v := model.Extras["greeting"].(type) {
+	case string:
+		require.Equal(t, "hello", v)
+	case []byte:
+		require.Equal(t, "hello", string(v))
+	default:
+		t.Fatalf("unexpected type %T", v)
+	}
+}
+
+func testStrictScan(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64  `bun:",pk"`
+		Name string `bun:",notnull"`
+	}
+
+	model := new(Model)
+	err := db.NewSelect().
+		ColumnExpr("1 AS id").
+		ColumnExpr("'x' AS name").
+		ColumnExpr("2 AS unknown").
+		StrictScan().
+		Scan(ctx, model)
 	require.Error(t, err)
+	require.Contains(t, err.Error(), `column "unknown"`)
 
-	err = db.NewSelect().Model((*Counter)(nil)).Scan(ctx, &count)
+	// Helper columns starting with an underscore are ignored.
+	err = db.NewSelect().
+		ColumnExpr("1 AS id").
+		ColumnExpr("'x' AS name").
+		ColumnExpr("2 AS _helper").
+		StrictScan().
+		Scan(ctx, model)
 	require.NoError(t, err)
-	require.Equal(t, 1, count)
+
+	err = db.NewSelect().
+		ColumnExpr("1 AS id").
+		ColumnExpr("NULL AS name").
+		StrictNullScan().
+		Scan(ctx, model)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Model.Name is NOT NULL")
 }
 
-func testJSONSpecialChars(t *testing.T, db *bun.DB) {
+func testTimeConfig(t *testing.T, db *bun.DB) {
 	type Model struct {
-		ID    int                    `bun:",pk,autoincrement"`
-		Attrs map[string]interface{} `bun:"type:json"`
+		ID        int64 `bun:",pk,autoincrement"`
+		CreatedAt time.Time
 	}
 
-	ctx := context.Background()
+	db = bun.NewDB(db.DB, db.Dialect(),
+		bun.WithTimeLocation(time.UTC),
+		bun.WithTimePrecision(time.Millisecond),
+		bun.WithTimestampType("TIMESTAMP"))
+
+	query := db.NewCreateTable().Model((*Model)(nil)).String()
+	require.Contains(t, query, "created_at\" TIMESTAMP")
 
 	mustResetModel(t, ctx, db, (*Model)(nil))
 
-	model := &Model{
-		Attrs: map[string]interface{}{
-			"hello": "\000world\nworld\u0000",
-		},
-	}
-	_, err := db.NewInsert().Model(model).Exec(ctx)
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 123456789, loc)
+
+	_, err := db.NewInsert().Model(&Model{CreatedAt: tm}).Exec(ctx)
 	require.NoError(t, err)
 
-	model = new(Model)
+	model := new(Model)
 	err = db.NewSelect().Model(model).Scan(ctx)
 	require.NoError(t, err)
-	switch db.Dialect().Name() {
-	case dialect.MySQL:
-		require.Equal(t, map[string]interface{}{
-			"hello": "\x00world\nworld\x00",
-		}, model.Attrs)
-	default:
-		require.Equal(t, map[string]interface{}{
-			"hello": "\\u0000world\nworld\\u0000",
-		}, model.Attrs)
+	require.Equal(t, time.UTC, model.CreatedAt.Location())
+	require.Equal(t, tm.UTC().Truncate(time.Millisecond), model.CreatedAt)
+}
+
+func testNetworkTypes(t *testing.T, db *bun.DB) {
+	type Host struct {
+		ID      int64 `bun:",pk,autoincrement"`
+		IP      net.IP
+		Network net.IPNet
+		Addr    netip.Addr
+		Prefix  netip.Prefix
+		MAC     net.HardwareAddr
+		NoAddr  netip.Addr
+	}
+
+	mustResetModel(t, ctx, db, (*Host)(nil))
+
+	_, network, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	mac, err := net.ParseMAC("08:00:2b:01:02:03")
+	require.NoError(t, err)
+
+	src := &Host{
+		IP:      net.ParseIP("192.168.0.1"),
+		Network: *network,
+		Addr:    netip.MustParseAddr("2001:db8::1"),
+		Prefix:  netip.MustParsePrefix("192.168.0.0/16"),
+		MAC:     mac,
 	}
+	_, err = db.NewInsert().Model(src).Exec(ctx)
+	require.NoError(t, err)
+
+	host := new(Host)
+	err = db.NewSelect().Model(host).Scan(ctx)
+	require.NoError(t, err)
+	require.True(t, src.IP.Equal(host.IP))
+	require.Equal(t, src.Network.String(), host.Network.String())
+	require.Equal(t, src.Addr, host.Addr)
+	require.Equal(t, src.Prefix, host.Prefix)
+	require.Equal(t, src.MAC, host.MAC)
+	require.False(t, host.NoAddr.IsValid())
 }
 
-func testJSONInterface(t *testing.T, db *bun.DB) {
+func testUUID(t *testing.T, db *bun.DB) {
 	type Model struct {
-		ID    int         `bun:",pk,autoincrement"`
-		Value interface{} `bun:"type:json"`
+		ID     uuid.UUID `bun:",pk"`
+		Ref    *uuid.UUID
+		Tagged [16]byte `bun:"type:uuid"`
 	}
 
-	ctx := context.Background()
-
 	mustResetModel(t, ctx, db, (*Model)(nil))
 
+	ref := uuid.New()
+	src := &Model{
+		ID:     uuid.New(),
+		Ref:    &ref,
+		Tagged: uuid.New(),
+	}
+	_, err := db.NewInsert().Model(src).Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewInsert().Model(&Model{ID: uuid.New()}).Exec(ctx)
+	require.NoError(t, err)
+
 	model := new(Model)
-	_, err := db.NewInsert().Model(model).Exec(ctx)
+	err = db.NewSelect().Model(model).Where("id = ?", src.ID).Scan(ctx)
 	require.NoError(t, err)
+	require.Equal(t, src, model)
 
-	model = &Model{
-		Value: "hello",
-	}
-	_, err = db.NewInsert().Model(model).Exec(ctx)
+	model = new(Model)
+	err = db.NewSelect().Model(model).Where("id != ?", src.ID).Scan(ctx)
 	require.NoError(t, err)
+	require.Nil(t, model.Ref)
 }
 
-type JSONValue struct {
-	str string
+type countingJSONProvider struct {
+	bunjson.StdProvider
+	marshaled   int
+	unmarshaled int
 }
 
-var _ driver.Valuer = (*JSONValue)(nil)
-
-func (v *JSONValue) Scan(src interface{}) error {
-	switch src := src.(type) {
-	case []byte:
-		v.str = string(src)
-	case string:
-		v.str = src
-	default:
-		panic("not reached")
-	}
-	return nil
+func (p *countingJSONProvider) Marshal(v interface{}) ([]byte, error) {
+	p.marshaled++
+	return p.StdProvider.Marshal(v)
 }
 
-func (v *JSONValue) Value() (driver.Value, error) {
-	return `"driver.Value"`, nil
+func (p *countingJSONProvider) Unmarshal(data []byte, v interface{}) error {
+	p.unmarshaled++
+	return p.StdProvider.Unmarshal(data, v)
 }
 
-func testJSONValuer(t *testing.T, db *bun.DB) {
+func testJSONProvider(t *testing.T, db *bun.DB) {
 	type Model struct {
-		ID    int       `bun:",pk,autoincrement"`
-		Value JSONValue `bun:"type:json"`
+		ID    int64 `bun:",pk,autoincrement"`
+		Attrs map[string]string
+		Tags  []string `bun:",json_omit_empty"`
 	}
 
-	ctx := context.Background()
+	provider := new(countingJSONProvider)
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithJSONProvider(provider))
 
 	mustResetModel(t, ctx, db, (*Model)(nil))
 
-	model := new(Model)
-	_, err := db.NewInsert().Model(model).Exec(ctx)
-	require.NoError(t, err)
-
-	model2 := new(Model)
-	err = db.NewSelect().Model(model2).Scan(ctx)
+	src := &Model{Attrs: map[string]string{"hello": "world"}, Tags: []string{}}
+	_, err := db.NewInsert().Model(src).Exec(ctx)
 	require.NoError(t, err)
-	require.Equal(t, `"driver.Value"`, model2.Value.str)
-}
+	require.Equal(t, 1, provider.marshaled)
 
-func testSelectBool(t *testing.T, db *bun.DB) {
-	var flag bool
-	err := db.NewSelect().ColumnExpr("1").Scan(ctx, &flag)
+	var isNull bool
+	err = db.NewSelect().Model((*Model)(nil)).ColumnExpr("tags IS NULL").Scan(ctx, &isNull)
 	require.NoError(t, err)
-	require.True(t, flag)
+	require.True(t, isNull)
 
-	err = db.NewSelect().ColumnExpr("0").Scan(ctx, &flag)
+	model := new(Model)
+	err = db.NewSelect().Model(model).Scan(ctx)
 	require.NoError(t, err)
-	require.False(t, flag)
+	require.Equal(t, src.Attrs, model.Attrs)
+	require.Nil(t, model.Tags)
+	require.Equal(t, 1, provider.unmarshaled)
 }
 
-func testRawQuery(t *testing.T, db *bun.DB) {
-	var num int
-	err := db.NewRaw("SELECT ?", 123).Scan(ctx, &num)
+func testNull(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID        int64 `bun:",pk,autoincrement"`
+		Name      bun.Null[string]
+		Count     bun.Null[int64]
+		CreatedAt bun.Null[time.Time]
+		Attrs     bun.Null[map[string]string]
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
 
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	src := &Model{
+		Name:      bun.NullOf("hello"),
+		Count:     bun.NullOf(int64(0)),
+		CreatedAt: bun.NullOf(tm),
+		Attrs:     bun.NullOf(map[string]string{"foo": "bar"}),
+	}
+	_, err := db.NewInsert().Model(src).Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewInsert().Model(&Model{}).Exec(ctx)
 	require.NoError(t, err)
-	require.Equal(t, 123, num)
 
-	_ = db.RunInTx(context.Background(), nil, func(ctx context.Context, tx bun.Tx) error {
-		var num int
-		err := db.NewRaw("SELECT ?", 456).Scan(ctx, &num)
-		require.NoError(t, err)
-		require.Equal(t, 456, num)
-		return nil
-	})
+	var models []Model
+	err = db.NewSelect().Model(&models).Order("id").Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, models, 2)
 
-	conn, err := db.Conn(context.Background())
+	require.Equal(t, src.Name, models[0].Name)
+	require.Equal(t, src.Count, models[0].Count)
+	require.True(t, models[0].CreatedAt.Valid)
+	require.True(t, tm.Equal(models[0].CreatedAt.V))
+	require.Equal(t, src.Attrs, models[0].Attrs)
+
+	require.False(t, models[1].Name.Valid)
+	require.False(t, models[1].Count.Valid)
+	require.False(t, models[1].CreatedAt.Valid)
+	require.False(t, models[1].Attrs.Valid)
+
+	var count int
+	count, err = db.NewSelect().Model((*Model)(nil)).Where("name IS NULL").Count(ctx)
 	require.NoError(t, err)
+	require.Equal(t, 1, count)
 
-	err = conn.NewRaw("SELECT ?", 789).Scan(ctx, &num)
+	b, err := json.Marshal(models)
 	require.NoError(t, err)
-	require.Equal(t, 789, num)
+	require.Contains(t, string(b), `"Name":"hello"`)
+	require.Contains(t, string(b), `"Name":null`)
 }
 
-func testFKViolation(t *testing.T, db *bun.DB) {
-	type Deck struct {
-		ID     int `bun:",pk,autoincrement"`
-		UserID int
-	}
+type EventPayload interface {
+	EventName() string
+}
 
-	type User struct {
-		ID int `bun:",pk,autoincrement"`
-	}
+type UserCreated struct {
+	UserID int64
+}
 
-	if db.Dialect().Name() == dialect.SQLite {
-		_, err := db.Exec("PRAGMA foreign_keys = ON;")
-		require.NoError(t, err)
+func (UserCreated) EventName() string { return "user created" }
+
+type OrderPaid struct {
+	OrderID int64
+	Amount  float64
+}
+
+func (*OrderPaid) EventName() string { return "order paid" }
+
+func init() {
+	schema.RegisterDiscriminator("user_created", UserCreated{})
+	schema.RegisterDiscriminator("order_paid", (*OrderPaid)(nil))
+}
+
+func testDiscriminator(t *testing.T, db *bun.DB) {
+	type Event struct {
+		ID          int64        `bun:",pk,autoincrement"`
+		Payload     EventPayload `bun:",discriminator"`
+		PayloadType string
+		Data        EventPayload `bun:",discriminator:payload_type"`
 	}
 
-	for _, model := range []interface{}{(*Deck)(nil), (*User)(nil)} {
-		_, err := db.NewDropTable().Model(model).IfExists().Exec(ctx)
-		require.NoError(t, err)
+	mustResetModel(t, ctx, db, (*Event)(nil))
+
+	src := []Event{
+		{Payload: UserCreated{UserID: 1}, Data: &OrderPaid{OrderID: 2, Amount: 9.5}},
+		{Payload: &OrderPaid{OrderID: 3, Amount: 1}, Data: UserCreated{UserID: 4}},
+		{},
 	}
+	_, err := db.NewInsert().Model(&src).Exec(ctx)
+	require.NoError(t, err)
 
-	mustResetModel(t, ctx, db, (*User)(nil))
-	_, err := db.NewCreateTable().
-		Model((*Deck)(nil)).
-		IfNotExists().
-		ForeignKey("(user_id) REFERENCES users (id) ON DELETE CASCADE").
-		Exec(ctx)
+	var events []Event
+	err = db.NewSelect().Model(&events).Order("id").Scan(ctx)
 	require.NoError(t, err)
-	mustDropTableOnCleanup(t, ctx, db, (*Deck)(nil))
+	require.Len(t, events, 3)
 
-	// Empty deck should violate FK constraint.
-	_, err = db.NewInsert().Model(new(Deck)).Exec(ctx)
-	require.Error(t, err)
+	require.Equal(t, UserCreated{UserID: 1}, events[0].Payload)
+	require.Equal(t, "order_paid", events[0].PayloadType)
+	require.Equal(t, &OrderPaid{OrderID: 2, Amount: 9.5}, events[0].Data)
 
-	// Create a deck that violates the user_id FK constraint
-	deck := &Deck{UserID: 42}
+	require.Equal(t, &OrderPaid{OrderID: 3, Amount: 1}, events[1].Payload)
+	require.Equal(t, "user_created", events[1].PayloadType)
+	require.Equal(t, UserCreated{UserID: 4}, events[1].Data)
 
-	_, err = db.NewInsert().Model(deck).Exec(ctx)
-	require.Error(t, err)
+	require.Nil(t, events[2].Payload)
+	require.Nil(t, events[2].Data)
 
-	decks := []*Deck{deck}
-	_, err = db.NewInsert().Model(&decks).Exec(ctx)
-	require.Error(t, err)
+	// The discriminator column is selected after the field.
+	events = nil
+	err = db.NewSelect().Model(&events).Column("id", "data", "payload_type").Order("id").Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, &OrderPaid{OrderID: 2, Amount: 9.5}, events[0].Data)
+	require.Equal(t, UserCreated{UserID: 4}, events[1].Data)
+	require.Nil(t, events[2].Data)
+
+	type Typed struct {
+		Data        EventPayload `bun:",discriminator:payload_type"`
+		PayloadType string
+	}
+	type EmbeddedEvent struct {
+		bun.BaseModel `bun:"table:events"`
 
-	n, err := db.NewSelect().Model((*Deck)(nil)).Count(ctx)
+		ID int64 `bun:",pk,autoincrement"`
+		Typed
+	}
+
+	var embedded []EmbeddedEvent
+	err = db.NewSelect().Model(&embedded).Order("id").Scan(ctx)
 	require.NoError(t, err)
-	require.Equal(t, 0, n)
+	require.Len(t, embedded, 3)
+	require.Equal(t, "order_paid", embedded[0].PayloadType)
+	require.Equal(t, &OrderPaid{OrderID: 2, Amount: 9.5}, embedded[0].Data)
+	require.Equal(t, UserCreated{UserID: 4}, embedded[1].Data)
 }
 
-func testWithForeignKeysAndRules(t *testing.T, db *bun.DB) {
-	type User struct {
-		ID   int    `bun:",pk"`
-		Type string `bun:",pk"`
-		Name string
-	}
-	type Deck struct {
-		ID       int `bun:",pk"`
-		UserID   int
-		UserType string
-		User     *User `bun:"rel:belongs-to,join:user_id=id,join:user_type=type,on_update:cascade,on_delete:set null"`
+func testBlob(t *testing.T, db *bun.DB) {
+	type File struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Data []byte
 	}
 
-	if db.Dialect().Name() == dialect.SQLite {
-		_, err := db.Exec("PRAGMA foreign_keys = ON;")
-		require.NoError(t, err)
-	}
-
-	for _, model := range []interface{}{(*Deck)(nil), (*User)(nil)} {
-		_, err := db.NewDropTable().Model(model).IfExists().Exec(ctx)
-		require.NoError(t, err)
-	}
+	mustResetModel(t, ctx, db, (*File)(nil))
 
-	mustResetModel(t, ctx, db, (*User)(nil))
-	_, err := db.NewCreateTable().
-		Model((*Deck)(nil)).
-		IfNotExists().
-		WithForeignKeys().
-		Exec(ctx)
+	file := new(File)
+	_, err := db.NewInsert().Model(file).Exec(ctx)
 	require.NoError(t, err)
-	mustDropTableOnCleanup(t, ctx, db, (*Deck)(nil))
 
-	// Empty deck should violate FK constraint.
-	_, err = db.NewInsert().Model(new(Deck)).Exec(ctx)
-	require.Error(t, err)
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i)
+	}
 
-	// Create a deck that violates the user_id FK constraint
-	deck := &Deck{UserID: 42}
+	blob := bun.NewBlob(db, file, "data").ChunkSize(4096)
+	n, err := blob.ReadFrom(ctx, bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), n)
 
-	_, err = db.NewInsert().Model(deck).Exec(ctx)
-	require.Error(t, err)
+	var buf bytes.Buffer
+	n, err = blob.WriteTo(ctx, &buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), n)
+	require.Equal(t, data, buf.Bytes())
 
-	decks := []*Deck{deck}
-	_, err = db.NewInsert().Model(&decks).Exec(ctx)
-	require.Error(t, err)
+	_, err = bun.NewBlob(db, &File{ID: file.ID + 1}, "data").ReadFrom(ctx, bytes.NewReader(data))
+	require.Equal(t, sql.ErrNoRows, err)
+}
 
-	n, err := db.NewSelect().Model((*Deck)(nil)).Count(ctx)
-	require.NoError(t, err)
-	require.Equal(t, 0, n)
+func testLargeObject(t *testing.T, db *bun.DB) {
+	if db.Dialect().Name() != dialect.PG {
+		_, err := bun.CreateLargeObject(ctx, db)
+		require.Error(t, err)
+		return
+	}
 
-	_, err = db.NewInsert().Model(&User{ID: 1, Type: "admin", Name: "root"}).Exec(ctx)
-	require.NoError(t, err)
-	res, err := db.NewInsert().Model(&Deck{UserID: 1, UserType: "admin"}).Exec(ctx)
+	oid, err := bun.CreateLargeObject(ctx, db)
 	require.NoError(t, err)
 
-	affected, err := res.RowsAffected()
-	require.NoError(t, err)
-	require.Equal(t, int64(1), affected)
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i)
+	}
 
-	// Update User ID and check for FK update
-	res, err = db.NewUpdate().Model(&User{}).Where("id = ?", 1).Where("type = ?", "admin").Set("id = ?", 2).Exec(ctx)
+	lo := bun.NewLargeObject(db, oid).ChunkSize(4096)
+	n, err := lo.ReadFrom(ctx, bytes.NewReader(data))
 	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), n)
 
-	affected, err = res.RowsAffected()
+	var buf bytes.Buffer
+	n, err = lo.WriteTo(ctx, &buf)
 	require.NoError(t, err)
-	require.Equal(t, int64(1), affected)
+	require.Equal(t, int64(len(data)), n)
+	require.Equal(t, data, buf.Bytes())
 
-	n, err = db.NewSelect().Model(&Deck{}).Where("user_id = 1").Count(ctx)
-	require.NoError(t, err)
-	require.Equal(t, 0, n)
+	require.NoError(t, lo.Unlink(ctx))
+	_, err = lo.WriteTo(ctx, &buf)
+	require.Error(t, err)
+}
 
-	n, err = db.NewSelect().Model(&Deck{}).Where("user_id = 2").Count(ctx)
-	require.NoError(t, err)
-	require.Equal(t, 1, n)
+type ValidatedModel struct {
+	ID    int64 `bun:",pk,autoincrement"`
+	Name  string
+	Email string
+}
 
-	// Delete user and check for FK delete
-	_, err = db.NewDelete().Model(&User{}).Where("id = ?", 2).Exec(ctx)
-	require.NoError(t, err)
+var _ bun.BeforeValidateHook = (*ValidatedModel)(nil)
 
-	n, err = db.NewSelect().Model(&Deck{}).Where("user_id = 2").Count(ctx)
-	require.NoError(t, err)
-	require.Equal(t, 0, n)
+func (m *ValidatedModel) BeforeValidate(ctx context.Context) error {
+	m.Email = strings.ToLower(strings.TrimSpace(m.Email))
+	return nil
 }
 
-func testWithForeignKeys(t *testing.T, db *bun.DB) {
-	type User struct {
-		ID   int    `bun:",pk,autoincrement"`
-		Type string `bun:",pk"`
-		Name string
-	}
-	type Deck struct {
-		ID       int `bun:",pk,autoincrement"`
-		UserID   int
-		UserType string
-		User     *User `bun:"rel:belongs-to,join:user_id=id,join:user_type=type"`
-	}
+var _ bun.ValidateHook = (*ValidatedModel)(nil)
 
-	if db.Dialect().Name() == dialect.SQLite {
-		_, err := db.Exec("PRAGMA foreign_keys = ON;")
-		require.NoError(t, err)
+func (m *ValidatedModel) Validate(ctx context.Context) error {
+	verr := new(bun.ValidationError)
+	if m.Name == "" {
+		verr.Add("name", "is required")
 	}
-
-	for _, model := range []interface{}{(*Deck)(nil), (*User)(nil)} {
-		_, err := db.NewDropTable().Model(model).IfExists().Exec(ctx)
-		require.NoError(t, err)
+	if !strings.Contains(m.Email, "@") {
+		verr.Add("email", "is invalid")
 	}
+	return verr.Err()
+}
 
-	mustResetModel(t, ctx, db, (*User)(nil))
+func testValidation(t *testing.T, db *bun.DB) {
+	mustResetModel(t, ctx, db, (*ValidatedModel)(nil))
 
-	_, err := db.NewCreateTable().
-		Model((*Deck)(nil)).
-		IfNotExists().
-		WithForeignKeys().
-		Exec(ctx)
+	model := &ValidatedModel{Name: "foo", Email: " FOO@Example.com "}
+	_, err := db.NewInsert().Model(model).Exec(ctx)
 	require.NoError(t, err)
-	mustDropTableOnCleanup(t, ctx, db, (*Deck)(nil))
-
-	// Empty deck should violate FK constraint.
-	_, err = db.NewInsert().Model(new(Deck)).Exec(ctx)
-	require.Error(t, err)
-
-	// Create a deck that violates the user_id FK constraint
-	deck := &Deck{UserID: 42}
+	require.Equal(t, "foo@example.com", model.Email)
 
-	_, err = db.NewInsert().Model(deck).Exec(ctx)
-	require.Error(t, err)
-
-	decks := []*Deck{deck}
-	_, err = db.NewInsert().Model(&decks).Exec(ctx)
-	require.Error(t, err)
-
-	n, err := db.NewSelect().Model((*Deck)(nil)).Count(ctx)
-	require.NoError(t, err)
-	require.Equal(t, 0, n)
+	model.Name = ""
+	_, err = db.NewUpdate().Model(model).WherePK().Exec(ctx)
+	var verr *bun.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, []bun.FieldError{{Field: "name", Message: "is required"}}, verr.Errors)
 
-	_, err = db.NewInsert().Model(&User{ID: 1, Type: "admin", Name: "root"}).Exec(ctx)
-	require.NoError(t, err)
-	res, err := db.NewInsert().Model(&Deck{UserID: 1, UserType: "admin"}).Exec(ctx)
-	require.NoError(t, err)
+	models := []ValidatedModel{{Name: "bar", Email: "bar@example.com"}, {Email: "baz"}}
+	_, err = db.NewInsert().Model(&models).Exec(ctx)
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "bun: validation failed: [1].name: is required; [1].email: is invalid", err.Error())
 
-	affected, err := res.RowsAffected()
-	require.NoError(t, err)
-	require.Equal(t, int64(1), affected)
+	ptrs := []*ValidatedModel{{Name: "qux", Email: "qux@example.com"}, nil}
+	_, err = db.NewInsert().Model(&ptrs).Exec(ctx)
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, []bun.FieldError{{Field: "[1]", Message: "is nil"}}, verr.Errors)
 
-	// Select with Relation should work
-	d := Deck{}
-	err = db.NewSelect().Model(&d).Relation("User").Scan(ctx)
+	count, err := db.NewSelect().Model((*ValidatedModel)(nil)).Where("name = ?", "foo").Count(ctx)
 	require.NoError(t, err)
-	require.NotNil(t, d.User)
-	require.Equal(t, d.User.Name, "root")
+	require.Equal(t, 1, count)
 }
 
-func testWithForeignKeysHasMany(t *testing.T, db *bun.DB) {
-	type User struct {
-		ID     int `bun:",pk"`
-		DeckID int
-		Name   string
-	}
-	type Deck struct {
-		ID    int     `bun:",pk"`
-		Users []*User `bun:"rel:has-many,join:id=deck_id"`
+func testUpdateChanges(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID        int64 `bun:",pk,autoincrement"`
+		Name      string
+		Email     string
+		CreatedAt time.Time
 	}
 
-	if db.Dialect().Name() == dialect.SQLite {
-		_, err := db.Exec("PRAGMA foreign_keys = ON;")
-		require.NoError(t, err)
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	models := []*Model{
+		{Name: "foo", Email: "foo@example.com", CreatedAt: tm},
+		{Name: "bar", CreatedAt: tm},
 	}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
 
-	for _, model := range []interface{}{(*Deck)(nil), (*User)(nil)} {
-		_, err := db.NewDropTable().Model(model).IfExists().Exec(ctx)
-		require.NoError(t, err)
+	model := &Model{
+		ID:        models[0].ID,
+		Name:      "foo",
+		Email:     "new@example.com",
+		CreatedAt: tm.In(time.FixedZone("UTC+3", 3*3600)),
 	}
+	q := db.NewUpdate().Model(model).WherePK()
 
-	mustResetModel(t, ctx, db, (*User)(nil))
-	_, err := db.NewCreateTable().
-		Model((*Deck)(nil)).
-		IfNotExists().
-		WithForeignKeys().
-		Exec(ctx)
+	changes, err := q.Changes(ctx)
 	require.NoError(t, err)
-	mustDropTableOnCleanup(t, ctx, db, (*Deck)(nil))
+	require.Len(t, changes, 1)
+	require.Equal(t, "email", changes[0].Field.Name)
+	require.Equal(t, "foo@example.com", changes[0].Old)
+	require.Equal(t, "new@example.com", changes[0].New)
 
-	deckID := 1
-	deck := Deck{ID: deckID}
-	_, err = db.NewInsert().Model(&deck).Exec(ctx)
+	_, err = q.Exec(ctx)
 	require.NoError(t, err)
 
-	userID1 := 1
-	userID2 := 2
-	users := []*User{
-		{ID: userID1, DeckID: deckID, Name: "user 1"},
-		{ID: userID2, DeckID: deckID, Name: "user 2"},
-	}
+	var queries int
+	db = bun.NewDB(db.DB, db.Dialect())
+	db.AddQueryHook(&queryHook{
+		beforeQuery: func(ctx context.Context, event *bun.QueryEvent) context.Context {
+			queries++
+			return ctx
+		},
+	})
 
-	res, err := db.NewInsert().Model(&users).Exec(ctx)
+	reversed := []*Model{models[1], models[0]}
+	q = db.NewUpdate().Model(&reversed).Bulk()
+	original, err := q.Original(ctx)
 	require.NoError(t, err)
+	require.Equal(t, 1, queries)
 
-	affected, err := res.RowsAffected()
-	require.NoError(t, err)
-	require.Equal(t, int64(2), affected)
+	originals := *original.(*[]*Model)
+	require.Len(t, originals, 2)
+	require.Equal(t, "bar", originals[0].Name)
+	require.Equal(t, "new@example.com", originals[1].Email)
 
-	err = db.NewSelect().Model(&deck).Relation("Users").Scan(ctx)
-	require.NoError(t, err)
-	require.Len(t, deck.Users, 2)
+	missing := []*Model{models[0], {ID: 12345}}
+	_, err = db.NewUpdate().Model(&missing).Bulk().Original(ctx)
+	require.Equal(t, sql.ErrNoRows, err)
+
+	_, err = db.NewUpdate().Model(&Model{ID: 12345}).WherePK().Changes(ctx)
+	require.Equal(t, sql.ErrNoRows, err)
 }
 
-func testWithPointerForeignKeysHasMany(t *testing.T, db *bun.DB) {
-	type User struct {
-		ID     *int `bun:",pk"`
-		DeckID *int
-		Name   string
-	}
-	type Deck struct {
-		ID    *int    `bun:",pk"`
-		Users []*User `bun:"rel:has-many,join:id=deck_id"`
+func testRegisterModelHook(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Name string
 	}
 
-	if db.Dialect().Name() == dialect.SQLite {
-		_, err := db.Exec("PRAGMA foreign_keys = ON;")
-		require.NoError(t, err)
-	}
+	var calls []string
+	db.RegisterModelHook((*Model)(nil), bun.BeforeInsert, func(ctx context.Context, query bun.Query) error {
+		calls = append(calls, "BeforeInsert")
+		query.GetModel().Value().(*Model).Name = "from hook"
+		return nil
+	})
+	db.RegisterModelHook((*Model)(nil), bun.AfterInsert, func(ctx context.Context, query bun.Query) error {
+		calls = append(calls, "AfterInsert")
+		return nil
+	})
+	db.RegisterModelHook((*Model)(nil), bun.BeforeDelete, func(ctx context.Context, query bun.Query) error {
+		return errors.New("delete is not allowed")
+	})
 
-	for _, model := range []interface{}{(*Deck)(nil), (*User)(nil)} {
-		_, err := db.NewDropTable().Model(model).IfExists().Exec(ctx)
-		require.NoError(t, err)
-	}
+	mustResetModel(t, ctx, db, (*Model)(nil))
 
-	mustResetModel(t, ctx, db, (*User)(nil))
-	_, err := db.NewCreateTable().
-		Model((*Deck)(nil)).
-		IfNotExists().
-		WithForeignKeys().
-		Exec(ctx)
+	model := new(Model)
+	_, err := db.NewInsert().Model(model).Exec(ctx)
 	require.NoError(t, err)
-	mustDropTableOnCleanup(t, ctx, db, (*Deck)(nil))
+	require.Equal(t, []string{"BeforeInsert", "AfterInsert"}, calls)
 
-	deckID := 1
-	deck := Deck{ID: &deckID}
-	_, err = db.NewInsert().Model(&deck).Exec(ctx)
+	err = db.NewSelect().Model(model).WherePK().Scan(ctx)
 	require.NoError(t, err)
+	require.Equal(t, "from hook", model.Name)
 
-	userID1 := 1
-	userID2 := 2
-	users := []*User{
-		{ID: &userID1, DeckID: &deckID, Name: "user 1"},
-		{ID: &userID2, DeckID: &deckID, Name: "user 2"},
+	_, err = db.NewDelete().Model(model).WherePK().Exec(ctx)
+	require.EqualError(t, err, "delete is not allowed")
+}
+
+func testSkipHooks(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Name string
 	}
 
-	res, err := db.NewInsert().Model(&users).Exec(ctx)
-	require.NoError(t, err)
+	var calls []string
+	for _, hook := range []bun.ModelHookType{bun.BeforeInsert, bun.AfterInsert} {
+		hook := hook
+		db.RegisterModelHook((*Model)(nil), hook, func(ctx context.Context, query bun.Query) error {
+			calls = append(calls, hook.String())
+			return nil
+		})
+	}
 
-	affected, err := res.RowsAffected()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	_, err := db.NewInsert().Model(&Model{}).SkipHooks().Exec(ctx)
 	require.NoError(t, err)
-	require.Equal(t, int64(2), affected)
+	require.Empty(t, calls)
 
-	err = db.NewSelect().Model(&deck).Relation("Users").Scan(ctx)
+	_, err = db.NewInsert().Model(&Model{}).SkipHook(bun.BeforeInsert).Exec(ctx)
 	require.NoError(t, err)
-	require.Len(t, deck.Users, 2)
+	require.Equal(t, []string{"AfterInsert"}, calls)
 }
 
-func testInterfaceAny(t *testing.T, db *bun.DB) {
-	switch db.Dialect().Name() {
-	case dialect.MySQL:
-		t.Skip()
+func testRunInTx(t *testing.T, db *bun.DB) {
+	type Counter struct {
+		Count int64
 	}
 
-	type Model struct {
-		Value interface{}
-	}
+	mustResetModel(t, ctx, db, (*Counter)(nil))
 
-	model := new(Model)
-	err := db.NewSelect().ColumnExpr("NULL AS value").Scan(ctx, model)
+	_, err := db.NewInsert().Model(&Counter{Count: 0}).Exec(ctx)
 	require.NoError(t, err)
-	require.Nil(t, model.Value)
 
-	model = new(Model)
-	err = db.NewSelect().ColumnExpr(`'hello' AS value`).Scan(ctx, model)
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model((*Counter)(nil)).
+			Set("count = count + 1").
+			Where("1 = 1").
+			Exec(ctx)
+		return err
+	})
 	require.NoError(t, err)
-	require.Equal(t, "hello", model.Value)
 
-	model = new(Model)
-	err = db.NewSelect().ColumnExpr(`42 AS value`).Scan(ctx, model)
+	var count int
+	err = db.NewSelect().Model((*Counter)(nil)).Scan(ctx, &count)
 	require.NoError(t, err)
-	require.Equal(t, int64(42), model.Value)
-}
-
-func testInterfaceJSON(t *testing.T, db *bun.DB) {
-	type Model struct {
-		Value interface{} `bun:"type:json"`
-	}
+	require.Equal(t, 1, count)
 
-	model := new(Model)
-	err := db.NewSelect().ColumnExpr("NULL AS value").Scan(ctx, model)
-	require.NoError(t, err)
-	require.Nil(t, model.Value)
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewUpdate().Model((*Counter)(nil)).
+			Set("count = count + 1").
+			Where("TRUE").
+			Exec(ctx); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	require.Error(t, err)
 
-	model = new(Model)
-	err = db.NewSelect().ColumnExpr(`'"hello"' AS value`).Scan(ctx, model)
+	err = db.NewSelect().Model((*Counter)(nil)).Scan(ctx, &count)
 	require.NoError(t, err)
-	require.Equal(t, "hello", model.Value)
+	require.Equal(t, 1, count)
 }
 
-func testScanRawMessage(t *testing.T, db *bun.DB) {
+func testJSONSpecialChars(t *testing.T, db *bun.DB) {
 	type Model struct {
-		ID    int64 `bun:",pk,autoincrement"`
-		Value json.RawMessage
+		ID    int                    `bun:",pk,autoincrement"`
+		Attrs map[string]interface{} `bun:"type:json"`
 	}
 
 	ctx := context.Background()
 
 	mustResetModel(t, ctx, db, (*Model)(nil))
 
-	models := []Model{
-		{Value: json.RawMessage(`"hello"`)},
-		{Value: json.RawMessage(`"world"`)},
+	model := &Model{
+		Attrs: map[string]interface{}{
+			"hello": "\000world\nworld\u0000",
+		},
 	}
-	_, err := db.NewInsert().Model(&models).Exec(ctx)
-	require.NoError(t, err)
-
-	var models1 []Model
-	err = db.NewSelect().Model(&models1).Order("id ASC").Scan(ctx)
+	_, err := db.NewInsert().Model(model).Exec(ctx)
 	require.NoError(t, err)
 
-	var models2 []Model
-	err = db.NewSelect().Model(&models2).Order("id DESC").Scan(ctx)
+	model = new(Model)
+	err = db.NewSelect().Model(model).Scan(ctx)
 	require.NoError(t, err)
-
-	require.Equal(t, models, models1)
+	switch db.Dialect().Name() {
+	case dialect.MySQL:
+		require.Equal(t, map[string]interface{}{
+			"hello": "\x00world\nworld\x00",
+		}, model.Attrs)
+	default:
+		require.Equal(t, map[string]interface{}{
+			"hello": "\\u0000world\nworld\\u0000",
+		}, model.Attrs)
+	}
 }
 
-func testPointers(t *testing.T, db *bun.DB) {
+func testJSONInterface(t *testing.T, db *bun.DB) {
 	type Model struct {
-		ID  *int64 `bun:",default:0"`
-		Str *string
+		ID    int         `bun:",pk,autoincrement"`
+		Value interface{} `bun:"type:json"`
 	}
 
 	ctx := context.Background()
 
-	for _, id := range []int64{-1, 0, 1} {
-		mustResetModel(t, ctx, db, (*Model)(nil))
-
-		var model Model
-		if id >= 0 {
-			str := "hello"
-			model.ID = &id
-			model.Str = &str
-
-		}
+	mustResetModel(t, ctx, db, (*Model)(nil))
 
-		_, err := db.NewInsert().Model(&model).Exec(ctx)
-		require.NoError(t, err)
+	model := new(Model)
+	_, err := db.NewInsert().Model(model).Exec(ctx)
+	require.NoError(t, err)
 
-		var model2 Model
-		err = db.NewSelect().Model(&model2).Order("id ASC").Scan(ctx)
-		require.NoError(t, err)
+	model = &Model{
+		Value: "hello",
 	}
-}
-
-func testExists(t *testing.T, db *bun.DB) {
-	ctx := context.Background()
-
-	exists, err := db.NewSelect().ColumnExpr("1").Exists(ctx)
+	_, err = db.NewInsert().Model(model).Exec(ctx)
 	require.NoError(t, err)
-	require.True(t, exists)
+}
 
-	exists, err = db.NewSelect().ColumnExpr("1").Where("1 = 2").Exists(ctx)
-	require.NoError(t, err)
-	require.False(t, exists)
+type JSONValue struct {
+	str string
 }
 
-func testScanTimeIntoString(t *testing.T, db *bun.DB) {
-	ctx := context.Background()
+var _ driver.Valuer = (*JSONValue)(nil)
 
-	var str string
-	err := db.NewSelect().ColumnExpr("CURRENT_TIMESTAMP").Scan(ctx, &str)
-	require.NoError(t, err)
-	require.NotZero(t, str)
+func (v *JSONValue) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		v.str = string(src)
+	case string:
+		v.str = src
+	default:
+		panic("not reached")
+	}
+	return nil
 }
 
-func testModelNonPointer(t *testing.T, db *bun.DB) {
-	type Model struct{}
-
-	_, err := db.NewInsert().Model(Model{}).ExcludeColumn("id").Returning("id").Exec(ctx)
-	require.Error(t, err)
-	require.Equal(t, "bun: Model(non-pointer dbtest_test.Model)", err.Error())
+func (v *JSONValue) Value() (driver.Value, error) {
+	return `"driver.Value"`, nil
 }
 
-func testBinaryData(t *testing.T, db *bun.DB) {
+func testJSONValuer(t *testing.T, db *bun.DB) {
 	type Model struct {
-		ID   int64 `bun:",pk,autoincrement"`
-		Data []byte
+		ID    int       `bun:",pk,autoincrement"`
+		Value JSONValue `bun:"type:json"`
 	}
 
 	ctx := context.Background()
+
 	mustResetModel(t, ctx, db, (*Model)(nil))
 
-	_, err := db.NewInsert().Model(&Model{Data: []byte("hello")}).Exec(ctx)
+	model := new(Model)
+	_, err := db.NewInsert().Model(model).Exec(ctx)
 	require.NoError(t, err)
 
-	var model Model
-	err = db.NewSelect().Model(&model).Scan(ctx)
+	model2 := new(Model)
+	err = db.NewSelect().Model(model2).Scan(ctx)
 	require.NoError(t, err)
-	require.Equal(t, []byte("hello"), model.Data)
+	require.Equal(t, `"driver.Value"`, model2.Value.str)
 }
 
-func testUpsert(t *testing.T, db *bun.DB) {
-	if db.Dialect().Name() == dialect.MSSQL {
-		t.Skip("mssql")
-	}
-
-	type Model struct {
-		ID  int64 `bun:",pk,autoincrement"`
-		Str string
-	}
+func testSelectBool(t *testing.T, db *bun.DB) {
+	var flag bool
+	err := db.NewSelect().ColumnExpr("1").Scan(ctx, &flag)
+	require.NoError(t, err)
+	require.True(t, flag)
 
-	ctx := context.Background()
-	mustResetModel(t, ctx, db, (*Model)(nil))
+	err = db.NewSelect().ColumnExpr("0").Scan(ctx, &flag)
+	require.NoError(t, err)
+	require.False(t, flag)
+}
 
-	model := &Model{ID: 1, Str: "hello"}
+func testRawQuery(t *testing.T, db *bun.DB) {
+	var num int
+	err := db.NewRaw("SELECT ?", 123).Scan(ctx, &num)
 
-	_, err := db.NewInsert().Model(model).Exec(ctx)
 	require.NoError(t, err)
+	require.Equal(t, 123, num)
 
-	model.Str = "world"
-
-	switch db.Dialect().Name() {
-	case dialect.MySQL:
-		_, err := db.NewInsert().Model(model).On("DUPLICATE KEY UPDATE").Exec(ctx)
-		require.NoError(t, err)
-	default:
-		_, err := db.NewInsert().Model(model).On("CONFLICT (id) DO UPDATE").Exec(ctx)
+	_ = db.RunInTx(context.Background(), nil, func(ctx context.Context, tx bun.Tx) error {
+		var num int
+		err := db.NewRaw("SELECT ?", 456).Scan(ctx, &num)
 		require.NoError(t, err)
-	}
+		require.Equal(t, 456, num)
+		return nil
+	})
 
-	err = db.NewSelect().Model(model).WherePK().Scan(ctx)
+	conn, err := db.Conn(context.Background())
 	require.NoError(t, err)
-	require.Equal(t, "world", model.Str)
+
+	err = conn.NewRaw("SELECT ?", 789).Scan(ctx, &num)
+	require.NoError(t, err)
+	require.Equal(t, 789, num)
 }
 
-func testMultiUpdate(t *testing.T, db *bun.DB) {
-	if !db.Dialect().Features().Has(feature.CTE) {
-		t.Skip()
-		return
+func testFKViolation(t *testing.T, db *bun.DB) {
+	type Deck struct {
+		ID     int `bun:",pk,autoincrement"`
+		UserID int
 	}
 
-	type Model struct {
-		ID  int64 `bun:",pk,autoincrement"`
-		Str string
+	type User struct {
+		ID int `bun:",pk,autoincrement"`
 	}
 
-	ctx := context.Background()
-	mustResetModel(t, ctx, db, (*Model)(nil))
-
-	model := &Model{ID: 1, Str: "hello"}
-
-	_, err := db.NewInsert().Model(model).Exec(ctx)
-	require.NoError(t, err)
+	if db.Dialect().Name() == dialect.SQLite {
+		_, err := db.Exec("PRAGMA foreign_keys = ON;")
+		require.NoError(t, err)
+	}
 
-	selq := db.NewSelect().Model(new(Model))
+	for _, model := range []interface{}{(*Deck)(nil), (*User)(nil)} {
+		_, err := db.NewDropTable().Model(model).IfExists().Exec(ctx)
+		require.NoError(t, err)
+	}
 
-	_, err = db.NewUpdate().
-		With("src", selq).
-		TableExpr("models").
-		Table("src").
-		Set("? = src.str", db.UpdateFQN("models", "str")).
-		Where("models.id = src.id").
+	mustResetModel(t, ctx, db, (*User)(nil))
+	_, err := db.NewCreateTable().
+		Model((*Deck)(nil)).
+		IfNotExists().
+		ForeignKey("(user_id) REFERENCES users (id) ON DELETE CASCADE").
 		Exec(ctx)
 	require.NoError(t, err)
-}
-
-func testUpdateWithSkipupdateTag(t *testing.T, db *bun.DB) {
-	type Model struct {
-		ID        int64 `bun:",pk,autoincrement"`
-		Name      string
-		CreatedAt time.Time `bun:",skipupdate"`
-	}
+	mustDropTableOnCleanup(t, ctx, db, (*Deck)(nil))
 
-	ctx := context.Background()
-	mustResetModel(t, ctx, db, (*Model)(nil))
+	// Empty deck should violate FK constraint.
+	_, err = db.NewInsert().Model(new(Deck)).Exec(ctx)
+	require.Error(t, err)
 
-	createdAt := time.Now().Truncate(time.Minute).UTC()
+	// Create a deck that violates the user_id FK constraint
+	deck := &Deck{UserID: 42}
 
-	model := &Model{ID: 1, Name: "foo", CreatedAt: createdAt}
+	_, err = db.NewInsert().Model(deck).Exec(ctx)
+	require.Error(t, err)
 
-	_, err := db.NewInsert().Model(model).Exec(ctx)
+	decks := []*Deck{deck}
+	_, err = db.NewInsert().Model(&decks).Exec(ctx)
+	require.Error(t, err)
+
+	n, err := db.NewSelect().Model((*Deck)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+}
+
+func testWithForeignKeysAndRules(t *testing.T, db *bun.DB) {
+	type User struct {
+		ID   int    `bun:",pk"`
+		Type string `bun:",pk"`
+		Name string
+	}
+	type Deck struct {
+		ID       int `bun:",pk"`
+		UserID   int
+		UserType string
+		User     *User `bun:"rel:belongs-to,join:user_id=id,join:user_type=type,on_update:cascade,on_delete:set null"`
+	}
+
+	if db.Dialect().Name() == dialect.SQLite {
+		_, err := db.Exec("PRAGMA foreign_keys = ON;")
+		require.NoError(t, err)
+	}
+
+	for _, model := range []interface{}{(*Deck)(nil), (*User)(nil)} {
+		_, err := db.NewDropTable().Model(model).IfExists().Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	mustResetModel(t, ctx, db, (*User)(nil))
+	_, err := db.NewCreateTable().
+		Model((*Deck)(nil)).
+		IfNotExists().
+		WithForeignKeys().
+		Exec(ctx)
+	require.NoError(t, err)
+	mustDropTableOnCleanup(t, ctx, db, (*Deck)(nil))
+
+	// Empty deck should violate FK constraint.
+	_, err = db.NewInsert().Model(new(Deck)).Exec(ctx)
+	require.Error(t, err)
+
+	// Create a deck that violates the user_id FK constraint
+	deck := &Deck{UserID: 42}
+
+	_, err = db.NewInsert().Model(deck).Exec(ctx)
+	require.Error(t, err)
+
+	decks := []*Deck{deck}
+	_, err = db.NewInsert().Model(&decks).Exec(ctx)
+	require.Error(t, err)
+
+	n, err := db.NewSelect().Model((*Deck)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	_, err = db.NewInsert().Model(&User{ID: 1, Type: "admin", Name: "root"}).Exec(ctx)
+	require.NoError(t, err)
+	res, err := db.NewInsert().Model(&Deck{UserID: 1, UserType: "admin"}).Exec(ctx)
+	require.NoError(t, err)
+
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affected)
+
+	// Update User ID and check for FK update
+	res, err = db.NewUpdate().Model(&User{}).Where("id = ?", 1).Where("type = ?", "admin").Set("id = ?", 2).Exec(ctx)
+	require.NoError(t, err)
+
+	affected, err = res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affected)
+
+	n, err = db.NewSelect().Model(&Deck{}).Where("user_id = 1").Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	n, err = db.NewSelect().Model(&Deck{}).Where("user_id = 2").Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// Delete user and check for FK delete
+	_, err = db.NewDelete().Model(&User{}).Where("id = ?", 2).Exec(ctx)
+	require.NoError(t, err)
+
+	n, err = db.NewSelect().Model(&Deck{}).Where("user_id = 2").Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+}
+
+func testWithForeignKeys(t *testing.T, db *bun.DB) {
+	type User struct {
+		ID   int    `bun:",pk,autoincrement"`
+		Type string `bun:",pk"`
+		Name string
+	}
+	type Deck struct {
+		ID       int `bun:",pk,autoincrement"`
+		UserID   int
+		UserType string
+		User     *User `bun:"rel:belongs-to,join:user_id=id,join:user_type=type"`
+	}
+
+	if db.Dialect().Name() == dialect.SQLite {
+		_, err := db.Exec("PRAGMA foreign_keys = ON;")
+		require.NoError(t, err)
+	}
+
+	for _, model := range []interface{}{(*Deck)(nil), (*User)(nil)} {
+		_, err := db.NewDropTable().Model(model).IfExists().Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	mustResetModel(t, ctx, db, (*User)(nil))
+
+	_, err := db.NewCreateTable().
+		Model((*Deck)(nil)).
+		IfNotExists().
+		WithForeignKeys().
+		Exec(ctx)
+	require.NoError(t, err)
+	mustDropTableOnCleanup(t, ctx, db, (*Deck)(nil))
+
+	// Empty deck should violate FK constraint.
+	_, err = db.NewInsert().Model(new(Deck)).Exec(ctx)
+	require.Error(t, err)
+
+	// Create a deck that violates the user_id FK constraint
+	deck := &Deck{UserID: 42}
+
+	_, err = db.NewInsert().Model(deck).Exec(ctx)
+	require.Error(t, err)
+
+	decks := []*Deck{deck}
+	_, err = db.NewInsert().Model(&decks).Exec(ctx)
+	require.Error(t, err)
+
+	n, err := db.NewSelect().Model((*Deck)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	_, err = db.NewInsert().Model(&User{ID: 1, Type: "admin", Name: "root"}).Exec(ctx)
+	require.NoError(t, err)
+	res, err := db.NewInsert().Model(&Deck{UserID: 1, UserType: "admin"}).Exec(ctx)
+	require.NoError(t, err)
+
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affected)
+
+	// Select with Relation should work
+	d := Deck{}
+	err = db.NewSelect().Model(&d).Relation("User").Scan(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, d.User)
+	require.Equal(t, d.User.Name, "root")
+}
+
+func testWithForeignKeysHasMany(t *testing.T, db *bun.DB) {
+	type User struct {
+		ID     int `bun:",pk"`
+		DeckID int
+		Name   string
+	}
+	type Deck struct {
+		ID    int     `bun:",pk"`
+		Users []*User `bun:"rel:has-many,join:id=deck_id"`
+	}
+
+	if db.Dialect().Name() == dialect.SQLite {
+		_, err := db.Exec("PRAGMA foreign_keys = ON;")
+		require.NoError(t, err)
+	}
+
+	for _, model := range []interface{}{(*Deck)(nil), (*User)(nil)} {
+		_, err := db.NewDropTable().Model(model).IfExists().Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	mustResetModel(t, ctx, db, (*User)(nil))
+	_, err := db.NewCreateTable().
+		Model((*Deck)(nil)).
+		IfNotExists().
+		WithForeignKeys().
+		Exec(ctx)
+	require.NoError(t, err)
+	mustDropTableOnCleanup(t, ctx, db, (*Deck)(nil))
+
+	deckID := 1
+	deck := Deck{ID: deckID}
+	_, err = db.NewInsert().Model(&deck).Exec(ctx)
+	require.NoError(t, err)
+
+	userID1 := 1
+	userID2 := 2
+	users := []*User{
+		{ID: userID1, DeckID: deckID, Name: "user 1"},
+		{ID: userID2, DeckID: deckID, Name: "user 2"},
+	}
+
+	res, err := db.NewInsert().Model(&users).Exec(ctx)
+	require.NoError(t, err)
+
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), affected)
+
+	err = db.NewSelect().Model(&deck).Relation("Users").Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, deck.Users, 2)
+}
+
+func testWithPointerForeignKeysHasMany(t *testing.T, db *bun.DB) {
+	type User struct {
+		ID     *int `bun:",pk"`
+		DeckID *int
+		Name   string
+	}
+	type Deck struct {
+		ID    *int    `bun:",pk"`
+		Users []*User `bun:"rel:has-many,join:id=deck_id"`
+	}
+
+	if db.Dialect().Name() == dialect.SQLite {
+		_, err := db.Exec("PRAGMA foreign_keys = ON;")
+		require.NoError(t, err)
+	}
+
+	for _, model := range []interface{}{(*Deck)(nil), (*User)(nil)} {
+		_, err := db.NewDropTable().Model(model).IfExists().Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	mustResetModel(t, ctx, db, (*User)(nil))
+	_, err := db.NewCreateTable().
+		Model((*Deck)(nil)).
+		IfNotExists().
+		WithForeignKeys().
+		Exec(ctx)
+	require.NoError(t, err)
+	mustDropTableOnCleanup(t, ctx, db, (*Deck)(nil))
+
+	deckID := 1
+	deck := Deck{ID: &deckID}
+	_, err = db.NewInsert().Model(&deck).Exec(ctx)
+	require.NoError(t, err)
+
+	userID1 := 1
+	userID2 := 2
+	users := []*User{
+		{ID: &userID1, DeckID: &deckID, Name: "user 1"},
+		{ID: &userID2, DeckID: &deckID, Name: "user 2"},
+	}
+
+	res, err := db.NewInsert().Model(&users).Exec(ctx)
+	require.NoError(t, err)
+
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), affected)
+
+	err = db.NewSelect().Model(&deck).Relation("Users").Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, deck.Users, 2)
+}
+
+func testInterfaceAny(t *testing.T, db *bun.DB) {
+	switch db.Dialect().Name() {
+	case dialect.MySQL:
+		t.Skip()
+	}
+
+	type Model struct {
+		Value interface{}
+	}
+
+	model := new(Model)
+	err := db.NewSelect().ColumnExpr("NULL AS value").Scan(ctx, model)
+	require.NoError(t, err)
+	require.Nil(t, model.Value)
+
+	model = new(Model)
+	err = db.NewSelect().ColumnExpr(`'hello' AS value`).Scan(ctx, model)
+	require.NoError(t, err)
+	require.Equal(t, "hello", model.Value)
+
+	model = new(Model)
+	err = db.NewSelect().ColumnExpr(`42 AS value`).Scan(ctx, model)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), model.Value)
+}
+
+func testInterfaceJSON(t *testing.T, db *bun.DB) {
+	type Model struct {
+		Value interface{} `bun:"type:json"`
+	}
+
+	model := new(Model)
+	err := db.NewSelect().ColumnExpr("NULL AS value").Scan(ctx, model)
+	require.NoError(t, err)
+	require.Nil(t, model.Value)
+
+	model = new(Model)
+	err = db.NewSelect().ColumnExpr(`'"hello"' AS value`).Scan(ctx, model)
+	require.NoError(t, err)
+	require.Equal(t, "hello", model.Value)
+}
+
+func testScanRawMessage(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID    int64 `bun:",pk,autoincrement"`
+		Value json.RawMessage
+	}
+
+	ctx := context.Background()
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	models := []Model{
+		{Value: json.RawMessage(`"hello"`)},
+		{Value: json.RawMessage(`"world"`)},
+	}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	var models1 []Model
+	err = db.NewSelect().Model(&models1).Order("id ASC").Scan(ctx)
+	require.NoError(t, err)
+
+	var models2 []Model
+	err = db.NewSelect().Model(&models2).Order("id DESC").Scan(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, models, models1)
+}
+
+func testPointers(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID  *int64 `bun:",default:0"`
+		Str *string
+	}
+
+	ctx := context.Background()
+
+	for _, id := range []int64{-1, 0, 1} {
+		mustResetModel(t, ctx, db, (*Model)(nil))
+
+		var model Model
+		if id >= 0 {
+			str := "hello"
+			model.ID = &id
+			model.Str = &str
+
+		}
+
+		_, err := db.NewInsert().Model(&model).Exec(ctx)
+		require.NoError(t, err)
+
+		var model2 Model
+		err = db.NewSelect().Model(&model2).Order("id ASC").Scan(ctx)
+		require.NoError(t, err)
+	}
+}
+
+func testExists(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+
+	exists, err := db.NewSelect().ColumnExpr("1").Exists(ctx)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = db.NewSelect().ColumnExpr("1").Where("1 = 2").Exists(ctx)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func testScanTimeIntoString(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+
+	var str string
+	err := db.NewSelect().ColumnExpr("CURRENT_TIMESTAMP").Scan(ctx, &str)
+	require.NoError(t, err)
+	require.NotZero(t, str)
+}
+
+func testModelNonPointer(t *testing.T, db *bun.DB) {
+	type Model struct{}
+
+	_, err := db.NewInsert().Model(Model{}).ExcludeColumn("id").Returning("id").Exec(ctx)
+	require.Error(t, err)
+	require.Equal(t, "bun: Model(non-pointer dbtest_test.Model)", err.Error())
+}
+
+func testBinaryData(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Data []byte
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	_, err := db.NewInsert().Model(&Model{Data: []byte("hello")}).Exec(ctx)
+	require.NoError(t, err)
+
+	var model Model
+	err = db.NewSelect().Model(&model).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), model.Data)
+}
+
+func testUpsert(t *testing.T, db *bun.DB) {
+	if db.Dialect().Name() == dialect.MSSQL {
+		t.Skip("mssql")
+	}
+
+	type Model struct {
+		ID  int64 `bun:",pk,autoincrement"`
+		Str string
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	model := &Model{ID: 1, Str: "hello"}
+
+	_, err := db.NewInsert().Model(model).Exec(ctx)
+	require.NoError(t, err)
+
+	model.Str = "world"
+
+	switch db.Dialect().Name() {
+	case dialect.MySQL:
+		_, err := db.NewInsert().Model(model).On("DUPLICATE KEY UPDATE").Exec(ctx)
+		require.NoError(t, err)
+	default:
+		_, err := db.NewInsert().Model(model).On("CONFLICT (id) DO UPDATE").Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	err = db.NewSelect().Model(model).WherePK().Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "world", model.Str)
+}
+
+func testMultiUpdate(t *testing.T, db *bun.DB) {
+	if !db.Dialect().Features().Has(feature.CTE) {
+		t.Skip()
+		return
+	}
+
+	type Model struct {
+		ID  int64 `bun:",pk,autoincrement"`
+		Str string
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	model := &Model{ID: 1, Str: "hello"}
+
+	_, err := db.NewInsert().Model(model).Exec(ctx)
+	require.NoError(t, err)
+
+	selq := db.NewSelect().Model(new(Model))
+
+	_, err = db.NewUpdate().
+		With("src", selq).
+		TableExpr("models").
+		Table("src").
+		Set("? = src.str", db.UpdateFQN("models", "str")).
+		Where("models.id = src.id").
+		Exec(ctx)
+	require.NoError(t, err)
+}
+
+func testUpdateWithSkipupdateTag(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID        int64 `bun:",pk,autoincrement"`
+		Name      string
+		CreatedAt time.Time `bun:",skipupdate"`
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	createdAt := time.Now().Truncate(time.Minute).UTC()
+
+	model := &Model{ID: 1, Name: "foo", CreatedAt: createdAt}
+
+	_, err := db.NewInsert().Model(model).Exec(ctx)
+	require.NoError(t, err)
+	require.NotZero(t, model.CreatedAt)
+
+	//
+	// update field with tag "skipupdate"
+	//
+	model.CreatedAt = model.CreatedAt.Add(2 * time.Minute)
+	_, err = db.NewUpdate().Model(model).WherePK().Exec(ctx)
+	require.NoError(t, err)
+
+	//
+	// check
+	//
+	model_ := new(Model)
+	model_.ID = model.ID
+	err = db.NewSelect().Model(model_).WherePK().Scan(ctx)
+	require.NoError(t, err, "select")
+	require.NotEmpty(t, model_)
+	require.Equal(t, model.ID, model_.ID)
+	require.Equal(t, model.Name, model_.Name)
+	require.Equal(t, createdAt.UTC(), model_.CreatedAt.UTC())
+
+	require.NotEqual(t, model.CreatedAt.UTC(), model_.CreatedAt.UTC())
+}
+
+func testScanAndCount(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID  int64 `bun:",pk,autoincrement"`
+		Str string
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	t.Run("tx", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+				var models []Model
+				count, err := tx.NewSelect().Model(&models).ScanAndCount(ctx)
+				require.NoError(t, err)
+				require.Equal(t, 0, count)
+				return err
+			})
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		src := []Model{
+			{Str: "str1"},
+			{Str: "str2"},
+		}
+		_, err := db.NewInsert().Model(&src).Exec(ctx)
+		require.NoError(t, err)
+
+		var dest []Model
+		count, err := db.NewSelect().Model(&dest).ScanAndCount(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		require.Equal(t, 2, len(dest))
+	})
+}
+
+func testEmbedModelValue(t *testing.T, db *bun.DB) {
+	type DoubleEmbed struct {
+		A string
+		B string
+	}
+	type Embed struct {
+		Foo string
+		Bar string
+		C   DoubleEmbed `bun:"embed:c_"`
+		D   DoubleEmbed `bun:"embed:d_"`
+	}
+	type Model struct {
+		X Embed `bun:"embed:x_"`
+		Y Embed `bun:"embed:y_"`
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	m1 := &Model{
+		X: Embed{
+			Foo: "x.foo",
+			Bar: "x.bar",
+			C: DoubleEmbed{
+				A: "x.c.a",
+				B: "x.c.b",
+			},
+			D: DoubleEmbed{
+				A: "x.d.a",
+				B: "x.d.b",
+			},
+		},
+		Y: Embed{
+			Foo: "y.foo",
+			Bar: "y.bar",
+			C: DoubleEmbed{
+				A: "y.c.a",
+				B: "y.c.b",
+			},
+			D: DoubleEmbed{
+				A: "y.d.a",
+				B: "y.d.b",
+			},
+		},
+	}
+	_, err := db.NewInsert().Model(m1).Exec(ctx)
+	require.NoError(t, err)
+
+	var m2 Model
+	err = db.NewSelect().Model(&m2).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, *m1, m2)
+}
+
+func testEmbedModelPointer(t *testing.T, db *bun.DB) {
+	type DoubleEmbed struct {
+		A string
+		B string
+	}
+	type Embed struct {
+		Foo string
+		Bar string
+		C   *DoubleEmbed `bun:"embed:c_"`
+		D   *DoubleEmbed `bun:"embed:d_"`
+	}
+	type Model struct {
+		X *Embed `bun:"embed:x_"`
+		Y *Embed `bun:"embed:y_"`
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	m1 := &Model{
+		X: &Embed{
+			Foo: "x.foo",
+			Bar: "x.bar",
+			C: &DoubleEmbed{
+				A: "x.c.a",
+				B: "x.c.b",
+			},
+			D: &DoubleEmbed{
+				A: "x.d.a",
+				B: "x.d.b",
+			},
+		},
+		Y: &Embed{
+			Foo: "y.foo",
+			Bar: "y.bar",
+			C: &DoubleEmbed{
+				A: "y.c.a",
+				B: "y.c.b",
+			},
+			D: &DoubleEmbed{
+				A: "y.d.a",
+				B: "y.d.b",
+			},
+		},
+	}
+	_, err := db.NewInsert().Model(m1).Exec(ctx)
+	require.NoError(t, err)
+
+	var m2 Model
+	err = db.NewSelect().Model(&m2).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, *m1, m2)
+}
+
+type JSONField struct {
+	Foo string `json:"foo"`
+}
+
+func (f *JSONField) MarshalJSON() ([]byte, error) {
+	return []byte(`{"foo": "bar"}`), nil
+}
+
+func testJSONMarshaler(t *testing.T, db *bun.DB) {
+	type Model struct {
+		Field *JSONField
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	m1 := &Model{Field: new(JSONField)}
+	_, err := db.NewInsert().Model(m1).Exec(ctx)
+	require.NoError(t, err)
+
+	var m2 Model
+	err = db.NewSelect().Model(&m2).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "bar", m2.Field.Foo)
+}
+
+type DriverValue struct {
+	s string
+}
+
+var _ driver.Valuer = (*DriverValue)(nil)
+
+func (v *DriverValue) Value() (driver.Value, error) {
+	return v.s, nil
+}
+
+func testNilDriverValue(t *testing.T, db *bun.DB) {
+	type Model struct {
+		Value *DriverValue `bun:"type:varchar(100)"`
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	_, err := db.NewInsert().Model(&Model{}).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewInsert().Model(&Model{Value: &DriverValue{s: "hello"}}).Exec(ctx)
+	require.NoError(t, err)
+}
+
+func testNestedRunInTx(t *testing.T, db *bun.DB) {
+	type Counter struct {
+		Count int64
+	}
+
+	mustResetModel(t, ctx, db, (*Counter)(nil))
+
+	insert := func(ctx context.Context, count int64, fail bool) error {
+		return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if _, err := tx.NewInsert().Model(&Counter{Count: count}).Exec(ctx); err != nil {
+				return err
+			}
+			if fail {
+				return errors.New("fake error")
+			}
+			return nil
+		})
+	}
+
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		require.NoError(t, insert(ctx, 1, false))
+		require.Error(t, insert(ctx, 2, true))
+		return insert(ctx, 3, false)
+	})
+	require.NoError(t, err)
+
+	var counts []int64
+	err = db.NewSelect().Model((*Counter)(nil)).Column("count").Order("count").Scan(ctx, &counts)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 3}, counts)
+
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		require.NoError(t, insert(ctx, 4, false))
+		return errors.New("fake error")
+	})
+	require.Error(t, err)
+
+	count, err := db.NewSelect().Model((*Counter)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	// Savepoints can't change the transaction options.
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+			return nil
+		})
+		require.NoError(t, err)
+
+		return db.RunInTx(ctx, &sql.TxOptions{ReadOnly: true}, func(ctx context.Context, tx bun.Tx) error {
+			return nil
+		})
+	})
+	require.ErrorContains(t, err, "nested RunInTx can't change the transaction options")
+}
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "SQLSTATE " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func testRunInTxRetry(t *testing.T, db *bun.DB) {
+	type Counter struct {
+		Count int64
+	}
+
+	mustResetModel(t, ctx, db, (*Counter)(nil))
+
+	var attempts int
+	err := db.RunInTxRetry(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		attempts++
+		if _, err := tx.NewInsert().Model(&Counter{Count: int64(attempts)}).Exec(ctx); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return fmt.Errorf("insert: %w", sqlStateError("40001"))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	var counts []int64
+	err = db.NewSelect().Model((*Counter)(nil)).Column("count").Scan(ctx, &counts)
+	require.NoError(t, err)
+	require.Equal(t, []int64{3}, counts)
+
+	attempts = 0
+	err = db.RunInTxRetry(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		attempts++
+		return sqlStateError("23505")
+	})
+	require.Equal(t, sqlStateError("23505"), err)
+	require.Equal(t, 1, attempts)
+
+	attempts = 0
+	err = db.RunInTxRetry(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		attempts++
+		return errors.New("Error 1213 (40001): Deadlock found when trying to get lock")
+	})
+	require.Error(t, err)
+	require.Equal(t, 4, attempts)
+}
+
+func testTxCallbacks(t *testing.T, db *bun.DB) {
+	var events []string
+	record := func(event string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			events = append(events, event)
+		}
+	}
+
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		tx.OnCommit(record("commit"))
+		tx.OnRollback(record("rollback"))
+
+		_ = tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
+			sp.OnCommit(record("sp1 commit"))
+			sp.OnRollback(record("sp1 rollback"))
+			return errors.New("fake error")
+		})
+
+		require.NoError(t, db.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
+			sp.OnCommit(record("sp2 commit"))
+			return nil
+		}))
+
+		require.Equal(t, []string{"sp1 rollback"}, events)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"sp1 rollback", "commit", "sp2 commit"}, events)
+
+	events = nil
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		tx.OnCommit(record("commit"))
+		tx.OnRollback(record("rollback"))
+		return errors.New("fake error")
+	})
+	require.Error(t, err)
+	require.Equal(t, []string{"rollback"}, events)
+
+	events = nil
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	tx.OnCommit(record("commit"))
+	require.NoError(t, tx.Commit())
+	require.Error(t, tx.Rollback())
+	require.Equal(t, []string{"commit"}, events)
+}
+
+func testContextWithTx(t *testing.T, db *bun.DB) {
+	type Counter struct {
+		Count int64
+	}
+
+	mustResetModel(t, ctx, db, (*Counter)(nil))
+
+	insert := func(ctx context.Context, count int64) error {
+		_, err := db.NewInsert().Model(&Counter{Count: count}).Exec(ctx)
+		return err
+	}
+	countRows := func(ctx context.Context) int {
+		n, err := db.FromContext(ctx).NewSelect().Model((*Counter)(nil)).Count(ctx)
+		require.NoError(t, err)
+		return n
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+
+	txCtx := bun.ContextWithTx(ctx, tx)
+	require.NoError(t, insert(txCtx, 1))
+	require.Equal(t, 1, countRows(txCtx))
+
+	// Raw queries executed with the DB use the transaction too.
+	_, err = db.ExecContext(txCtx, "INSERT INTO ? (?) VALUES (?)",
+		bun.Ident("counters"), bun.Ident("count"), 3)
+	require.NoError(t, err)
+	var n int
+	err = db.QueryRowContext(txCtx, "SELECT count(*) FROM ?", bun.Ident("counters")).Scan(&n)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	// Hooks get the transaction that executes the query.
+	var idb bun.IDB
+	hookDB := bun.NewDB(db.DB, db.Dialect())
+	hookDB.AddQueryHook(&queryHook{
+		beforeQuery: func(ctx context.Context, event *bun.QueryEvent) context.Context {
+			idb = event.IQuery.(interface{ GetIDB() bun.IDB }).GetIDB()
+			return ctx
+		},
+	})
+	_, err = hookDB.NewSelect().Model((*Counter)(nil)).Count(txCtx)
+	require.NoError(t, err)
+	hookTx, ok := idb.(bun.Tx)
+	require.True(t, ok)
+	require.Equal(t, tx.Tx, hookTx.Tx)
+	hookTx.OnCommit(func(ctx context.Context) {})
+
+	_, ok = db.FromContext(txCtx).(bun.Tx)
+	require.True(t, ok)
+	_, ok = db.FromContext(ctx).(*bun.DB)
+	require.True(t, ok)
+
+	require.NoError(t, tx.Rollback())
+	require.Equal(t, 0, countRows(ctx))
+
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := insert(ctx, 2); err != nil {
+			return err
+		}
+		return errors.New("fake error")
+	})
+	require.Error(t, err)
+	require.Equal(t, 0, countRows(ctx))
+}
+
+func testStmtCache(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Name string
+	}
+
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithStmtCache(1))
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	for _, name := range []string{"foo", "bar"} {
+		_, err := db.NewInsert().Model(&Model{Name: name}).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		var models []Model
+		err := db.NewSelect().Model(&models).Order("id").Scan(ctx)
+		require.NoError(t, err)
+		require.Len(t, models, 2)
+		require.Equal(t, "foo", models[0].Name)
+
+		// Evicts the previous statement.
+		count, err := db.NewSelect().Model((*Model)(nil)).Count(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+	}
+
+	// Recreating the table resets the cache.
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	count, err := db.NewSelect().Model((*Model)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	// So do schema changes made with raw queries.
+	var rows []map[string]interface{}
+	err = db.NewRaw("SELECT * FROM ?", bun.Ident("models")).Scan(ctx, &rows)
+	require.NoError(t, err)
+
+	addColumn := "ALTER TABLE ? ADD COLUMN extra int"
+	if db.Dialect().Name() == dialect.MSSQL {
+		addColumn = "ALTER TABLE ? ADD extra int"
+	}
+	_, err = db.NewRaw(addColumn, bun.Ident("models")).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewInsert().Model(&Model{Name: "foo"}).Exec(ctx)
+	require.NoError(t, err)
+
+	rows = nil
+	err = db.NewRaw("SELECT * FROM ?", bun.Ident("models")).Scan(ctx, &rows)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Contains(t, rows[0], "extra")
+
+	// And schema changes made in transactions and on connections.
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.ExecContext(ctx, strings.Replace(addColumn, "extra", "extra2", 1), bun.Ident("models"))
+		return err
+	})
+	require.NoError(t, err)
+
+	rows = nil
+	err = db.NewRaw("SELECT * FROM ?", bun.Ident("models")).Scan(ctx, &rows)
+	require.NoError(t, err)
+	require.Contains(t, rows[0], "extra2")
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, strings.Replace(addColumn, "extra", "extra3", 1), bun.Ident("models"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	rows = nil
+	err = db.NewRaw("SELECT * FROM ?", bun.Ident("models")).Scan(ctx, &rows)
+	require.NoError(t, err)
+	require.Contains(t, rows[0], "extra3")
+
+	db.ResetStmtCache()
+}
+
+func testQueryTimeout(t *testing.T, db *bun.DB) {
+	slowQuery := func(db *bun.DB) *bun.SelectQuery {
+		q := db.NewSelect()
+		switch db.Dialect().Name() {
+		case dialect.PG:
+			return q.ColumnExpr("pg_sleep(1)")
+		case dialect.MySQL:
+			return q.ColumnExpr("SLEEP(1)")
+		default:
+			return q.
+				With("t", db.NewRaw("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT x FROM c")).
+				ColumnExpr("count(*)").
+				Table("t")
+		}
+	}
+
+	if db.Dialect().Name() == dialect.MSSQL {
+		t.Skip("MSSQL does not support WITH RECURSIVE")
+	}
+
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithQueryTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	var v interface{}
+	err := slowQuery(db).Scan(ctx, &v)
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 900*time.Millisecond)
+
+	var num int
+	err = db.NewSelect().ColumnExpr("1").Timeout(time.Second).Scan(ctx, &num)
+	require.NoError(t, err)
+	require.Equal(t, 1, num)
+
+	start = time.Now()
+	rows, err := slowQuery(db).Rows(ctx)
+	if err == nil {
+		for rows.Next() {
+		}
+		err = rows.Err()
+		rows.Close()
+	}
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 900*time.Millisecond)
+}
+
+func testDeleteReturning(t *testing.T, db *bun.DB) {
+	if !db.Dialect().Features().Has(feature.Returning | feature.DeleteReturning) {
+		t.Skip()
+	}
+
+	type Model struct {
+		ID  int64 `bun:",pk,autoincrement"`
+		Str string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	_, err := db.NewInsert().Model(&Model{Str: "hello"}).Exec(ctx)
+	require.NoError(t, err)
+
+	var deleted []Model
+	err = db.NewDelete().
+		Model(&deleted).
+		Where("str = ?", "hello").
+		Returning("*").
+		Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	require.Equal(t, "hello", deleted[0].Str)
+}
+
+type registeredEmail string
+
+type registeredCode string
+
+func testRegisterSQLType(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID      int64 `bun:",pk,autoincrement"`
+		Email   registeredEmail
+		Backup  *registeredEmail
+		Code    registeredCode
+		Country registeredCode `bun:"type:varchar(2)"`
+	}
+
+	schema.RegisterSQLType((*registeredCode)(nil), "char(3)")
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithSQLType((*registeredEmail)(nil), "varchar(100)"))
+
+	table := db.Table(reflect.TypeOf((*Model)(nil)).Elem())
+	require.Equal(t, "varchar(100)", table.FieldMap["email"].CreateTableSQLType)
+	require.Equal(t, "varchar(100)", table.FieldMap["backup"].CreateTableSQLType)
+	require.Equal(t, "char(3)", table.FieldMap["code"].CreateTableSQLType)
+	require.Equal(t, "varchar(2)", table.FieldMap["country"].CreateTableSQLType)
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	email := registeredEmail("backup@example.com")
+	_, err := db.NewInsert().Model(&Model{
+		Email:   "user@example.com",
+		Backup:  &email,
+		Code:    "USA",
+		Country: "US",
+	}).Exec(ctx)
+	require.NoError(t, err)
+
+	model := new(Model)
+	err = db.NewSelect().Model(model).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, registeredEmail("user@example.com"), model.Email)
+	require.Equal(t, email, *model.Backup)
+	require.Equal(t, registeredCode("USA"), model.Code)
+}
+
+func testSQLiteTableOptions(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID        int64 `bun:",pk,autoincrement"`
+		Name      string
+		Active    bool
+		Score     float64
+		CreatedAt time.Time
+	}
+
+	type KeyValue struct {
+		Key   string `bun:",pk"`
+		Value []byte
+	}
+
+	if db.Dialect().Name() != dialect.SQLite {
+		_, err := db.NewCreateTable().Model((*Model)(nil)).Strict().AppendQuery(db.Formatter(), nil)
+		require.Error(t, err)
+		return
+	}
+
+	query := db.NewCreateTable().Model((*Model)(nil)).Strict().String()
+	require.Equal(t, `CREATE TABLE "models" ("id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, `+
+		`"name" TEXT, "active" INTEGER, "score" REAL, "created_at" ANY) STRICT`, query)
+
+	_, err := db.NewDropTable().Model((*Model)(nil)).IfExists().Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewCreateTable().Model((*Model)(nil)).Strict().Exec(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.NewDropTable().Model((*Model)(nil)).IfExists().Exec(ctx)
+	})
+
+	model := &Model{Name: "hello", Active: true, Score: 1.5, CreatedAt: time.Unix(0, 0)}
+	_, err = db.NewInsert().Model(model).Exec(ctx)
+	require.NoError(t, err)
+
+	model2 := new(Model)
+	err = db.NewSelect().Model(model2).Where("id = ?", model.ID).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "hello", model2.Name)
+	require.True(t, model2.Active)
+	require.Equal(t, 1.5, model2.Score)
+	require.True(t, model2.CreatedAt.Equal(model.CreatedAt))
+
+	query = db.NewCreateTable().Model((*KeyValue)(nil)).Strict().WithoutRowID().String()
+	require.Equal(t, `CREATE TABLE "key_values" ("key" TEXT NOT NULL, "value" BLOB, `+
+		`PRIMARY KEY ("key")) STRICT, WITHOUT ROWID`, query)
+
+	mustDropTableOnCleanup(t, ctx, db, (*KeyValue)(nil))
+	_, err = db.NewCreateTable().Model((*KeyValue)(nil)).WithoutRowID().Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewInsert().Model(&KeyValue{Key: "a", Value: []byte("b")}).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewCreateTable().Model((*Model)(nil)).WithoutRowID().AppendQuery(db.Formatter(), nil)
+	require.Error(t, err)
+}
+
+func testGeneratedColumn(t *testing.T, db *bun.DB) {
+	if db.Dialect().Name() == dialect.MSSQL {
+		t.Skip("MSSQL computed columns have no type")
+	}
+
+	type Item struct {
+		ID       int64 `bun:",pk,autoincrement"`
+		Price    int64
+		Quantity int64
+		Total    int64 `bun:",generated:(price * quantity),stored"`
+	}
+
+	mustResetModel(t, ctx, db, (*Item)(nil))
+
+	item := &Item{Price: 3, Quantity: 2, Total: 100}
+	_, err := db.NewInsert().Model(item).Exec(ctx)
+	require.NoError(t, err)
+
+	item.Quantity = 5
+	_, err = db.NewUpdate().Model(item).WherePK().Exec(ctx)
+	require.NoError(t, err)
+
+	item2 := new(Item)
+	err = db.NewSelect().Model(item2).Where("id = ?", item.ID).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(15), item2.Total)
+}
+
+func testRunInTxAndSavepoint(t *testing.T, db *bun.DB) {
+	type Counter struct {
+		Count int64
+	}
+
+	mustResetModel(t, ctx, db, (*Counter)(nil))
+
+	_, err := db.NewInsert().Model(&Counter{Count: 0}).Exec(ctx)
+	require.NoError(t, err)
+
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
+			_, err := sp.NewUpdate().Model((*Counter)(nil)).
+				Set("count = count + 1").
+				Where("1 = 1").
+				Exec(ctx)
+			return err
+		})
+		require.NoError(t, err)
+		// rolling back the transaction should rollback what happened inside savepoint
+		return errors.New("fake error")
+	})
+	require.Error(t, err)
+
+	var count int
+	err = db.NewSelect().Model((*Counter)(nil)).Scan(ctx, &count)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
+			_, err := sp.NewInsert().Model(&Counter{Count: 1}).
+				Exec(ctx)
+			require.NoError(t, err)
+			return err
+		})
+		require.NoError(t, err)
+
+		// ignored on purpose this error
+		// rolling back a savepoint should not affect the transaction
+		// nor other savepoints on the same level
+		_ = tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
+			_, err := sp.NewInsert().Model(&Counter{Count: 2}).
+				Exec(ctx)
+			require.NoError(t, err)
+			return errors.New("fake error")
+		})
+
+		return err
+	})
+	require.NoError(t, err)
+
+	count, err = db.NewSelect().Model((*Counter)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	err = db.ResetModel(ctx, (*Counter)(nil))
+	require.NoError(t, err)
+
+	// happy path, commit transaction, savepoints and sub-savepoints
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(&Counter{Count: 1}).
+			Exec(ctx)
+		require.NoError(t, err)
+
+		err = tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
+			_, err := sp.NewInsert().Model(&Counter{Count: 1}).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+
+			return sp.RunInTx(ctx, nil, func(ctx context.Context, subSp bun.Tx) error {
+				_, err := subSp.NewInsert().Model(&Counter{Count: 1}).
+					Exec(ctx)
+				return err
+			})
+		})
+		require.NoError(t, err)
+
+		err = tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
+			_, err := sp.NewInsert().Model(&Counter{Count: 2}).
+				Exec(ctx)
+			return err
+		})
+
+		return err
+	})
+	require.NoError(t, err)
+
+	count, err = db.NewSelect().Model((*Counter)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 4, count)
+}
+
+type anotherString string
+
+var _ driver.Valuer = (*anotherString)(nil)
+
+func (v anotherString) Value() (driver.Value, error) {
+	return v, nil
+}
+
+func testDriverValuerReturnsItself(t *testing.T, db *bun.DB) {
+	expectedValue := anotherString("example value")
+
+	type Model struct {
+		ID    int           `bun:",pk,autoincrement"`
+		Value anotherString `bun:"value"`
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	model := &Model{Value: expectedValue}
+	_, err := db.NewInsert().Model(model).Exec(ctx)
+	require.Error(t, err)
+}
+
+func testNoPanicWhenReturningNullColumns(t *testing.T, db *bun.DB) {
+	type Model struct {
+		Value     string  `bun:"value,notnull"`
+		NullValue *string `bun:"null_value"`
+	}
+
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	modelSlice := []*Model{{Value: "boom"}}
+
+	require.NotPanics(t, func() {
+		db.NewInsert().Model(&modelSlice).Exec(ctx)
+	})
+}
+
+func mustResetModel(tb testing.TB, ctx context.Context, db *bun.DB, models ...interface{}) {
+	err := db.ResetModel(ctx, models...)
+	require.NoError(tb, err, "must reset model")
+	mustDropTableOnCleanup(tb, ctx, db, models...)
+}
+
+func mustDropTableOnCleanup(tb testing.TB, ctx context.Context, db *bun.DB, models ...interface{}) {
+	tb.Cleanup(func() {
+		for _, model := range models {
+			drop := db.NewDropTable().IfExists().Model(model)
+			_, err := drop.Exec(ctx)
+			require.NoError(tb, err, "must drop table: %q", drop.GetTableName())
+		}
+	})
+}
+
+func testQueryRelease(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Name string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	for i := 0; i < 3; i++ {
+		sq := db.NewSelect().
+			With("cte", db.NewSelect().ColumnExpr("1 AS one")).
+			Model((*Model)(nil)).
+			Column("id").
+			Where("id > ?", i).
+			Group("id").
+			Having("count(*) > 0").
+			Order("id")
+		require.NotEmpty(t, sq.String())
+		sq.Release()
+
+		sq = db.NewSelect().Model((*Model)(nil)).Where("id = ?", 1)
+		require.NotContains(t, sq.String(), "cte")
+		require.NotContains(t, sq.String(), "GROUP BY")
+		require.NotContains(t, sq.String(), "ORDER BY")
+
+		_, err := sq.Count(ctx)
+		require.NoError(t, err)
+		sq.Release()
+
+		iq := db.NewInsert().Model(&Model{Name: "foo"}).Value("name", "?", "bar")
+		_, err = iq.Exec(ctx)
+		require.NoError(t, err)
+		iq.Release()
+
+		uq := db.NewUpdate().Model((*Model)(nil)).Set("name = ?", "baz").Where("name = ?", "bar")
+		_, err = uq.Exec(ctx)
+		require.NoError(t, err)
+		uq.Release()
+
+		dq := db.NewDelete().Model((*Model)(nil)).Where("name = ?", "baz")
+		res, err := dq.Exec(ctx)
+		require.NoError(t, err)
+		dq.Release()
+
+		n, err := res.RowsAffected()
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+	}
+}
+
+func testQueryCache(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID        int64 `bun:",pk,autoincrement"`
+		Name      string
+		CreatedAt time.Time
+	}
+
+	cached := bun.NewDB(db.DB, db.Dialect(), bun.WithQueryCache(16))
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	queries := []func(db *bun.DB, i int) *bun.SelectQuery{
+		func(db *bun.DB, i int) *bun.SelectQuery {
+			return db.NewSelect().Model((*Model)(nil)).Where("id = ?", i)
+		},
+		func(db *bun.DB, i int) *bun.SelectQuery {
+			return db.NewSelect().Model((*Model)(nil)).
+				Column("id", "name").
+				Where("name IN (?)", bun.In([]string{"foo", fmt.Sprint(i)})).
+				WhereOr("created_at > ?", time.Unix(int64(i), 0)).
+				Order("id").
+				Limit(10)
+		},
+		func(db *bun.DB, i int) *bun.SelectQuery {
+			return db.NewSelect().Model((*Model)(nil)).
+				ColumnExpr("count(*)").
+				Where("id IN (?)", db.NewSelect().Model((*Model)(nil)).Column("id").Where("id > ?", i)).
+				Group("name").
+				Having("count(*) > ?1 AND count(*) < ?0", i+10, i)
+		},
+		func(db *bun.DB, i int) *bun.SelectQuery {
+			return db.NewSelect().Model((*Model)(nil)).Where("?TableAlias.id = ?", i)
+		},
+		func(db *bun.DB, i int) *bun.SelectQuery {
+			return db.NewSelect().Model(&Model{ID: int64(i)}).WherePK()
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		for _, query := range queries {
+			require.Equal(t, query(db, i).String(), query(cached, i).String())
+		}
+	}
+
+	_, err := cached.NewInsert().Model(&Model{Name: "foo"}).Exec(ctx)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		var models []Model
+		err := cached.NewSelect().Model(&models).Where("name = ?", "foo").Scan(ctx)
+		require.NoError(t, err)
+		require.Len(t, models, 1)
+
+		count, err := cached.NewSelect().Model((*Model)(nil)).Where("name = ?", "bar").Count(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+	}
+}
+
+func testQueryClone(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Name string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	models := []Model{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	base := db.NewSelect().Model((*Model)(nil)).Where("name != ?", "c")
+	baseSQL := base.String()
+
+	count, err := base.Clone().Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	var page []Model
+	err = base.Clone().Model(&page).Where("name != ?", "a").Order("id").Limit(1).Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, "b", page[0].Name)
+	require.Equal(t, baseSQL, base.String())
+
+	type Avatar struct {
+		ID int64 `bun:",pk"`
+	}
+	type Person struct {
+		ID       int64 `bun:",pk"`
+		AvatarID int64
+		Avatar   *Avatar `bun:"rel:belongs-to"`
+	}
+	type Article struct {
+		ID       int64 `bun:",pk"`
+		AuthorID int64
+		Author   *Person `bun:"rel:belongs-to"`
+		EditorID int64
+		Editor   *Person `bun:"rel:belongs-to"`
+	}
+
+	articles := db.NewSelect().Model((*Article)(nil)).Relation("Author")
+	articlesSQL := articles.String()
+	clone := articles.Clone().Relation("Author.Avatar").Relation("Editor")
+	require.Equal(t, articlesSQL, articles.String())
+	require.Contains(t, clone.String(), "author__avatar")
+	require.Contains(t, clone.String(), "editor")
+
+	insert := db.NewInsert().Model(&Model{Name: "d"})
+	insertSQL := insert.String()
+	insert.Clone().Value("name", "?", "e").Returning("id")
+	require.Equal(t, insertSQL, insert.String())
+
+	update := db.NewUpdate().Model((*Model)(nil)).Set("name = ?", "x").Where("id = ?", 1)
+	updateSQL := update.String()
+	update.Clone().Set("id = ?", 2).Where("name = ?", "a")
+	require.Equal(t, updateSQL, update.String())
+
+	del := db.NewDelete().Model((*Model)(nil)).Where("id = ?", 1)
+	delSQL := del.String()
+	_, err = del.Clone().WhereOr("id = ?", 2).Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, delSQL, del.String())
+
+	count, err = db.NewSelect().Model((*Model)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	create := db.NewCreateTable().Model((*Model)(nil))
+	createSQL := create.String()
+	create.Clone().IfNotExists().ForeignKey("(id) REFERENCES models (id)")
+	require.Equal(t, createSQL, create.String())
+}
+
+func testQueryToSQL(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID        int64 `bun:",pk,autoincrement"`
+		Name      string
+		Tags      []string
+		DeletedAt time.Time `bun:",nullzero"`
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	query, args, err := db.NewInsert().
+		Model(&Model{ID: 1, Name: "hello", Tags: []string{"a"}}).
+		ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(1), "hello"}, args)
+	require.NotContains(t, query, "hello")
+	require.Contains(t, query, `'["a"]'`)
+	_, err = db.ExecContext(ctx, query, args...)
+	require.NoError(t, err)
+
+	q := db.NewSelect().
+		Model((*Model)(nil)).
+		Column("id").
+		Where("name = ?", "hello").
+		Where("id IN (?)", bun.In([]int64{1, 2})).
+		Where("? = ?", bun.Ident("name"), bun.Safe("name"))
+	query, args, err = q.ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"hello"}, args)
+	require.NotContains(t, query, "hello")
+	require.Contains(t, query, "IN (1, 2)")
+
+	var id int64
+	err = db.QueryRowContext(ctx, query, args...).Scan(&id)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), id)
+
+	query, args, err = db.NewUpdate().
+		Model((*Model)(nil)).
+		Set("name = ?", "world").
+		Where("id = ?", 1).
+		ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"world", int64(1)}, args)
+	_, err = db.ExecContext(ctx, query, args...)
+	require.NoError(t, err)
+
+	var name string
+	err = db.NewSelect().Model((*Model)(nil)).Column("name").Where("id = 1").Scan(ctx, &name)
+	require.NoError(t, err)
+	require.Equal(t, "world", name)
+}
+
+func testQueryError(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64 `bun:",pk"`
+		Name string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithQueryErrors(bun.QueryErrorOptions{
+		MaxQueryLen: 20,
+		Sanitize: func(query string) string {
+			return strings.ReplaceAll(query, "secret", "***")
+		},
+	}))
+
+	_, err := db.NewInsert().Model(&Model{ID: 1, Name: "secret"}).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewInsert().Model(&Model{ID: 1, Name: "secret"}).Exec(ctx)
+	require.Error(t, err)
+
+	var queryErr *bun.QueryError
+	require.True(t, errors.As(err, &queryErr))
+	require.Equal(t, "INSERT", queryErr.Operation)
+	require.Equal(t, "Model", queryErr.Model)
+	require.Equal(t, "models", queryErr.Table)
+	require.NotContains(t, queryErr.Query, "secret")
+	require.Len(t, queryErr.Query, 23)
+	require.NotZero(t, queryErr.Duration)
+	require.NotNil(t, queryErr.Unwrap())
+	require.Contains(t, err.Error(), "bun: INSERT models failed after")
+
+	err = db.NewSelect().Model(new(Model)).Where("id = 2").Scan(ctx)
+	require.Equal(t, sql.ErrNoRows, err)
+
+	_, err = db.NewSelect().Model((*Model)(nil)).Where("no_such_column = 1").Count(ctx)
+	require.True(t, errors.As(err, &queryErr))
+	require.Equal(t, "SELECT", queryErr.Operation)
+
+	err = db.NewRaw("SELECT * FROM no_such_table").Scan(ctx, new(Model))
+	require.True(t, errors.As(err, &queryErr))
+	require.Equal(t, "", queryErr.Table)
+
+	// Literals are replaced by default.
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithQueryErrors(bun.QueryErrorOptions{}))
+
+	_, err = db.NewInsert().Model(&Model{ID: 1, Name: "secret"}).Exec(ctx)
+	require.True(t, errors.As(err, &queryErr))
+	require.NotContains(t, queryErr.Query, "secret")
+	require.Contains(t, queryErr.Query, "VALUES (?, ")
+
+	// Queries are truncated on a character boundary.
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithQueryErrors(bun.QueryErrorOptions{
+		MaxQueryLen: 22,
+	}))
+
+	err = db.NewRaw(`SELECT * FROM "ÿÿÿÿÿÿÿÿ"`).Scan(ctx, new(Model))
+	require.True(t, errors.As(err, &queryErr))
+	require.True(t, utf8.ValidString(queryErr.Query))
+	require.Equal(t, `SELECT * FROM "ÿÿÿ...`, queryErr.Query)
+}
+
+func testSQLError(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID    int64  `bun:",pk"`
+		Email string `bun:",unique"`
+		Name  string `bun:",notnull"`
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithSQLErrors(), bun.WithQueryErrors(bun.QueryErrorOptions{}))
+
+	_, err := db.NewInsert().Model(&Model{ID: 1, Email: "a@example.com", Name: "a"}).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewInsert().Model(&Model{ID: 2, Email: "a@example.com", Name: "b"}).Exec(ctx)
+	require.ErrorIs(t, err, bun.ErrUniqueViolation)
+	require.NotErrorIs(t, err, bun.ErrNotNullViolation)
+
+	var sqlErr *bun.SQLError
+	require.True(t, errors.As(err, &sqlErr))
+	require.Equal(t, bun.ErrUniqueViolation, sqlErr.Kind)
+	if db.Dialect().Name() == dialect.SQLite {
+		require.Equal(t, "models", sqlErr.Table)
+		require.Equal(t, "email", sqlErr.Column)
+	}
+
+	var queryErr *bun.QueryError
+	require.True(t, errors.As(err, &queryErr))
+
+	_, err = db.NewInsert().Model(&Model{ID: 1, Email: "b@example.com", Name: "b"}).Exec(ctx)
+	require.ErrorIs(t, err, bun.ErrUniqueViolation)
+
+	_, err = db.NewInsert().Model(&Model{ID: 3, Email: "c@example.com"}).Value("name", "NULL").Exec(ctx)
+	require.ErrorIs(t, err, bun.ErrNotNullViolation)
+
+	err = db.NewSelect().Model(new(Model)).Where("id = 4").Scan(ctx)
+	require.Equal(t, sql.ErrNoRows, err)
+
+	err = bun.ClassifyError(errors.New("Error 1062 (23000): Duplicate entry 'a' for key 'users.email'"))
+	require.True(t, errors.As(err, &sqlErr))
+	require.Equal(t, "users", sqlErr.Table)
+	require.Equal(t, "email", sqlErr.Constraint)
+	require.Equal(t, err, bun.ClassifyError(err))
+	require.ErrorIs(t, bun.ClassifyError(errors.New("ORA-08177: can't serialize access")), bun.ErrSerializationFailure)
+}
+
+func testPredicate(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID     int64 `bun:",pk,autoincrement"`
+		Status string
+		Age    *int
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	age := func(n int) *int { return &n }
+	models := []Model{
+		{Status: "active", Age: age(20)},
+		{Status: "active", Age: age(10)},
+		{Status: "active"},
+		{Status: "blocked", Age: age(30)},
+	}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	pred := bun.And(
+		bun.Eq("status", "active"),
+		bun.Or(bun.Gt("age", 18), bun.IsNull("age")),
+		nil,
+	)
+	require.Equal(t,
+		`("status" = 'active') AND (("age" > 18) OR ("age" IS NULL))`,
+		strings.ReplaceAll(db.Formatter().FormatQuery("?", pred), "`", `"`))
+
+	var ids []int64
+	err = db.NewSelect().Model((*Model)(nil)).Column("id").Where("?", pred).Order("id").Scan(ctx, &ids)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 3}, ids)
+
+	tests := []struct {
+		pred bun.Predicate
+		ids  []int64
+	}{
+		{bun.And(), []int64{1, 2, 3, 4}},
+		{bun.Or(), nil},
+		{bun.Not(bun.Eq("status", "active")), []int64{4}},
+		{bun.Ne("age", nil), []int64{1, 2, 4}},
+		{bun.IsIn("id", []int64{2, 4}), []int64{2, 4}},
+		{bun.IsIn("id", []int64{}), nil},
+		{bun.NotIn("id", []int64{}), []int64{1, 2, 3, 4}},
+		{bun.Between("age", 10, 20), []int64{1, 2}},
+		{bun.Cmp("age", "<=", 10), []int64{2}},
+		{bun.Like("status", "block%"), []int64{4}},
+		{bun.ILike("status", "BLOCK%"), []int64{4}},
+		{bun.IEq("status", "Active"), []int64{1, 2, 3}},
+		{bun.Cmp("status", "ilike", "ACT%"), []int64{1, 2, 3}},
+		{bun.IsDistinctFrom("age", 20), []int64{2, 3, 4}},
+		{bun.IsDistinctFrom("age", nil), []int64{1, 2, 4}},
+		{bun.IsNotDistinctFrom("age", 10), []int64{2}},
+		{bun.IsNotDistinctFrom("age", nil), []int64{3}},
+		{bun.Or(bun.SafeQuery("id = ?", 1), bun.Le("id", 2)), []int64{1, 2}},
+	}
+	for i, test := range tests {
+		var ids []int64
+		err := db.NewSelect().Model((*Model)(nil)).Column("id").Where("?", test.pred).Order("id").Scan(ctx, &ids)
+		require.NoError(t, err, i)
+		require.Equal(t, test.ids, ids, i)
+	}
+
+	err = db.NewSelect().Model((*Model)(nil)).Column("id").Where("?", bun.Cmp("id", "; DROP", 1)).Scan(ctx, &ids)
+	require.Error(t, err)
+}
+
+func testPaginate(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID  int64 `bun:",pk,autoincrement"`
+		Str string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	models := []Model{{Str: "a"}, {Str: "b"}, {Str: "c"}, {Str: "d"}, {Str: "e"}}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	for _, concurrent := range []bool{false, true} {
+		var page []Model
+		res, err := db.NewSelect().Model((*Model)(nil)).Order("id").
+			Paginate(ctx, &page, bun.Page{Number: 2, Size: 2, Concurrent: concurrent})
+		require.NoError(t, err)
+		require.Equal(t, bun.PageResult{Number: 2, Size: 2, Total: 5, Pages: 3, HasNext: true}, res)
+		require.Equal(t, []Model{{ID: 3, Str: "c"}, {ID: 4, Str: "d"}}, page)
+	}
+
+	var page []Model
+	res, err := db.NewSelect().Model(&page).Order("id").Paginate(ctx, &page, bun.Page{Number: 3, Size: 2})
+	require.NoError(t, err)
+	require.False(t, res.HasNext)
+	require.Equal(t, []Model{{ID: 5, Str: "e"}}, page)
+
+	page = nil
+	res, err = db.NewSelect().Model(&page).Paginate(ctx, &page, bun.Page{Number: 4, Size: 2})
+	require.NoError(t, err)
+	require.Equal(t, 5, res.Total)
+	require.Empty(t, page)
+
+	_, err = db.NewSelect().Model(&page).Paginate(ctx, &page, bun.Page{})
+	require.Error(t, err)
+}
+
+func testNamedParams(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID     int64 `bun:",pk,autoincrement"`
+		Status string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	models := []Model{{Status: "active"}, {Status: "active"}, {Status: "blocked"}}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	var ids []int64
+	err = db.NewRaw(
+		"SELECT id FROM models WHERE id IN (:ids) AND status = :status AND ':ids' != '' ORDER BY id",
+		bun.Named(map[string]interface{}{
+			"ids":    []int64{1, 3},
+			"status": "active",
+		}),
+	).Scan(ctx, &ids)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, ids)
+
+	params := struct {
+		Status string
+		IDs    []int64 `bun:"ids"`
+	}{Status: "blocked", IDs: []int64{2, 3}}
+	ids = nil
+	err = db.NewSelect().Model((*Model)(nil)).Column("id").
+		Where("id IN (:ids) AND status = :status", bun.Named(&params)).
+		Scan(ctx, &ids)
+	require.NoError(t, err)
+	require.Equal(t, []int64{3}, ids)
+
+	require.Equal(t, "id IN (NULL)",
+		db.Formatter().FormatQuery("id IN (:ids)", bun.Named(map[string][]int64{"ids": nil})))
+
+	err = db.NewRaw("SELECT :missing", bun.Named(map[string]interface{}{})).Scan(ctx, &ids)
+	require.Error(t, err)
+}
+
+func testContextArg(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID       int64 `bun:",pk,autoincrement"`
+		TenantID int64
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	models := []Model{{TenantID: 1}, {TenantID: 2}, {TenantID: 1}}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	type tenantKey struct{}
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithContextArg("tenant_id",
+		func(ctx context.Context) (interface{}, bool) {
+			id, ok := ctx.Value(tenantKey{}).(int64)
+			return id, ok
+		}))
+
+	tenantCtx := context.WithValue(ctx, tenantKey{}, int64(1))
+
+	var ids []int64
+	err = db.NewSelect().Model((*Model)(nil)).Column("id").
+		Where("tenant_id = ?tenant_id").Order("id").Scan(tenantCtx, &ids)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 3}, ids)
+
+	count, err := db.NewSelect().Model((*Model)(nil)).Where("tenant_id = ?tenant_id").
+		Count(context.WithValue(ctx, tenantKey{}, int64(2)))
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	res, err := db.NewDelete().Model((*Model)(nil)).Where("tenant_id = ?tenant_id").Exec(tenantCtx)
+	require.NoError(t, err)
+	n, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	err = db.NewRaw("SELECT id FROM models WHERE tenant_id = ?tenant_id").Scan(ctx, &ids)
+	require.Error(t, err)
+	require.Contains(t, db.Formatter().FormatQuery("?tenant_id"), "?tenant_id")
+}
+
+func testDefaultScope(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID       int64 `bun:",pk,autoincrement"`
+		TenantID int64
+		Status   string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	models := []Model{
+		{TenantID: 1, Status: "active"},
+		{TenantID: 2, Status: "active"},
+		{TenantID: 1, Status: "archived"},
+		{TenantID: 1, Status: "active"},
+	}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	type tenantKey struct{}
+	db = bun.NewDB(db.DB, db.Dialect(),
+		bun.WithContextArg("current_tenant", func(ctx context.Context) (interface{}, bool) {
+			id, ok := ctx.Value(tenantKey{}).(int64)
+			return id, ok
+		}),
+		bun.WithDefaultScope((*Model)(nil), "tenant_id = ?current_tenant"),
+		bun.WithDefaultScope((*Model)(nil), "status != ?", "archived"))
+
+	tenantCtx := context.WithValue(ctx, tenantKey{}, int64(1))
+
+	var ids []int64
+	err = db.NewSelect().Model((*Model)(nil)).Column("id").Order("id").Scan(tenantCtx, &ids)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 4}, ids)
+
+	// OR conditions do not bypass the scopes.
+	ids = nil
+	err = db.NewSelect().Model((*Model)(nil)).Column("id").
+		Where("id = 1").WhereOr("id = 2").Order("id").Scan(tenantCtx, &ids)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, ids)
+
+	count, err := db.NewSelect().Model((*Model)(nil)).Unscoped().Count(tenantCtx)
+	require.NoError(t, err)
+	require.Equal(t, 4, count)
+
+	res, err := db.NewUpdate().Model((*Model)(nil)).Set("status = 'done'").
+		Where("id > 0").Exec(tenantCtx)
+	require.NoError(t, err)
+	n, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	res, err = db.NewDelete().Model((*Model)(nil)).Where("id > 0").Exec(tenantCtx)
+	require.NoError(t, err)
+	n, err = res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	count, err = db.NewSelect().Model((*Model)(nil)).Unscoped().Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	_, err = db.NewSelect().Model((*Model)(nil)).Count(ctx)
+	require.Error(t, err)
+}
+
+func testEncrypted(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID    int64   `bun:",pk,autoincrement"`
+		Email string  `bun:",encrypted:deterministic"`
+		Notes *string `bun:",encrypted"`
+	}
+
+	plainDB := db
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithEncryptor(schema.NewAESGCMEncryptor(
+		schema.StaticKey("v1", []byte("0123456789abcdef0123456789abcdef")))))
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	notes := "private"
+	models := []Model{{Email: "alice@example.com", Notes: &notes}, {Email: "bob@example.com"}}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	var raw string
+	err = db.NewSelect().Model((*Model)(nil)).Column("email").Where("id = 1").Scan(ctx, &raw)
+	require.NoError(t, err)
+	require.NotContains(t, raw, "alice")
+
+	model := new(Model)
+	err = db.NewSelect().Model(model).
+		Where("email = ?", bun.Encrypted("alice@example.com")).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", model.Email)
+	require.Equal(t, "private", *model.Notes)
+
+	model = new(Model)
+	err = db.NewSelect().Model(model).Where("id = 2").Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "bob@example.com", model.Email)
+	require.Nil(t, model.Notes)
+
+	err = plainDB.NewSelect().Model(new(Model)).Where("id = 1").Scan(ctx)
+	require.ErrorContains(t, err, "bun: encryptor is not set, see bun.WithEncryptor")
+}
+
+func testLoader(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Name string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	models := []Model{{Name: "one"}, {Name: "two"}, {Name: "three"}}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	var queries atomic.Int32
+	db.AddQueryHook(&queryHook{
+		beforeQuery: func(ctx context.Context, event *bun.QueryEvent) context.Context {
+			queries.Add(1)
+			return ctx
+		},
+	})
+
+	loader := bun.NewLoader[Model](db, bun.WithLoaderWait(10*time.Millisecond))
+
+	var wg sync.WaitGroup
+	names := make([]string, 4)
+	errs := make([]error, 4)
+	for i, id := range []int{1, 2, 3, 1} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			model, err := loader.Load(ctx, id)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			names[i] = model.Name
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, errors.Join(errs...))
+	require.Equal(t, []string{"one", "two", "three", "one"}, names)
+	require.Equal(t, int32(1), queries.Load())
+
+	_, err = loader.Load(ctx, 42)
+	require.Equal(t, sql.ErrNoRows, err)
+
+	loaded, err := loader.LoadMany(ctx, int64(3), int64(42), int64(1))
+	require.NoError(t, err)
+	require.Len(t, loaded, 3)
+	require.Equal(t, "three", loaded[0].Name)
+	require.Nil(t, loaded[1])
+	require.Equal(t, "one", loaded[2].Name)
+
+	_, err = loader.Load(ctx, "1")
+	require.Error(t, err)
+}
+
+func testExport(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID    int64 `bun:",pk,autoincrement"`
+		Name  string
+		Email *string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	email := "one@example.com"
+	models := []Model{{Name: "one", Email: &email}, {Name: "two, three"}}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	q := db.NewSelect().Model((*Model)(nil)).Order("id")
+
+	var buf bytes.Buffer
+	err = q.ExportCSV(ctx, &buf, bun.CSVOptions{
+		Headers: map[string]string{"email": "E-mail"},
+		Null:    "-",
+		Encoders: map[string]func(interface{}) (string, error){
+			"name": func(value interface{}) (string, error) {
+				return strings.ToUpper(fmt.Sprintf("%s", value)), nil
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "id,name,E-mail\n1,ONE,one@example.com\n2,\"TWO, THREE\",-\n", buf.String())
+
+	buf.Reset()
+	err = q.ExportCSV(ctx, &buf, bun.CSVOptions{Comma: ';', NoHeader: true})
+	require.NoError(t, err)
+	require.Equal(t, "1;one;one@example.com\n2;two, three;\n", buf.String())
+
+	buf.Reset()
+	err = db.NewSelect().Model((*Model)(nil)).Column("name", "email").Order("id").
+		ExportNDJSON(ctx, &buf)
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"one","email":"one@example.com"}
+{"name":"two, three","email":null}
+`, buf.String())
+}
+
+func testImportCSV(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID    int64 `bun:",pk,autoincrement"`
+		Name  string
+		Age   int
+		Email *string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	const data = `Name,age,E-mail,Notes
+one,1,one@example.com,x
+two,not a number,,x
+three,3,,x
+four,4
+five,5,five@example.com,x
+`
+
+	var chunks []int
+	res, err := bun.ImportCSV[Model](ctx, db, strings.NewReader(data), bun.CSVImportOptions{
+		Columns:   map[string]string{"E-mail": "email", "Notes": "-"},
+		ChunkSize: 2,
+		Insert: func(ctx context.Context, models interface{}) error {
+			chunks = append(chunks, len(*models.(*[]Model)))
+			_, err := db.NewInsert().Model(models).Exec(ctx)
+			return err
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, res.Rows)
+	require.Equal(t, []int{2, 1}, chunks)
+	require.Len(t, res.Errors, 2)
+	require.Equal(t, 3, res.Errors[0].Line)
+	require.Equal(t, "age", res.Errors[0].Column)
+	require.Equal(t, 5, res.Errors[1].Line)
+	require.ErrorIs(t, res.Errors[1], csv.ErrFieldCount)
+
+	var models []Model
+	err = db.NewSelect().Model(&models).Order("id").Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, models, 3)
+	require.Equal(t, "one", models[0].Name)
+	require.Equal(t, "one@example.com", *models[0].Email)
+	require.Equal(t, 3, models[1].Age)
+	require.Nil(t, models[1].Email)
+	require.Equal(t, "five", models[2].Name)
+
+	res, err = bun.ImportCSV[Model](ctx, db, strings.NewReader("x;1\ny;z\n"), bun.CSVImportOptions{
+		Comma:  ';',
+		Header: []string{"name", "age"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, res.Rows)
+	require.Len(t, res.Errors, 1)
+
+	_, err = bun.ImportCSV[Model](ctx, db, strings.NewReader("name,age\nx,y\nz,w\n"), bun.CSVImportOptions{
+		MaxErrors: 1,
+	})
+	require.Error(t, err)
+
+	_, err = bun.ImportCSV[Model](ctx, db, strings.NewReader("unknown\n"), bun.CSVImportOptions{})
+	require.Error(t, err)
+}
+
+func testLongJoinAlias(t *testing.T, db *bun.DB) {
+	type Country struct {
+		ID                                  int64 `bun:",pk"`
+		OfficialNameOfTheCountryInEnglish   string
+		OfficialNameOfTheCountryInLocalLang string
+	}
+	type City struct {
+		ID                                 int64 `bun:",pk"`
+		CountryID                          int64
+		CountryTheCityIsTheAdministrativeC *Country `bun:"rel:belongs-to,join:country_id=id"`
+	}
+	type Address struct {
+		ID                                    int64 `bun:",pk"`
+		CityID                                int64
+		CityWhereTheBuildingWithAddressIsInIt *City `bun:"rel:belongs-to,join:city_id=id"`
+	}
+
+	mustResetModel(t, ctx, db, (*Country)(nil), (*City)(nil), (*Address)(nil))
+
+	_, err := db.NewInsert().Model(&Country{
+		ID:                                  1,
+		OfficialNameOfTheCountryInEnglish:   "english",
+		OfficialNameOfTheCountryInLocalLang: "local",
+	}).Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewInsert().Model(&City{ID: 2, CountryID: 1}).Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewInsert().Model(&Address{ID: 3, CityID: 2}).Exec(ctx)
+	require.NoError(t, err)
+
+	address := new(Address)
+	err = db.NewSelect().
+		Model(address).
+		Relation("CityWhereTheBuildingWithAddressIsInIt.CountryTheCityIsTheAdministrativeC").
+		Scan(ctx)
+	require.NoError(t, err)
+
+	country := address.CityWhereTheBuildingWithAddressIsInIt.CountryTheCityIsTheAdministrativeC
+	require.Equal(t, "english", country.OfficialNameOfTheCountryInEnglish)
+	require.Equal(t, "local", country.OfficialNameOfTheCountryInLocalLang)
+}
+
+func testRelationAlias(t *testing.T, db *bun.DB) {
+	type Profile struct {
+		ID  int64 `bun:",pk"`
+		Bio string
+	}
+	type Author struct {
+		ID        int64 `bun:",pk"`
+		ProfileID int64
+		Profile   *Profile `bun:"rel:belongs-to,join:profile_id=id"`
+	}
+	type Book struct {
+		ID       int64 `bun:",pk"`
+		AuthorID int64
+		Author   *Author `bun:"rel:belongs-to,join:author_id=id"`
+		EditorID int64
+		Editor   *Author `bun:"rel:belongs-to,join:editor_id=id"`
+	}
+
+	mustResetModel(t, ctx, db, (*Profile)(nil), (*Author)(nil), (*Book)(nil))
+
+	_, err := db.NewInsert().Model(&[]Profile{{ID: 1, Bio: "writer"}, {ID: 2, Bio: "editor"}}).Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewInsert().Model(&[]Author{{ID: 1, ProfileID: 1}, {ID: 2, ProfileID: 2}}).Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewInsert().Model(&[]Book{
+		{ID: 1, AuthorID: 1, EditorID: 2},
+		{ID: 2, AuthorID: 2, EditorID: 1},
+	}).Exec(ctx)
+	require.NoError(t, err)
+
+	var books []Book
+	err = db.NewSelect().
+		Model(&books).
+		Relation("Author.Profile").
+		Relation("Editor.Profile").
+		Where(`?RelationAlias("Author.Profile").bio = ?`, "writer").
+		Where(`?RelationAlias('Editor.Profile').bio = ?`, "editor").
+		Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, books, 1)
+	require.Equal(t, int64(1), books[0].ID)
+	require.Equal(t, "writer", books[0].Author.Profile.Bio)
+	require.Equal(t, "editor", books[0].Editor.Profile.Bio)
+
+	query := db.NewSelect().
+		Model(&books).
+		Relation("Author").
+		Where(`?RelationAlias("Editor").id = 1`).
+		String()
+	require.Contains(t, query, `relation "Editor" is not joined`)
+}
+
+func testTableFunc(t *testing.T, db *bun.DB) {
+	switch db.Dialect().Name() {
+	case dialect.PG, dialect.MySQL, dialect.SQLite:
+	default:
+		t.Skip("table functions are not supported")
+	}
+
+	type Model struct {
+		ID   int64 `bun:",pk"`
+		Name string
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	_, err := db.NewInsert().Model(&[]Model{{ID: 1, Name: "one"}, {ID: 3, Name: "three"}}).Exec(ctx)
+	require.NoError(t, err)
+
+	var rows []struct {
+		ID    int64
+		Label string
+		Name  sql.NullString
+	}
+	err = db.NewSelect().
+		TableFunc(bun.Unnest([]int64{1, 2, 3}, []string{"a", "b", "c"}).As("t", "id", "label")).
+		ColumnExpr("t.id, t.label, m.name").
+		Join("LEFT JOIN models AS m ON m.id = t.id").
+		OrderExpr("t.id").
+		Scan(ctx, &rows)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Equal(t, "b", rows[1].Label)
+	require.Equal(t, "three", rows[2].Name.String)
+	require.False(t, rows[1].Name.Valid)
+
+	var values []int64
+	err = db.NewSelect().
+		TableFunc(bun.GenerateSeries(1, 6, 2).As("s", "n")).
+		ColumnExpr("s.n").
+		OrderExpr("s.n").
+		Scan(ctx, &values)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 3, 5}, values)
+
+	err = db.NewSelect().
+		TableFunc(bun.GenerateSeries(3, 1, 1)).
+		ColumnExpr("t.value").
+		Scan(ctx, &values)
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	err = db.NewSelect().
+		TableFunc(bun.Unnest([]int64{1})).
+		ColumnExpr("*").
+		Scan(ctx, &values)
+	require.Error(t, err)
+}
+
+func testDateTrunc(t *testing.T, db *bun.DB) {
+	switch db.Dialect().Name() {
+	case dialect.PG, dialect.MySQL, dialect.SQLite:
+	default:
+		t.Skip("DateTrunc requires SQL Server 2022")
+	}
+
+	type Event struct {
+		ID        int64 `bun:",pk,autoincrement"`
+		CreatedAt time.Time
+	}
+
+	mustResetModel(t, ctx, db, (*Event)(nil))
+
+	events := []Event{
+		{CreatedAt: time.Date(2024, time.May, 14, 10, 20, 30, 0, time.UTC)}, // Tuesday
+		{CreatedAt: time.Date(2024, time.May, 14, 23, 59, 59, 0, time.UTC)},
+		{CreatedAt: time.Date(2024, time.May, 19, 12, 0, 0, 0, time.UTC)}, // Sunday
+		{CreatedAt: time.Date(2024, time.August, 1, 0, 7, 0, 0, time.UTC)},
+	}
+	_, err := db.NewInsert().Model(&events).Exec(ctx)
+	require.NoError(t, err)
+
+	type Bucket struct {
+		Bucket time.Time
+		Count  int
+	}
+
+	for _, test := range []struct {
+		expr schema.QueryAppender
+		want []Bucket
+	}{
+		{bun.DateTrunc("day", "created_at"), []Bucket{
+			{time.Date(2024, time.May, 14, 0, 0, 0, 0, time.UTC), 2},
+			{time.Date(2024, time.May, 19, 0, 0, 0, 0, time.UTC), 1},
+			{time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), 1},
+		}},
+		{bun.DateTrunc("week", "created_at"), []Bucket{
+			{time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC), 3},
+			{time.Date(2024, time.July, 29, 0, 0, 0, 0, time.UTC), 1},
+		}},
+		{bun.DateTrunc("quarter", "created_at"), []Bucket{
+			{time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), 3},
+			{time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC), 1},
+		}},
+		{bun.DateTrunc("hour", "created_at"), []Bucket{
+			{time.Date(2024, time.May, 14, 10, 0, 0, 0, time.UTC), 1},
+			{time.Date(2024, time.May, 14, 23, 0, 0, 0, time.UTC), 1},
+			{time.Date(2024, time.May, 19, 12, 0, 0, 0, time.UTC), 1},
+			{time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), 1},
+		}},
+		{bun.TimeBucket(12*time.Hour, "created_at"), []Bucket{
+			{time.Date(2024, time.May, 14, 0, 0, 0, 0, time.UTC), 1},
+			{time.Date(2024, time.May, 14, 12, 0, 0, 0, time.UTC), 1},
+			{time.Date(2024, time.May, 19, 12, 0, 0, 0, time.UTC), 1},
+			{time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), 1},
+		}},
+	} {
+		var buckets []Bucket
+		err := db.NewSelect().
+			Model((*Event)(nil)).
+			ColumnExpr("? AS bucket, count(*) AS count", test.expr).
+			GroupExpr("bucket").
+			OrderExpr("bucket").
+			Scan(ctx, &buckets)
+		require.NoError(t, err)
+		require.Len(t, buckets, len(test.want))
+		for i := range buckets {
+			require.True(t, test.want[i].Bucket.Equal(buckets[i].Bucket),
+				"got %s, wanted %s", buckets[i].Bucket, test.want[i].Bucket)
+			require.Equal(t, test.want[i].Count, buckets[i].Count)
+		}
+	}
+
+	require.Contains(t, db.NewSelect().ColumnExpr("?", bun.DateTrunc("decade", "created_at")).String(),
+		`unsupported unit "decade"`)
+}
+
+func testScanKVMap(t *testing.T, db *bun.DB) {
+	type Order struct {
+		ID     int64 `bun:",pk,autoincrement"`
+		Status string
+		Amount int64
+	}
+
+	mustResetModel(t, ctx, db, (*Order)(nil))
+
+	orders := []Order{
+		{Status: "new", Amount: 10},
+		{Status: "paid", Amount: 20},
+		{Status: "paid", Amount: 30},
+		{Status: "new", Amount: 40},
+		{Status: "shipped", Amount: 50},
+	}
+	_, err := db.NewInsert().Model(&orders).Exec(ctx)
 	require.NoError(t, err)
-	require.NotZero(t, model.CreatedAt)
 
-	//
-	// update field with tag "skipupdate"
-	//
-	model.CreatedAt = model.CreatedAt.Add(2 * time.Minute)
-	_, err = db.NewUpdate().Model(model).WherePK().Exec(ctx)
+	counts := map[string]int64{"stale": 1}
+	err = db.NewSelect().
+		Model((*Order)(nil)).
+		ColumnExpr("status, count(*)").
+		Group("status").
+		Scan(ctx, &counts)
 	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"new": 2, "paid": 2, "shipped": 1}, counts)
 
-	//
-	// check
-	//
-	model_ := new(Model)
-	model_.ID = model.ID
-	err = db.NewSelect().Model(model_).WherePK().Scan(ctx)
-	require.NoError(t, err, "select")
-	require.NotEmpty(t, model_)
-	require.Equal(t, model.ID, model_.ID)
-	require.Equal(t, model.Name, model_.Name)
-	require.Equal(t, createdAt.UTC(), model_.CreatedAt.UTC())
+	var statuses map[int64]string
+	err = db.NewSelect().Model((*Order)(nil)).Column("id", "status").Scan(ctx, &statuses)
+	require.NoError(t, err)
+	require.Len(t, statuses, 5)
+	require.Equal(t, "shipped", statuses[5])
 
-	require.NotEqual(t, model.CreatedAt.UTC(), model_.CreatedAt.UTC())
+	var amounts map[string][]int64
+	err = db.NewSelect().
+		Model((*Order)(nil)).
+		Column("status", "amount").
+		Order("id").
+		Scan(ctx, &amounts)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]int64{
+		"new":     {10, 40},
+		"paid":    {20, 30},
+		"shipped": {50},
+	}, amounts)
+
+	var empty map[string]int64
+	err = db.NewSelect().Model((*Order)(nil)).Column("status", "amount").
+		Where("1 = 0").Scan(ctx, &empty)
+	require.NoError(t, err)
+	require.NotNil(t, empty)
+	require.Empty(t, empty)
+
+	err = db.NewSelect().Model((*Order)(nil)).Column("id", "status", "amount").Scan(ctx, &statuses)
+	require.Error(t, err)
 }
 
-func testScanAndCount(t *testing.T, db *bun.DB) {
-	type Model struct {
-		ID  int64 `bun:",pk,autoincrement"`
-		Str string
+func testOnConflictConstraint(t *testing.T, db *bun.DB) {
+	type Subscriber struct {
+		ID    int64  `bun:",pk,autoincrement"`
+		Email string `bun:",unique:subscribers_email_key"`
+		Name  string
 	}
 
-	ctx := context.Background()
-	mustResetModel(t, ctx, db, (*Model)(nil))
+	if !db.HasFeature(feature.OnConflictConstraint) {
+		q := db.NewInsert().
+			Model(&Subscriber{Email: "a@example.com"}).
+			OnConflictConstraint("subscribers_email_key", "DO NOTHING")
+		_, err := q.AppendQuery(db.Formatter(), nil)
+		require.ErrorContains(t, err, "ON CONFLICT ON CONSTRAINT is not supported")
 
-	t.Run("tx", func(t *testing.T) {
-		for i := 0; i < 100; i++ {
-			err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-				var models []Model
-				count, err := tx.NewSelect().Model(&models).ScanAndCount(ctx)
-				require.NoError(t, err)
-				require.Equal(t, 0, count)
-				return err
-			})
-			require.NoError(t, err)
-		}
-	})
+		_, err = q.Clone().AppendQuery(db.Formatter(), nil)
+		require.ErrorContains(t, err, "ON CONFLICT ON CONSTRAINT is not supported")
+		return
+	}
 
-	t.Run("no limit", func(t *testing.T) {
-		src := []Model{
-			{Str: "str1"},
-			{Str: "str2"},
-		}
-		_, err := db.NewInsert().Model(&src).Exec(ctx)
-		require.NoError(t, err)
+	mustResetModel(t, ctx, db, (*Subscriber)(nil))
 
-		var dest []Model
-		count, err := db.NewSelect().Model(&dest).ScanAndCount(ctx)
-		require.NoError(t, err)
-		require.Equal(t, 2, count)
-		require.Equal(t, 2, len(dest))
-	})
+	_, err := db.NewInsert().Model(&Subscriber{Email: "a@example.com", Name: "A"}).Exec(ctx)
+	require.NoError(t, err)
+
+	q := db.NewInsert().
+		Model(&Subscriber{Email: "a@example.com", Name: "B"}).
+		OnConflictConstraint("subscribers_email_key", "DO UPDATE").
+		Set("name = EXCLUDED.name")
+	require.Contains(t, q.String(), `ON CONFLICT ON CONSTRAINT "subscribers_email_key" DO UPDATE SET`)
+	require.Equal(t, q.String(), q.Clone().String())
+	_, err = q.Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewInsert().
+		Model(&Subscriber{Email: "a@example.com", Name: "C"}).
+		OnConflictConstraint("subscribers_email_key", "DO NOTHING").
+		Exec(ctx)
+	require.NoError(t, err)
+
+	var subscribers []Subscriber
+	err = db.NewSelect().Model(&subscribers).Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, subscribers, 1)
+	require.Equal(t, "B", subscribers[0].Name)
 }
 
-func testEmbedModelValue(t *testing.T, db *bun.DB) {
-	type DoubleEmbed struct {
-		A string
-		B string
+func testExecExpectingRows(t *testing.T, db *bun.DB) {
+	type Document struct {
+		ID      int64 `bun:",pk,autoincrement"`
+		Body    string
+		Version int64
 	}
-	type Embed struct {
-		Foo string
-		Bar string
-		C   DoubleEmbed `bun:"embed:c_"`
-		D   DoubleEmbed `bun:"embed:d_"`
+
+	mustResetModel(t, ctx, db, (*Document)(nil))
+
+	doc := &Document{Body: "draft", Version: 1}
+	_, err := db.NewInsert().Model(doc).Exec(ctx)
+	require.NoError(t, err)
+
+	update := func(body string, version int64) (sql.Result, error) {
+		return db.NewUpdate().
+			Model((*Document)(nil)).
+			Set("body = ?", body).
+			Set("version = version + 1").
+			Where("id = ?", doc.ID).
+			Where("version = ?", version).
+			ExecExpectingRows(ctx, 1)
+	}
+
+	res, err := update("final", 1)
+	require.NoError(t, err)
+	require.NoError(t, bun.MustAffect(res, 1))
+
+	_, err = update("stale", 1)
+	require.ErrorIs(t, err, bun.ErrUnexpectedRowsAffected)
+	var rowsErr *bun.RowsAffectedError
+	require.ErrorAs(t, err, &rowsErr)
+	require.Equal(t, int64(1), rowsErr.Expected)
+	require.Equal(t, int64(0), rowsErr.Actual)
+	require.EqualError(t, err, "bun: expected 1 rows affected, got 0")
+
+	_, err = db.NewDelete().Model((*Document)(nil)).Where("id = ?", doc.ID).ExecExpectingRows(ctx, 2)
+	require.ErrorAs(t, err, &rowsErr)
+	require.Equal(t, int64(1), rowsErr.Actual)
+
+	_, err = db.NewDelete().Model((*Document)(nil)).Where("id = ?", doc.ID).ExecExpectingRows(ctx, 0)
+	require.NoError(t, err)
+}
+
+func testRelationJoinType(t *testing.T, db *bun.DB) {
+	type Profile struct {
+		ID     int64 `bun:",pk,autoincrement"`
+		Active bool
+	}
+	type User struct {
+		ID        int64 `bun:",pk,autoincrement"`
+		Name      string
+		ProfileID int64
+		Profile   *Profile `bun:"rel:belongs-to"`
+	}
+	type InnerUser struct {
+		bun.BaseModel `bun:"table:users"`
+
+		ID        int64 `bun:",pk,autoincrement"`
+		Name      string
+		ProfileID int64
+		Profile   *Profile `bun:"rel:belongs-to,join_type:inner"`
+	}
+
+	mustResetModel(t, ctx, db, (*User)(nil), (*Profile)(nil))
+
+	profiles := []Profile{{Active: true}, {Active: false}}
+	_, err := db.NewInsert().Model(&profiles).Exec(ctx)
+	require.NoError(t, err)
+
+	users := []User{
+		{Name: "active", ProfileID: profiles[0].ID},
+		{Name: "inactive", ProfileID: profiles[1].ID},
+		{Name: "orphan", ProfileID: 100},
+	}
+	_, err = db.NewInsert().Model(&users).Exec(ctx)
+	require.NoError(t, err)
+
+	// The joined columns are selected too, so select the names from a subquery.
+	names := func(q *bun.SelectQuery) []string {
+		var names []string
+		err := db.NewSelect().
+			TableExpr("(?) AS t", q).
+			ColumnExpr("t.name").
+			OrderExpr("t.name").
+			Scan(ctx, &names)
+		require.NoError(t, err)
+		return names
 	}
+
+	var users2 []User
+	q := db.NewSelect().Model(&users2).Relation("Profile")
+	require.Contains(t, q.String(), "LEFT JOIN")
+	require.Equal(t, []string{"active", "inactive", "orphan"}, names(q))
+
+	q = db.NewSelect().Model(&users2).RelationWithJoinType("Profile", bun.InnerJoin)
+	require.Contains(t, q.String(), "INNER JOIN")
+	require.Equal(t, []string{"active", "inactive"}, names(q))
+
+	count, err := db.NewSelect().Model(&users2).RelationWithJoinType("Profile", bun.InnerJoin).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	err = db.NewSelect().
+		Model(&users2).
+		RelationWithJoinType("Profile", bun.InnerJoin, func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("profile.active = ?", true)
+		}).
+		Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, users2, 1)
+	require.Equal(t, "active", users2[0].Name)
+	require.NotNil(t, users2[0].Profile)
+
+	var innerUsers []InnerUser
+	q = db.NewSelect().Model(&innerUsers).Relation("Profile")
+	require.Contains(t, q.String(), "INNER JOIN")
+	require.Equal(t, []string{"active", "inactive"}, names(q))
+
+	q = db.NewSelect().Model(&innerUsers).RelationWithJoinType("Profile", bun.LeftJoin)
+	require.Contains(t, q.String(), "LEFT JOIN")
+	require.Equal(t, []string{"active", "inactive", "orphan"}, names(q))
+}
+
+func testRegexp(t *testing.T, db *bun.DB) {
 	type Model struct {
-		X Embed `bun:"embed:x_"`
-		Y Embed `bun:"embed:y_"`
+		ID   int64 `bun:",pk,autoincrement"`
+		Code string
+	}
+
+	if !db.HasFeature(feature.Regexp) {
+		// Errors of query args are appended to the query.
+		b, err := db.NewSelect().
+			Model((*Model)(nil)).
+			Where("?", bun.Regexp("code", "^a")).
+			AppendQuery(db.Formatter(), nil)
+		require.NoError(t, err)
+		require.Contains(t, string(b), "bun: Regexp is not supported by "+db.Dialect().Name().String())
+		return
 	}
 
-	ctx := context.Background()
 	mustResetModel(t, ctx, db, (*Model)(nil))
 
-	m1 := &Model{
-		X: Embed{
-			Foo: "x.foo",
-			Bar: "x.bar",
-			C: DoubleEmbed{
-				A: "x.c.a",
-				B: "x.c.b",
-			},
-			D: DoubleEmbed{
-				A: "x.d.a",
-				B: "x.d.b",
-			},
-		},
-		Y: Embed{
-			Foo: "y.foo",
-			Bar: "y.bar",
-			C: DoubleEmbed{
-				A: "y.c.a",
-				B: "y.c.b",
-			},
-			D: DoubleEmbed{
-				A: "y.d.a",
-				B: "y.d.b",
-			},
-		},
+	models := []Model{{Code: "abc-123"}, {Code: "ABC-456"}, {Code: "xyz"}}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
+	require.NoError(t, err)
+
+	tests := []struct {
+		pred bun.Predicate
+		ids  []int64
+	}{
+		{bun.Regexp("code", "[0-9]+$"), []int64{1, 2}},
+		{bun.IRegexp("code", "^abc"), []int64{1, 2}},
+		{bun.Not(bun.IRegexp("code", "^ABC")), []int64{3}},
 	}
-	_, err := db.NewInsert().Model(m1).Exec(ctx)
+	for i, test := range tests {
+		var ids []int64
+		err := db.NewSelect().Model((*Model)(nil)).Column("id").Where("?", test.pred).Order("id").Scan(ctx, &ids)
+		require.NoError(t, err, i)
+		require.Equal(t, test.ids, ids, i)
+	}
+}
+
+func testValuesTable(t *testing.T, db *bun.DB) {
+	type Item struct {
+		ID    int64 `bun:",pk"`
+		Name  string
+		Price int64
+	}
+	type Row struct {
+		ID    int64
+		Price int64
+	}
+
+	mustResetModel(t, ctx, db, (*Item)(nil))
+
+	items := []Item{{ID: 1, Name: "one", Price: 10}, {ID: 2, Name: "two", Price: 20}, {ID: 3, Name: "three", Price: 30}}
+	_, err := db.NewInsert().Model(&items).Exec(ctx)
 	require.NoError(t, err)
 
-	var m2 Model
-	err = db.NewSelect().Model(&m2).Scan(ctx)
+	rows := []Row{{ID: 1, Price: 15}, {ID: 3, Price: 35}}
+	values := db.NewValues(&rows)
+
+	var names []string
+	err = db.NewSelect().
+		TableExpr("?", values.AsTable("v")).
+		Join("JOIN items AS i ON i.id = v.id").
+		ColumnExpr("i.name").
+		OrderExpr("v.price").
+		Scan(ctx, &names)
 	require.NoError(t, err)
-	require.Equal(t, *m1, m2)
+	require.Equal(t, []string{"one", "three"}, names)
+
+	var sums []map[string]interface{}
+	err = db.NewSelect().
+		TableExpr("?", db.NewValues(&[]map[string]interface{}{{"n": 1}, {"n": 2}}).AsTable("m")).
+		ColumnExpr("sum(m.n) AS total").
+		Scan(ctx, &sums)
+	require.NoError(t, err)
+	require.Len(t, sums, 1)
+	require.EqualValues(t, 3, sums[0]["total"])
+
+	if !db.HasFeature(feature.ValuesTable) {
+		b, err := db.NewSelect().TableExpr("?", db.NewValues(&[]Row{}).AsTable("v")).
+			AppendQuery(db.Formatter(), nil)
+		require.NoError(t, err)
+		require.Contains(t, string(b), "bun: Values requires at least one row")
+	}
+
+	_, err = db.NewUpdate().
+		Model((*Item)(nil)).
+		TableExpr("?", values.AsTable("_data")).
+		Set("price = _data.price").
+		Where("?TableAlias.id = _data.id").
+		Exec(ctx)
+	require.NoError(t, err)
+
+	var prices []int64
+	err = db.NewSelect().Model((*Item)(nil)).Column("price").Order("id").Scan(ctx, &prices)
+	require.NoError(t, err)
+	require.Equal(t, []int64{15, 20, 35}, prices)
 }
 
-func testEmbedModelPointer(t *testing.T, db *bun.DB) {
-	type DoubleEmbed struct {
-		A string
-		B string
+func testSystemVersioning(t *testing.T, db *bun.DB) {
+	type Price struct {
+		ID        int64 `bun:",pk,autoincrement"`
+		Amount    int64
+		ValidFrom time.Time `bun:",row_start"`
+		ValidTo   time.Time `bun:",row_end"`
 	}
-	type Embed struct {
-		Foo string
-		Bar string
-		C   *DoubleEmbed `bun:"embed:c_"`
-		D   *DoubleEmbed `bun:"embed:d_"`
+
+	name := db.Dialect().Name().String()
+
+	if !db.HasFeature(feature.SystemVersioning) {
+		_, err := db.NewCreateTable().Model((*Price)(nil)).AppendQuery(db.Formatter(), nil)
+		require.EqualError(t, err, "bun: system-versioned tables are not supported by "+name)
+
+		_, err = db.NewSelect().Model((*Price)(nil)).ForSystemTime("ALL").AppendQuery(db.Formatter(), nil)
+		require.EqualError(t, err, "bun: FOR SYSTEM_TIME is not supported by "+name)
+
+		if d, ok := db.Dialect().(*pgdialect.Dialect); !ok || !d.IsCockroachDB() {
+			err = db.NewSelect().Model((*Price)(nil)).AsOf(time.Now()).Scan(ctx)
+			require.EqualError(t, err, "bun: AsOf is not supported by "+name)
+		}
+		return
 	}
-	type Model struct {
-		X *Embed `bun:"embed:x_"`
-		Y *Embed `bun:"embed:y_"`
+
+	q := db.NewSelect().Model((*Price)(nil)).ForSystemTime("ALL").Where("id = 1")
+	require.Contains(t, q.String(), " FOR SYSTEM_TIME ALL AS ")
+
+	if db.Dialect().Name() == dialect.MSSQL {
+		// SQL Server requires disabling SYSTEM_VERSIONING to drop the table.
+		b, err := db.NewCreateTable().Model((*Price)(nil)).AppendQuery(db.Formatter(), nil)
+		require.NoError(t, err)
+		require.Contains(t, string(b), `"valid_from" DATETIME2 GENERATED ALWAYS AS ROW START NOT NULL`)
+		require.Contains(t, string(b), `PERIOD FOR SYSTEM_TIME ("valid_from", "valid_to")`)
+		require.True(t, strings.HasSuffix(string(b), " WITH (SYSTEM_VERSIONING = ON)"))
+		return
 	}
 
-	ctx := context.Background()
-	mustResetModel(t, ctx, db, (*Model)(nil))
+	mustResetModel(t, ctx, db, (*Price)(nil))
 
-	m1 := &Model{
-		X: &Embed{
-			Foo: "x.foo",
-			Bar: "x.bar",
-			C: &DoubleEmbed{
-				A: "x.c.a",
-				B: "x.c.b",
-			},
-			D: &DoubleEmbed{
-				A: "x.d.a",
-				B: "x.d.b",
-			},
-		},
-		Y: &Embed{
-			Foo: "y.foo",
-			Bar: "y.bar",
-			C: &DoubleEmbed{
-				A: "y.c.a",
-				B: "y.c.b",
-			},
-			D: &DoubleEmbed{
-				A: "y.d.a",
-				B: "y.d.b",
-			},
+	price := &Price{Amount: 10}
+	_, err := db.NewInsert().Model(price).Exec(ctx)
+	require.NoError(t, err)
+
+	var tm time.Time
+	err = db.NewSelect().ColumnExpr("CURRENT_TIMESTAMP(6)").Scan(ctx, &tm)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	price.Amount = 20
+	_, err = db.NewUpdate().Model(price).WherePK().Exec(ctx)
+	require.NoError(t, err)
+
+	var amount int64
+	err = db.NewSelect().Model((*Price)(nil)).Column("amount").AsOf(tm).Scan(ctx, &amount)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), amount)
+
+	err = db.NewSelect().Model((*Price)(nil)).Column("amount").Scan(ctx, &amount)
+	require.NoError(t, err)
+	require.Equal(t, int64(20), amount)
+
+	var history []Price
+	err = db.NewSelect().Model(&history).ForSystemTime("ALL").Order("valid_from").Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, int64(10), history[0].Amount)
+	require.Equal(t, history[0].ValidTo, history[1].ValidFrom)
+}
+
+func testEntityCache(t *testing.T, db *bun.DB) {
+	type CachedUser struct {
+		bun.BaseModel `bun:"table:cached_users,cache"`
+
+		ID        int64 `bun:",pk,autoincrement"`
+		Name      string
+		Avatar    []byte
+		CreatedAt time.Time
+	}
+
+	cache := bun.NewLRUEntityCache(100)
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithEntityCache(cache, time.Minute))
+	mustResetModel(t, ctx, db, (*CachedUser)(nil))
+
+	var queries atomic.Int32
+	db.AddQueryHook(&queryHook{
+		beforeQuery: func(ctx context.Context, event *bun.QueryEvent) context.Context {
+			queries.Add(1)
+			return ctx
 		},
+	})
+
+	users := []CachedUser{
+		{Name: "one", Avatar: []byte{1, 2}, CreatedAt: time.Unix(1e9, 0)},
+		{Name: "two", CreatedAt: time.Unix(1e9, 0)},
+	}
+	_, err := db.NewInsert().Model(&users).Exec(ctx)
+	require.NoError(t, err)
+
+	selectUser := func(id int64) (*CachedUser, error) {
+		user := &CachedUser{ID: id}
+		err := db.NewSelect().Model(user).WherePK().Scan(ctx)
+		return user, err
 	}
-	_, err := db.NewInsert().Model(m1).Exec(ctx)
+
+	queries.Store(0)
+	for i := 0; i < 3; i++ {
+		user, err := selectUser(1)
+		require.NoError(t, err)
+		require.Equal(t, "one", user.Name)
+		require.Equal(t, []byte{1, 2}, user.Avatar)
+		require.True(t, user.CreatedAt.Equal(time.Unix(1e9, 0)))
+	}
+	require.Equal(t, int32(1), queries.Load())
+
+	// Other queries are not cached.
+	var names []string
+	err = db.NewSelect().Model((*CachedUser)(nil)).Column("name").Order("id").Scan(ctx, &names)
 	require.NoError(t, err)
+	require.Equal(t, []string{"one", "two"}, names)
+	require.Equal(t, int32(2), queries.Load())
 
-	var m2 Model
-	err = db.NewSelect().Model(&m2).Scan(ctx)
+	// Changes made with raw queries are not tracked.
+	_, err = db.NewRaw("UPDATE cached_users SET name = 'raw' WHERE id = 1").Exec(ctx)
 	require.NoError(t, err)
-	require.Equal(t, *m1, m2)
-}
+	user, err := selectUser(1)
+	require.NoError(t, err)
+	require.Equal(t, "one", user.Name)
 
-type JSONField struct {
-	Foo string `json:"foo"`
-}
+	user.Name = "updated"
+	_, err = db.NewUpdate().Model(user).WherePK().Exec(ctx)
+	require.NoError(t, err)
+	user, err = selectUser(1)
+	require.NoError(t, err)
+	require.Equal(t, "updated", user.Name)
 
-func (f *JSONField) MarshalJSON() ([]byte, error) {
-	return []byte(`{"foo": "bar"}`), nil
+	// Queries without WherePK invalidate the whole table.
+	_, err = selectUser(2)
+	require.NoError(t, err)
+	_, err = db.NewUpdate().Model((*CachedUser)(nil)).
+		Set("name = ?", "bulk").
+		Where("id > ?", 0).
+		Exec(ctx)
+	require.NoError(t, err)
+	for _, id := range []int64{1, 2} {
+		user, err := selectUser(id)
+		require.NoError(t, err)
+		require.Equal(t, "bulk", user.Name)
+	}
+
+	// Queries in transactions are not served from the cache.
+	queries.Store(0)
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		user := &CachedUser{ID: 1}
+		return tx.NewSelect().Model(user).WherePK().Scan(ctx)
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(3), queries.Load())
+
+	// Uncommitted rows read through the context transaction are not cached.
+	errRollback := errors.New("rollback")
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		ctx = bun.ContextWithTx(ctx, tx)
+		user := &CachedUser{ID: 1, Name: "uncommitted"}
+		if _, err := db.NewUpdate().Model(user).Column("name").WherePK().Exec(ctx); err != nil {
+			return err
+		}
+		user = &CachedUser{ID: 1}
+		if err := db.NewSelect().Model(user).WherePK().Scan(ctx); err != nil {
+			return err
+		}
+		require.Equal(t, "uncommitted", user.Name)
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+	user, err = selectUser(1)
+	require.NoError(t, err)
+	require.Equal(t, "bulk", user.Name)
+
+	_, err = db.NewDelete().Model(&CachedUser{ID: 1}).WherePK().Exec(ctx)
+	require.NoError(t, err)
+	_, err = selectUser(1)
+	require.Equal(t, sql.ErrNoRows, err)
 }
 
-func testJSONMarshaler(t *testing.T, db *bun.DB) {
+func testStats(t *testing.T, db *bun.DB) {
 	type Model struct {
-		Field *JSONField
+		ID   int64 `bun:",pk,autoincrement"`
+		Name string
 	}
 
-	ctx := context.Background()
+	db = bun.NewDB(db.DB, db.Dialect())
 	mustResetModel(t, ctx, db, (*Model)(nil))
+	before := db.PoolStats().Counters
 
-	m1 := &Model{Field: new(JSONField)}
-	_, err := db.NewInsert().Model(m1).Exec(ctx)
+	model := &Model{Name: "one"}
+	_, err := db.NewInsert().Model(model).Exec(ctx)
+	require.NoError(t, err)
+	err = db.NewSelect().Model(model).WherePK().Scan(ctx)
+	require.NoError(t, err)
+	_, err = db.NewUpdate().Model(model).WherePK().Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewDelete().Model(model).WherePK().Exec(ctx)
 	require.NoError(t, err)
+	_, err = db.NewRaw("SELECT 1").Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewRaw("SELECT * FROM missing_table").Exec(ctx)
+	require.Error(t, err)
 
-	var m2 Model
-	err = db.NewSelect().Model(&m2).Scan(ctx)
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return nil
+	})
 	require.NoError(t, err)
-	require.Equal(t, "bar", m2.Field.Foo)
-}
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return errors.New("rollback")
+	})
+	require.Error(t, err)
 
-type DriverValue struct {
-	s string
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	tx, err := conn.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	stats := db.PoolStats()
+	require.Equal(t, db.DB.Stats().MaxOpenConnections, stats.MaxOpenConnections)
+
+	after := stats.Counters
+	require.Equal(t, uint32(1), after.Inserts-before.Inserts)
+	require.Equal(t, uint32(1), after.Selects-before.Selects)
+	require.Equal(t, uint32(1), after.Updates-before.Updates)
+	require.Equal(t, uint32(1), after.Deletes-before.Deletes)
+	require.Equal(t, uint32(3), after.TxBegins-before.TxBegins)
+	require.Equal(t, uint32(1), after.TxCommits-before.TxCommits)
+	require.Equal(t, uint32(2), after.TxRollbacks-before.TxRollbacks)
+	// 2 raw queries and BEGIN, COMMIT, BEGIN, ROLLBACK, BEGIN, ROLLBACK.
+	require.Equal(t, uint32(8), after.OtherQueries-before.OtherQueries)
+	require.Equal(t, uint32(12), after.Queries-before.Queries)
+	require.Equal(t, uint32(1), after.Errors-before.Errors)
+
+	name := fmt.Sprintf("bun_stats_%s_%d", db.Dialect().Name(), time.Now().UnixNano())
+	db.PublishExpvar(name)
+	require.Contains(t, expvar.Get(name).String(), `"TxCommits":1`)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pingErr := make(chan error, 1)
+	go db.CollectStats(ctx, 10*time.Millisecond, func(stats bun.Stats, err error) {
+		cancel()
+		pingErr <- err
+	})
+	require.NoError(t, <-pingErr)
 }
 
-var _ driver.Valuer = (*DriverValue)(nil)
+func testBulkInsertIDs(t *testing.T, db *bun.DB) {
+	type Account struct {
+		ID    int64  `bun:",pk,autoincrement"`
+		Email string `bun:",unique"`
+		Name  string
+	}
 
-func (v *DriverValue) Value() (driver.Value, error) {
-	return v.s, nil
-}
+	mustResetModel(t, ctx, db, (*Account)(nil))
 
-func testNilDriverValue(t *testing.T, db *bun.DB) {
-	type Model struct {
-		Value *DriverValue `bun:"type:varchar(100)"`
-	}
+	requireIDs := func(accounts []Account) {
+		t.Helper()
+		for _, account := range accounts {
+			require.NotZero(t, account.ID)
 
-	ctx := context.Background()
-	mustResetModel(t, ctx, db, (*Model)(nil))
+			var email string
+			err := db.NewSelect().Model((*Account)(nil)).Column("email").
+				Where("id = ?", account.ID).Scan(ctx, &email)
+			require.NoError(t, err)
+			require.Equal(t, account.Email, email)
+		}
+	}
 
-	_, err := db.NewInsert().Model(&Model{}).Exec(ctx)
+	accounts := []Account{{Email: "a@test"}, {Email: "b@test"}, {Email: "c@test"}}
+	_, err := db.NewInsert().Model(&accounts).Exec(ctx)
 	require.NoError(t, err)
+	requireIDs(accounts)
 
-	_, err = db.NewInsert().Model(&Model{Value: &DriverValue{s: "hello"}}).Exec(ctx)
-	require.NoError(t, err)
+	// Rows with IDs are inserted as is when zero IDs are replaced with DEFAULT.
+	if db.HasFeature(feature.DefaultPlaceholder) {
+		mixed := []*Account{{Email: "d@test"}, {ID: 100, Email: "e@test"}, {Email: "f@test"}}
+		_, err = db.NewInsert().Model(&mixed).Exec(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(100), mixed[1].ID)
+		requireIDs([]Account{*mixed[0], *mixed[1], *mixed[2]})
+	}
+
+	if db.HasFeature(feature.InsertOnDuplicateKey) {
+		upserted := []Account{{Email: "a@test", Name: "updated"}, {Email: "g@test"}}
+		_, err = db.NewInsert().Model(&upserted).
+			On("DUPLICATE KEY UPDATE name = VALUES(name)").
+			Exec(ctx)
+		require.NoError(t, err)
+		require.Equal(t, accounts[0].ID, upserted[0].ID)
+		requireIDs(upserted)
+	}
+
+	// Without a unique key, IDs that may not be consecutive are left unset.
+	type Note struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Body string
+	}
+
+	d, ok := db.Dialect().(interface{ AutoIncrementStep() (int64, bool) })
+	if !ok {
+		return
+	}
+	if _, consecutive := d.AutoIncrementStep(); !consecutive {
+		mustResetModel(t, ctx, db, (*Note)(nil))
+
+		notes := []Note{{Body: "a"}, {Body: "b"}}
+		_, err = db.NewInsert().Model(&notes).Exec(ctx)
+		require.NoError(t, err)
+		require.Zero(t, notes[0].ID)
+		require.Zero(t, notes[1].ID)
+	}
 }
 
-func testRunInTxAndSavepoint(t *testing.T, db *bun.DB) {
-	type Counter struct {
-		Count int64
+func testWhereIn(t *testing.T, db *bun.DB) {
+	type Model struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Name string
 	}
 
-	mustResetModel(t, ctx, db, (*Counter)(nil))
+	mustResetModel(t, ctx, db, (*Model)(nil))
 
-	_, err := db.NewInsert().Model(&Counter{Count: 0}).Exec(ctx)
+	models := []Model{{Name: "one"}, {Name: "two"}, {Name: "three"}}
+	_, err := db.NewInsert().Model(&models).Exec(ctx)
 	require.NoError(t, err)
 
-	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		err := tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
-			_, err := sp.NewUpdate().Model((*Counter)(nil)).
-				Set("count = count + 1").
-				Where("1 = 1").
-				Exec(ctx)
-			return err
-		})
+	selectIDs := func(q *bun.SelectQuery) []int64 {
+		var ids []int64
+		err := q.Column("id").Order("id").Scan(ctx, &ids)
 		require.NoError(t, err)
-		// rolling back the transaction should rollback what happened inside savepoint
-		return errors.New("fake error")
-	})
-	require.Error(t, err)
+		return ids
+	}
 
-	var count int
-	err = db.NewSelect().Model((*Counter)(nil)).Scan(ctx, &count)
+	tests := []struct {
+		q   *bun.SelectQuery
+		ids []int64
+	}{
+		{db.NewSelect().Model((*Model)(nil)).WhereIn("id IN (?)", []int64{1, 3}), []int64{1, 3}},
+		{db.NewSelect().Model((*Model)(nil)).WhereIn("id IN (?)", []int64{}), nil},
+		{db.NewSelect().Model((*Model)(nil)).WhereIn("id IN (?)", []int64(nil)), nil},
+		{db.NewSelect().Model((*Model)(nil)).WhereNotIn("id NOT IN (?)", []int64{1}), []int64{2, 3}},
+		{db.NewSelect().Model((*Model)(nil)).WhereNotIn("id NOT IN (?)", []int64{}), []int64{1, 2, 3}},
+		{
+			db.NewSelect().Model((*Model)(nil)).
+				WhereIn("id IN (?) OR name = 'three'", []string{}),
+			nil,
+		},
+		{
+			db.NewSelect().Model((*Model)(nil)).
+				Where("name != ?", "two").
+				WhereIn("name IN (?) OR id = 2", []string{"one", "two"}),
+			[]int64{1},
+		},
+	}
+	for i, test := range tests {
+		require.Equal(t, test.ids, selectIDs(test.q), i)
+	}
+
+	res, err := db.NewUpdate().Model((*Model)(nil)).
+		Set("name = ?", "updated").
+		WhereIn("id IN (?)", []int64{}).
+		Exec(ctx)
 	require.NoError(t, err)
-	require.Equal(t, 0, count)
+	n, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), n)
 
-	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		err := tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
-			_, err := sp.NewInsert().Model(&Counter{Count: 1}).
-				Exec(ctx)
-			require.NoError(t, err)
-			return err
-		})
-		require.NoError(t, err)
+	res, err = db.NewDelete().Model((*Model)(nil)).WhereNotIn("id NOT IN (?)", []int64{2}).Exec(ctx)
+	require.NoError(t, err)
+	n, err = res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+}
 
-		// ignored on purpose this error
-		// rolling back a savepoint should not affect the transaction
-		// nor other savepoints on the same level
-		_ = tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
-			_, err := sp.NewInsert().Model(&Counter{Count: 2}).
-				Exec(ctx)
-			require.NoError(t, err)
-			return errors.New("fake error")
-		})
+func testColumnGroup(t *testing.T, db *bun.DB) {
+	type Article struct {
+		ID    int64  `bun:",pk,autoincrement,group:list,group:detail"`
+		Title string `bun:",group:list,group:detail"`
+		Body  string `bun:",group:detail"`
+		Views int64
+	}
 
-		return err
-	})
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithColumnGroup((*Article)(nil), "stats", "id", "views"))
+	mustResetModel(t, ctx, db, (*Article)(nil))
+
+	_, err := db.NewInsert().Model(&Article{Title: "title", Body: "body", Views: 10}).Exec(ctx)
 	require.NoError(t, err)
 
-	count, err = db.NewSelect().Model((*Counter)(nil)).Count(ctx)
+	var articles []Article
+	err = db.NewSelect().Model(&articles).ColumnGroup("list").Scan(ctx)
 	require.NoError(t, err)
-	require.Equal(t, 2, count)
+	require.Equal(t, []Article{{ID: 1, Title: "title"}}, articles)
 
-	err = db.ResetModel(ctx, (*Counter)(nil))
+	article := new(Article)
+	err = db.NewSelect().Model(article).ColumnGroup("detail", "stats").Where("id = 1").Scan(ctx)
 	require.NoError(t, err)
+	require.Equal(t, &Article{ID: 1, Title: "title", Body: "body", Views: 10}, article)
 
-	// happy path, commit transaction, savepoints and sub-savepoints
-	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		_, err := tx.NewInsert().Model(&Counter{Count: 1}).
-			Exec(ctx)
-		require.NoError(t, err)
+	err = db.NewSelect().Model(article).ColumnGroup("unknown").Scan(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `does not have column group "unknown"`)
 
-		err = tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
-			_, err := sp.NewInsert().Model(&Counter{Count: 1}).
-				Exec(ctx)
-			if err != nil {
-				return err
-			}
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithColumnGroup((*Article)(nil), "bad", "missing"))
+	err = db.NewSelect().Model(article).ColumnGroup("bad").Scan(ctx)
+	require.Error(t, err)
+}
 
-			return sp.RunInTx(ctx, nil, func(ctx context.Context, subSp bun.Tx) error {
-				_, err := subSp.NewInsert().Model(&Counter{Count: 1}).
-					Exec(ctx)
-				return err
-			})
-		})
-		require.NoError(t, err)
+func testScanProjection(t *testing.T, db *bun.DB) {
+	type Profile struct {
+		ID     int64 `bun:",pk"`
+		UserID int64
+	}
+	type User struct {
+		ID      int64 `bun:",pk,autoincrement"`
+		Name    string
+		Email   string
+		Bio     string
+		Profile *Profile `bun:"rel:has-one,join:id=user_id"`
+	}
+	type UserSummary struct {
+		ID       int64
+		Name     string
+		Nickname string
+	}
 
-		err = tx.RunInTx(ctx, nil, func(ctx context.Context, sp bun.Tx) error {
-			_, err := sp.NewInsert().Model(&Counter{Count: 2}).
-				Exec(ctx)
-			return err
-		})
+	mustResetModel(t, ctx, db, (*User)(nil))
 
-		return err
-	})
+	users := []User{{Name: "one", Email: "one@example.com"}, {Name: "two", Email: "two@example.com"}}
+	_, err := db.NewInsert().Model(&users).Exec(ctx)
 	require.NoError(t, err)
 
-	count, err = db.NewSelect().Model((*Counter)(nil)).Count(ctx)
+	var summaries []UserSummary
+	err = db.NewSelect().Model((*User)(nil)).Order("id").Scan(ctx, &summaries)
 	require.NoError(t, err)
-	require.Equal(t, 4, count)
-}
+	require.Equal(t, []UserSummary{{ID: 1, Name: "one"}, {ID: 2, Name: "two"}}, summaries)
 
-type anotherString string
+	summary := new(UserSummary)
+	err = db.NewSelect().Model((*User)(nil)).Where("id = 2").Scan(ctx, summary)
+	require.NoError(t, err)
+	require.Equal(t, &UserSummary{ID: 2, Name: "two"}, summary)
 
-var _ driver.Valuer = (*anotherString)(nil)
+	type Other struct {
+		Title string
+	}
 
-func (v anotherString) Value() (driver.Value, error) {
-	return v, nil
+	err = db.NewSelect().Model((*User)(nil)).Scan(ctx, new(Other))
+	require.Error(t, err)
+
+	err = db.NewSelect().Model((*User)(nil)).Relation("Profile").Scan(ctx, new(UserSummary))
+	require.Error(t, err)
 }
 
-func testDriverValuerReturnsItself(t *testing.T, db *bun.DB) {
-	expectedValue := anotherString("example value")
+func testRelationUniqueKey(t *testing.T, db *bun.DB) {
+	type Country struct {
+		Code string `bun:",unique"`
+		Name string
+	}
+	type Settings struct {
+		CountryCode string `bun:",unique"`
+		Currency    string
+	}
+	type Address struct {
+		ID          int64 `bun:",pk,autoincrement"`
+		CountryCode string
+		Country     *Country `bun:"rel:belongs-to,join:country_code=code"`
+	}
+	type CountryWithSettings struct {
+		bun.BaseModel `bun:"table:countries,alias:country"`
 
-	type Model struct {
-		ID    int           `bun:",pk,autoincrement"`
-		Value anotherString `bun:"value"`
+		Code     string `bun:",unique"`
+		Name     string
+		Settings *Settings `bun:"rel:has-one,join:code=country_code"`
 	}
 
-	ctx := context.Background()
-	mustResetModel(t, ctx, db, (*Model)(nil))
+	mustResetModel(t, ctx, db, (*Country)(nil), (*Settings)(nil), (*Address)(nil))
 
-	model := &Model{Value: expectedValue}
-	_, err := db.NewInsert().Model(model).Exec(ctx)
-	require.Error(t, err)
+	countries := []Country{{Code: "FR", Name: "France"}, {Code: "DE", Name: "Germany"}}
+	_, err := db.NewInsert().Model(&countries).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewInsert().Model(&Settings{CountryCode: "DE", Currency: "EUR"}).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewInsert().Model(&Address{CountryCode: "DE"}).Exec(ctx)
+	require.NoError(t, err)
+
+	address := new(Address)
+	err = db.NewSelect().Model(address).Relation("Country").Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &Country{Code: "DE", Name: "Germany"}, address.Country)
+
+	var withSettings []CountryWithSettings
+	err = db.NewSelect().Model(&withSettings).Relation("Settings").OrderExpr("country.code").Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, withSettings, 2)
+	require.Equal(t, &Settings{CountryCode: "DE", Currency: "EUR"}, withSettings[0].Settings)
+	require.Nil(t, withSettings[1].Settings)
 }
 
-func testNoPanicWhenReturningNullColumns(t *testing.T, db *bun.DB) {
+func testCollate(t *testing.T, db *bun.DB) {
+	if db.Dialect().Name() != dialect.SQLite {
+		t.Skip("NOCASE is an SQLite collation")
+	}
+
 	type Model struct {
-		Value     string  `bun:"value,notnull"`
-		NullValue *string `bun:"null_value"`
+		ID   int64  `bun:",pk,autoincrement"`
+		Name string `bun:",collate:NOCASE"`
+		Code string
 	}
 
-	ctx := context.Background()
 	mustResetModel(t, ctx, db, (*Model)(nil))
 
-	modelSlice := []*Model{{Value: "boom"}}
-
-	require.NotPanics(t, func() {
-		db.NewInsert().Model(&modelSlice).Exec(ctx)
-	})
-}
+	_, err := db.NewInsert().Model(&Model{Name: "Hello", Code: "ABC"}).Exec(ctx)
+	require.NoError(t, err)
 
-func mustResetModel(tb testing.TB, ctx context.Context, db *bun.DB, models ...interface{}) {
-	err := db.ResetModel(ctx, models...)
-	require.NoError(tb, err, "must reset model")
-	mustDropTableOnCleanup(tb, ctx, db, models...)
-}
+	count := func(q *bun.SelectQuery) int {
+		n, err := q.Count(ctx)
+		require.NoError(t, err)
+		return n
+	}
 
-func mustDropTableOnCleanup(tb testing.TB, ctx context.Context, db *bun.DB, models ...interface{}) {
-	tb.Cleanup(func() {
-		for _, model := range models {
-			drop := db.NewDropTable().IfExists().Model(model)
-			_, err := drop.Exec(ctx)
-			require.NoError(tb, err, "must drop table: %q", drop.GetTableName())
-		}
-	})
+	require.Equal(t, 1, count(db.NewSelect().Model((*Model)(nil)).Where("name = ?", "hello")))
+	require.Equal(t, 0, count(db.NewSelect().Model((*Model)(nil)).Where("code = ?", "abc")))
+	require.Equal(t, 1, count(db.NewSelect().Model((*Model)(nil)).
+		Where("? = ?", bun.Collate("code", "NOCASE"), "abc")))
 }
@@ -0,0 +1,59 @@
+package dbtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dbfactory"
+)
+
+func TestFactory(t *testing.T) {
+	type FactoryUser struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Name string
+	}
+	type FactoryOrder struct {
+		ID     int64 `bun:",pk,autoincrement"`
+		Status string
+		UserID int64
+		User   *FactoryUser `bun:"rel:belongs-to"`
+	}
+
+	testEachDB(t, func(t *testing.T, dbName string, db *bun.DB) {
+		mustResetModel(t, ctx, db, (*FactoryUser)(nil), (*FactoryOrder)(nil))
+
+		factory := dbfactory.New(db).
+			Define(&FactoryUser{}, func(u *FactoryUser, seq int) {
+				u.Name = fmt.Sprintf("user %d", seq)
+			}).
+			Define(&FactoryOrder{}, func(o *FactoryOrder, seq int) {
+				o.Status = "new"
+			})
+
+		var users []*FactoryUser
+		err := factory.CreateMany(ctx, &users, 3)
+		require.NoError(t, err)
+		require.Len(t, users, 3)
+		require.Equal(t, "user 3", users[2].Name)
+		require.NotZero(t, users[2].ID)
+
+		order := &FactoryOrder{Status: "paid"}
+		err = factory.Create(ctx, order)
+		require.NoError(t, err)
+		require.Equal(t, "paid", order.Status)
+		require.NotNil(t, order.User)
+		require.Equal(t, "user 4", order.User.Name)
+		require.Equal(t, order.User.ID, order.UserID)
+
+		order = &FactoryOrder{User: users[0]}
+		err = factory.Create(ctx, order)
+		require.NoError(t, err)
+		require.Equal(t, users[0].ID, order.UserID)
+
+		count, err := db.NewSelect().Model((*FactoryUser)(nil)).Count(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+	})
+}
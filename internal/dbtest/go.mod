@@ -14,6 +14,8 @@ replace github.com/uptrace/bun/driver/pgdriver => ../../driver/pgdriver
 
 replace github.com/uptrace/bun/driver/sqliteshim => ../../driver/sqliteshim
 
+replace github.com/uptrace/bun/driver/pgxdriver => ../../driver/pgxdriver
+
 replace github.com/uptrace/bun/dialect/mysqldialect => ../../dialect/mysqldialect
 
 replace github.com/uptrace/bun/dialect/sqlitedialect => ../../dialect/sqlitedialect
@@ -22,6 +24,14 @@ replace github.com/uptrace/bun/dialect/mssqldialect => ../../dialect/mssqldialec
 
 replace github.com/uptrace/bun/extra/bundebug => ../../extra/bundebug
 
+replace github.com/uptrace/bun/extra/bunaudit => ../../extra/bunaudit
+
+replace github.com/uptrace/bun/extra/bunmock => ../../extra/bunmock
+
+replace github.com/uptrace/bun/extra/bungolden => ../../extra/bungolden
+
+replace github.com/uptrace/bun/pgnotify => ../../pgnotify
+
 require (
 	github.com/bradleyjkemp/cupaloy v2.3.0+incompatible
 	github.com/brianvoe/gofakeit/v6 v6.4.1
@@ -29,6 +39,7 @@ require (
 	github.com/go-sql-driver/mysql v1.6.0
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v4 v4.11.0
+	github.com/jackc/pgx/v5 v5.5.5
 	github.com/stretchr/testify v1.8.1
 	github.com/uptrace/bun v1.2.5
 	github.com/uptrace/bun/dbfixture v1.2.5
@@ -37,8 +48,13 @@ require (
 	github.com/uptrace/bun/dialect/pgdialect v1.2.5
 	github.com/uptrace/bun/dialect/sqlitedialect v1.2.5
 	github.com/uptrace/bun/driver/pgdriver v1.2.5
+	github.com/uptrace/bun/driver/pgxdriver v1.2.5
 	github.com/uptrace/bun/driver/sqliteshim v1.2.5
+	github.com/uptrace/bun/extra/bunaudit v1.2.5
 	github.com/uptrace/bun/extra/bundebug v1.2.5
+	github.com/uptrace/bun/extra/bungolden v1.2.5
+	github.com/uptrace/bun/extra/bunmock v1.2.5
+	github.com/uptrace/bun/pgnotify v1.2.5
 )
 
 require (
@@ -53,8 +69,9 @@ require (
 	github.com/jackc/pgio v1.0.0 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgproto3/v2 v2.0.6 // indirect
-	github.com/jackc/pgservicefile v0.0.0-20200714003250-2b9c44734f2b // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
 	github.com/jackc/pgtype v1.7.0 // indirect
+	github.com/jackc/puddle/v2 v2.2.1 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
@@ -70,6 +87,7 @@ require (
 	golang.org/x/crypto v0.28.0 // indirect
 	golang.org/x/exp v0.0.0-20241009180824-f66d83c29e7c // indirect
 	golang.org/x/mod v0.21.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.26.0 // indirect
 	golang.org/x/text v0.19.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
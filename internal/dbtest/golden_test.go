@@ -0,0 +1,73 @@
+package dbtest_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/extra/bungolden"
+	"github.com/uptrace/bun/extra/bunmock"
+)
+
+func TestGolden(t *testing.T) {
+	type Order struct {
+		ID     int64 `bun:",pk,autoincrement"`
+		UserID int64
+		Status string
+	}
+
+	dir := bungolden.WithDir(filepath.Join("testdata", "golden"))
+
+	mock := bunmock.New()
+	for _, db := range []*bun.DB{
+		bun.NewDB(mock.DB(), pgdialect.New()),
+		bun.NewDB(mock.DB(), sqlitedialect.New()),
+	} {
+		q := db.NewSelect().
+			Model((*Order)(nil)).
+			ColumnExpr("user_id, count(*)").
+			Where("status IN (?)", bun.In([]string{"new", "paid"})).
+			Where("id > (SELECT min(id) FROM orders WHERE status = 'new')").
+			Group("user_id").
+			Order("user_id").
+			Limit(10)
+		bungolden.Assert(t, "orders", q, dir)
+	}
+
+	got, err := bungolden.Format(bun.NewDB(mock.DB(), pgdialect.New()).
+		NewUpdate().Model((*Order)(nil)).Set("status = 'from  here'").Where("id = 1"))
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE \"orders\" AS \"order\"\nSET status = 'from  here'\nWHERE (id = 1)\n", got)
+
+	db := bun.NewDB(mock.DB(), pgdialect.New())
+	tb := &goldenTB{TB: t}
+	bungolden.Assert(tb, "orders", db.NewSelect().Model((*Order)(nil)),
+		dir, bungolden.WithUpdate(false))
+	require.Contains(t, tb.err, "query does not match")
+
+	tb = &goldenTB{TB: t}
+	bungolden.Assert(tb, "missing", db.NewSelect().Model((*Order)(nil)),
+		dir, bungolden.WithUpdate(false))
+	require.Contains(t, tb.err, "run the test with BUNGOLDEN_UPDATE=1 to create it")
+}
+
+// goldenTB records failures instead of failing the test.
+type goldenTB struct {
+	testing.TB
+	err string
+}
+
+func (tb *goldenTB) Helper() {}
+
+func (tb *goldenTB) Errorf(format string, args ...interface{}) {
+	tb.err = fmt.Sprintf(format, args...)
+}
+
+func (tb *goldenTB) Fatalf(format string, args ...interface{}) {
+	tb.err = fmt.Sprintf(format, args...)
+}
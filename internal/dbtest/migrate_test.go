@@ -1,12 +1,20 @@
 package dbtest_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log/slog"
+	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/extra/bunmock"
 	"github.com/uptrace/bun/migrate"
 )
 
@@ -34,6 +42,14 @@ func TestMigrate(t *testing.T) {
 	tests := []Test{
 		{run: testMigrateUpAndDown},
 		{run: testMigrateUpError},
+		{run: testMigrateLocking},
+		{run: testMigrateLockingCancel},
+		{run: testMigrateDryRun},
+		{run: testRunInBatches},
+		{run: testDumpSchema},
+		{run: testMigrateSquash},
+		{run: testMigrateDependencies},
+		{run: testMultiMigrator},
 	}
 
 	testEachDB(t, func(t *testing.T, dbName string, db *bun.DB) {
@@ -89,6 +105,15 @@ func testMigrateUpAndDown(t *testing.T, db *bun.DB) {
 	require.Len(t, group.Migrations, 2)
 	require.Equal(t, []string{"up1", "up2"}, history)
 
+	status, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, status.IsUpToDate())
+	require.Len(t, status.Applied, 2)
+	require.Empty(t, status.Missing)
+	require.Equal(t, int64(1), status.LastGroupID)
+	require.Equal(t, "20060102160405", status.Applied[0].Name)
+	require.False(t, status.Applied[0].MigratedAt.IsZero())
+
 	history = nil
 	group, err = m.Rollback(ctx)
 	require.NoError(t, err)
@@ -158,3 +183,390 @@ func testMigrateUpError(t *testing.T, db *bun.DB) {
 	require.Len(t, group.Migrations, 2)
 	require.Equal(t, []string{"down2", "down1"}, history)
 }
+
+func testMigrateLocking(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+
+	var other *migrate.Migrator
+	var otherErr error
+
+	migrations := migrate.NewMigrations()
+	migrations.Add(migrate.Migration{
+		Name: "20060102150405",
+		Up: func(ctx context.Context, db *bun.DB) error {
+			_, otherErr = other.Migrate(ctx)
+			return nil
+		},
+	})
+
+	newMigrator := func() *migrate.Migrator {
+		return migrate.NewMigrator(db, migrations,
+			migrate.WithTableName(migrationsTable),
+			migrate.WithLocksTableName(migrationLocksTable),
+			migrate.WithLocking(true),
+		)
+	}
+
+	m := newMigrator()
+	other = newMigrator()
+
+	err := m.Reset(ctx)
+	require.NoError(t, err)
+
+	group, err := m.Migrate(ctx)
+	require.NoError(t, err)
+	require.Len(t, group.Migrations, 1)
+	require.Error(t, otherErr)
+
+	group, err = other.Migrate(ctx)
+	require.NoError(t, err)
+	require.True(t, group.IsZero())
+}
+
+func testMigrateLockingCancel(t *testing.T, db *bun.DB) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	migrations := migrate.NewMigrations()
+	migrations.Add(migrate.Migration{
+		Name: "20060102150405",
+		Up: func(ctx context.Context, db *bun.DB) error {
+			cancel()
+			return ctx.Err()
+		},
+	})
+
+	m := migrate.NewMigrator(db, migrations,
+		migrate.WithTableName(migrationsTable),
+		migrate.WithLocksTableName(migrationLocksTable),
+		migrate.WithLocking(true),
+	)
+
+	err := m.Reset(ctx)
+	require.NoError(t, err)
+
+	_, err = m.Migrate(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The lock is released even though the context was cancelled.
+	if db.Dialect().Name() == dialect.PG {
+		var n int
+		err = db.NewSelect().
+			ColumnExpr("count(*)").
+			TableExpr("pg_locks").
+			Where("locktype = 'advisory'").
+			Scan(context.Background(), &n)
+		require.NoError(t, err)
+		require.Zero(t, n, "advisory lock is not released")
+	}
+	err = m.Lock(context.Background())
+	require.NoError(t, err)
+	err = m.Unlock(context.Background())
+	require.NoError(t, err)
+}
+
+func testMigrateDryRun(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+
+	migrations := migrate.NewMigrations()
+	err := migrations.Discover(fstest.MapFS{
+		"20060102150405_first.up.sql":   {Data: []byte("SELECT 1\n--bun:split\nSELECT 2\n")},
+		"20060102150405_first.down.sql": {Data: []byte("SELECT 3\n")},
+	})
+	require.NoError(t, err)
+
+	m := migrate.NewMigrator(db, migrations,
+		migrate.WithTableName(migrationsTable),
+		migrate.WithLocksTableName(migrationLocksTable),
+	)
+	err = m.Reset(ctx)
+	require.NoError(t, err)
+
+	plan, err := m.Plan(ctx)
+	require.NoError(t, err)
+	require.Len(t, plan, 1)
+	require.Equal(t, "20060102150405", plan[0].Migration.Name)
+	require.Equal(t, []string{"SELECT 1\n", "SELECT 2\n"}, plan[0].Statements)
+
+	group, err := m.Migrate(ctx, migrate.WithDryRun())
+	if db.Dialect().Name() == dialect.MySQL {
+		require.ErrorContains(t, err, "does not support dry runs")
+	} else {
+		require.NoError(t, err)
+		require.Len(t, group.Migrations, 1)
+	}
+
+	ms, err := m.MigrationsWithStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, ms.Unapplied(), 1)
+}
+
+func TestMigrateDryRunImplicitDDLCommit(t *testing.T) {
+	mock := bunmock.New()
+	db := bun.NewDB(mock.DB(), mysqldialect.New())
+	t.Cleanup(func() { db.Close() })
+
+	migrations := migrate.NewMigrations()
+	err := migrations.Discover(fstest.MapFS{
+		"20060102150405_first.up.sql":   {Data: []byte("CREATE TABLE dry_run (id INT)\n")},
+		"20060102150405_first.down.sql": {Data: []byte("DROP TABLE dry_run\n")},
+	})
+	require.NoError(t, err)
+
+	m := migrate.NewMigrator(db, migrations)
+	mock.Reset()
+
+	_, err = m.Migrate(ctx, migrate.WithDryRun())
+	require.ErrorContains(t, err, "mysql implicitly commits DDL statements")
+	_, err = m.Rollback(ctx, migrate.WithDryRun())
+	require.ErrorContains(t, err, "mysql implicitly commits DDL statements")
+	require.Empty(t, mock.Queries())
+}
+
+func testRunInBatches(t *testing.T, db *bun.DB) {
+	type BatchItem struct {
+		ID    int64 `bun:",pk"`
+		Value int64
+	}
+
+	ctx := context.Background()
+
+	err := db.ResetModel(ctx, (*BatchItem)(nil))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.NewDropTable().Model((*BatchItem)(nil)).IfExists().Exec(ctx)
+		_, _ = db.NewDropTable().Table("bun_migration_checkpoints").IfExists().Exec(ctx)
+	})
+
+	items := make([]BatchItem, 10)
+	for i := range items {
+		items[i].ID = int64(i + 1)
+	}
+	_, err = db.NewInsert().Model(&items).Exec(ctx)
+	require.NoError(t, err)
+
+	var batches []int
+	err = migrate.RunInBatches(ctx, db, "backfill", "batch_items",
+		func(ctx context.Context, tx bun.Tx, batch *migrate.Batch) error {
+			_, err := tx.NewUpdate().
+				Model((*BatchItem)(nil)).
+				Set("value = id * 2").
+				Where("id BETWEEN ? AND ?", batch.FirstPK, batch.LastPK).
+				Exec(ctx)
+			return err
+		},
+		migrate.WithBatchSize(4),
+		migrate.WithBatchProgress(func(batch *migrate.Batch) {
+			batches = append(batches, batch.Size)
+		}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []int{4, 4, 2}, batches)
+
+	var sum int64
+	err = db.NewSelect().Model((*BatchItem)(nil)).ColumnExpr("SUM(value)").Scan(ctx, &sum)
+	require.NoError(t, err)
+	require.Equal(t, int64(110), sum)
+}
+
+func testDumpSchema(t *testing.T, db *bun.DB) {
+	type DumpAuthor struct {
+		ID int64 `bun:",pk"`
+	}
+	type DumpBook struct {
+		ID       int64 `bun:",pk"`
+		AuthorID int64
+		Author   *DumpAuthor `bun:"rel:belongs-to"`
+	}
+
+	db.RegisterModel((*DumpBook)(nil), (*DumpAuthor)(nil))
+
+	m := migrate.NewMigrator(db, migrate.NewMigrations())
+
+	var buf bytes.Buffer
+	err := m.DumpSchema(&buf)
+	require.NoError(t, err)
+
+	dump := buf.String()
+	authors := strings.Index(dump, "CREATE TABLE "+string(db.Formatter().AppendIdent(nil, "dump_authors")))
+	books := strings.Index(dump, "CREATE TABLE "+string(db.Formatter().AppendIdent(nil, "dump_books")))
+	require.True(t, authors >= 0 && books > authors, dump)
+	require.Contains(t, dump, "FOREIGN KEY")
+}
+
+func testMigrateSquash(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	migrations := migrate.NewMigrations(migrate.WithMigrationsDirectory(dir))
+	for _, name := range []string{"20060102150405", "20060102160405", "20060102170405"} {
+		migrations.Add(migrate.Migration{Name: name})
+	}
+
+	m := migrate.NewMigrator(db, migrations,
+		migrate.WithTableName(migrationsTable),
+		migrate.WithLocksTableName(migrationLocksTable),
+	)
+	err := m.Reset(ctx)
+	require.NoError(t, err)
+
+	_, err = m.Squash(ctx)
+	require.Error(t, err)
+
+	_, err = m.Migrate(ctx)
+	require.NoError(t, err)
+
+	mf, err := m.Squash(ctx, migrate.WithSquashSchema(strings.NewReader("SELECT 1\n")))
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(mf.Name, "_baseline.up.sql"), mf.Name)
+	require.Equal(t, "SELECT 1\n", mf.Content)
+
+	applied, err := m.AppliedMigrations(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	require.Equal(t, strings.TrimSuffix(mf.Name, "_baseline.up.sql"), applied[0].Name)
+	require.Equal(t, int64(1), applied[0].GroupID)
+
+	squashed := migrate.NewMigrations()
+	err = squashed.Discover(os.DirFS(dir))
+	require.NoError(t, err)
+
+	m = migrate.NewMigrator(db, squashed,
+		migrate.WithTableName(migrationsTable),
+		migrate.WithLocksTableName(migrationLocksTable),
+	)
+	status, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, status.IsUpToDate())
+	require.Empty(t, status.Missing)
+	require.Len(t, status.Applied, 1)
+}
+
+func testMigrateDependencies(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+
+	var history []string
+	var progress []int64
+
+	newMigration := func(name string, dependsOn ...string) migrate.Migration {
+		return migrate.Migration{
+			Name:      name,
+			DependsOn: dependsOn,
+			Up: func(ctx context.Context, db *bun.DB) error {
+				history = append(history, "up"+name)
+				migrate.Logger(ctx).Info("running")
+				migrate.ReportProgress(ctx, 1, 2)
+				return nil
+			},
+			Down: func(ctx context.Context, db *bun.DB) error {
+				history = append(history, "down"+name)
+				return nil
+			},
+		}
+	}
+
+	migrations := migrate.NewMigrations()
+	migrations.Add(newMigration("1", "3"))
+	migrations.Add(newMigration("2"))
+	migrations.Add(newMigration("3"))
+
+	var buf bytes.Buffer
+	m := migrate.NewMigrator(db, migrations,
+		migrate.WithTableName(migrationsTable),
+		migrate.WithLocksTableName(migrationLocksTable),
+		migrate.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		migrate.WithProgress(func(migration *migrate.Migration, done, total int64) {
+			progress = append(progress, done, total)
+		}),
+	)
+	err := m.Reset(ctx)
+	require.NoError(t, err)
+
+	plan, err := m.Plan(ctx)
+	require.NoError(t, err)
+	require.Len(t, plan, 3)
+	require.Equal(t, "3", plan[0].Migration.Name)
+
+	_, err = m.Migrate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"up3", "up1", "up2"}, history)
+	require.Equal(t, []int64{1, 2, 1, 2, 1, 2}, progress)
+	require.Contains(t, buf.String(), "msg=running migration=3 direction=up")
+	require.Contains(t, buf.String(), `msg="migration finished" migration=1 direction=up`)
+
+	history = nil
+	_, err = m.Rollback(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"down2", "down1", "down3"}, history)
+
+	migrations.Add(newMigration("4", "5"))
+	_, err = m.Migrate(ctx)
+	require.EqualError(t, err, "migrate: migration 4 depends on unknown migration 5")
+}
+
+func testMultiMigrator(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+
+	var history []string
+
+	newMigrations := func(target string) *migrate.Migrations {
+		migrations := migrate.NewMigrations()
+		migrations.Add(migrate.Migration{
+			Name: "20060102150405",
+			Up: func(ctx context.Context, db *bun.DB) error {
+				history = append(history, target)
+				return nil
+			},
+		})
+		return migrations
+	}
+
+	const analyticsTable = "test_analytics_migrations"
+	t.Cleanup(func() {
+		_, _ = db.NewDropTable().Table(analyticsTable).IfExists().Exec(ctx)
+	})
+
+	main := migrate.NewMigrator(db, newMigrations("main"),
+		migrate.WithTableName(migrationsTable),
+		migrate.WithLocksTableName(migrationLocksTable),
+	)
+	analytics := migrate.NewMigrator(db, newMigrations("analytics"),
+		migrate.WithTableName(analyticsTable),
+		migrate.WithLocksTableName(migrationLocksTable),
+	)
+
+	mm := migrate.NewMultiMigrator()
+	mm.MustAdd("main", main)
+	mm.MustAdd("analytics", analytics)
+	require.Error(t, mm.Add("analytics", analytics))
+	require.Error(t, mm.Add("other", main))
+	require.Equal(t, []string{"main", "analytics"}, mm.Names())
+
+	err := main.Reset(ctx)
+	require.NoError(t, err)
+	_, err = db.NewDropTable().Table(analyticsTable).IfExists().Exec(ctx)
+	require.NoError(t, err)
+	err = mm.InitAll(ctx)
+	require.NoError(t, err)
+
+	groups, err := mm.MigrateAll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"main", "analytics"}, history)
+	require.Len(t, groups["main"].Migrations, 1)
+	require.Len(t, groups["analytics"].Migrations, 1)
+
+	statuses, err := mm.StatusAll(ctx)
+	require.NoError(t, err)
+	require.True(t, statuses["main"].IsUpToDate())
+	require.True(t, statuses["analytics"].IsUpToDate())
+
+	_, err = main.Rollback(ctx)
+	require.NoError(t, err)
+
+	history = nil
+	groups, err = mm.MigrateAll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"main"}, history)
+	require.Len(t, groups["main"].Migrations, 1)
+	require.Empty(t, groups["analytics"].Migrations)
+}
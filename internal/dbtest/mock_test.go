@@ -0,0 +1,99 @@
+package dbtest_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/extra/bunmock"
+)
+
+func TestMock(t *testing.T) {
+	type User struct {
+		ID   int64 `bun:",pk,autoincrement"`
+		Name string
+	}
+
+	mock := bunmock.New()
+	db := bun.NewDB(mock.DB(), pgdialect.New())
+	defer db.Close()
+
+	t.Run("record", func(t *testing.T) {
+		mock.Reset()
+
+		var users []User
+		err := db.NewSelect().Model(&users).Where("id > ?", 1).Scan(ctx)
+		require.NoError(t, err)
+		require.Empty(t, users)
+
+		_, err = db.NewDelete().Model((*User)(nil)).Where("id = ?", 2).Exec(ctx)
+		require.NoError(t, err)
+
+		queries := mock.Queries()
+		require.Len(t, queries, 2)
+		require.Equal(t,
+			`SELECT "user"."id", "user"."name" FROM "users" AS "user" WHERE (id > 1)`,
+			queries[0].SQL)
+		require.Equal(t, `DELETE FROM "users" AS "user" WHERE (id = 2)`, mock.LastQuery().SQL)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("expect", func(t *testing.T) {
+		mock.Reset()
+
+		mock.Expect(`
+			SELECT "user"."id", "user"."name"
+			FROM "users" AS "user"
+			WHERE (id = 1)
+		`).ReturnRows(bunmock.NewRows("id", "name").AddRow(1, "admin"))
+		mock.ExpectRegexp(`^UPDATE "users"`).ReturnResult(0, 3)
+		mock.Expect(`SELECT 1`).WithArgs(42)
+
+		user := new(User)
+		err := db.NewSelect().Model(user).Where("id = 1").Scan(ctx)
+		require.NoError(t, err)
+		require.Equal(t, &User{ID: 1, Name: "admin"}, user)
+
+		res, err := db.NewUpdate().Model((*User)(nil)).Set("name = 'x'").Where("id > 0").Exec(ctx)
+		require.NoError(t, err)
+		n, err := res.RowsAffected()
+		require.NoError(t, err)
+		require.Equal(t, int64(3), n)
+
+		err = mock.ExpectationsWereMet()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "SELECT 1")
+
+		_, err = db.DB.ExecContext(ctx, "SELECT 1", 42)
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+
+		_, err = db.NewSelect().ColumnExpr("1").Exec(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unexpected query")
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		mock.Reset()
+
+		errBusy := errors.New("busy")
+		mock.Expect(`SELECT 1`).ReturnError(errBusy)
+		mock.Expect(`SELECT 2`)
+
+		var num int
+		err := db.NewSelect().ColumnExpr("1").Scan(ctx, &num)
+		require.ErrorIs(t, err, errBusy)
+
+		err = db.NewSelect().ColumnExpr("3").Scan(ctx, &num)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match the expected query")
+
+		err = db.NewSelect().ColumnExpr("2").Scan(ctx, &num)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,75 @@
+package dbtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/extra/bunmock"
+)
+
+func TestMysqlBinaryUUID(t *testing.T) {
+	db := mysql8(t)
+	db = bun.NewDB(db.DB, mysqldialect.New(mysqldialect.WithBinaryUUID()))
+
+	type Model struct {
+		ID     uuid.UUID `bun:",pk"`
+		Ref    *uuid.UUID
+		Tagged [16]byte `bun:"type:uuid"`
+	}
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	var length int
+	err := db.NewRaw(
+		"SELECT character_octet_length FROM information_schema.columns "+
+			"WHERE table_schema = DATABASE() AND table_name = 'models' AND column_name = 'id'",
+	).Scan(ctx, &length)
+	require.NoError(t, err)
+	require.Equal(t, 16, length)
+
+	ref := uuid.New()
+	src := &Model{
+		ID:     uuid.New(),
+		Ref:    &ref,
+		Tagged: uuid.New(),
+	}
+	_, err = db.NewInsert().Model(src).Exec(ctx)
+	require.NoError(t, err)
+
+	var n int
+	err = db.NewRaw("SELECT length(id) FROM models").Scan(ctx, &n)
+	require.NoError(t, err)
+	require.Equal(t, 16, n)
+
+	model := &Model{ID: src.ID}
+	err = db.NewSelect().Model(model).WherePK().Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, src, model)
+}
+
+func TestMysqlQueryTimeoutHint(t *testing.T) {
+	mock := bunmock.New()
+	db := bun.NewDB(mock.DB(), mysqldialect.New(), bun.WithQueryTimeout(1500*time.Millisecond))
+	t.Cleanup(func() { db.Close() })
+
+	subq := db.NewSelect().ColumnExpr("1")
+	q := db.NewSelect().ColumnExpr("(?)", subq)
+	require.Equal(t, "SELECT (SELECT 1)", q.String())
+
+	_, err := q.Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT /*+ MAX_EXECUTION_TIME(1500) */ (SELECT 1)", mock.LastQuery().SQL)
+
+	_, err = db.NewSelect().ColumnExpr("1").Timeout(0).Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT 1", mock.LastQuery().SQL)
+
+	_, err = db.NewRaw("INSERT INTO t ?", subq).Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO t SELECT 1", mock.LastQuery().SQL)
+}
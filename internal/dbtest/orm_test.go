@@ -34,6 +34,9 @@ func TestORM(t *testing.T) {
 		{testRelationBelongsToSelf},
 		{testCompositeHasMany},
 		{testCompositeM2M},
+		{testRelationChunks},
+		{testRelationParallel},
+		{testFixtureRelations},
 	}
 
 	testEachDB(t, func(t *testing.T, dbName string, db *bun.DB) {
@@ -519,6 +522,118 @@ func testCompositeM2M(t *testing.T, db *bun.DB) {
 	require.Equal(t, 1, len(ordersOut2[0].Items))
 }
 
+func testRelationChunks(t *testing.T, db *bun.DB) {
+	chunked := bun.NewDB(db.DB, db.Dialect(), bun.WithRelationChunkSize(1))
+
+	var queries []string
+	hook := &queryHook{
+		beforeQuery: func(ctx context.Context, event *bun.QueryEvent) context.Context {
+			queries = append(queries, event.Query)
+			return ctx
+		},
+	}
+	chunked.AddQueryHook(hook)
+
+	selectAuthors := func(db *bun.DB) []Author {
+		var authors []Author
+		err := db.NewSelect().
+			Model(&authors).
+			Relation("Books", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.OrderExpr("book.id ASC")
+			}).
+			Relation("Books.Genres", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.OrderExpr("genre.id ASC")
+			}).
+			Relation("Books.Translations", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.OrderExpr("tr.id ASC")
+			}).
+			OrderExpr("author.id ASC").
+			Scan(ctx)
+		require.NoError(t, err)
+		return authors
+	}
+
+	want := selectAuthors(db)
+	got := selectAuthors(chunked)
+	require.Equal(t, want, got)
+
+	var numBooks int
+	for _, author := range want {
+		numBooks += len(author.Books)
+	}
+	require.Greater(t, numBooks, 1)
+	// One query for authors, one per author for books,
+	// and one per book for genres and translations.
+	require.Len(t, queries, 1+len(want)+2*numBooks)
+}
+
+func testRelationParallel(t *testing.T, db *bun.DB) {
+	parallel := bun.NewDB(db.DB, db.Dialect(),
+		bun.WithRelationParallelism(4), bun.WithRelationChunkSize(2))
+
+	selectBooks := func(db bun.IDB) []Book {
+		var books []Book
+		err := db.NewSelect().
+			Model(&books).
+			Relation("Author").
+			Relation("Author.Books", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.OrderExpr("book.id ASC")
+			}).
+			Relation("Genres", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.OrderExpr("genre.id ASC")
+			}).
+			Relation("Genres.Subgenres").
+			Relation("Translations", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.OrderExpr("tr.id ASC")
+			}).
+			Relation("Translations.Comments").
+			Relation("Comments").
+			OrderExpr("book.id ASC").
+			Scan(ctx)
+		require.NoError(t, err)
+		return books
+	}
+
+	want := selectBooks(db)
+	require.Equal(t, want, selectBooks(parallel))
+
+	err := parallel.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		require.Equal(t, want, selectBooks(tx))
+		return nil
+	})
+	require.NoError(t, err)
+
+	var books []Book
+	err = parallel.NewSelect().
+		Model(&books).
+		Relation("Genres").
+		Relation("Translations", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("no_such_column = 1")
+		}).
+		Scan(ctx)
+	require.Error(t, err)
+}
+
+func testFixtureRelations(t *testing.T, db *bun.DB) {
+	fixture := dbfixture.New(db, dbfixture.WithTruncateTables())
+	err := fixture.Load(ctx, os.DirFS("testdata"), "fixture_relations.yaml")
+	require.NoError(t, err)
+
+	book := new(Book)
+	err = db.NewSelect().
+		Model(book).
+		Column("book.id", "book.author_id", "book.editor_id").
+		Relation("Author").
+		Relation("Editor").
+		Where("book.id = ?", 200).
+		Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 20, book.AuthorID)
+	require.Equal(t, "john", book.Author.Name)
+	require.Equal(t, 21, book.EditorID)
+	require.Equal(t, "jane", book.Editor.Name)
+}
+
 type Genre struct {
 	ID     int `bun:",pk"`
 	Name   string
@@ -58,6 +58,40 @@ func TestPostgresArray(t *testing.T) {
 	require.Nil(t, strs)
 }
 
+func TestPostgresArrayJSONProvider(t *testing.T) {
+	type Model struct {
+		ID    int64             `bun:",pk,autoincrement"`
+		Array []string          `bun:",array"`
+		Attrs map[string]string `bun:",hstore"`
+		Data  map[string]string
+	}
+
+	provider := new(countingJSONProvider)
+	db := pg(t)
+	t.Cleanup(func() { db.Close() })
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithJSONProvider(provider))
+
+	_, err := db.Exec(`CREATE EXTENSION IF NOT EXISTS HSTORE;`)
+	require.NoError(t, err)
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	src := &Model{
+		ID:    1,
+		Array: []string{"one", "two"},
+		Attrs: map[string]string{"foo": "bar"},
+		Data:  map[string]string{"hello": "world"},
+	}
+	_, err = db.NewInsert().Model(src).Exec(ctx)
+	require.NoError(t, err)
+
+	model := new(Model)
+	err = db.NewSelect().Model(model).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, src, model)
+	require.Equal(t, 1, provider.marshaled)
+	require.Equal(t, 1, provider.unmarshaled)
+}
+
 func TestPostgresArrayQuote(t *testing.T) {
 	db := pg(t)
 	t.Cleanup(func() { db.Close() })
@@ -877,3 +911,195 @@ func TestPostgresMultiRange(t *testing.T) {
 	err = db.NewSelect().Model(out).Scan(ctx)
 	require.NoError(t, err)
 }
+
+func TestPostgresRangeExclusion(t *testing.T) {
+	type Booking struct {
+		bun.BaseModel `bun:"table:bookings,exclude:gist(room_id WITH =, during WITH &&)"`
+
+		ID     int64 `bun:",pk,autoincrement"`
+		RoomID int64
+		During pgdialect.Range[time.Time]
+		Guests pgdialect.Range[int32]
+	}
+
+	ctx := context.Background()
+
+	db := pg(t)
+	t.Cleanup(func() { db.Close() })
+
+	_, err := db.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS btree_gist")
+	require.NoError(t, err)
+
+	mustResetModel(t, ctx, db, (*Booking)(nil))
+
+	tm := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	in := &Booking{
+		RoomID: 1,
+		During: pgdialect.NewRange(tm, tm.Add(2*time.Hour)),
+		Guests: pgdialect.Range[int32]{Lower: 1, LowerBound: pgdialect.RangeBoundInclusiveLeft},
+	}
+	_, err = db.NewInsert().Model(in).Exec(ctx)
+	require.NoError(t, err)
+
+	out := new(Booking)
+	err = db.NewSelect().Model(out).Where("during @> ?::timestamptz", tm.Add(time.Hour)).Scan(ctx)
+	require.NoError(t, err)
+	require.True(t, out.During.Lower.Equal(tm))
+	require.Equal(t, in.Guests, out.Guests)
+
+	overlapping := &Booking{RoomID: 1, During: pgdialect.NewRange(tm.Add(time.Hour), tm.Add(3*time.Hour))}
+	_, err = db.NewInsert().Model(overlapping).Exec(ctx)
+	require.Error(t, err)
+
+	overlapping.RoomID = 2
+	_, err = db.NewInsert().Model(overlapping).Exec(ctx)
+	require.NoError(t, err)
+}
+
+func TestPostgresIdentityAlways(t *testing.T) {
+	type Invoice struct {
+		ID     int64 `bun:",pk,identity:always(START WITH 1000 INCREMENT BY 10)"`
+		Number int64 `bun:",identity"`
+		Title  string
+	}
+
+	ctx := context.Background()
+
+	db := pg(t)
+	t.Cleanup(func() { db.Close() })
+
+	mustResetModel(t, ctx, db, (*Invoice)(nil))
+
+	invoices := []Invoice{{Title: "one"}, {Title: "two"}}
+	_, err := db.NewInsert().Model(&invoices).Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), invoices[0].ID)
+	require.Equal(t, int64(1010), invoices[1].ID)
+	require.Equal(t, int64(1), invoices[0].Number)
+
+	invoices[0].Title = "updated"
+	_, err = db.NewUpdate().Model(&invoices[0]).WherePK().Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewInsert().Model(&Invoice{ID: 1}).Exec(ctx)
+	require.Error(t, err)
+}
+
+func TestCockroachDBDialect(t *testing.T) {
+	type Model struct {
+		ID   int64     `bun:",pk,autoincrement"`
+		UUID uuid.UUID `bun:",pk"`
+	}
+
+	sqldb := sql.OpenDB(pgdriver.NewConnector())
+	t.Cleanup(func() { sqldb.Close() })
+
+	dialect := pgdialect.New(pgdialect.WithCockroachDB())
+	db := bun.NewDB(sqldb, dialect)
+	require.True(t, dialect.IsCockroachDB())
+
+	query := db.NewCreateTable().Model((*Model)(nil)).String()
+	require.Equal(t, `CREATE TABLE "models" ("id" BIGINT NOT NULL DEFAULT unique_rowid(), `+
+		`"uuid" UUID NOT NULL DEFAULT gen_random_uuid(), PRIMARY KEY ("id", "uuid"))`, query)
+
+	query = db.NewSelect().
+		Model((*Model)(nil)).
+		AsOfSystemTime("?", "-10s").
+		Where("id = 1").
+		String()
+	require.Equal(t, `SELECT "model"."id", "model"."uuid" FROM "models" AS "model" `+
+		`AS OF SYSTEM TIME '-10s' WHERE (id = 1)`, query)
+
+	b, err := db.NewTruncateTable().Model((*Model)(nil)).AppendQuery(db.Formatter(), nil)
+	require.NoError(t, err)
+	require.Equal(t, `TRUNCATE TABLE "models"`, string(b))
+}
+
+func TestPostgresDialectInitTimeout(t *testing.T) {
+	// The server accepts connections but never responds.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	sqldb := sql.OpenDB(pgdriver.NewConnector(
+		pgdriver.WithAddr(ln.Addr().String()),
+		pgdriver.WithInsecure(true),
+		pgdriver.WithTimeout(time.Minute),
+	))
+	t.Cleanup(func() { sqldb.Close() })
+
+	start := time.Now()
+	db := bun.NewDB(sqldb, pgdialect.New())
+	require.Less(t, time.Since(start), 10*time.Second)
+	require.Equal(t, "DB<dialect=pg>", db.String())
+}
+
+func TestPostgresVectorQuery(t *testing.T) {
+	type Item struct {
+		ID        int64            `bun:",pk,autoincrement"`
+		Embedding pgdialect.Vector `bun:"type:vector(3)"`
+		Other     []float32        `bun:"type:vector(3)"`
+		Raw       pgdialect.Vector
+	}
+
+	sqldb := sql.OpenDB(pgdriver.NewConnector())
+	t.Cleanup(func() { sqldb.Close() })
+	db := bun.NewDB(sqldb, pgdialect.New())
+
+	query := db.NewCreateTable().Model((*Item)(nil)).String()
+	require.Contains(t, query, `"embedding" vector(3), "other" vector(3), "raw" vector`)
+
+	vec := []float32{1, 2, 3}
+	query = db.NewInsert().Model(&Item{Embedding: vec, Other: vec}).String()
+	require.Contains(t, query, `VALUES (DEFAULT, '[1,2,3]', '[1,2,3]', NULL)`)
+
+	query = db.NewSelect().
+		Model((*Item)(nil)).
+		Column("id").
+		Apply(pgdialect.NearestNeighbors(pgdialect.CosineDistance("embedding", vec), 5)).
+		String()
+	require.Equal(t, `SELECT "item"."id" FROM "items" AS "item" `+
+		`ORDER BY "embedding" <=> '[1,2,3]' LIMIT 5`, query)
+}
+
+func TestPostgresSessionVar(t *testing.T) {
+	type tenantKey struct{}
+
+	db := pg(t)
+	db = bun.NewDB(db.DB, db.Dialect(), bun.WithSessionVar("app.current_tenant",
+		func(ctx context.Context) (string, bool) {
+			tenant, ok := ctx.Value(tenantKey{}).(string)
+			return tenant, ok
+		}))
+
+	tenantCtx := context.WithValue(ctx, tenantKey{}, "42")
+
+	currentTenant := func(ctx context.Context, db bun.IDB) string {
+		var tenant sql.NullString
+		err := db.NewSelect().ColumnExpr("current_setting('app.current_tenant', true)").
+			Scan(ctx, &tenant)
+		require.NoError(t, err)
+		return tenant.String
+	}
+
+	require.Equal(t, "42", currentTenant(tenantCtx, db))
+	require.Equal(t, "", currentTenant(ctx, db))
+
+	err := db.RunInTx(tenantCtx, nil, func(ctx context.Context, tx bun.Tx) error {
+		require.Equal(t, "42", currentTenant(ctx, tx))
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The setting is local to the transaction.
+	require.Equal(t, "", currentTenant(ctx, db))
+}
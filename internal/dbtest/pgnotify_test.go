@@ -0,0 +1,75 @@
+package dbtest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/pgnotify"
+)
+
+func TestPGNotify(t *testing.T) {
+	ctx := context.Background()
+
+	db := pg(t)
+
+	notifier := pgnotify.New(db)
+	defer notifier.Close()
+
+	ch1, err := notifier.Listen(ctx, "test_notify")
+	require.NoError(t, err)
+
+	listenCtx, cancel := context.WithCancel(ctx)
+	ch2, err := notifier.Listen(listenCtx, "test_notify")
+	require.NoError(t, err)
+
+	err = pgnotify.Notify(ctx, db, "test_notify", "foo")
+	require.NoError(t, err)
+
+	for _, ch := range []<-chan pgnotify.Notification{ch1, ch2} {
+		select {
+		case n := <-ch:
+			require.Equal(t, "test_notify", n.Channel)
+			require.Equal(t, "foo", n.Payload)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-ch2
+		return !ok
+	}, 3*time.Second, 10*time.Millisecond)
+
+	errRollback := errors.New("rollback")
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := pgnotify.Notify(ctx, tx, "test_notify", "rolled back"); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return pgnotify.Notify(ctx, tx, "test_notify", "committed")
+	})
+	require.NoError(t, err)
+
+	select {
+	case n := <-ch1:
+		require.Equal(t, "committed", n.Payload)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	require.NoError(t, notifier.Close())
+	require.Eventually(t, func() bool {
+		_, ok := <-ch1
+		return !ok
+	}, 3*time.Second, 10*time.Millisecond)
+}
@@ -1,13 +1,16 @@
 package dbtest_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/extra/bundebug"
 	"github.com/uptrace/bun/schema"
 )
 
@@ -76,6 +79,120 @@ func testQueryHook(t *testing.T, dbName string, db *bun.DB) {
 		require.Equal(t, 1, num)
 		hook.require(t)
 	}
+
+	{
+		hook.reset()
+		hook.beforeQuery = func(ctx context.Context, event *bun.QueryEvent) context.Context {
+			return ctx
+		}
+		hook.afterQuery = func(ctx context.Context, event *bun.QueryEvent) {
+			event.Err = errors.New("hook error")
+		}
+
+		_, err := db.Exec("SELECT 1")
+		require.EqualError(t, err, "hook error")
+
+		_, err = db.QueryContext(ctx, "SELECT 1")
+		require.EqualError(t, err, "hook error")
+
+		err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			_, err := tx.ExecContext(ctx, "SELECT 1")
+			return err
+		})
+		require.EqualError(t, err, "hook error")
+
+		_, err = db.Exec("SELECT * FROM missing_table")
+		require.ErrorContains(t, err, "missing_table")
+	}
+
+	{
+		hook.reset()
+		hook.beforeQuery = func(ctx context.Context, event *bun.QueryEvent) context.Context {
+			return ctx
+		}
+		hook.afterQuery = func(ctx context.Context, event *bun.QueryEvent) {
+			event.Err = nil
+		}
+
+		_, err := db.Exec("SELECT * FROM missing_table")
+		require.ErrorContains(t, err, "missing_table")
+	}
+
+	{
+		hook.reset()
+		hook.beforeQuery = func(ctx context.Context, event *bun.QueryEvent) context.Context {
+			return ctx
+		}
+		hook.afterQuery = func(ctx context.Context, event *bun.QueryEvent) {
+			if event.Query == "COMMIT" || event.Query == "ROLLBACK" {
+				event.Err = errors.New("hook error")
+			}
+		}
+
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		tx, err = db.Begin()
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+	}
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	db := sqlite(t)
+
+	var buf bytes.Buffer
+	db.AddQueryHook(bundebug.NewQueryHook(
+		bundebug.WithWriter(&buf),
+		bundebug.WithSlowQueryThreshold(time.Nanosecond),
+		bundebug.WithExplain(true),
+	))
+
+	var num int
+	err := db.NewSelect().
+		TableExpr("(SELECT 1 AS c) AS t").
+		ColumnExpr("c").
+		Scan(ctx, &num)
+	require.NoError(t, err)
+
+	out := buf.String()
+	require.Contains(t, out, "SELECT c FROM (SELECT 1 AS c) AS t")
+	require.Contains(t, out, "slow query at ")
+	require.Contains(t, out, "query_hook_test.go:")
+	require.Contains(t, out, "SCAN")
+}
+
+func TestFilterQueryHook(t *testing.T) {
+	type Model struct {
+		ID int64 `bun:",pk,autoincrement"`
+	}
+
+	db := sqlite(t)
+
+	var queries []string
+	hook := &queryHook{
+		beforeQuery: func(ctx context.Context, event *bun.QueryEvent) context.Context {
+			queries = append(queries, event.Query)
+			return ctx
+		},
+	}
+	db.AddQueryHook(bun.FilterQueryHook(hook,
+		bun.OperationFilter("insert", "DELETE"),
+		bun.TableFilter("models"),
+	))
+
+	mustResetModel(t, ctx, db, (*Model)(nil))
+	queries = nil
+
+	_, err := db.NewInsert().Model(&Model{ID: 1}).Exec(ctx)
+	require.NoError(t, err)
+	err = db.NewSelect().Model(new(Model)).Limit(1).Scan(ctx)
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM models")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{`INSERT INTO "models" ("id") VALUES (1)`}, queries)
 }
 
 type queryHook struct {
@@ -0,0 +1,137 @@
+package dbtest_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func TestQueryRetry(t *testing.T) {
+	type Model struct {
+		ID int64 `bun:",pk,autoincrement"`
+	}
+
+	sqldb, err := sql.Open(sqliteshim.DriverName(), filepath.Join(t.TempDir(), "sqlite.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, sqldb.Close())
+	})
+
+	connector := &flakyConnector{dsn: filepath.Join(t.TempDir(), "sqlite.db"), driver: sqldb.Driver()}
+	flakydb := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		require.NoError(t, flakydb.Close())
+	})
+
+	db := bun.NewDB(flakydb, sqlitedialect.New(), bun.WithQueryRetryPolicy(bun.QueryRetryPolicy{
+		MinBackoff: time.Millisecond,
+	}))
+	mustResetModel(t, ctx, db, (*Model)(nil))
+
+	connector.failures.Store(2)
+	count, err := db.NewSelect().Model((*Model)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	require.Equal(t, int32(0), connector.failures.Load())
+
+	// Writes are not idempotent by default.
+	connector.failures.Store(1)
+	_, err = db.NewInsert().Model(&Model{}).Exec(ctx)
+	require.ErrorIs(t, err, syscall.ECONNRESET)
+
+	// Too many failures.
+	connector.failures.Store(5)
+	_, err = db.NewSelect().Model((*Model)(nil)).Count(ctx)
+	require.ErrorIs(t, err, syscall.ECONNRESET)
+	require.Equal(t, int32(1), connector.failures.Load())
+	connector.failures.Store(0)
+
+	// Raw writes are not retried either, even though RawQuery reports SELECT.
+	// database/sql itself retries driver.ErrBadConn up to 3 times.
+	connector.err = driver.ErrBadConn
+	connector.failures.Store(4)
+	_, err = db.NewRaw("INSERT INTO ? DEFAULT VALUES", bun.Ident("models")).Exec(ctx)
+	require.ErrorIs(t, err, driver.ErrBadConn)
+	require.Equal(t, int32(1), connector.failures.Load())
+	connector.failures.Store(0)
+
+	count, err = db.NewSelect().Model((*Model)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+}
+
+// flakyConnector opens connections that fail queries with err or a connection reset
+// error while failures is positive.
+type flakyConnector struct {
+	dsn      string
+	driver   driver.Driver
+	err      error
+	failures atomic.Int32
+}
+
+func (c *flakyConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &flakyConn{Conn: conn, connector: c}, nil
+}
+
+func (c *flakyConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type flakyConn struct {
+	driver.Conn
+	connector *flakyConnector
+}
+
+func (c *flakyConn) fail() error {
+	for {
+		n := c.connector.failures.Load()
+		if n <= 0 {
+			return nil
+		}
+		if c.connector.failures.CompareAndSwap(n, n-1) {
+			if c.connector.err != nil {
+				return c.connector.err
+			}
+			return syscall.ECONNRESET
+		}
+	}
+}
+
+func (c *flakyConn) QueryContext(
+	ctx context.Context, query string, args []driver.NamedValue,
+) (driver.Rows, error) {
+	if err := c.fail(); err != nil {
+		return nil, err
+	}
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *flakyConn) ExecContext(
+	ctx context.Context, query string, args []driver.NamedValue,
+) (driver.Result, error) {
+	if err := c.fail(); err != nil {
+		return nil, err
+	}
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/bradleyjkemp/cupaloy"
 
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/schema"
 )
 
@@ -1585,6 +1586,83 @@ func TestQuery(t *testing.T) {
 				return db.NewUpdate().Model(new(Story)).Set("name = ?", "new-name").WherePK().Order("id").Limit(1)
 			},
 		},
+		{
+			id: 172,
+			query: func(db *bun.DB) schema.QueryAppender {
+				models := []Model{{ID: 1}, {ID: 2}, {ID: 1}}
+				return db.NewSelect().Model(&models).WherePK()
+			},
+		},
+		{
+			id: 173,
+			query: func(db *bun.DB) schema.QueryAppender {
+				return db.NewSelect().Model(&Model{Str: "hello"}).WherePK("str")
+			},
+		},
+		{
+			id: 174,
+			query: func(db *bun.DB) schema.QueryAppender {
+				// UNIQUE NULLS NOT DISTINCT (...) DEFERRABLE INITIALLY DEFERRED (PostgreSQL)
+				type Booking struct {
+					RoomID   int64     `bun:",unique:booking_slot(deferred nulls_not_distinct)"`
+					StartsAt time.Time `bun:",unique:booking_slot"`
+				}
+				return db.NewCreateTable().Model((*Booking)(nil))
+			},
+		},
+		{
+			id: 175,
+			query: func(db *bun.DB) schema.QueryAppender {
+				// EXCLUDE USING gist (...) (PostgreSQL)
+				type Booking struct {
+					bun.BaseModel `bun:"table:bookings,exclude:gist(room_id WITH =, during WITH &&)"`
+
+					RoomID int64
+					During pgdialect.Range[time.Time]
+				}
+				return db.NewCreateTable().Model((*Booking)(nil))
+			},
+		},
+		{
+			id: 176,
+			query: func(db *bun.DB) schema.QueryAppender {
+				// GENERATED ALWAYS AS IDENTITY (...) (PostgreSQL)
+				type Invoice struct {
+					ID     int64 `bun:",pk,identity:always(START WITH 1000 INCREMENT BY 10)"`
+					Number int64 `bun:",identity"`
+				}
+				return db.NewCreateTable().Model((*Invoice)(nil))
+			},
+		},
+		{
+			id: 177,
+			query: func(db *bun.DB) schema.QueryAppender {
+				type Model struct {
+					ID   int64  `bun:",pk,autoincrement"`
+					Name string `bun:",notnull,collate:C"`
+				}
+				return db.NewCreateTable().Model((*Model)(nil))
+			},
+		},
+		{
+			id: 178,
+			query: func(db *bun.DB) schema.QueryAppender {
+				return db.NewSelect().Model((*Model)(nil)).
+					Where("? = ?", bun.Collate("str", "und-x-icu"), "hello").
+					OrderExpr("? DESC", bun.Collate("str", "de_DE"))
+			},
+		},
+		{
+			id: 179,
+			query: func(db *bun.DB) schema.QueryAppender {
+				models := []Model{
+					{ID: 1, Str: "hello"},
+					{ID: 2, Str: "world"},
+					{ID: 1, Str: "hello"},
+				}
+				return db.NewSelect().Model(&models).WherePK("id", "str")
+			},
+		},
 	}
 
 	timeRE := regexp.MustCompile(`'2\d{3}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(\.\d+)?(\+\d{2}:\d{2})?'`)
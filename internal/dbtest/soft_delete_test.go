@@ -2,6 +2,7 @@ package dbtest_test
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/feature"
+	"github.com/uptrace/bun/schema"
 )
 
 func TestSoftDelete(t *testing.T) {
@@ -21,6 +23,9 @@ func TestSoftDelete(t *testing.T) {
 		{run: testSoftDeleteAPI},
 		{run: testSoftDeleteBulk},
 		{run: testSoftDeleteForce},
+		{run: testSoftDeleteFlag},
+		{run: testSoftDeleteStatus},
+		{run: testSoftDeleteCustom},
 	}
 	testEachDB(t, func(t *testing.T, dbName string, db *bun.DB) {
 		for _, test := range tests {
@@ -179,3 +184,136 @@ func testSoftDeleteBulk(t *testing.T, db *bun.DB) {
 	require.NoError(t, err)
 	require.Equal(t, 0, count)
 }
+
+type FlagVideo struct {
+	ID        int64 `bun:",pk,autoincrement"`
+	Name      string
+	IsDeleted bool `bun:",soft_delete:flag,notnull"`
+}
+
+func testSoftDeleteFlag(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*FlagVideo)(nil))
+
+	videos := []FlagVideo{{Name: "video1"}, {Name: "video2"}}
+	_, err := db.NewInsert().Model(&videos).Exec(ctx)
+	require.NoError(t, err)
+
+	video := &videos[0]
+	_, err = db.NewDelete().Model(video).WherePK().Exec(ctx)
+	require.NoError(t, err)
+	require.True(t, video.IsDeleted)
+
+	var names []string
+	err = db.NewSelect().Model((*FlagVideo)(nil)).Column("name").Scan(ctx, &names)
+	require.NoError(t, err)
+	require.Equal(t, []string{"video2"}, names)
+
+	var deleted []FlagVideo
+	err = db.NewSelect().Model(&deleted).WhereDeleted().Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	require.Equal(t, "video1", deleted[0].Name)
+	require.True(t, deleted[0].IsDeleted)
+
+	count, err := db.NewSelect().Model((*FlagVideo)(nil)).WhereAllWithDeleted().Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+}
+
+type StatusVideo struct {
+	ID     int64 `bun:",pk,autoincrement"`
+	Name   string
+	Status string `bun:",soft_delete:archived"`
+}
+
+func testSoftDeleteStatus(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*StatusVideo)(nil))
+
+	videos := []StatusVideo{
+		{Name: "video1", Status: "published"},
+		{Name: "video2", Status: "draft"},
+		{Name: "video3"},
+	}
+	_, err := db.NewInsert().Model(&videos).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewDelete().Model((*StatusVideo)(nil)).Where("name = ?", "video1").Exec(ctx)
+	require.NoError(t, err)
+
+	var names []string
+	err = db.NewSelect().Model((*StatusVideo)(nil)).Column("name").Order("name").Scan(ctx, &names)
+	require.NoError(t, err)
+	require.Equal(t, []string{"video2", "video3"}, names)
+
+	names = nil
+	err = db.NewSelect().Model((*StatusVideo)(nil)).Column("name").WhereDeleted().Scan(ctx, &names)
+	require.NoError(t, err)
+	require.Equal(t, []string{"video1"}, names)
+
+	var status string
+	err = db.NewSelect().Model((*StatusVideo)(nil)).Column("status").
+		Where("name = ?", "video1").WhereAllWithDeleted().Scan(ctx, &status)
+	require.NoError(t, err)
+	require.Equal(t, "archived", status)
+}
+
+type CustomVideo struct {
+	ID        int64 `bun:",pk,autoincrement"`
+	Name      string
+	DeletedAt int64 `bun:",soft_delete"`
+}
+
+var _ schema.SoftDeleteModel = (*CustomVideo)(nil)
+
+func (*CustomVideo) SoftDeleteStrategy(field *schema.Field) schema.SoftDelete {
+	return customSoftDelete{field: field}
+}
+
+// customSoftDelete stores the deletion time in seconds and uses 0 for rows that are not deleted.
+type customSoftDelete struct {
+	field *schema.Field
+}
+
+func (s customSoftDelete) AppendDeletedValue(fmter schema.Formatter, b []byte, tm time.Time) []byte {
+	return schema.Append(fmter, b, tm.Unix())
+}
+
+func (s customSoftDelete) AppendWhere(fmter schema.Formatter, b []byte, column []byte, deleted bool) []byte {
+	b = append(b, column...)
+	if deleted {
+		return append(b, " > 0"...)
+	}
+	return append(b, " = 0"...)
+}
+
+func (s customSoftDelete) SetDeleted(fv reflect.Value, tm time.Time) error {
+	fv.SetInt(tm.Unix())
+	return nil
+}
+
+func testSoftDeleteCustom(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+	mustResetModel(t, ctx, db, (*CustomVideo)(nil))
+
+	videos := []CustomVideo{{Name: "video1"}, {Name: "video2"}}
+	_, err := db.NewInsert().Model(&videos).Exec(ctx)
+	require.NoError(t, err)
+
+	start := time.Now().Unix()
+	video := &videos[1]
+	_, err = db.NewDelete().Model(video).WherePK().Exec(ctx)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, video.DeletedAt, start)
+
+	var names []string
+	err = db.NewSelect().Model((*CustomVideo)(nil)).Column("name").Scan(ctx, &names)
+	require.NoError(t, err)
+	require.Equal(t, []string{"video1"}, names)
+
+	var deleted CustomVideo
+	err = db.NewSelect().Model(&deleted).WhereDeleted().Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, video.DeletedAt, deleted.DeletedAt)
+}
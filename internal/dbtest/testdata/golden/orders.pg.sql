@@ -0,0 +1,6 @@
+SELECT user_id, count(*)
+FROM "orders" AS "order"
+WHERE (status IN ('new', 'paid')) AND (id > (SELECT min(id) FROM orders WHERE status = 'new'))
+GROUP BY "user_id"
+ORDER BY "user_id"
+LIMIT 10
@@ -110,6 +110,33 @@ func (p *Parser) ReadSep(sep byte) ([]byte, bool) {
 	return b, true
 }
 
+// ReadCall reads a parenthesized argument list that follows an identifier,
+// e.g. ("Author.Profile"), and returns it with the parentheses. Parentheses inside
+// quoted strings are ignored.
+func (p *Parser) ReadCall() (string, bool) {
+	if p.Peek() != '(' {
+		return "", false
+	}
+
+	var quote byte
+	for i := p.i + 1; i < len(p.b); i++ {
+		c := p.b[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ')':
+			b := p.b[p.i : i+1]
+			p.i = i + 1
+			return internal.String(b), true
+		}
+	}
+	return "", false
+}
+
 func (p *Parser) ReadIdentifier() (string, bool) {
 	if p.i < len(p.b) && p.b[p.i] == '(' {
 		s := p.i + 1
@@ -436,6 +436,73 @@ func TestParser_ReadIdentifier(t *testing.T) {
 	}
 }
 
+func TestParser_ReadCall(t *testing.T) {
+	type fields struct {
+		b []byte
+		i int
+	}
+	tests := []struct {
+		name        string
+		fields      fields
+		want        string
+		wantOk      bool
+		idAfterRead int
+	}{
+		{
+			name: "read call with a quoted argument",
+			fields: fields{
+				b: []byte(`?RelationAlias("Author").id`),
+				i: 14,
+			},
+			want:        `("Author")`,
+			wantOk:      true,
+			idAfterRead: 24,
+		},
+		{
+			name: "ignore parenthesis inside quotes",
+			fields: fields{
+				b: []byte(`?Fn(')(') = 1`),
+				i: 3,
+			},
+			want:        `(')(')`,
+			wantOk:      true,
+			idAfterRead: 9,
+		},
+		{
+			name: "no call",
+			fields: fields{
+				b: []byte("?TableAlias.id"),
+				i: 11,
+			},
+			want:        "",
+			wantOk:      false,
+			idAfterRead: 11,
+		},
+		{
+			name: "unclosed call",
+			fields: fields{
+				b: []byte(`?Fn("x"`),
+				i: 3,
+			},
+			want:        "",
+			wantOk:      false,
+			idAfterRead: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{
+				b: tt.fields.b,
+				i: tt.fields.i,
+			}
+			got, gotOk := p.ReadCall()
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantOk, gotOk)
+			require.Equal(t, tt.idAfterRead, p.i)
+		})
+	}
+}
+
 func TestParser_ReadNumber(t *testing.T) {
 	type fields struct {
 		b []byte
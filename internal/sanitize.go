@@ -0,0 +1,91 @@
+package internal
+
+import "strings"
+
+// SanitizeQuery replaces string and numeric literals in the query with ?, so formatted
+// queries can be logged or recorded without leaking values, for example:
+//
+//	SELECT * FROM users WHERE email = 'foo@example.com' AND id = 1
+//	SELECT * FROM users WHERE email = ? AND id = ?
+//
+// Quoted identifiers are left intact. Backslashes escape quotes in string literals
+// like in MySQL, e.g. 'It\'s'. In PostgreSQL standard strings a backslash
+// before the closing quote is literal, so the rest of the query is replaced too,
+// which hides more than needed, but never leaks the value.
+func SanitizeQuery(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			i = skipString(query, i)
+			b.WriteByte('?')
+		case c == '"' || c == '`':
+			j := skipQuoted(query, i, c)
+			b.WriteString(query[i:j])
+			i = j
+		case isDigit(c) && (i == 0 || !isIdentByte(query[i-1])):
+			for i < len(query) && (isIdentByte(query[i]) || query[i] == '.') {
+				i++
+			}
+			b.WriteByte('?')
+		case isIdentByte(c):
+			j := i
+			for j < len(query) && isIdentByte(query[j]) {
+				j++
+			}
+			b.WriteString(query[i:j])
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+// skipString returns the index after the string literal starting at i.
+// Quotes are escaped by doubling them or with a backslash.
+func skipString(s string, i int) int {
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '\'':
+			if i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// skipQuoted returns the index after the quoted identifier starting at i.
+// Quotes are escaped by doubling them.
+func skipQuoted(s string, i int, quote byte) int {
+	for i++; i < len(s); i++ {
+		if s[i] != quote {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(s)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
@@ -0,0 +1,216 @@
+package bun
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun/schema"
+)
+
+type loaderConfig struct {
+	wait     time.Duration
+	maxBatch int
+}
+
+type LoaderOption func(c *loaderConfig)
+
+// WithLoaderWait configures how long the loader waits for more keys before it executes
+// the query. Default is 1ms.
+func WithLoaderWait(wait time.Duration) LoaderOption {
+	return func(c *loaderConfig) {
+		c.wait = wait
+	}
+}
+
+// WithLoaderMaxBatch configures the maximum number of keys loaded with a single query.
+// Default is 100.
+func WithLoaderMaxBatch(size int) LoaderOption {
+	return func(c *loaderConfig) {
+		c.maxBatch = size
+	}
+}
+
+// Loader loads models by primary keys, coalescing concurrent loads into a single
+// WHERE pk IN (...) query, for example, in GraphQL resolvers:
+//
+//	loader := bun.NewLoader[User](db)
+//
+//	// Concurrent calls are executed as one query.
+//	user, err := loader.Load(ctx, userID)
+//
+// The model must have a single primary key. Keys are converted to the type of
+// the primary key, e.g. int to int64. The query uses the context of the first load
+// in the batch, without its cancellation. Loaded models are not cached.
+type Loader[T any] struct {
+	db    IDB
+	table *schema.Table
+	cfg   loaderConfig
+
+	mu    sync.Mutex
+	batch *loaderBatch[T]
+}
+
+type loaderBatch[T any] struct {
+	ctx   context.Context
+	keys  []interface{}
+	seen  map[interface{}]struct{}
+	timer *time.Timer
+
+	done   chan struct{}
+	models map[interface{}]*T
+	err    error
+}
+
+func NewLoader[T any](db IDB, opts ...LoaderOption) *Loader[T] {
+	l := &Loader[T]{
+		db:    db,
+		table: db.Dialect().Tables().Get(reflect.TypeOf((*T)(nil)).Elem()),
+		cfg: loaderConfig{
+			wait:     time.Millisecond,
+			maxBatch: 100,
+		},
+	}
+	for _, opt := range opts {
+		opt(&l.cfg)
+	}
+	return l
+}
+
+// Load returns the model with the primary key or sql.ErrNoRows if it does not exist.
+func (l *Loader[T]) Load(ctx context.Context, key interface{}) (*T, error) {
+	key, err := l.normalizeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := l.add(ctx, key)
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if batch.err != nil {
+		return nil, batch.err
+	}
+	model, ok := batch.models[key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return model, nil
+}
+
+// LoadMany returns the models with the primary keys in the same order.
+// Models that do not exist are nil.
+func (l *Loader[T]) LoadMany(ctx context.Context, keys ...interface{}) ([]*T, error) {
+	normKeys := make([]interface{}, len(keys))
+	batches := make([]*loaderBatch[T], len(keys))
+	for i, key := range keys {
+		key, err := l.normalizeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		normKeys[i] = key
+		batches[i] = l.add(ctx, key)
+	}
+
+	models := make([]*T, len(keys))
+	for i, batch := range batches {
+		select {
+		case <-batch.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if batch.err != nil {
+			return nil, batch.err
+		}
+		models[i] = batch.models[normKeys[i]]
+	}
+	return models, nil
+}
+
+func (l *Loader[T]) normalizeKey(key interface{}) (interface{}, error) {
+	if len(l.table.PKs) != 1 {
+		return nil, fmt.Errorf("bun: Loader requires a model with a single primary key, %s has %d",
+			l.table.TypeName, len(l.table.PKs))
+	}
+
+	typ := l.table.PKs[0].StructField.Type
+	v := reflect.ValueOf(key)
+	if !v.IsValid() {
+		return nil, errors.New("bun: Loader got a nil key")
+	}
+	if v.Type() == typ {
+		return key, nil
+	}
+	if !v.CanConvert(typ) {
+		return nil, fmt.Errorf("bun: Loader can't use %T as a key of %s", key, l.table.TypeName)
+	}
+	return v.Convert(typ).Interface(), nil
+}
+
+// add adds the key to the current batch and returns the batch.
+func (l *Loader[T]) add(ctx context.Context, key interface{}) *loaderBatch[T] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	batch := l.batch
+	if batch == nil {
+		batch = &loaderBatch[T]{
+			ctx:  context.WithoutCancel(ctx),
+			seen: make(map[interface{}]struct{}),
+			done: make(chan struct{}),
+		}
+		l.batch = batch
+		batch.timer = time.AfterFunc(l.cfg.wait, func() {
+			l.dispatch(batch)
+		})
+	}
+
+	if _, ok := batch.seen[key]; !ok {
+		batch.seen[key] = struct{}{}
+		batch.keys = append(batch.keys, key)
+	}
+
+	if len(batch.keys) >= l.cfg.maxBatch && batch.timer.Stop() {
+		l.batch = nil
+		go l.load(batch)
+	}
+	return batch
+}
+
+func (l *Loader[T]) dispatch(batch *loaderBatch[T]) {
+	l.mu.Lock()
+	if l.batch == batch {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	l.load(batch)
+}
+
+func (l *Loader[T]) load(batch *loaderBatch[T]) {
+	defer close(batch.done)
+
+	var models []T
+	if err := l.db.NewSelect().
+		Model(&models).
+		Where("?TablePKs IN (?)", In(batch.keys)).
+		Scan(batch.ctx); err != nil {
+		batch.err = err
+		return
+	}
+
+	pk := l.table.PKs[0]
+	batch.models = make(map[interface{}]*T, len(models))
+	for i := range models {
+		strct := reflect.ValueOf(&models[i]).Elem()
+		batch.models[pk.Value(strct).Interface()] = &models[i]
+	}
+}
@@ -0,0 +1,214 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Batch describes a range of rows processed by a BatchFunc.
+type Batch struct {
+	// Number is the 1-based number of the batch in the current run.
+	Number int
+	// FirstPK and LastPK are the inclusive bounds of the primary keys in the batch.
+	FirstPK int64
+	LastPK  int64
+	// Size is the number of rows in the batch.
+	Size int
+	// Processed is the number of rows processed in the current run including this batch.
+	Processed int64
+}
+
+// BatchFunc processes a batch of rows. It runs in a transaction together with
+// the checkpoint update, so a batch is either fully processed or retried on resume.
+type BatchFunc func(ctx context.Context, tx bun.Tx, batch *Batch) error
+
+type batchConfig struct {
+	pk              string
+	batchSize       int
+	sleep           time.Duration
+	progress        func(batch *Batch)
+	checkpointTable string
+}
+
+type BatchOption func(cfg *batchConfig)
+
+// WithBatchPK sets the integer primary key column used to order and split the table.
+// The default is "id".
+func WithBatchPK(column string) BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.pk = column
+	}
+}
+
+// WithBatchSize sets the number of rows in a batch. The default is 1000.
+func WithBatchSize(size int) BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.batchSize = size
+	}
+}
+
+// WithBatchSleep sets the pause between batches to reduce load on the database.
+func WithBatchSleep(d time.Duration) BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.sleep = d
+	}
+}
+
+// WithBatchProgress sets a callback that is called after each committed batch.
+func WithBatchProgress(fn func(batch *Batch)) BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.progress = fn
+	}
+}
+
+// WithCheckpointTable sets the table used to store checkpoints.
+// The default is "bun_migration_checkpoints".
+func WithCheckpointTable(table string) BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.checkpointTable = table
+	}
+}
+
+type migrationCheckpoint struct {
+	Name      string    `bun:",pk"`
+	LastPK    int64     `bun:",notnull"`
+	UpdatedAt time.Time `bun:",notnull"`
+}
+
+// RunInBatches iterates the table in batches ordered by the primary key and calls fn
+// for each batch. After each batch the last processed key is stored in a checkpoint
+// table under the name, so an interrupted run resumes where it stopped.
+// The checkpoint is removed when all rows are processed.
+func RunInBatches(
+	ctx context.Context, db *bun.DB, name, table string, fn BatchFunc, opts ...BatchOption,
+) error {
+	cfg := &batchConfig{
+		pk:              "id",
+		batchSize:       1000,
+		checkpointTable: "bun_migration_checkpoints",
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if name == "" {
+		return errors.New("migrate: batch migration name can't be empty")
+	}
+	if cfg.batchSize <= 0 {
+		return fmt.Errorf("migrate: invalid batch size: %d", cfg.batchSize)
+	}
+
+	if _, err := db.NewCreateTable().
+		Model((*migrationCheckpoint)(nil)).
+		ModelTableExpr(cfg.checkpointTable).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	lastPK, resumed, err := selectCheckpoint(ctx, db, cfg, name)
+	if err != nil {
+		return err
+	}
+
+	batch := new(Batch)
+	for {
+		q := db.NewSelect().
+			ColumnExpr("?", bun.Ident(cfg.pk)).
+			TableExpr("?", bun.Ident(table)).
+			OrderExpr("? ASC", bun.Ident(cfg.pk)).
+			Limit(cfg.batchSize)
+		if resumed || batch.Number > 0 {
+			q = q.Where("? > ?", bun.Ident(cfg.pk), lastPK)
+		}
+
+		var pks []int64
+		if err := q.Scan(ctx, &pks); err != nil {
+			return err
+		}
+		if len(pks) == 0 {
+			break
+		}
+
+		batch.Number++
+		batch.FirstPK = pks[0]
+		batch.LastPK = pks[len(pks)-1]
+		batch.Size = len(pks)
+		batch.Processed += int64(len(pks))
+
+		if err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if err := fn(ctx, tx, batch); err != nil {
+				return err
+			}
+			return saveCheckpoint(ctx, tx, cfg, name, batch.LastPK)
+		}); err != nil {
+			return err
+		}
+		lastPK = batch.LastPK
+
+		if cfg.progress != nil {
+			cfg.progress(batch)
+		}
+
+		if len(pks) < cfg.batchSize {
+			break
+		}
+		if cfg.sleep > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(cfg.sleep):
+			}
+		}
+	}
+
+	_, err = db.NewDelete().
+		Model((*migrationCheckpoint)(nil)).
+		ModelTableExpr(cfg.checkpointTable).
+		Where("name = ?", name).
+		Exec(ctx)
+	return err
+}
+
+func selectCheckpoint(
+	ctx context.Context, db *bun.DB, cfg *batchConfig, name string,
+) (int64, bool, error) {
+	checkpoint := new(migrationCheckpoint)
+	if err := db.NewSelect().
+		ColumnExpr("*").
+		Model(checkpoint).
+		ModelTableExpr(cfg.checkpointTable).
+		Where("name = ?", name).
+		Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return checkpoint.LastPK, true, nil
+}
+
+func saveCheckpoint(ctx context.Context, tx bun.Tx, cfg *batchConfig, name string, lastPK int64) error {
+	if _, err := tx.NewDelete().
+		Model((*migrationCheckpoint)(nil)).
+		ModelTableExpr(cfg.checkpointTable).
+		Where("name = ?", name).
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	_, err := tx.NewInsert().
+		Model(&migrationCheckpoint{
+			Name:      name,
+			LastPK:    lastPK,
+			UpdatedAt: time.Now(),
+		}).
+		ModelTableExpr(cfg.checkpointTable).
+		Exec(ctx)
+	return err
+}
@@ -0,0 +1,88 @@
+package migrate
+
+import (
+	"io"
+	"sort"
+
+	"github.com/uptrace/bun/schema"
+)
+
+// DumpSchema writes CREATE TABLE statements for the models registered with
+// bun.DB.RegisterModel, for example, to keep a schema.sql file up to date.
+// Tables are sorted by name, but a table referenced by a foreign key is always
+// written before the tables that reference it. The statements are generated
+// from the models, so the database is not queried.
+func (m *Migrator) DumpSchema(w io.Writer) error {
+	queries, err := m.schemaQueries()
+	if err != nil {
+		return err
+	}
+	for _, query := range queries {
+		if _, err := io.WriteString(w, query+";\n\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaQueries returns CREATE TABLE queries for the registered models in the order
+// used by DumpSchema.
+func (m *Migrator) schemaQueries() ([]string, error) {
+	tables := m.db.Dialect().Tables().Registered()
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Name < tables[j].Name
+	})
+
+	fmter := m.db.Formatter()
+	var queries []string
+	for _, table := range sortTablesByFKs(tables) {
+		b, err := m.db.NewCreateTable().
+			Model(table.ZeroIface).
+			WithForeignKeys().
+			AppendQuery(fmter, nil)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, string(b))
+	}
+	return queries, nil
+}
+
+// sortTablesByFKs orders tables so that referenced tables come first
+// while otherwise preserving the input order.
+func sortTablesByFKs(tables []*schema.Table) []*schema.Table {
+	known := make(map[*schema.Table]bool, len(tables))
+	for _, table := range tables {
+		known[table] = true
+	}
+
+	sorted := make([]*schema.Table, 0, len(tables))
+	visited := make(map[*schema.Table]bool, len(tables))
+
+	var visit func(table *schema.Table)
+	visit = func(table *schema.Table) {
+		if visited[table] {
+			return
+		}
+		visited[table] = true
+
+		names := make([]string, 0, len(table.Relations))
+		for name := range table.Relations {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			rel := table.Relations[name]
+			if rel.References() && known[rel.JoinTable] {
+				visit(rel.JoinTable)
+			}
+		}
+		sorted = append(sorted, table)
+	}
+
+	for _, table := range tables {
+		visit(table)
+	}
+	return sorted
+}
@@ -0,0 +1,182 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+const lockRetryInterval = 100 * time.Millisecond
+
+// WithLocking makes Migrate and Rollback hold a distributed lock while they run
+// so that several app replicas starting at the same time don't race to apply
+// the same migrations.
+//
+// PostgreSQL uses a session-level advisory lock, MySQL and MariaDB use GET_LOCK,
+// and other databases fall back to the locks table used by Lock and Unlock.
+func WithLocking(enabled bool) MigratorOption {
+	return func(m *Migrator) {
+		m.locking = enabled
+	}
+}
+
+// WithLockTimeout sets how long Migrate and Rollback wait for a lock held by
+// another migrator. The default is to fail immediately.
+func WithLockTimeout(timeout time.Duration) MigratorOption {
+	return func(m *Migrator) {
+		m.lockTimeout = timeout
+	}
+}
+
+type distLock struct {
+	conn   bun.Conn
+	unlock func(ctx context.Context, conn bun.Conn) error
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func() error) (retErr error) {
+	if !m.locking {
+		return fn()
+	}
+
+	lock, err := m.acquireLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := m.releaseLock(ctx, lock); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+
+	return fn()
+}
+
+func (m *Migrator) acquireLock(ctx context.Context) (*distLock, error) {
+	switch m.db.Dialect().Name() {
+	case dialect.PG:
+		key := m.advisoryLockKey()
+		return m.acquireConnLock(ctx, func(ctx context.Context, conn bun.Conn) (bool, error) {
+			var ok bool
+			err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(?)", key).Scan(&ok)
+			return ok, err
+		}, func(ctx context.Context, conn bun.Conn) error {
+			_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(?)", key)
+			return err
+		})
+	case dialect.MySQL:
+		name := m.namedLockName()
+		return m.acquireConnLock(ctx, func(ctx context.Context, conn bun.Conn) (bool, error) {
+			var res sql.NullInt64
+			err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", name).Scan(&res)
+			return res.Valid && res.Int64 == 1, err
+		}, func(ctx context.Context, conn bun.Conn) error {
+			_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name)
+			return err
+		})
+	default:
+		var lastErr error
+		if err := m.retryLock(ctx, func(ctx context.Context) (bool, error) {
+			lastErr = m.Lock(ctx)
+			return lastErr == nil, nil
+		}); err != nil {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, err
+		}
+		return new(distLock), nil
+	}
+}
+
+// acquireConnLock acquires a session-level lock on a dedicated connection
+// that is kept open until the lock is released.
+func (m *Migrator) acquireConnLock(
+	ctx context.Context,
+	try func(ctx context.Context, conn bun.Conn) (bool, error),
+	unlock func(ctx context.Context, conn bun.Conn) error,
+) (*distLock, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.retryLock(ctx, func(ctx context.Context) (bool, error) {
+		return try(ctx, conn)
+	}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &distLock{
+		conn:   conn,
+		unlock: unlock,
+	}, nil
+}
+
+// releaseLock releases the lock even if ctx is already cancelled,
+// e.g. because the migrations timed out.
+func (m *Migrator) releaseLock(ctx context.Context, lock *distLock) error {
+	ctx = context.WithoutCancel(ctx)
+
+	if lock.unlock == nil {
+		return m.Unlock(ctx)
+	}
+
+	err := lock.unlock(ctx, lock.conn)
+	if err != nil {
+		// The session still holds the lock, so discard the connection
+		// instead of returning it to the pool.
+		_ = lock.conn.Raw(func(interface{}) error {
+			return driver.ErrBadConn
+		})
+	}
+	if closeErr := lock.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (m *Migrator) retryLock(ctx context.Context, try func(ctx context.Context) (bool, error)) error {
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		ok, err := try(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return errors.New("migrate: migrations are locked by another migrator")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// advisoryLockKey derives a stable PostgreSQL advisory lock key from the migrations table name.
+func (m *Migrator) advisoryLockKey() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(m.formattedTableName(m.db)))
+	return int64(h.Sum64())
+}
+
+// namedLockName returns a MySQL lock name; MySQL limits lock names to 64 characters.
+func (m *Migrator) namedLockName() string {
+	name := "bun:" + m.formattedTableName(m.db)
+	if len(name) > 64 {
+		name = fmt.Sprintf("bun:%x", m.advisoryLockKey())
+	}
+	return name
+}
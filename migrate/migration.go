@@ -23,8 +23,14 @@ type Migration struct {
 	GroupID    int64
 	MigratedAt time.Time `bun:",notnull,nullzero,default:current_timestamp"`
 
-	Up   MigrationFunc `bun:"-"`
-	Down MigrationFunc `bun:"-"`
+	// DependsOn are the names of migrations that must be applied before this migration.
+	DependsOn []string `bun:"-" json:"-"`
+
+	Up   MigrationFunc `bun:"-" json:"-"`
+	Down MigrationFunc `bun:"-" json:"-"`
+
+	upSQL   *sqlFile
+	downSQL *sqlFile
 }
 
 func (m Migration) String() string {
@@ -37,20 +43,109 @@ func (m Migration) IsApplied() bool {
 
 type MigrationFunc func(ctx context.Context, db *bun.DB) error
 
+// TxMigrationFunc is a Go migration registered with RegisterTx. The db is the transaction
+// the migration runs in or the *bun.DB when the migration is registered WithNoTx.
+type TxMigrationFunc func(ctx context.Context, db bun.IDB) error
+
+type registerConfig struct {
+	noTx      bool
+	dependsOn []string
+}
+
+func newRegisterConfig(opts []RegisterOption) *registerConfig {
+	cfg := new(registerConfig)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+func (cfg *registerConfig) txFunc(fn TxMigrationFunc) MigrationFunc {
+	if fn == nil {
+		return nil
+	}
+	if cfg.noTx {
+		return func(ctx context.Context, db *bun.DB) error {
+			return fn(ctx, db)
+		}
+	}
+	return func(ctx context.Context, db *bun.DB) error {
+		return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			return fn(ctx, tx)
+		})
+	}
+}
+
+type RegisterOption func(cfg *registerConfig)
+
+// WithNoTx runs a migration registered with RegisterTx outside of a transaction,
+// for example, for CREATE INDEX CONCURRENTLY, which can't run in a transaction.
+func WithNoTx() RegisterOption {
+	return func(cfg *registerConfig) {
+		cfg.noTx = true
+	}
+}
+
+// WithDependsOn makes Migrate apply the migrations with the names, e.g. "20060102150405",
+// before the registered migration, even if they are sorted after it.
+func WithDependsOn(names ...string) RegisterOption {
+	return func(cfg *registerConfig) {
+		cfg.dependsOn = append(cfg.dependsOn, names...)
+	}
+}
+
 func NewSQLMigrationFunc(fsys fs.FS, name string) MigrationFunc {
+	return newSQLFile(fsys, name).migrationFunc()
+}
+
+// sqlFile is an SQL migration file that can be executed or inspected without running it.
+type sqlFile struct {
+	fsys fs.FS
+	name string
+}
+
+func newSQLFile(fsys fs.FS, name string) *sqlFile {
+	return &sqlFile{
+		fsys: fsys,
+		name: name,
+	}
+}
+
+func (f *sqlFile) isTx() bool {
+	return strings.HasSuffix(f.name, ".tx.up.sql") || strings.HasSuffix(f.name, ".tx.down.sql")
+}
+
+func (f *sqlFile) queries() ([]string, error) {
+	r, err := f.fsys.Open(f.name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return readQueries(r)
+}
+
+func (f *sqlFile) migrationFunc() MigrationFunc {
 	return func(ctx context.Context, db *bun.DB) error {
-		f, err := fsys.Open(name)
+		queries, err := f.queries()
 		if err != nil {
 			return err
 		}
-
-		isTx := strings.HasSuffix(name, ".tx.up.sql") || strings.HasSuffix(name, ".tx.down.sql")
-		return Exec(ctx, db, f, isTx)
+		return execQueries(ctx, db, queries, f.isTx())
 	}
 }
 
 // Exec reads and executes the SQL migration in the f.
 func Exec(ctx context.Context, db *bun.DB, f io.Reader, isTx bool) error {
+	queries, err := readQueries(f)
+	if err != nil {
+		return err
+	}
+	return execQueries(ctx, db, queries, isTx)
+}
+
+// readQueries splits the SQL migration in the f into queries using --bun:split directives.
+func readQueries(f io.Reader) ([]string, error) {
 	scanner := bufio.NewScanner(f)
 	var queries []string
 
@@ -66,7 +161,7 @@ func Exec(ctx context.Context, db *bun.DB, f io.Reader, isTx bool) error {
 				query = query[:0]
 				continue
 			}
-			return fmt.Errorf("bun: unknown directive: %q", b)
+			return nil, fmt.Errorf("bun: unknown directive: %q", b)
 		}
 
 		query = append(query, b...)
@@ -77,9 +172,12 @@ func Exec(ctx context.Context, db *bun.DB, f io.Reader, isTx bool) error {
 		queries = append(queries, string(query))
 	}
 	if err := scanner.Err(); err != nil {
-		return err
+		return nil, err
 	}
+	return queries, nil
+}
 
+func execQueries(ctx context.Context, db *bun.DB, queries []string, isTx bool) error {
 	var idb bun.IConn
 
 	if isTx {
@@ -259,6 +357,23 @@ func (g MigrationGroup) String() string {
 	return fmt.Sprintf("group #%d (%s)", g.ID, g.Migrations)
 }
 
+// MigrationStatus describes the state of migrations in the database.
+type MigrationStatus struct {
+	// Applied migrations in descending order.
+	Applied MigrationSlice
+	// Pending migrations in the order Migrate applies them.
+	Pending MigrationSlice
+	// Missing migrations are applied but can no longer be found.
+	Missing MigrationSlice
+
+	LastGroupID int64
+}
+
+// IsUpToDate reports whether there are no pending migrations.
+func (s *MigrationStatus) IsUpToDate() bool {
+	return len(s.Pending) == 0
+}
+
 type MigrationFile struct {
 	Name    string
 	Path    string
@@ -268,7 +383,8 @@ type MigrationFile struct {
 //------------------------------------------------------------------------------
 
 type migrationConfig struct {
-	nop bool
+	nop    bool
+	dryRun bool
 }
 
 func newMigrationConfig(opts []MigrationOption) *migrationConfig {
@@ -287,6 +403,18 @@ func WithNopMigration() MigrationOption {
 	}
 }
 
+// WithDryRun runs SQL migrations and marks them as applied inside a single transaction
+// that is always rolled back. Go migrations can't be contained in the transaction,
+// so a dry run fails if any of the migrations is a Go migration.
+//
+// Databases that implicitly commit DDL statements, i.e. MySQL and Oracle, don't support
+// dry runs, and Migrate and Rollback return an error without running any migrations.
+func WithDryRun() MigrationOption {
+	return func(cfg *migrationConfig) {
+		cfg.dryRun = true
+	}
+}
+
 //------------------------------------------------------------------------------
 
 func sortAsc(ms MigrationSlice) {
@@ -295,6 +423,59 @@ func sortAsc(ms MigrationSlice) {
 	})
 }
 
+// sortByDependencies reorders the migrations so that each migration comes after
+// the migrations it depends on while otherwise preserving the order. Dependencies
+// outside of the migrations must be registered and are assumed to be applied.
+func sortByDependencies(ms MigrationSlice, registered MigrationSlice) (MigrationSlice, error) {
+	index := make(map[string]int, len(ms))
+	for i := range ms {
+		index[ms[i].Name] = i
+	}
+	known := migrationMap(registered)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make([]int, len(ms))
+	sorted := make(MigrationSlice, 0, len(ms))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("migrate: migration %s has a dependency cycle", ms[i].Name)
+		}
+		state[i] = visiting
+
+		for _, name := range ms[i].DependsOn {
+			if j, ok := index[name]; ok {
+				if err := visit(j); err != nil {
+					return err
+				}
+				continue
+			}
+			if _, ok := known[name]; !ok {
+				return fmt.Errorf("migrate: migration %s depends on unknown migration %s",
+					ms[i].Name, name)
+			}
+		}
+
+		state[i] = visited
+		sorted = append(sorted, ms[i])
+		return nil
+	}
+
+	for i := range ms {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
 func sortDesc(ms MigrationSlice) {
 	sort.Slice(ms, func(i, j int) bool {
 		return ms[i].Name > ms[j].Name
@@ -42,24 +42,51 @@ func (m *Migrations) Sorted() MigrationSlice {
 	return migrations
 }
 
-func (m *Migrations) MustRegister(up, down MigrationFunc) {
-	if err := m.Register(up, down); err != nil {
+func (m *Migrations) MustRegister(up, down MigrationFunc, opts ...RegisterOption) {
+	if err := m.register(up, down, opts); err != nil {
 		panic(err)
 	}
 }
 
-func (m *Migrations) Register(up, down MigrationFunc) error {
+// Register registers Go up and down migrations. The migration name is extracted from
+// the name of the file that calls Register. The functions run outside of a transaction,
+// see RegisterTx.
+func (m *Migrations) Register(up, down MigrationFunc, opts ...RegisterOption) error {
+	return m.register(up, down, opts)
+}
+
+// MustRegisterTx is like RegisterTx, but panics on errors.
+func (m *Migrations) MustRegisterTx(up, down TxMigrationFunc, opts ...RegisterOption) {
+	if err := m.registerTx(up, down, opts); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterTx registers Go up and down migrations that run in a transaction,
+// unless the migration opts out with WithNoTx, for example, to create an index concurrently.
+func (m *Migrations) RegisterTx(up, down TxMigrationFunc, opts ...RegisterOption) error {
+	return m.registerTx(up, down, opts)
+}
+
+func (m *Migrations) registerTx(up, down TxMigrationFunc, opts []RegisterOption) error {
+	cfg := newRegisterConfig(opts)
+	return m.register(cfg.txFunc(up), cfg.txFunc(down), opts)
+}
+
+func (m *Migrations) register(up, down MigrationFunc, opts []RegisterOption) error {
 	fpath := migrationFile()
 	name, comment, err := extractMigrationName(fpath)
 	if err != nil {
 		return err
 	}
 
+	cfg := newRegisterConfig(opts)
 	m.Add(Migration{
-		Name:    name,
-		Comment: comment,
-		Up:      up,
-		Down:    down,
+		Name:      name,
+		Comment:   comment,
+		DependsOn: cfg.dependsOn,
+		Up:        up,
+		Down:      down,
 	})
 
 	return nil
@@ -97,14 +124,16 @@ func (m *Migrations) Discover(fsys fs.FS) error {
 
 		migration := m.getOrCreateMigration(name)
 		migration.Comment = comment
-		migrationFunc := NewSQLMigrationFunc(fsys, path)
+		file := newSQLFile(fsys, path)
 
 		if strings.HasSuffix(path, ".up.sql") {
-			migration.Up = migrationFunc
+			migration.Up = file.migrationFunc()
+			migration.upSQL = file
 			return nil
 		}
 		if strings.HasSuffix(path, ".down.sql") {
-			migration.Down = migrationFunc
+			migration.Down = file.migrationFunc()
+			migration.downSQL = file
 			return nil
 		}
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -43,6 +44,12 @@ type Migrator struct {
 	table                string
 	locksTable           string
 	markAppliedOnSuccess bool
+
+	locking     bool
+	lockTimeout time.Duration
+
+	logger   *slog.Logger
+	progress ProgressFunc
 }
 
 func NewMigrator(db *bun.DB, migrations *Migrations, opts ...MigratorOption) *Migrator {
@@ -130,17 +137,34 @@ func (m *Migrator) Reset(ctx context.Context) error {
 
 // Migrate runs unapplied migrations. If a migration fails, migrate immediately exits.
 func (m *Migrator) Migrate(ctx context.Context, opts ...MigrationOption) (*MigrationGroup, error) {
-	cfg := newMigrationConfig(opts)
-
 	if err := m.validate(); err != nil {
 		return nil, err
 	}
 
+	cfg := newMigrationConfig(opts)
+	if cfg.dryRun {
+		if err := m.checkDryRun(); err != nil {
+			return nil, err
+		}
+	}
+
+	var group *MigrationGroup
+	err := m.withLock(ctx, func() (err error) {
+		group, err = m.migrate(ctx, cfg)
+		return err
+	})
+	return group, err
+}
+
+func (m *Migrator) migrate(ctx context.Context, cfg *migrationConfig) (*MigrationGroup, error) {
 	migrations, lastGroupID, err := m.migrationsWithStatus(ctx)
 	if err != nil {
 		return nil, err
 	}
-	migrations = migrations.Unapplied()
+	migrations, err = sortByDependencies(migrations.Unapplied(), m.migrations.ms)
+	if err != nil {
+		return nil, err
+	}
 
 	group := new(MigrationGroup)
 	if len(migrations) == 0 {
@@ -148,6 +172,14 @@ func (m *Migrator) Migrate(ctx context.Context, opts ...MigrationOption) (*Migra
 	}
 	group.ID = lastGroupID + 1
 
+	if cfg.dryRun {
+		for i := range migrations {
+			migrations[i].GroupID = group.ID
+		}
+		group.Migrations = migrations
+		return group, m.dryRun(ctx, migrations, true)
+	}
+
 	for i := range migrations {
 		migration := &migrations[i]
 		migration.GroupID = group.ID
@@ -161,7 +193,7 @@ func (m *Migrator) Migrate(ctx context.Context, opts ...MigrationOption) (*Migra
 		group.Migrations = migrations[:i+1]
 
 		if !cfg.nop && migration.Up != nil {
-			if err := migration.Up(ctx, m.db); err != nil {
+			if err := m.runMigration(ctx, migration, migration.Up, "up"); err != nil {
 				return group, err
 			}
 		}
@@ -177,18 +209,40 @@ func (m *Migrator) Migrate(ctx context.Context, opts ...MigrationOption) (*Migra
 }
 
 func (m *Migrator) Rollback(ctx context.Context, opts ...MigrationOption) (*MigrationGroup, error) {
-	cfg := newMigrationConfig(opts)
-
 	if err := m.validate(); err != nil {
 		return nil, err
 	}
 
+	cfg := newMigrationConfig(opts)
+	if cfg.dryRun {
+		if err := m.checkDryRun(); err != nil {
+			return nil, err
+		}
+	}
+
+	var group *MigrationGroup
+	err := m.withLock(ctx, func() (err error) {
+		group, err = m.rollback(ctx, cfg)
+		return err
+	})
+	return group, err
+}
+
+func (m *Migrator) rollback(ctx context.Context, cfg *migrationConfig) (*MigrationGroup, error) {
 	migrations, err := m.MigrationsWithStatus(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	lastGroup := migrations.LastGroup()
+	lastGroup.Migrations, err = sortByDependencies(lastGroup.Migrations, m.migrations.ms)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.dryRun {
+		return lastGroup, m.dryRun(ctx, lastGroup.Migrations, false)
+	}
 
 	for i := len(lastGroup.Migrations) - 1; i >= 0; i-- {
 		migration := &lastGroup.Migrations[i]
@@ -200,7 +254,7 @@ func (m *Migrator) Rollback(ctx context.Context, opts ...MigrationOption) (*Migr
 		}
 
 		if !cfg.nop && migration.Down != nil {
-			if err := migration.Down(ctx, m.db); err != nil {
+			if err := m.runMigration(ctx, migration, migration.Down, "down"); err != nil {
 				return lastGroup, err
 			}
 		}
@@ -369,6 +423,26 @@ func (m *Migrator) TruncateTable(ctx context.Context) error {
 	return err
 }
 
+// Status returns applied, pending, and missing migrations.
+func (m *Migrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	migrations, lastGroupID, err := m.migrationsWithStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	missing, err := m.MissingMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MigrationStatus{
+		Applied:     migrations.Applied(),
+		Pending:     migrations.Unapplied(),
+		Missing:     missing,
+		LastGroupID: lastGroupID,
+	}, nil
+}
+
 // MissingMigrations returns applied migrations that can no longer be found.
 func (m *Migrator) MissingMigrations(ctx context.Context) (MigrationSlice, error) {
 	applied, err := m.AppliedMigrations(ctx)
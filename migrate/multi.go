@@ -0,0 +1,108 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MultiMigrator runs several migrators, for example, one for the main database and
+// one for an analytics database, as a single unit. Each migrator keeps its own
+// migrations and migrations table.
+type MultiMigrator struct {
+	names     []string
+	migrators map[string]*Migrator
+}
+
+func NewMultiMigrator() *MultiMigrator {
+	return &MultiMigrator{
+		migrators: make(map[string]*Migrator),
+	}
+}
+
+// Add adds the migrator under the name. Migrators run in the order they are added.
+// Migrators that share a database must use different tables, see WithTableName.
+func (mm *MultiMigrator) Add(name string, m *Migrator) error {
+	if name == "" {
+		return errors.New("migrate: target name can't be empty")
+	}
+	if _, ok := mm.migrators[name]; ok {
+		return fmt.Errorf("migrate: target %s already exists", name)
+	}
+	for _, other := range mm.names {
+		o := mm.migrators[other]
+		if o.db == m.db && o.formattedTableName(o.db) == m.formattedTableName(m.db) {
+			return fmt.Errorf("migrate: targets %s and %s use the same migrations table %s",
+				other, name, m.table)
+		}
+	}
+
+	mm.names = append(mm.names, name)
+	mm.migrators[name] = m
+	return nil
+}
+
+// MustAdd is like Add, but panics on errors.
+func (mm *MultiMigrator) MustAdd(name string, m *Migrator) {
+	if err := mm.Add(name, m); err != nil {
+		panic(err)
+	}
+}
+
+// Names returns the names of the targets in the order they run.
+func (mm *MultiMigrator) Names() []string {
+	return append([]string(nil), mm.names...)
+}
+
+// Migrator returns the migrator of the target or nil.
+func (mm *MultiMigrator) Migrator(name string) *Migrator {
+	return mm.migrators[name]
+}
+
+// InitAll creates the migrations tables of all targets.
+func (mm *MultiMigrator) InitAll(ctx context.Context) error {
+	for _, name := range mm.names {
+		if err := mm.migrators[name].Init(ctx); err != nil {
+			return fmt.Errorf("migrate: target %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// MigrateAll runs unapplied migrations of all targets and returns the applied groups
+// by the target name. Targets without migrations are skipped. If a target fails,
+// MigrateAll stops and returns the groups applied so far together with the error;
+// the targets that already ran are not rolled back.
+func (mm *MultiMigrator) MigrateAll(
+	ctx context.Context, opts ...MigrationOption,
+) (map[string]*MigrationGroup, error) {
+	groups := make(map[string]*MigrationGroup, len(mm.names))
+	for _, name := range mm.names {
+		m := mm.migrators[name]
+		if len(m.migrations.ms) == 0 {
+			continue
+		}
+
+		group, err := m.Migrate(ctx, opts...)
+		if group != nil {
+			groups[name] = group
+		}
+		if err != nil {
+			return groups, fmt.Errorf("migrate: target %s: %w", name, err)
+		}
+	}
+	return groups, nil
+}
+
+// StatusAll returns the migration status of all targets by the target name.
+func (mm *MultiMigrator) StatusAll(ctx context.Context) (map[string]*MigrationStatus, error) {
+	statuses := make(map[string]*MigrationStatus, len(mm.names))
+	for _, name := range mm.names {
+		status, err := mm.migrators[name].Status(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: target %s: %w", name, err)
+		}
+		statuses[name] = status
+	}
+	return statuses, nil
+}
@@ -0,0 +1,129 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// PlannedMigration is a pending migration returned by Plan.
+type PlannedMigration struct {
+	Migration Migration
+
+	// Statements are the statements an SQL migration would run.
+	// It is empty for Go migrations.
+	Statements []string
+}
+
+func (p PlannedMigration) IsSQL() bool {
+	return p.Migration.upSQL != nil
+}
+
+// Plan returns pending migrations in the order Migrate would apply them.
+func (m *Migrator) Plan(ctx context.Context) ([]PlannedMigration, error) {
+	migrations, lastGroupID, err := m.migrationsWithStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err = sortByDependencies(migrations.Unapplied(), m.migrations.ms)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make([]PlannedMigration, len(migrations))
+	for i := range migrations {
+		migration := migrations[i]
+		migration.GroupID = lastGroupID + 1
+
+		plan[i].Migration = migration
+		if migration.upSQL != nil {
+			queries, err := migration.upSQL.queries()
+			if err != nil {
+				return nil, err
+			}
+			plan[i].Statements = queries
+		}
+	}
+	return plan, nil
+}
+
+var errDryRun = errors.New("migrate: dry run")
+
+// checkDryRun returns an error if the database implicitly commits DDL statements,
+// because the dry run transaction could not roll them back.
+func (m *Migrator) checkDryRun() error {
+	switch name := m.db.Dialect().Name(); name {
+	case dialect.MySQL, dialect.Oracle:
+		return fmt.Errorf("migrate: %s implicitly commits DDL statements and does not support dry runs", name)
+	}
+	return nil
+}
+
+// dryRun runs SQL migrations in a transaction and rolls it back.
+func (m *Migrator) dryRun(ctx context.Context, migrations MigrationSlice, up bool) error {
+	for i := range migrations {
+		migration := &migrations[i]
+		if migrationSQLFile(migration, up) == nil && migrationFunc(migration, up) != nil {
+			return fmt.Errorf("migrate: dry run does not support Go migration %s", migration.Name)
+		}
+	}
+
+	err := m.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for i := range migrations {
+			migration := &migrations[i]
+
+			if file := migrationSQLFile(migration, up); file != nil {
+				queries, err := file.queries()
+				if err != nil {
+					return err
+				}
+				for _, q := range queries {
+					if _, err := tx.ExecContext(ctx, q); err != nil {
+						return err
+					}
+				}
+			}
+
+			if up {
+				// Insert a copy so the returned group doesn't look applied.
+				applied := *migration
+				if _, err := tx.NewInsert().
+					Model(&applied).
+					ModelTableExpr(m.table).
+					Exec(ctx); err != nil {
+					return err
+				}
+			} else {
+				if _, err := tx.NewDelete().
+					Model(migration).
+					ModelTableExpr(m.table).
+					Where("id = ?", migration.ID).
+					Exec(ctx); err != nil {
+					return err
+				}
+			}
+		}
+		return errDryRun
+	})
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
+
+func migrationSQLFile(migration *Migration, up bool) *sqlFile {
+	if up {
+		return migration.upSQL
+	}
+	return migration.downSQL
+}
+
+func migrationFunc(migration *Migration, up bool) MigrationFunc {
+	if up {
+		return migration.Up
+	}
+	return migration.Down
+}
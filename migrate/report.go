@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// ProgressFunc is called by ReportProgress with the running migration.
+type ProgressFunc func(migration *Migration, done, total int64)
+
+// WithLogger sets the logger used to log applied and rolled back migrations.
+// Running migrations get the logger with Logger.
+func WithLogger(logger *slog.Logger) MigratorOption {
+	return func(m *Migrator) {
+		m.logger = logger
+	}
+}
+
+// WithProgress sets the function that receives progress reported by running
+// migrations with ReportProgress, for example, to render a progress bar.
+func WithProgress(fn ProgressFunc) MigratorOption {
+	return func(m *Migrator) {
+		m.progress = fn
+	}
+}
+
+type reporterKey struct{}
+
+type reporter struct {
+	logger    *slog.Logger
+	migration *Migration
+	progress  ProgressFunc
+}
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// Logger returns the logger of the migrator running the migration with the name
+// of the migration attached. Without WithLogger it discards the records.
+func Logger(ctx context.Context) *slog.Logger {
+	if r, ok := ctx.Value(reporterKey{}).(*reporter); ok && r.logger != nil {
+		return r.logger
+	}
+	return discardLogger
+}
+
+// ReportProgress reports that the running migration has processed done of the total
+// items. The progress is logged at the debug level and passed to the WithProgress function.
+func ReportProgress(ctx context.Context, done, total int64) {
+	r, ok := ctx.Value(reporterKey{}).(*reporter)
+	if !ok {
+		return
+	}
+	if r.logger != nil {
+		r.logger.DebugContext(ctx, "migration progress", "done", done, "total", total)
+	}
+	if r.progress != nil {
+		r.progress(r.migration, done, total)
+	}
+}
+
+// runMigration runs the up or down function of the migration with a reporter
+// in the context and logs the result.
+func (m *Migrator) runMigration(
+	ctx context.Context, migration *Migration, fn MigrationFunc, direction string,
+) error {
+	r := &reporter{
+		migration: migration,
+		progress:  m.progress,
+	}
+	if m.logger != nil {
+		r.logger = m.logger.With("migration", migration.Name, "direction", direction)
+	}
+	ctx = context.WithValue(ctx, reporterKey{}, r)
+
+	start := time.Now()
+	err := fn(ctx, m.db)
+	if r.logger != nil {
+		if err != nil {
+			r.logger.ErrorContext(ctx, "migration failed",
+				"duration", time.Since(start), "error", err)
+		} else {
+			r.logger.InfoContext(ctx, "migration finished",
+				"comment", migration.Comment, "group_id", migration.GroupID,
+				"duration", time.Since(start))
+		}
+	}
+	return err
+}
@@ -0,0 +1,144 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type squashConfig struct {
+	name   string
+	schema io.Reader
+}
+
+type SquashOption func(cfg *squashConfig)
+
+// WithSquashName sets the name of the baseline migration. The default is "baseline".
+func WithSquashName(name string) SquashOption {
+	return func(cfg *squashConfig) {
+		cfg.name = name
+	}
+}
+
+// WithSquashSchema uses the schema dump in the r, for example, the output of pg_dump,
+// as the baseline instead of the CREATE TABLE queries generated from the registered models.
+// The dump is copied as is, so use --bun:split directives to split it into queries
+// when the driver can't execute several queries at once.
+func WithSquashSchema(r io.Reader) SquashOption {
+	return func(cfg *squashConfig) {
+		cfg.schema = r
+	}
+}
+
+// Squash collapses the applied migrations into a single baseline migration.
+// It creates an up SQL migration file with the current schema, generated from the
+// models registered with bun.DB.RegisterModel (see DumpSchema) or read from a dump
+// (see WithSquashSchema), and replaces the applied migrations in the migrations table
+// with the baseline, so the baseline is applied in the current database and
+// only runs on new databases.
+//
+// All migrations must be applied before squashing. After squashing, remove the files
+// of the squashed migrations; otherwise they are reported as pending.
+// The baseline has no down migration, so rolling it back only marks it as unapplied.
+func (m *Migrator) Squash(ctx context.Context, opts ...SquashOption) (*MigrationFile, error) {
+	cfg := &squashConfig{
+		name: "baseline",
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	name, err := m.genMigrationName(cfg.name)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := m.squashContent(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var mf *MigrationFile
+	err = m.withLock(ctx, func() error {
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if len(status.Applied) == 0 {
+			return errors.New("migrate: there are no applied migrations to squash")
+		}
+		if !status.IsUpToDate() {
+			return fmt.Errorf("migrate: can't squash with pending migrations: %s", status.Pending)
+		}
+
+		fname := name + ".up.sql"
+		fpath := filepath.Join(m.migrations.getDirectory(), fname)
+		if err := os.WriteFile(fpath, []byte(content), 0o644); err != nil {
+			return err
+		}
+		mf = &MigrationFile{
+			Name:    fname,
+			Path:    fpath,
+			Content: content,
+		}
+
+		version, comment, err := extractMigrationName(fname)
+		if err != nil {
+			return err
+		}
+		baseline := &Migration{
+			Name:       version,
+			Comment:    comment,
+			GroupID:    1,
+			MigratedAt: time.Now(),
+		}
+
+		return m.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if _, err := tx.NewDelete().
+				Model((*Migration)(nil)).
+				ModelTableExpr(m.table).
+				Where("1 = 1").
+				Exec(ctx); err != nil {
+				return err
+			}
+			_, err := tx.NewInsert().
+				Model(baseline).
+				ModelTableExpr(m.table).
+				Exec(ctx)
+			return err
+		})
+	})
+	if err != nil {
+		if mf != nil {
+			_ = os.Remove(mf.Path)
+		}
+		return nil, err
+	}
+	return mf, nil
+}
+
+func (m *Migrator) squashContent(cfg *squashConfig) (string, error) {
+	if cfg.schema != nil {
+		b, err := io.ReadAll(cfg.schema)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	queries, err := m.schemaQueries()
+	if err != nil {
+		return "", err
+	}
+	if len(queries) == 0 {
+		return "", errors.New("migrate: there are no registered models to squash into a baseline")
+	}
+	return strings.Join(queries, "\n\n--bun:split\n\n") + "\n", nil
+}
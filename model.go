@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/uptrace/bun/internal"
 	"github.com/uptrace/bun/schema"
 )
 
@@ -44,7 +45,9 @@ type TableModel interface {
 	parentIndex() []int
 	mount(reflect.Value)
 
+	validate(context.Context) error
 	updateSoftDeleteField(time.Time) error
+	setScanFlags(internal.Flag)
 }
 
 func newModel(db *DB, dest []interface{}) (Model, error) {
@@ -114,6 +117,9 @@ func _newModel(db *DB, dest interface{}, scan bool) (Model, error) {
 
 	switch v.Kind() {
 	case reflect.Map:
+		if scan && isKVMap(typ) {
+			return newKVMapModel(v), nil
+		}
 		if err := validMap(typ); err != nil {
 			return nil, err
 		}
@@ -199,6 +205,7 @@ func isSingleRowModel(m Model) bool {
 	switch m.(type) {
 	case *mapModel,
 		*structTableModel,
+		*entityCacheModel,
 		*scanModel:
 		return true
 	default:
@@ -0,0 +1,122 @@
+package bun
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/uptrace/bun/schema"
+)
+
+// ModelHookType is the type of a hook registered with DB.RegisterModelHook.
+type ModelHookType int
+
+const (
+	BeforeSelect ModelHookType = iota
+	AfterSelect
+	BeforeInsert
+	AfterInsert
+	BeforeUpdate
+	AfterUpdate
+	BeforeDelete
+	AfterDelete
+	BeforeCreateTable
+	AfterCreateTable
+	BeforeDropTable
+	AfterDropTable
+)
+
+func (t ModelHookType) String() string {
+	switch t {
+	case BeforeSelect:
+		return "BeforeSelect"
+	case AfterSelect:
+		return "AfterSelect"
+	case BeforeInsert:
+		return "BeforeInsert"
+	case AfterInsert:
+		return "AfterInsert"
+	case BeforeUpdate:
+		return "BeforeUpdate"
+	case AfterUpdate:
+		return "AfterUpdate"
+	case BeforeDelete:
+		return "BeforeDelete"
+	case AfterDelete:
+		return "AfterDelete"
+	case BeforeCreateTable:
+		return "BeforeCreateTable"
+	case AfterCreateTable:
+		return "AfterCreateTable"
+	case BeforeDropTable:
+		return "BeforeDropTable"
+	case AfterDropTable:
+		return "AfterDropTable"
+	default:
+		return fmt.Sprintf("ModelHookType(%d)", int(t))
+	}
+}
+
+// ModelHookFunc is a model hook registered with DB.RegisterModelHook.
+// The query is one of *SelectQuery, *InsertQuery, *UpdateQuery, *DeleteQuery,
+// *CreateTableQuery, or *DropTableQuery depending on the hook type.
+type ModelHookFunc func(ctx context.Context, query Query) error
+
+type modelHookKey struct {
+	typ  reflect.Type
+	hook ModelHookType
+}
+
+type modelHooks struct {
+	mu    sync.RWMutex
+	hooks map[modelHookKey][]ModelHookFunc
+}
+
+// RegisterModelHook registers a hook for the model without implementing hook interfaces,
+// for example, when the model is declared in a package that does not import Bun:
+//
+//	db.RegisterModelHook((*User)(nil), bun.AfterInsert, func(ctx context.Context, query bun.Query) error {
+//		user := query.GetModel().Value().(*User)
+//		return nil
+//	})
+//
+// Hooks registered with RegisterModelHook run after the hook interfaces implemented by the model
+// in the order of registration. They are shared by the DB and its clones.
+func (db *DB) RegisterModelHook(model interface{}, hook ModelHookType, fn ModelHookFunc) {
+	typ := reflect.TypeOf(model)
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Errorf("bun: RegisterModelHook(unsupported %T)", model))
+	}
+
+	h := db.modelHooks
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.hooks == nil {
+		h.hooks = make(map[modelHookKey][]ModelHookFunc)
+	}
+	key := modelHookKey{typ: typ, hook: hook}
+	fns := h.hooks[key]
+	// Copy the slice so runModelHooks can iterate without holding the lock.
+	h.hooks[key] = append(fns[:len(fns):len(fns)], fn)
+}
+
+func (db *DB) runModelHooks(
+	ctx context.Context, hook ModelHookType, table *schema.Table, query Query,
+) error {
+	h := db.modelHooks
+	h.mu.RLock()
+	fns := h.hooks[modelHookKey{typ: table.Type, hook: hook}]
+	h.mu.RUnlock()
+
+	for _, fn := range fns {
+		if err := fn(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
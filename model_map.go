@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"reflect"
 	"sort"
+	"strings"
 
+	"github.com/uptrace/bun/internal"
 	"github.com/uptrace/bun/schema"
 )
 
@@ -77,7 +80,12 @@ func (m *mapModel) Scan(src interface{}) error {
 		return err
 	}
 
-	scanType := columnTypes[m.scanIndex].ScanType()
+	columnType := columnTypes[m.scanIndex]
+	if v, ok := decodeMapValue(columnType.DatabaseTypeName(), src.([]byte)); ok {
+		return m.scanRaw(v)
+	}
+
+	scanType := columnType.ScanType()
 	switch scanType.Kind() {
 	case reflect.Interface:
 		return m.scanRaw(src)
@@ -94,9 +102,35 @@ func (m *mapModel) Scan(src interface{}) error {
 		return err
 	}
 
+	// Unwrap sql.NullInt64 and friends so the map holds plain values or nil.
+	if valuer, ok := dest.Interface().(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		return m.scanRaw(v)
+	}
+
 	return m.scanRaw(dest.Interface())
 }
 
+// decodeMapValue decodes numeric and time values that drivers using a text protocol
+// return as raw bytes. Other columns, e.g. text columns on MySQL, keep the driver value.
+func decodeMapValue(typeName string, b []byte) (interface{}, bool) {
+	switch strings.ToUpper(typeName) {
+	case "NUMERIC", "DECIMAL":
+		// Keep the exact value; float64 would lose precision.
+		return string(b), true
+	case "DATE", "DATETIME", "DATETIME2", "TIMESTAMP", "TIMESTAMPTZ":
+		tm, err := internal.ParseTime(string(b))
+		if err != nil {
+			return nil, false
+		}
+		return tm, true
+	}
+	return nil, false
+}
+
 func (m *mapModel) columnTypes() ([]*sql.ColumnType, error) {
 	if m._columnTypes == nil {
 		columnTypes, err := m.rows.ColumnTypes()
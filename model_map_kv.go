@@ -0,0 +1,121 @@
+package bun
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/uptrace/bun/schema"
+)
+
+// kvMapModel scans queries that select two columns into a map[K]V, using the first column
+// as the key and the second as the value, or into a map[K][]V, appending the values
+// of rows with the same key.
+type kvMapModel struct {
+	dest reflect.Value // map
+	m    reflect.Value
+
+	multi     bool
+	keyType   reflect.Type
+	valueType reflect.Type
+	scanKey   schema.ScannerFunc
+	scanValue schema.ScannerFunc
+
+	key       reflect.Value
+	value     reflect.Value
+	scanIndex int
+}
+
+var _ Model = (*kvMapModel)(nil)
+
+func newKVMapModel(v reflect.Value) *kvMapModel {
+	typ := v.Type()
+	m := &kvMapModel{
+		dest:      v,
+		keyType:   typ.Key(),
+		valueType: typ.Elem(),
+	}
+	if typ.Elem().Kind() == reflect.Slice && typ.Elem() != bytesType {
+		m.multi = true
+		m.valueType = typ.Elem().Elem()
+	}
+	m.scanKey = schema.Scanner(m.keyType)
+	m.scanValue = schema.Scanner(m.valueType)
+	return m
+}
+
+// isKVMap reports whether the map type can be scanned by kvMapModel.
+func isKVMap(typ reflect.Type) bool {
+	if typ.Key().Kind() == reflect.String && typ.Elem().Kind() == reflect.Interface {
+		// map[string]interface{} is scanned by mapModel.
+		return false
+	}
+	return typ.Key().Comparable()
+}
+
+func (m *kvMapModel) Value() interface{} {
+	return m.dest.Addr().Interface()
+}
+
+func (m *kvMapModel) ScanRows(ctx context.Context, rows *sql.Rows) (int, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if len(columns) != 2 {
+		return 0, fmt.Errorf("bun: Scan(%s) requires a query with 2 columns, got %d",
+			m.dest.Type(), len(columns))
+	}
+
+	m.m = m.dest
+	if m.m.IsNil() {
+		m.m = reflect.MakeMap(m.dest.Type())
+	} else {
+		m.m.Clear()
+	}
+
+	dest := makeDest(m, len(columns))
+
+	var n int
+
+	for rows.Next() {
+		m.key = reflect.New(m.keyType).Elem()
+		m.value = reflect.New(m.valueType).Elem()
+
+		m.scanIndex = 0
+		if err := rows.Scan(dest...); err != nil {
+			return 0, err
+		}
+
+		if m.multi {
+			values := m.m.MapIndex(m.key)
+			if !values.IsValid() {
+				values = reflect.MakeSlice(m.dest.Type().Elem(), 0, 1)
+			}
+			m.m.SetMapIndex(m.key, reflect.Append(values, m.value))
+		} else {
+			m.m.SetMapIndex(m.key, m.value)
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	m.dest.Set(m.m)
+	return n, nil
+}
+
+func (m *kvMapModel) Scan(src interface{}) error {
+	i := m.scanIndex
+	m.scanIndex++
+
+	if i == 0 {
+		if src == nil {
+			return fmt.Errorf("bun: Scan(%s) got a NULL key", m.dest.Type())
+		}
+		return m.scanKey(m.key, src)
+	}
+	return m.scanValue(m.value, src)
+}
@@ -160,3 +160,36 @@ func (m *mapSliceModel) initKeys() error {
 
 	return nil
 }
+
+func (m *mapSliceModel) appendSelectUnion(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if err := m.initKeys(); err != nil {
+		return nil, err
+	}
+	slice := *m.dest
+	if fmter.IsNop() && len(slice) > 1 {
+		slice = slice[:1]
+	}
+
+	for i, el := range slice {
+		if i > 0 {
+			b = append(b, " UNION ALL "...)
+		}
+		b = append(b, "SELECT "...)
+		for j, key := range m.keys {
+			if j > 0 {
+				b = append(b, ", "...)
+			}
+			if fmter.IsNop() {
+				b = append(b, '?')
+			} else {
+				b = schema.Append(fmter, b, el[key])
+			}
+			if i == 0 {
+				b = append(b, " AS "...)
+				b = fmter.AppendIdent(b, key)
+			}
+		}
+	}
+
+	return b, nil
+}
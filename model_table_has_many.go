@@ -64,6 +64,9 @@ func (m *hasManyModel) ScanRows(ctx context.Context, rows *sql.Rows) (int, error
 		if err := rows.Scan(dest...); err != nil {
 			return 0, err
 		}
+		if err := m.scanDeferred(); err != nil {
+			return 0, err
+		}
 
 		if err := m.parkStruct(); err != nil {
 			return 0, err
@@ -87,7 +90,9 @@ func (m *hasManyModel) Scan(src interface{}) error {
 		return fmt.Errorf("bun: %s does not have column %q", m.table.TypeName, column)
 	}
 
-	if err := field.ScanValue(m.strct, src); err != nil {
+	if src != nil && field.HasDiscriminatorColumn() {
+		m.deferScan(field, src)
+	} else if err := field.ScanValue(m.strct, src); err != nil {
 		return err
 	}
 
@@ -65,6 +65,9 @@ func (m *m2mModel) ScanRows(ctx context.Context, rows *sql.Rows) (int, error) {
 		if err := rows.Scan(dest...); err != nil {
 			return 0, err
 		}
+		if err := m.scanDeferred(); err != nil {
+			return 0, err
+		}
 
 		if err := m.parkStruct(); err != nil {
 			return 0, err
@@ -89,6 +92,10 @@ func (m *m2mModel) Scan(src interface{}) error {
 	}
 
 	if field, ok := m.table.FieldMap[column]; ok {
+		if src != nil && field.HasDiscriminatorColumn() {
+			m.deferScan(field, src)
+			return nil
+		}
 		return field.ScanValue(m.strct, src)
 	}
 
@@ -1,12 +1,14 @@
 package bun
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/uptrace/bun/internal"
 	"github.com/uptrace/bun/schema"
@@ -28,7 +30,18 @@ type structTableModel struct {
 	structInitErr error
 
 	columns   []string
+	fields    []*schema.Field // fields of the columns or nil
 	scanIndex int
+	scanFlags internal.Flag
+
+	// deferred are the values of fields with a discriminator column,
+	// which are scanned after the row, see scanDeferred.
+	deferred []deferredScan
+}
+
+type deferredScan struct {
+	field *schema.Field
+	src   interface{}
 }
 
 var _ TableModel = (*structTableModel)(nil)
@@ -260,7 +273,7 @@ func (m *structTableModel) ScanRow(ctx context.Context, rows *sql.Rows) error {
 		return err
 	}
 
-	m.columns = columns
+	m.setColumns(columns)
 	dest := makeDest(m, len(columns))
 
 	return m.scanRow(ctx, rows, dest)
@@ -275,6 +288,9 @@ func (m *structTableModel) scanRow(ctx context.Context, rows *sql.Rows, dest []i
 	if err := rows.Scan(dest...); err != nil {
 		return err
 	}
+	if err := m.scanDeferred(); err != nil {
+		return err
+	}
 
 	if err := m.AfterScanRow(ctx); err != nil {
 		return err
@@ -283,18 +299,85 @@ func (m *structTableModel) scanRow(ctx context.Context, rows *sql.Rows, dest []i
 	return nil
 }
 
+// setColumns sets the columns of the rows and looks up their fields once
+// instead of for every scanned row.
+func (m *structTableModel) setColumns(columns []string) {
+	m.columns = m.untruncateColumns(columns)
+	m.fields = make([]*schema.Field, len(columns))
+	for i, column := range columns {
+		m.fields[i] = m.table.LookupField(unquote(column))
+	}
+}
+
+// untruncateColumns replaces the join column aliases that were truncated to fit
+// in the dialect identifier length limit with the full aliases, e.g. author__name.
+func (m *structTableModel) untruncateColumns(columns []string) []string {
+	maxLen := schema.MaxIdentLen(m.db.Dialect())
+	if maxLen == 0 || len(m.joins) == 0 {
+		return columns
+	}
+
+	var truncated map[string]string
+	for i, column := range columns {
+		if len(column) < maxLen-utf8.UTFMax {
+			continue
+		}
+		if truncated == nil {
+			truncated = make(map[string]string)
+			collectTruncatedColumns(truncated, m.joins, maxLen)
+		}
+		if full, ok := truncated[unquote(column)]; ok {
+			columns[i] = full
+		}
+	}
+	return columns
+}
+
+func collectTruncatedColumns(truncated map[string]string, joins []relationJoin, maxLen int) {
+	for i := range joins {
+		j := &joins[i]
+		alias := string(appendAlias(nil, j)) + "__"
+		for _, field := range j.JoinModel.Table().Fields {
+			if column := alias + field.Name; len(column) > maxLen {
+				truncated[schema.TruncateIdent(column, maxLen)] = column
+			}
+		}
+		collectTruncatedColumns(truncated, j.JoinModel.getJoins(), maxLen)
+	}
+}
+
 func (m *structTableModel) Scan(src interface{}) error {
-	column := m.columns[m.scanIndex]
+	i := m.scanIndex
 	m.scanIndex++
 
-	return m.ScanColumn(unquote(column), src)
+	if i < len(m.fields) {
+		if field := m.fields[i]; field != nil {
+			if src != nil {
+				if err := m.initStruct(); err != nil {
+					return err
+				}
+			}
+			return m.scanField(field, m.columns[i], src)
+		}
+	}
+	return m.ScanColumn(unquote(m.columns[i]), src)
 }
 
 func (m *structTableModel) ScanColumn(column string, src interface{}) error {
 	if ok, err := m.scanColumn(column, src); ok {
 		return err
 	}
-	if column == "" || column[0] == '_' || m.db.flags.Has(discardUnknownColumns) {
+	if column == "" || column[0] == '_' {
+		return nil
+	}
+	if m.table.ExtrasField != nil {
+		return m.scanExtra(column, src)
+	}
+	if m.hasScanFlag(strictScan) {
+		return fmt.Errorf("bun: strict scan: %s does not have a field for column %q",
+			m.table.TypeName, column)
+	}
+	if m.db.flags.Has(discardUnknownColumns) {
 		return nil
 	}
 	return fmt.Errorf("bun: %s does not have column %q", m.table.TypeName, column)
@@ -308,14 +391,12 @@ func (m *structTableModel) scanColumn(column string, src interface{}) (bool, err
 	}
 
 	if field := m.table.LookupField(column); field != nil {
-		if src == nil && m.isNil() {
-			return true, nil
-		}
-		return true, field.ScanValue(m.strct, src)
+		return true, m.scanField(field, column, src)
 	}
 
 	if joinName, column := splitColumn(column); joinName != "" {
 		if join := m.getJoin(joinName); join != nil {
+			join.JoinModel.setScanFlags(m.scanFlags)
 			return true, join.JoinModel.ScanColumn(column, src)
 		}
 
@@ -327,6 +408,96 @@ func (m *structTableModel) scanColumn(column string, src interface{}) (bool, err
 	return false, nil
 }
 
+func (m *structTableModel) scanField(field *schema.Field, column string, src interface{}) error {
+	if src == nil && m.isNil() {
+		return nil
+	}
+	if src == nil && field.NotNull && m.hasScanFlag(strictNullScan) {
+		return fmt.Errorf("bun: strict scan: %s.%s is NOT NULL, but column %q is NULL",
+			m.table.TypeName, field.GoName, column)
+	}
+	if src != nil && field.HasDiscriminatorColumn() {
+		m.deferScan(field, src)
+		return nil
+	}
+	if field.Encrypted {
+		return field.ScanEncryptedValue(m.strct, src, m.db.fmter.Encryptor())
+	}
+	if p := m.db.fmter.JSONProvider(); p != nil && field.JSON {
+		return field.ScanJSONValue(m.strct, src, p)
+	}
+	if err := field.ScanValue(m.strct, src); err != nil {
+		return err
+	}
+	if cfg := m.db.fmter.TimeConfig(); cfg != nil && field.IndirectType == timeType {
+		convertTime(cfg, field.Value(m.strct))
+	}
+	return nil
+}
+
+// deferScan defers scanning the value of a field with a discriminator column,
+// because the discriminator column can come after the field in the row.
+func (m *structTableModel) deferScan(field *schema.Field, src interface{}) {
+	if b, ok := src.([]byte); ok {
+		// Reference types such as []byte are only valid until the next call to Scan.
+		src = bytes.Clone(b)
+	}
+	m.deferred = append(m.deferred, deferredScan{field: field, src: src})
+}
+
+// scanDeferred scans the deferred values of the row and of the joined rows.
+func (m *structTableModel) scanDeferred() error {
+	for i, d := range m.deferred {
+		m.deferred[i] = deferredScan{}
+		if err := d.field.ScanValue(m.strct, d.src); err != nil {
+			m.deferred = m.deferred[:0]
+			return err
+		}
+	}
+	m.deferred = m.deferred[:0]
+
+	for i := range m.joins {
+		if jm, ok := m.joins[i].JoinModel.(*structTableModel); ok {
+			if err := jm.scanDeferred(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanExtra stores an unmapped column in the map field tagged with the extras option.
+func (m *structTableModel) scanExtra(column string, src interface{}) error {
+	if err := m.initStruct(); err != nil {
+		return err
+	}
+
+	fv := m.table.ExtrasField.Value(m.strct)
+	if fv.IsNil() {
+		fv.Set(reflect.MakeMap(fv.Type()))
+	}
+
+	value := reflect.Zero(fv.Type().Elem())
+	switch src := src.(type) {
+	case nil:
+	case []byte:
+		// Reference types such as []byte are only valid until the next call to Scan.
+		value = reflect.ValueOf(bytes.Clone(src))
+	default:
+		value = reflect.ValueOf(src)
+	}
+	fv.SetMapIndex(reflect.ValueOf(column), value)
+	return nil
+}
+
+func (m *structTableModel) setScanFlags(flags internal.Flag) {
+	m.scanFlags = flags
+}
+
+func (m *structTableModel) hasScanFlag(flag internal.Flag) bool {
+	return m.scanFlags.Has(flag) || m.db.flags.Has(flag)
+}
+
 func (m *structTableModel) isNil() bool {
 	return m.strct.Kind() == reflect.Ptr && m.strct.IsNil()
 }
@@ -334,6 +505,9 @@ func (m *structTableModel) isNil() bool {
 func (m *structTableModel) AppendNamedArg(
 	fmter schema.Formatter, b []byte, name string,
 ) ([]byte, bool) {
+	if !m.strct.IsValid() {
+		return b, false
+	}
 	return m.table.AppendNamedArg(fmter, b, name, m.strct)
 }
 
@@ -354,3 +528,15 @@ func splitColumn(s string) (string, string) {
 	}
 	return "", s
 }
+
+// convertTime applies the time config to a scanned time.Time or *time.Time field.
+func convertTime(cfg *schema.TimeConfig, fv reflect.Value) {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return
+		}
+		fv = fv.Elem()
+	}
+	tm := fv.Addr().Interface().(*time.Time)
+	*tm = cfg.Convert(*tm)
+}
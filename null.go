@@ -0,0 +1,99 @@
+package bun
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// Null represents a value of any type that may be NULL. Unlike sql.Null,
+// it works with all types supported by bun, including JSON values and types
+// registered with schema.RegisterCodec, and marshals invalid values as JSON null.
+//
+//	type User struct {
+//		ID   int64
+//		Name bun.Null[string]
+//	}
+type Null[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NullOf returns a valid Null with the value.
+func NullOf[T any](v T) Null[T] {
+	return Null[T]{V: v, Valid: true}
+}
+
+var (
+	_ schema.QueryAppender = Null[int]{}
+	_ schema.NullWrapper   = Null[int]{}
+	_ sql.Scanner          = (*Null[int])(nil)
+	_ json.Marshaler       = Null[int]{}
+	_ json.Unmarshaler     = (*Null[int])(nil)
+)
+
+// IsZero reports whether the value is NULL.
+func (n Null[T]) IsZero() bool {
+	return !n.Valid
+}
+
+// Ptr returns a pointer to the value or nil if the value is NULL.
+func (n Null[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	return &n.V
+}
+
+func (n Null[T]) NullValueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (n Null[T]) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if !n.Valid {
+		return dialect.AppendNull(b), nil
+	}
+	return fmter.AppendValue(b, reflect.ValueOf(&n.V).Elem()), nil
+}
+
+func (n *Null[T]) Scan(src interface{}) error {
+	if src == nil {
+		*n = Null[T]{}
+		return nil
+	}
+
+	v := reflect.ValueOf(&n.V).Elem()
+	scanner := schema.Scanner(v.Type())
+	if scanner == nil {
+		return fmt.Errorf("bun: Null[%s] is not supported", v.Type())
+	}
+	if err := scanner(v, src); err != nil {
+		return err
+	}
+
+	n.Valid = true
+	return nil
+}
+
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.V)
+}
+
+func (n *Null[T]) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = Null[T]{}
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.V); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
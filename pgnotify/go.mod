@@ -0,0 +1,23 @@
+module github.com/uptrace/bun/pgnotify
+
+go 1.22
+
+replace github.com/uptrace/bun => ../
+
+replace github.com/uptrace/bun/driver/pgdriver => ../driver/pgdriver
+
+require (
+	github.com/uptrace/bun v1.2.5
+	github.com/uptrace/bun/driver/pgdriver v1.2.5
+)
+
+require (
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/puzpuzpuz/xsync/v3 v3.4.0 // indirect
+	github.com/tmthrgd/go-hex v0.0.0-20190904060850-447a3041c3bc // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/crypto v0.28.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+	mellium.im/sasl v0.3.2 // indirect
+)
@@ -0,0 +1,190 @@
+package pgnotify
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+var errClosed = errors.New("bun: notifier is closed")
+
+// Notification is a notification received on a channel.
+type Notification = pgdriver.Notification
+
+// Notify sends the notification on the channel. The db can be a transaction,
+// in which case the notification is delivered when the transaction is committed
+// and is discarded if the transaction is rolled back.
+func Notify(ctx context.Context, db bun.IDB, channel, payload string) error {
+	_, err := db.ExecContext(ctx, "SELECT pg_notify(?, ?)", channel, payload)
+	return err
+}
+
+// Notifier receives notifications for any number of Listen calls using a single
+// connection. The connection is checked with pings and is reestablished when it breaks,
+// after which the channels are listened again. Notifications sent while the connection
+// is broken are lost.
+//
+// Notifier requires a DB that uses pgdriver.
+type Notifier struct {
+	ln      *pgdriver.Listener
+	bufSize int
+
+	// listenMu serializes LISTEN and UNLISTEN with the changes of subs.
+	listenMu sync.Mutex
+
+	mu     sync.Mutex
+	subs   map[string][]*subscription
+	closed bool
+	exit   chan struct{}
+}
+
+type subscription struct {
+	ch chan Notification
+}
+
+// New creates a notifier for the DB. Close it to release the connection.
+func New(db *bun.DB, opts ...Option) *Notifier {
+	n := &Notifier{
+		ln:      pgdriver.NewListener(db),
+		bufSize: 100,
+		subs:    make(map[string][]*subscription),
+		exit:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	go n.dispatch(n.ln.Channel())
+	return n
+}
+
+type Option func(n *Notifier)
+
+// WithBufferSize configures the number of notifications buffered by the channels
+// returned by Listen. Default is 100.
+func WithBufferSize(size int) Option {
+	return func(n *Notifier) {
+		n.bufSize = size
+	}
+}
+
+// Listen starts listening on the channel and returns a channel for receiving
+// notifications, for example:
+//
+//	ch, err := notifier.Listen(ctx, "jobs")
+//	for n := range ch {
+//		fmt.Println(n.Payload)
+//	}
+//
+// The returned channel is closed when the context is done or the notifier is closed.
+// Notifications that do not fit in the channel buffer are dropped, see WithBufferSize.
+func (n *Notifier) Listen(ctx context.Context, channel string) (<-chan Notification, error) {
+	sub := &subscription{ch: make(chan Notification, n.bufSize)}
+
+	n.listenMu.Lock()
+	defer n.listenMu.Unlock()
+
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return nil, errClosed
+	}
+	first := len(n.subs[channel]) == 0
+	n.subs[channel] = append(n.subs[channel], sub)
+	n.mu.Unlock()
+
+	if first {
+		if err := n.ln.Listen(ctx, channel); err != nil {
+			n.removeSub(channel, sub)
+			return nil, err
+		}
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			n.unsubscribe(context.WithoutCancel(ctx), channel, sub)
+		case <-n.exit:
+		}
+	}()
+
+	return sub.ch, nil
+}
+
+func (n *Notifier) unsubscribe(ctx context.Context, channel string, sub *subscription) {
+	n.listenMu.Lock()
+	defer n.listenMu.Unlock()
+
+	if last, ok := n.removeSub(channel, sub); ok && last {
+		_ = n.ln.Unlisten(ctx, channel)
+	}
+}
+
+// removeSub removes the subscription and closes its channel. It reports whether
+// the subscription was the last one on the channel.
+func (n *Notifier) removeSub(channel string, sub *subscription) (last, ok bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	subs := n.subs[channel]
+	i := indexOf(subs, sub)
+	if i == -1 {
+		// The notifier is closed.
+		return false, false
+	}
+
+	subs = append(subs[:i:i], subs[i+1:]...)
+	if len(subs) == 0 {
+		delete(n.subs, channel)
+	} else {
+		n.subs[channel] = subs
+	}
+	close(sub.ch)
+	return len(subs) == 0, true
+}
+
+// Close closes the notifier and the channels returned by Listen.
+func (n *Notifier) Close() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return errClosed
+	}
+	n.closed = true
+	close(n.exit)
+	for channel, subs := range n.subs {
+		for _, sub := range subs {
+			close(sub.ch)
+		}
+		delete(n.subs, channel)
+	}
+	n.mu.Unlock()
+
+	return n.ln.Close()
+}
+
+func (n *Notifier) dispatch(ch <-chan Notification) {
+	for notif := range ch {
+		n.mu.Lock()
+		for _, sub := range n.subs[notif.Channel] {
+			select {
+			case sub.ch <- notif:
+			default:
+				pgdriver.Logger.Printf(context.TODO(),
+					"pgnotify: %s buffer is full (notification is dropped)", notif.Channel)
+			}
+		}
+		n.mu.Unlock()
+	}
+}
+
+func indexOf(subs []*subscription, sub *subscription) int {
+	for i, s := range subs {
+		if s == sub {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,425 @@
+package bun
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/feature"
+	"github.com/uptrace/bun/schema"
+)
+
+// Predicate is a condition that can be combined with other conditions and passed to Where,
+// for example:
+//
+//	q.Where("?", bun.And(
+//		bun.Eq("status", "active"),
+//		bun.Or(bun.Gt("age", 18), bun.IsNull("age")),
+//	))
+//
+// Columns are quoted as identifiers and values are appended as args, so predicates can be
+// safely built from API query parameters. Nil predicates are ignored, so optional filters
+// can be left unset. SafeQuery can be used as a predicate to add raw SQL.
+type Predicate interface {
+	schema.QueryAppender
+}
+
+// And returns a predicate that is true if all predicates are true, or if there are none.
+func And(preds ...Predicate) Predicate {
+	return &compoundPredicate{sep: " AND ", empty: "1 = 1", preds: preds}
+}
+
+// Or returns a predicate that is true if any of the predicates is true.
+// It is false if there are no predicates.
+func Or(preds ...Predicate) Predicate {
+	return &compoundPredicate{sep: " OR ", empty: "1 = 0", preds: preds}
+}
+
+// Not returns a predicate that negates the predicate.
+func Not(pred Predicate) Predicate {
+	return &notPredicate{pred: pred}
+}
+
+// Eq returns a predicate for column = value, or column IS NULL if the value is nil.
+func Eq(column string, value interface{}) Predicate {
+	if value == nil {
+		return IsNull(column)
+	}
+	return &cmpPredicate{column: column, op: "=", value: value}
+}
+
+// Ne returns a predicate for column != value, or column IS NOT NULL if the value is nil.
+func Ne(column string, value interface{}) Predicate {
+	if value == nil {
+		return IsNotNull(column)
+	}
+	return &cmpPredicate{column: column, op: "!=", value: value}
+}
+
+// Lt returns a predicate for column < value.
+func Lt(column string, value interface{}) Predicate {
+	return &cmpPredicate{column: column, op: "<", value: value}
+}
+
+// Le returns a predicate for column <= value.
+func Le(column string, value interface{}) Predicate {
+	return &cmpPredicate{column: column, op: "<=", value: value}
+}
+
+// Gt returns a predicate for column > value.
+func Gt(column string, value interface{}) Predicate {
+	return &cmpPredicate{column: column, op: ">", value: value}
+}
+
+// Ge returns a predicate for column >= value.
+func Ge(column string, value interface{}) Predicate {
+	return &cmpPredicate{column: column, op: ">=", value: value}
+}
+
+// Like returns a predicate for column LIKE pattern.
+func Like(column string, pattern string) Predicate {
+	return &cmpPredicate{column: column, op: "LIKE", value: pattern}
+}
+
+// ILike returns a predicate for a case-insensitive column LIKE pattern. It uses ILIKE
+// on PostgreSQL and LOWER(column) LIKE LOWER(pattern) on other dialects.
+func ILike(column string, pattern string) Predicate {
+	return &ciPredicate{column: column, op: "LIKE", value: pattern}
+}
+
+// IEq returns a predicate for a case-insensitive column = value, which is rendered as
+// LOWER(column) = LOWER(value), or column IS NULL if the value is nil. Queries can use
+// an index on the expression, e.g. NewCreateIndex().ColumnExpr("lower(email)").
+func IEq(column string, value interface{}) Predicate {
+	if value == nil {
+		return IsNull(column)
+	}
+	return &ciPredicate{column: column, op: "=", value: value}
+}
+
+// Regexp returns a predicate that is true if the column matches the regular expression.
+// It uses ~ on PostgreSQL and REGEXP on MySQL and SQLite. SQLite supports it only when
+// the driver registers the REGEXP function and the dialect is created with
+// sqlitedialect.WithRegexp. Other dialects make the query fail with an error,
+// see feature.Regexp.
+//
+// Matching is case-sensitive on PostgreSQL, but MySQL REGEXP follows the collation
+// of the column, so it is case-insensitive with the default _ci collations.
+func Regexp(column string, pattern string) Predicate {
+	return &regexpPredicate{column: column, pattern: pattern}
+}
+
+// IRegexp is like Regexp, but matches case-insensitively. It uses ~* on PostgreSQL and
+// prepends the (?i) flag to the pattern on MySQL 8.0+, MariaDB, and SQLite.
+func IRegexp(column string, pattern string) Predicate {
+	return &regexpPredicate{column: column, pattern: pattern, ci: true}
+}
+
+// Cmp returns a predicate that compares the column with the value using the operator,
+// e.g. an operator from API query parameters. Supported operators are =, !=, <>, <, <=,
+// >, >=, LIKE, NOT LIKE, and ILIKE. Other operators make the query fail with an error.
+func Cmp(column, op string, value interface{}) Predicate {
+	op = strings.ToUpper(strings.TrimSpace(op))
+	switch op {
+	case "=":
+		return Eq(column, value)
+	case "!=", "<>":
+		return Ne(column, value)
+	case "<", "<=", ">", ">=", "LIKE", "NOT LIKE":
+		return &cmpPredicate{column: column, op: op, value: value}
+	case "ILIKE":
+		return &ciPredicate{column: column, op: "LIKE", value: value}
+	default:
+		return &errPredicate{err: fmt.Errorf("bun: unsupported predicate operator %q", op)}
+	}
+}
+
+// IsNull returns a predicate for column IS NULL.
+func IsNull(column string) Predicate {
+	return &nullPredicate{column: column, op: " IS NULL"}
+}
+
+// IsNotNull returns a predicate for column IS NOT NULL.
+func IsNotNull(column string) Predicate {
+	return &nullPredicate{column: column, op: " IS NOT NULL"}
+}
+
+// IsIn returns a predicate for column IN (values...). The predicate is false
+// if the slice is empty.
+func IsIn(column string, slice interface{}) Predicate {
+	return &inPredicate{column: column, slice: slice}
+}
+
+// NotIn returns a predicate for column NOT IN (values...). The predicate is true
+// if the slice is empty.
+func NotIn(column string, slice interface{}) Predicate {
+	return &inPredicate{column: column, slice: slice, not: true}
+}
+
+// Between returns a predicate for column BETWEEN lower AND upper.
+func Between(column string, lower, upper interface{}) Predicate {
+	return &betweenPredicate{column: column, lower: lower, upper: upper}
+}
+
+// IsDistinctFrom returns a null-safe predicate for column != value, which is true
+// if only one of them is NULL and false if both are NULL. It uses IS DISTINCT FROM
+// on PostgreSQL, NOT (column <=> value) on MySQL, IS NOT on SQLite, and
+// NOT EXISTS (SELECT column INTERSECT SELECT value) on SQL Server.
+func IsDistinctFrom(column string, value interface{}) Predicate {
+	return &distinctPredicate{column: column, value: value, not: true}
+}
+
+// IsNotDistinctFrom returns a null-safe predicate for column = value, which is true
+// if both are NULL, see IsDistinctFrom.
+func IsNotDistinctFrom(column string, value interface{}) Predicate {
+	return &distinctPredicate{column: column, value: value}
+}
+
+//------------------------------------------------------------------------------
+
+type compoundPredicate struct {
+	sep   string
+	empty string
+	preds []Predicate
+}
+
+func (p *compoundPredicate) AppendQuery(fmter schema.Formatter, b []byte) (_ []byte, err error) {
+	preds := make([]Predicate, 0, len(p.preds))
+	for _, pred := range p.preds {
+		if pred != nil {
+			preds = append(preds, pred)
+		}
+	}
+
+	switch len(preds) {
+	case 0:
+		return append(b, p.empty...), nil
+	case 1:
+		return preds[0].AppendQuery(fmter, b)
+	}
+
+	for i, pred := range preds {
+		if i > 0 {
+			b = append(b, p.sep...)
+		}
+		b = append(b, '(')
+		b, err = pred.AppendQuery(fmter, b)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, ')')
+	}
+	return b, nil
+}
+
+type notPredicate struct {
+	pred Predicate
+}
+
+func (p *notPredicate) AppendQuery(fmter schema.Formatter, b []byte) (_ []byte, err error) {
+	if p.pred == nil {
+		return append(b, "1 = 0"...), nil
+	}
+	b = append(b, "NOT ("...)
+	b, err = p.pred.AppendQuery(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, ')'), nil
+}
+
+type cmpPredicate struct {
+	column string
+	op     string
+	value  interface{}
+}
+
+func (p *cmpPredicate) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	b = fmter.AppendIdent(b, p.column)
+	b = append(b, ' ')
+	b = append(b, p.op...)
+	b = append(b, ' ')
+	return fmter.AppendArg(b, p.value), nil
+}
+
+type ciPredicate struct {
+	column string
+	op     string
+	value  interface{}
+}
+
+func (p *ciPredicate) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if p.op == "LIKE" && fmter.Dialect().Name() == dialect.PG {
+		b = fmter.AppendIdent(b, p.column)
+		b = append(b, " ILIKE "...)
+		return fmter.AppendArg(b, p.value), nil
+	}
+
+	b = append(b, "LOWER("...)
+	b = fmter.AppendIdent(b, p.column)
+	b = append(b, ") "...)
+	b = append(b, p.op...)
+	b = append(b, " LOWER("...)
+	b = fmter.AppendArg(b, p.value)
+	return append(b, ')'), nil
+}
+
+type regexpPredicate struct {
+	column  string
+	pattern string
+	ci      bool
+}
+
+func (p *regexpPredicate) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if !fmter.HasFeature(feature.Regexp) {
+		return nil, fmt.Errorf("bun: Regexp is not supported by %s", fmter.Dialect().Name())
+	}
+
+	b = fmter.AppendIdent(b, p.column)
+	if fmter.Dialect().Name() == dialect.PG {
+		if p.ci {
+			b = append(b, " ~* "...)
+		} else {
+			b = append(b, " ~ "...)
+		}
+		return fmter.AppendArg(b, p.pattern), nil
+	}
+
+	b = append(b, " REGEXP "...)
+	if p.ci {
+		return fmter.AppendArg(b, "(?i)"+p.pattern), nil
+	}
+	return fmter.AppendArg(b, p.pattern), nil
+}
+
+type nullPredicate struct {
+	column string
+	op     string
+}
+
+func (p *nullPredicate) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	b = fmter.AppendIdent(b, p.column)
+	return append(b, p.op...), nil
+}
+
+type inPredicate struct {
+	column string
+	slice  interface{}
+	not    bool
+}
+
+func (p *inPredicate) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	v := reflect.ValueOf(p.slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("bun: IsIn and NotIn require a slice, got %T", p.slice)
+	}
+	if v.Len() == 0 {
+		if p.not {
+			return append(b, "1 = 1"...), nil
+		}
+		return append(b, "1 = 0"...), nil
+	}
+
+	b = fmter.AppendIdent(b, p.column)
+	if p.not {
+		b = append(b, " NOT IN ("...)
+	} else {
+		b = append(b, " IN ("...)
+	}
+	b = fmter.AppendArg(b, In(p.slice))
+	return append(b, ')'), nil
+}
+
+type betweenPredicate struct {
+	column       string
+	lower, upper interface{}
+}
+
+func (p *betweenPredicate) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	b = fmter.AppendIdent(b, p.column)
+	b = append(b, " BETWEEN "...)
+	b = fmter.AppendArg(b, p.lower)
+	b = append(b, " AND "...)
+	return fmter.AppendArg(b, p.upper), nil
+}
+
+type errPredicate struct {
+	err error
+}
+
+func (p *errPredicate) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	return nil, p.err
+}
+
+type distinctPredicate struct {
+	column string
+	value  interface{}
+	not    bool
+}
+
+func (p *distinctPredicate) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	switch fmter.Dialect().Name() {
+	case dialect.MySQL:
+		if p.not {
+			b = append(b, "NOT ("...)
+		}
+		b = fmter.AppendIdent(b, p.column)
+		b = append(b, " <=> "...)
+		b = fmter.AppendArg(b, p.value)
+		if p.not {
+			b = append(b, ')')
+		}
+		return b, nil
+	case dialect.SQLite:
+		b = fmter.AppendIdent(b, p.column)
+		if p.not {
+			b = append(b, " IS NOT "...)
+		} else {
+			b = append(b, " IS "...)
+		}
+		return fmter.AppendArg(b, p.value), nil
+	case dialect.MSSQL:
+		if p.not {
+			b = append(b, "NOT "...)
+		}
+		b = append(b, "EXISTS (SELECT "...)
+		b = fmter.AppendIdent(b, p.column)
+		b = append(b, " INTERSECT SELECT "...)
+		b = fmter.AppendArg(b, p.value)
+		return append(b, ')'), nil
+	default:
+		b = fmter.AppendIdent(b, p.column)
+		if p.not {
+			b = append(b, " IS DISTINCT FROM "...)
+		} else {
+			b = append(b, " IS NOT DISTINCT FROM "...)
+		}
+		return fmter.AppendArg(b, p.value), nil
+	}
+}
+
+// whereInQuery appends the query with the slice as an IN list or a constant condition
+// if the slice is empty, see SelectQuery.WhereIn.
+type whereInQuery struct {
+	query string
+	slice interface{}
+	not   bool
+}
+
+func (q *whereInQuery) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	v := reflect.Indirect(reflect.ValueOf(q.slice))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("bun: WhereIn and WhereNotIn require a slice, got %T", q.slice)
+	}
+	if v.Len() == 0 {
+		if q.not {
+			return append(b, "1 = 1"...), nil
+		}
+		return append(b, "1 = 0"...), nil
+	}
+
+	b = append(b, '(')
+	b = fmter.AppendQuery(b, q.query, In(v.Interface()))
+	return append(b, ')'), nil
+}
@@ -20,6 +20,8 @@ const (
 	forceDeleteFlag internal.Flag = 1 << iota
 	deletedFlag
 	allWithDeletedFlag
+	skipRelationsFlag
+	unscopedFlag
 )
 
 type withQuery struct {
@@ -93,8 +95,10 @@ var (
 )
 
 type baseQuery struct {
-	db   *DB
-	conn IConn
+	db    *DB
+	conn  IConn
+	idb   IDB // Bun wrapper of conn
+	ctxTx *Tx // context transaction that executes the query, see bindTx
 
 	model Model
 	err   error
@@ -107,7 +111,10 @@ type baseQuery struct {
 	tables         []schema.QueryWithArgs
 	columns        []schema.QueryWithArgs
 
-	flags internal.Flag
+	flags        internal.Flag
+	scanFlags    internal.Flag // DB flags that only apply to this query, e.g. strictScan
+	skippedHooks uint64        // bit mask of ModelHookType
+	timeout      *time.Duration
 }
 
 func (q *baseQuery) DB() *DB {
@@ -118,6 +125,58 @@ func (q *baseQuery) GetConn() IConn {
 	return q.conn
 }
 
+// GetIDB returns the DB, Conn, or Tx that executes the query, which allows
+// hooks to execute other queries using the same connection or transaction.
+func (q *baseQuery) GetIDB() IDB {
+	if q.ctxTx != nil {
+		return *q.ctxTx
+	}
+	if q.idb != nil {
+		return q.idb
+	}
+	switch conn := q.conn.(type) {
+	case *sql.Conn:
+		return Conn{db: q.db, Conn: conn}
+	case *sql.Tx:
+		// The transaction was not started by Bun, so it has no callbacks.
+		return Tx{ctx: context.Background(), db: q.db, Tx: conn}
+	default:
+		return q.db
+	}
+}
+
+// txFromContext returns the transaction from the context if the query is executed in it:
+// queries that use the DB connection are executed in the context transaction,
+// see ContextWithTx.
+func (q *baseQuery) txFromContext(ctx context.Context) (Tx, bool) {
+	tx, ok := TxFromContext(ctx)
+	if !ok || tx.db.DB != q.db.DB {
+		return Tx{}, false
+	}
+	if (q.idb == nil && q.conn == IConn(q.db.DB)) || q.conn == IConn(tx.Tx) {
+		return tx, true
+	}
+	return Tx{}, false
+}
+
+// resolveConn returns the connection that executes the query.
+func (q *baseQuery) resolveConn(ctx context.Context) IConn {
+	if tx, ok := q.txFromContext(ctx); ok {
+		return tx.Tx
+	}
+	return q.conn
+}
+
+// bindTx makes GetIDB return the context transaction that executes the query,
+// so query hooks can use it.
+func (q *baseQuery) bindTx(ctx context.Context) {
+	if tx, ok := q.txFromContext(ctx); ok {
+		q.ctxTx = &tx
+	} else if q.ctxTx != nil {
+		q.ctxTx = nil
+	}
+}
+
 func (q *baseQuery) GetModel() Model {
 	return q.model
 }
@@ -154,12 +213,16 @@ func (q *baseQuery) setConn(db IConn) {
 	switch db := db.(type) {
 	case *DB:
 		q.conn = db.DB
+		q.idb = db
 	case Conn:
 		q.conn = db.Conn
+		q.idb = db
 	case Tx:
 		q.conn = db.Tx
+		q.idb = db
 	default:
 		q.conn = db
+		q.idb = nil
 	}
 }
 
@@ -200,6 +263,27 @@ func (q *baseQuery) beforeAppendModel(ctx context.Context, query Query) error {
 	return nil
 }
 
+func (q *baseQuery) skipAllHooks() {
+	q.skippedHooks = ^uint64(0)
+}
+
+func (q *baseQuery) skipHooks(hooks ...ModelHookType) {
+	for _, hook := range hooks {
+		q.skippedHooks |= 1 << uint(hook)
+	}
+}
+
+func (q *baseQuery) isHookSkipped(hook ModelHookType) bool {
+	return q.skippedHooks&(1<<uint(hook)) != 0
+}
+
+func (q *baseQuery) validateModel(ctx context.Context) error {
+	if q.tableModel != nil {
+		return q.tableModel.validate(ctx)
+	}
+	return nil
+}
+
 func (q *baseQuery) hasFeature(feature feature.Feature) bool {
 	return q.db.features.Has(feature)
 }
@@ -286,7 +370,10 @@ func (q *baseQuery) appendCTE(
 ) (_ []byte, err error) {
 	if !fmter.Dialect().Features().Has(feature.WithValues) {
 		if values, ok := cte.query.(*ValuesQuery); ok {
-			return q.appendSelectFromValues(fmter, b, cte, values)
+			if fmter.HasFeature(feature.ValuesTable) {
+				return q.appendSelectFromValues(fmter, b, cte, values)
+			}
+			return q.appendSelectUnionValues(fmter, b, cte, values)
 		}
 	}
 
@@ -337,6 +424,21 @@ func (q *baseQuery) appendSelectFromValues(
 	return b, nil
 }
 
+func (q *baseQuery) appendSelectUnionValues(
+	fmter schema.Formatter, b []byte, cte withQuery, values *ValuesQuery,
+) (_ []byte, err error) {
+	b = fmter.AppendIdent(b, cte.name)
+	b = append(b, " AS ("...)
+
+	b, err = values.appendSelectUnion(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+
+	b = append(b, ")"...)
+	return b, nil
+}
+
 //------------------------------------------------------------------------------
 
 func (q *baseQuery) addTable(table schema.QueryWithArgs) {
@@ -398,17 +500,19 @@ func (q *baseQuery) hasTables() bool {
 func (q *baseQuery) appendTables(
 	fmter schema.Formatter, b []byte,
 ) (_ []byte, err error) {
-	return q._appendTables(fmter, b, false)
+	return q._appendTables(fmter, b, false, schema.QueryWithArgs{})
 }
 
 func (q *baseQuery) appendTablesWithAlias(
 	fmter schema.Formatter, b []byte,
 ) (_ []byte, err error) {
-	return q._appendTables(fmter, b, true)
+	return q._appendTables(fmter, b, true, schema.QueryWithArgs{})
 }
 
+// _appendTables appends the model table and other tables. The FOR SYSTEM_TIME clause
+// of temporal tables is appended after the model table name and before the alias.
 func (q *baseQuery) _appendTables(
-	fmter schema.Formatter, b []byte, withAlias bool,
+	fmter schema.Formatter, b []byte, withAlias bool, sysTime schema.QueryWithArgs,
 ) (_ []byte, err error) {
 	startLen := len(b)
 
@@ -420,6 +524,13 @@ func (q *baseQuery) _appendTables(
 			}
 		} else {
 			b = fmter.AppendQuery(b, string(q.table.SQLNameForSelects))
+			if !sysTime.IsZero() {
+				b = append(b, " FOR SYSTEM_TIME "...)
+				b, err = sysTime.AppendQuery(fmter, b)
+				if err != nil {
+					return nil, err
+				}
+			}
 			if withAlias && q.table.SQLAlias != q.table.SQLNameForSelects {
 				if q.db.dialect.Name() == dialect.Oracle {
 					b = append(b, ' ')
@@ -563,16 +674,54 @@ func (q *baseQuery) scan(
 	query string,
 	model Model,
 	hasDest bool,
-) (sql.Result, error) {
+) (_ sql.Result, err error) {
+	if q.db.wrapsErrors() {
+		defer q.db.wrapQueryError(&err, iquery, query, time.Now())
+	}
+
+	ctx, cancel := q.withTimeout(ctx)
+	defer cancel()
+
 	ctx, event := q.db.beforeQuery(ctx, iquery, query, nil, query, q.model)
 
-	rows, err := q.conn.QueryContext(ctx, query)
+	conn, done, err := q.db.withSessionVars(ctx, q.resolveConn(ctx))
+	if err != nil {
+		q.db.afterQuery(ctx, event, nil, err)
+		return nil, err
+	}
+	defer done(&err)
+
+	stmt, err := q.db.cachedStmt(ctx, conn, operationOf(iquery, query), query)
+	if err != nil {
+		q.db.afterQuery(ctx, event, nil, err)
+		return nil, err
+	}
+
+	if stmt != nil {
+		defer q.db.stmtCache.release(stmt)
+	}
+
+	var rows *sql.Rows
+	err = q.db.retryQuery(ctx, conn, iquery, query, func() (err error) {
+		if stmt != nil {
+			rows, err = stmt.stmt.QueryContext(ctx)
+		} else {
+			rows, err = conn.QueryContext(ctx, query)
+		}
+		return err
+	})
 	if err != nil {
 		q.db.afterQuery(ctx, event, nil, err)
 		return nil, err
 	}
 	defer rows.Close()
 
+	if q.scanFlags != 0 {
+		if tm, ok := model.(TableModel); ok {
+			tm.setScanFlags(q.scanFlags)
+		}
+	}
+
 	numRow, err := model.ScanRows(ctx, rows)
 	if err != nil {
 		q.db.afterQuery(ctx, event, nil, err)
@@ -584,7 +733,7 @@ func (q *baseQuery) scan(
 	}
 
 	res := driver.RowsAffected(numRow)
-	q.db.afterQuery(ctx, event, res, err)
+	err = q.db.afterQuery(ctx, event, res, err)
 
 	return res, err
 }
@@ -593,10 +742,43 @@ func (q *baseQuery) exec(
 	ctx context.Context,
 	iquery Query,
 	query string,
-) (sql.Result, error) {
+) (_ sql.Result, err error) {
+	if q.db.wrapsErrors() {
+		defer q.db.wrapQueryError(&err, iquery, query, time.Now())
+	}
+
+	ctx, cancel := q.withTimeout(ctx)
+	defer cancel()
+
 	ctx, event := q.db.beforeQuery(ctx, iquery, query, nil, query, q.model)
-	res, err := q.conn.ExecContext(ctx, query)
-	q.db.afterQuery(ctx, event, res, err)
+
+	conn, done, err := q.db.withSessionVars(ctx, q.resolveConn(ctx))
+	if err != nil {
+		err = q.db.afterQuery(ctx, event, nil, err)
+		return nil, err
+	}
+	defer done(&err)
+
+	stmt, err := q.db.cachedStmt(ctx, conn, operationOf(iquery, query), query)
+	if err != nil {
+		err = q.db.afterQuery(ctx, event, nil, err)
+		return nil, err
+	}
+
+	var res sql.Result
+	err = q.db.retryQuery(ctx, conn, iquery, query, func() (err error) {
+		if stmt != nil {
+			res, err = stmt.stmt.ExecContext(ctx)
+		} else {
+			res, err = conn.ExecContext(ctx, query)
+		}
+		return err
+	})
+	if stmt != nil {
+		q.db.stmtCache.release(stmt)
+	}
+	err = q.db.afterQuery(ctx, event, res, err)
+	q.invalidateStmtCache(ctx, operationOf(iquery, query))
 	return res, err
 }
 
@@ -634,9 +816,65 @@ func (q *baseQuery) AppendNamedArg(fmter schema.Formatter, b []byte, name string
 		return b, true
 	}
 
+	if path, ok := namedArgCall(name, "RelationAlias"); ok && q.tableModel != nil {
+		return appendRelationAlias(fmter, b, q.tableModel, path), true
+	}
+
 	return b, false
 }
 
+var _ schema.TableAliasAppender = (*baseQuery)(nil)
+
+// AppendTableAlias appends the alias of the query table or, when the table
+// is joined as a relation, the alias of the relation.
+func (q *baseQuery) AppendTableAlias(
+	fmter schema.Formatter, b []byte, table *schema.Table,
+) ([]byte, bool) {
+	if q.table == nil {
+		return b, false
+	}
+	if q.table == table {
+		return append(b, q.table.SQLAlias...), true
+	}
+	if q.tableModel == nil {
+		return b, false
+	}
+	if join := findInlineJoin(q.tableModel.getJoins(), table); join != nil {
+		return join.appendAlias(fmter, b), true
+	}
+	return b, false
+}
+
+func findInlineJoin(joins []relationJoin, table *schema.Table) *relationJoin {
+	for i := range joins {
+		j := &joins[i]
+		if !j.isInline() {
+			continue
+		}
+		if j.JoinModel.Table() == table {
+			return j
+		}
+		if j := findInlineJoin(j.JoinModel.getJoins(), table); j != nil {
+			return j
+		}
+	}
+	return nil
+}
+
+// namedArgCall returns the quoted argument of the named arg call, e.g. "Author"
+// for ?RelationAlias("Author").
+func namedArgCall(name, fn string) (string, bool) {
+	arg, ok := strings.CutPrefix(name, fn+"(")
+	if !ok {
+		return "", false
+	}
+	arg, ok = strings.CutSuffix(arg, ")")
+	if !ok || len(arg) < 2 || (arg[0] != '"' && arg[0] != '\'') || arg[len(arg)-1] != arg[0] {
+		return "", false
+	}
+	return arg[1 : len(arg)-1], true
+}
+
 //------------------------------------------------------------------------------
 
 func (q *baseQuery) Dialect() schema.Dialect {
@@ -732,6 +970,12 @@ func (q *whereBaseQuery) addWhere(where schema.QueryWithSep) {
 	q.where = append(q.where, where)
 }
 
+// addWhereIn adds a condition with the slice as the only arg, see SelectQuery.WhereIn.
+func (q *whereBaseQuery) addWhereIn(query string, slice interface{}, not bool) {
+	in := &whereInQuery{query: query, slice: slice, not: not}
+	q.addWhere(schema.SafeQueryWithSep("?", []interface{}{in}, " AND "))
+}
+
 func (q *whereBaseQuery) addWhereGroup(sep string, where []schema.QueryWithSep) {
 	if len(where) == 0 {
 		return
@@ -791,7 +1035,8 @@ func (q *whereBaseQuery) mustAppendWhere(
 func (q *whereBaseQuery) appendWhere(
 	fmter schema.Formatter, b []byte, withAlias bool,
 ) (_ []byte, err error) {
-	if len(q.where) == 0 && q.whereFields == nil && !q.isSoftDelete() {
+	scopes := q.defaultScopes()
+	if len(q.where) == 0 && q.whereFields == nil && !q.isSoftDelete() && len(scopes) == 0 {
 		return b, nil
 	}
 
@@ -799,10 +1044,18 @@ func (q *whereBaseQuery) appendWhere(
 	startLen := len(b)
 
 	if len(q.where) > 0 {
+		// Group the conditions so OR does not bypass the default scopes.
+		group := len(scopes) > 0 && len(q.where) > 1
+		if group {
+			b = append(b, '(')
+		}
 		b, err = appendWhere(fmter, b, q.where)
 		if err != nil {
 			return nil, err
 		}
+		if group {
+			b = append(b, ')')
+		}
 	}
 
 	if q.isSoftDelete() {
@@ -810,29 +1063,26 @@ func (q *whereBaseQuery) appendWhere(
 			b = append(b, " AND "...)
 		}
 
+		table := q.tableModel.Table()
+		var column []byte
 		if withAlias {
-			b = append(b, q.tableModel.Table().SQLAlias...)
+			column = append(column, table.SQLAlias...)
 		} else {
-			b = append(b, q.tableModel.Table().SQLName...)
+			column = append(column, table.SQLName...)
 		}
-		b = append(b, '.')
+		column = append(column, '.')
+		column = append(column, table.SoftDeleteField.SQLName...)
 
-		field := q.tableModel.Table().SoftDeleteField
-		b = append(b, field.SQLName...)
+		b = table.SoftDelete.AppendWhere(fmter, b, column, q.flags.Has(deletedFlag))
+	}
 
-		if field.IsPtr || field.NullZero {
-			if q.flags.Has(deletedFlag) {
-				b = append(b, " IS NOT NULL"...)
-			} else {
-				b = append(b, " IS NULL"...)
-			}
-		} else {
-			if q.flags.Has(deletedFlag) {
-				b = append(b, " != "...)
-			} else {
-				b = append(b, " = "...)
-			}
-			b = fmter.Dialect().AppendTime(b, time.Time{})
+	if len(scopes) > 0 {
+		if len(b) > startLen {
+			b = append(b, " AND "...)
+		}
+		b, err = appendDefaultScopes(fmter, b, scopes)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -929,14 +1179,19 @@ func (q *whereBaseQuery) appendWhereSliceFields(
 	fields []*schema.Field,
 	withAlias bool,
 ) (_ []byte, err error) {
+	var alias schema.Safe
+	if withAlias {
+		alias = q.table.SQLAlias
+	}
+
+	if len(fields) > 1 && !fmter.HasFeature(feature.CompositeIn) {
+		return appendWhereSliceFieldsMulti(fmter, b, model, fields, alias), nil
+	}
+
 	if len(fields) > 1 {
 		b = append(b, '(')
 	}
-	if withAlias {
-		b = appendColumns(b, q.table.SQLAlias, fields)
-	} else {
-		b = appendColumns(b, "", fields)
-	}
+	b = appendColumns(b, alias, fields)
 	if len(fields) > 1 {
 		b = append(b, ')')
 	}
@@ -946,16 +1201,24 @@ func (q *whereBaseQuery) appendWhereSliceFields(
 	isTemplate := fmter.IsNop()
 	slice := model.slice
 	sliceLen := slice.Len()
+	var seen map[string]struct{}
+	if sliceLen > 1 && !isTemplate {
+		seen = make(map[string]struct{}, sliceLen)
+	}
+	var n int
 	for i := 0; i < sliceLen; i++ {
-		if i > 0 {
-			if isTemplate {
-				break
-			}
-			b = append(b, ", "...)
+		if i > 0 && isTemplate {
+			break
 		}
 
 		el := indirect(slice.Index(i))
 
+		start := len(b)
+		if n > 0 {
+			b = append(b, ", "...)
+		}
+		valueStart := len(b)
+
 		if len(fields) > 1 {
 			b = append(b, '(')
 		}
@@ -972,6 +1235,17 @@ func (q *whereBaseQuery) appendWhereSliceFields(
 		if len(fields) > 1 {
 			b = append(b, ')')
 		}
+
+		// Skip duplicate keys, e.g. when the slice contains the same row twice.
+		if seen != nil {
+			key := string(b[valueStart:])
+			if _, ok := seen[key]; ok {
+				b = b[:start]
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		n++
 	}
 
 	b = append(b, ')')
@@ -979,6 +1253,73 @@ func (q *whereBaseQuery) appendWhereSliceFields(
 	return b, nil
 }
 
+// appendWhereSliceFieldsMulti appends ((a = 1 AND b = 2) OR (a = 3 AND b = 4)) for dialects
+// that don't support composite IN, e.g. MSSQL.
+func appendWhereSliceFieldsMulti(
+	fmter schema.Formatter,
+	b []byte,
+	model *sliceTableModel,
+	fields []*schema.Field,
+	alias schema.Safe,
+) []byte {
+	isTemplate := fmter.IsNop()
+	slice := model.slice
+	sliceLen := slice.Len()
+
+	var seen map[string]struct{}
+	if sliceLen > 1 && !isTemplate {
+		seen = make(map[string]struct{}, sliceLen)
+	}
+	var n int
+
+	b = append(b, '(')
+	for i := 0; i < sliceLen; i++ {
+		if i > 0 && isTemplate {
+			break
+		}
+
+		el := indirect(slice.Index(i))
+
+		start := len(b)
+		if n > 0 {
+			b = append(b, " OR "...)
+		}
+		valueStart := len(b)
+
+		b = append(b, '(')
+		for i, f := range fields {
+			if i > 0 {
+				b = append(b, " AND "...)
+			}
+			if alias != "" {
+				b = append(b, alias...)
+				b = append(b, '.')
+			}
+			b = append(b, f.SQLName...)
+			b = append(b, " = "...)
+			if isTemplate {
+				b = append(b, '?')
+			} else {
+				b = f.AppendValue(fmter, b, el)
+			}
+		}
+		b = append(b, ')')
+
+		// Skip duplicate keys, e.g. when the slice contains the same row twice.
+		if seen != nil {
+			key := string(b[valueStart:])
+			if _, ok := seen[key]; ok {
+				b = b[:start]
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		n++
+	}
+	b = append(b, ')')
+	return b
+}
+
 //------------------------------------------------------------------------------
 
 type returningQuery struct {
@@ -0,0 +1,348 @@
+package bun
+
+import (
+	"bytes"
+	"container/list"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/uptrace/bun/schema"
+)
+
+// WithQueryCache enables a cache of the SQL generated by select queries. Queries with
+// the same shape, i.e. the same model, clauses, and placeholders, but different args
+// reuse the cached SQL and only format the args, which saves formatting work in hot
+// code paths. Up to size least recently used shapes are cached.
+//
+// Queries with CTEs, unions, relations, WherePK, index hints, or named placeholders
+// such as ?TableAlias are always formatted, because their SQL depends on more than the args.
+func WithQueryCache(size int) DBOption {
+	return func(db *DB) {
+		if size > 0 {
+			db.queryCache = newQueryCache(size)
+		}
+	}
+}
+
+type queryCache struct {
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List // of *queryCacheEntry, the most recently used first
+	items map[string]*list.Element
+}
+
+type queryCacheEntry struct {
+	key string
+	tpl *queryTemplate // nil if the shape can't be cached
+}
+
+func newQueryCache(size int) *queryCache {
+	return &queryCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *queryCache) lookup(key []byte) (*queryTemplate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[string(key)]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*queryCacheEntry).tpl, true
+}
+
+func (c *queryCache) store(key string, tpl *queryTemplate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&queryCacheEntry{key: key, tpl: tpl})
+	for c.ll.Len() > c.size {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.items, el.Value.(*queryCacheEntry).key)
+	}
+}
+
+// appendSelect appends the SQL of the select query using the cached template
+// and reports whether the template was used.
+func (c *queryCache) appendSelect(
+	fmter schema.Formatter, b []byte, q *SelectQuery, count bool,
+) ([]byte, bool) {
+	s := queryShapePool.Get().(*queryShape)
+	defer s.release()
+
+	q.appendShape(s, count)
+	if !s.ok {
+		return b, false
+	}
+
+	tpl, ok := c.lookup(s.key)
+	if !ok {
+		var err error
+		tpl, err = newSelectTemplate(fmter, q, count, s.args)
+		if err != nil {
+			return b, false
+		}
+		c.store(string(s.key), tpl)
+	}
+	if tpl == nil {
+		return b, false
+	}
+	return tpl.append(formatterWithModel(fmter, q), b, s.args), true
+}
+
+// newSelectTemplate formats a copy of the query with args replaced by slots
+// that record their positions. It returns nil if the template does not reproduce the query.
+func newSelectTemplate(
+	fmter schema.Formatter, q *SelectQuery, count bool, args []interface{},
+) (*queryTemplate, error) {
+	want, err := q.appendQueryNoCache(fmter, nil, count)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := q.cloneForShape()
+	s := &queryShape{ok: true, slots: true}
+	cp.appendShape(s, count)
+
+	b, err := cp.appendQueryNoCache(fmter, nil, count)
+	if err != nil {
+		return nil, nil
+	}
+
+	tpl := &queryTemplate{sql: b, holes: s.holes}
+	if !tpl.valid() || !bytes.Equal(tpl.append(formatterWithModel(fmter, q), nil, args), want) {
+		return nil, nil
+	}
+	return tpl, nil
+}
+
+//------------------------------------------------------------------------------
+
+// queryTemplate is the SQL of a query shape with holes where the args are appended.
+type queryTemplate struct {
+	sql   []byte
+	holes []queryHole
+}
+
+type queryHole struct {
+	offset int
+	arg    int
+}
+
+func (t *queryTemplate) valid() bool {
+	prev := 0
+	for _, h := range t.holes {
+		if h.offset < prev || h.offset > len(t.sql) {
+			return false
+		}
+		prev = h.offset
+	}
+	return true
+}
+
+func (t *queryTemplate) append(fmter schema.Formatter, b []byte, args []interface{}) []byte {
+	var prev int
+	for _, h := range t.holes {
+		b = append(b, t.sql[prev:h.offset]...)
+		b = fmter.AppendArg(b, args[h.arg])
+		prev = h.offset
+	}
+	return append(b, t.sql[prev:]...)
+}
+
+//------------------------------------------------------------------------------
+
+var queryShapePool = sync.Pool{
+	New: func() interface{} {
+		return &queryShape{
+			key: make([]byte, 0, 256),
+		}
+	},
+}
+
+// queryShape collects the parts of a query that determine its SQL, except args,
+// into a key. With slots, it replaces args with slots that record their positions instead.
+type queryShape struct {
+	key  []byte
+	args []interface{}
+	ok   bool
+
+	slots bool
+	holes []queryHole
+}
+
+func (s *queryShape) release() {
+	clear(s.args)
+	s.key = s.key[:0]
+	s.args = s.args[:0]
+	queryShapePool.Put(s)
+}
+
+func (s *queryShape) reset(kind byte) {
+	s.key = append(s.key[:0], kind)
+	s.ok = true
+}
+
+func (s *queryShape) addInt(n int64) {
+	s.key = strconv.AppendInt(s.key, n, 10)
+	s.key = append(s.key, ',')
+}
+
+func (s *queryShape) addString(str string) {
+	s.addInt(int64(len(str)))
+	s.key = append(s.key, str...)
+}
+
+func (s *queryShape) addPtr(v interface{}) {
+	s.key = strconv.AppendUint(s.key, uint64(reflect.ValueOf(v).Pointer()), 16)
+	s.key = append(s.key, ',')
+}
+
+func (s *queryShape) addQuery(q *schema.QueryWithArgs) {
+	if hasNamedPlaceholder(q.Query) {
+		s.ok = false
+		return
+	}
+
+	if s.slots {
+		if len(q.Args) > 0 {
+			args := make([]interface{}, len(q.Args))
+			for i := range args {
+				args[i] = s.slot(len(s.args))
+				s.args = append(s.args, nil)
+			}
+			q.Args = args
+		}
+		return
+	}
+
+	s.addString(q.Query)
+	if q.Args == nil {
+		s.addInt(-1)
+	} else {
+		s.addInt(int64(len(q.Args)))
+	}
+	s.args = append(s.args, q.Args...)
+}
+
+func (s *queryShape) addQueries(qs []schema.QueryWithArgs) {
+	if qs == nil {
+		s.addInt(-1)
+		return
+	}
+	s.addInt(int64(len(qs)))
+	for i := range qs {
+		s.addQuery(&qs[i])
+	}
+}
+
+func (s *queryShape) addWhere(ws []schema.QueryWithSep) {
+	s.addInt(int64(len(ws)))
+	for i := range ws {
+		s.addString(ws[i].Sep)
+		s.addQuery(&ws[i].QueryWithArgs)
+	}
+}
+
+func (s *queryShape) slot(arg int) queryCacheSlot {
+	return func(b []byte) []byte {
+		s.holes = append(s.holes, queryHole{offset: len(b), arg: arg})
+		return b
+	}
+}
+
+// queryCacheSlot is a func, so it is never mistaken for a struct with named args.
+type queryCacheSlot func(b []byte) []byte
+
+var _ schema.QueryAppender = (queryCacheSlot)(nil)
+
+func (fn queryCacheSlot) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	return fn(b), nil
+}
+
+// hasNamedPlaceholder reports whether the query contains placeholders such as ?TableAlias,
+// which can be replaced with model values.
+func hasNamedPlaceholder(query string) bool {
+	for i := 0; i < len(query)-1; i++ {
+		if query[i] != '?' {
+			continue
+		}
+		c := query[i+1]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			return true
+		}
+	}
+	return false
+}
+
+//------------------------------------------------------------------------------
+
+func (q *SelectQuery) appendShape(s *queryShape, count bool) {
+	if len(q.with) > 0 || len(q.union) > 0 || q.whereFields != nil ||
+		q.use != nil || q.ignore != nil || q.force != nil ||
+		(q.tableModel != nil && len(q.tableModel.getJoins()) > 0) ||
+		len(q.defaultScopes()) > 0 {
+		s.ok = false
+		return
+	}
+
+	s.reset('S')
+	if count {
+		s.addInt(1)
+	} else {
+		s.addInt(0)
+	}
+	s.addPtr(q.table)
+	s.addInt(int64(q.flags))
+	s.addInt(int64(q.limit))
+	s.addInt(int64(q.offset))
+	s.addInt(int64(q.queryTimeout()))
+
+	s.addQuery(&q.modelTableName)
+	s.addQueries(q.tables)
+	s.addQueries(q.columns)
+	s.addQueries(q.distinctOn)
+	s.addInt(int64(len(q.joins)))
+	for i := range q.joins {
+		s.addQuery(&q.joins[i].join)
+		s.addWhere(q.joins[i].on)
+	}
+	s.addQuery(&q.asOf)
+	s.addQuery(&q.sysTime)
+	s.addWhere(q.where)
+	s.addQueries(q.group)
+	s.addQueries(q.having)
+	s.addQueries(q.order)
+	s.addQuery(&q.selFor)
+}
+
+// cloneForShape returns a copy of the query that can be modified by appendShape with slots.
+func (q *SelectQuery) cloneForShape() *SelectQuery {
+	cp := *q
+	cp.tables = slices.Clone(q.tables)
+	cp.columns = slices.Clone(q.columns)
+	cp.distinctOn = slices.Clone(q.distinctOn)
+	cp.joins = slices.Clone(q.joins)
+	for i := range cp.joins {
+		cp.joins[i].on = slices.Clone(cp.joins[i].on)
+	}
+	cp.where = slices.Clone(q.where)
+	cp.group = slices.Clone(q.group)
+	cp.having = slices.Clone(q.having)
+	cp.order = slices.Clone(q.order)
+	return &cp
+}
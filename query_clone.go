@@ -0,0 +1,234 @@
+package bun
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns a copy of the query that can be modified and executed independently,
+// e.g. to build a base query once and use it for both a count and a page query.
+//
+// The clone scans into the same destination as the original query, but relations
+// added to the clone with Relation don't change the original. Use Model to scan
+// the clone into another destination.
+func (q *SelectQuery) Clone() *SelectQuery {
+	if q == nil {
+		return nil
+	}
+
+	clone := *q
+	clone.whereBaseQuery = q.whereBaseQuery.clone()
+	clone.idxHintsQuery = q.idxHintsQuery.clone()
+	clone.orderLimitOffsetQuery = q.orderLimitOffsetQuery.clone()
+	clone.distinctOn = slices.Clone(q.distinctOn)
+	clone.joins = cloneJoinQueries(q.joins)
+	clone.group = slices.Clone(q.group)
+	clone.having = slices.Clone(q.having)
+	clone.union = slices.Clone(q.union)
+	for i := range clone.union {
+		clone.union[i].query = clone.union[i].query.Clone()
+	}
+	clone.relationSem = nil
+	return &clone
+}
+
+// Clone returns a copy of the query that can be modified and executed independently.
+// See SelectQuery.Clone.
+func (q *InsertQuery) Clone() *InsertQuery {
+	if q == nil {
+		return nil
+	}
+
+	clone := *q
+	clone.whereBaseQuery = q.whereBaseQuery.clone()
+	clone.returningQuery = q.returningQuery.clone()
+	clone.customValueQuery = q.customValueQuery.clone()
+	clone.setQuery = q.setQuery.clone()
+	return &clone
+}
+
+// Clone returns a copy of the query that can be modified and executed independently.
+// See SelectQuery.Clone.
+func (q *UpdateQuery) Clone() *UpdateQuery {
+	if q == nil {
+		return nil
+	}
+
+	clone := *q
+	clone.whereBaseQuery = q.whereBaseQuery.clone()
+	clone.orderLimitOffsetQuery = q.orderLimitOffsetQuery.clone()
+	clone.returningQuery = q.returningQuery.clone()
+	clone.customValueQuery = q.customValueQuery.clone()
+	clone.setQuery = q.setQuery.clone()
+	clone.idxHintsQuery = q.idxHintsQuery.clone()
+	clone.joins = cloneJoinQueries(q.joins)
+	return &clone
+}
+
+// Clone returns a copy of the query that can be modified and executed independently.
+// See SelectQuery.Clone.
+func (q *DeleteQuery) Clone() *DeleteQuery {
+	if q == nil {
+		return nil
+	}
+
+	clone := *q
+	clone.whereBaseQuery = q.whereBaseQuery.clone()
+	clone.orderLimitOffsetQuery = q.orderLimitOffsetQuery.clone()
+	clone.returningQuery = q.returningQuery.clone()
+	return &clone
+}
+
+// Clone returns a copy of the query that can be modified and executed independently.
+// See SelectQuery.Clone.
+func (q *CreateTableQuery) Clone() *CreateTableQuery {
+	if q == nil {
+		return nil
+	}
+
+	clone := *q
+	clone.baseQuery = q.baseQuery.clone()
+	clone.fks = slices.Clone(q.fks)
+	return &clone
+}
+
+// clone returns a copy of the query with its own slices.
+// Slices where nil has a special meaning, e.g. columns, stay nil.
+func (q *baseQuery) clone() baseQuery {
+	clone := *q
+	clone.with = slices.Clone(q.with)
+	clone.tables = slices.Clone(q.tables)
+	clone.columns = slices.Clone(q.columns)
+	if q.timeout != nil {
+		timeout := *q.timeout
+		clone.timeout = &timeout
+	}
+	if q.tableModel != nil {
+		clone.tableModel = cloneTableModel(q.tableModel)
+		if q.model == Model(q.tableModel) {
+			clone.model = clone.tableModel
+		}
+	}
+	return clone
+}
+
+func (q *whereBaseQuery) clone() whereBaseQuery {
+	clone := *q
+	clone.baseQuery = q.baseQuery.clone()
+	clone.where = slices.Clone(q.where)
+	clone.whereFields = slices.Clone(q.whereFields)
+	return clone
+}
+
+func (q *orderLimitOffsetQuery) clone() orderLimitOffsetQuery {
+	clone := *q
+	clone.order = slices.Clone(q.order)
+	return clone
+}
+
+func (q *returningQuery) clone() returningQuery {
+	return returningQuery{
+		returning:       slices.Clone(q.returning),
+		returningFields: slices.Clone(q.returningFields),
+	}
+}
+
+func (q *customValueQuery) clone() customValueQuery {
+	return customValueQuery{
+		modelValues: maps.Clone(q.modelValues),
+		extraValues: slices.Clone(q.extraValues),
+	}
+}
+
+func (q *setQuery) clone() setQuery {
+	return setQuery{
+		set: slices.Clone(q.set),
+	}
+}
+
+func (ih *idxHintsQuery) clone() idxHintsQuery {
+	return idxHintsQuery{
+		use:    ih.use.clone(),
+		ignore: ih.ignore.clone(),
+		force:  ih.force.clone(),
+	}
+}
+
+func (ih *indexHints) clone() *indexHints {
+	if ih == nil {
+		return nil
+	}
+	return &indexHints{
+		names:      slices.Clone(ih.names),
+		forJoin:    slices.Clone(ih.forJoin),
+		forOrderBy: slices.Clone(ih.forOrderBy),
+		forGroupBy: slices.Clone(ih.forGroupBy),
+	}
+}
+
+func cloneJoinQueries(joins []joinQuery) []joinQuery {
+	joins = slices.Clone(joins)
+	for i := range joins {
+		joins[i].on = slices.Clone(joins[i].on)
+	}
+	return joins
+}
+
+// cloneTableModel returns a copy of the table model with its own relation joins,
+// so relations added to the copy don't change the original. Both models scan
+// into the same destination.
+func cloneTableModel(model TableModel) TableModel {
+	c := tableModelCloner{
+		models: make(map[TableModel]TableModel),
+		joins:  make(map[*relationJoin]*relationJoin),
+	}
+	return c.clone(model)
+}
+
+type tableModelCloner struct {
+	models map[TableModel]TableModel
+	joins  map[*relationJoin]*relationJoin
+}
+
+func (c *tableModelCloner) clone(model TableModel) TableModel {
+	switch m := model.(type) {
+	case *structTableModel:
+		clone := *m
+		c.models[m] = &clone
+		c.cloneJoins(&clone)
+		return &clone
+	case *sliceTableModel:
+		clone := *m
+		c.models[m] = &clone
+		// Joins added with Relation use the embedded struct model as the base model.
+		c.models[&m.structTableModel] = &clone.structTableModel
+		c.cloneJoins(&clone.structTableModel)
+		return &clone
+	default:
+		return model
+	}
+}
+
+func (c *tableModelCloner) cloneJoins(m *structTableModel) {
+	if len(m.joins) == 0 {
+		return
+	}
+
+	joins := m.joins
+	m.joins = make([]relationJoin, len(joins))
+	for i := range joins {
+		j := joins[i]
+		c.joins[&joins[i]] = &m.joins[i]
+
+		if base, ok := c.models[j.BaseModel]; ok {
+			j.BaseModel = base
+		}
+		if parent, ok := c.joins[j.Parent]; ok {
+			j.Parent = parent
+		}
+		j.columns = slices.Clone(j.columns)
+		j.JoinModel = c.clone(j.JoinModel)
+
+		m.joins[i] = j
+	}
+}
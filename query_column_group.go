@@ -0,0 +1,84 @@
+package bun
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithColumnGroup registers a named group of the model columns that can be selected
+// with SelectQuery.ColumnGroup. Groups can also be defined with the group tag option:
+//
+//	type Article struct {
+//		ID    int64  `bun:",pk,autoincrement,group:list,group:detail"`
+//		Title string `bun:",group:list,group:detail"`
+//		Body  string `bun:",group:detail"`
+//	}
+//
+// A registered group replaces the group with the same name defined with tags.
+func WithColumnGroup(model interface{}, name string, columns ...string) DBOption {
+	typ := reflect.TypeOf(model)
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return func(db *DB) {
+		if db.columnGroups == nil {
+			db.columnGroups = make(map[reflect.Type]map[string][]string)
+		}
+		if db.columnGroups[typ] == nil {
+			db.columnGroups[typ] = make(map[string][]string)
+		}
+		db.columnGroups[typ][name] = columns
+	}
+}
+
+// ColumnGroup selects the columns of the named groups of the model, e.g. only
+// the columns needed by a list endpoint without large JSON and blob columns:
+//
+//	db.NewSelect().Model(&articles).ColumnGroup("list").Scan(ctx)
+//
+// The primary keys are not added implicitly, so include them in the group
+// when relations are selected too. See WithColumnGroup.
+func (q *SelectQuery) ColumnGroup(names ...string) *SelectQuery {
+	if q.table == nil {
+		q.setErr(fmt.Errorf("bun: ColumnGroup requires a struct or slice-based model, got %T", q.model))
+		return q
+	}
+
+	seen := make(map[string]struct{})
+	for _, name := range names {
+		columns, err := q.columnGroup(name)
+		if err != nil {
+			q.setErr(err)
+			return q
+		}
+		for _, column := range columns {
+			if _, ok := seen[column]; ok {
+				continue
+			}
+			seen[column] = struct{}{}
+			q.Column(column)
+		}
+	}
+	return q
+}
+
+func (q *SelectQuery) columnGroup(name string) ([]string, error) {
+	if columns, ok := q.db.columnGroups[q.table.Type][name]; ok {
+		for _, column := range columns {
+			if _, err := q.table.Field(column); err != nil {
+				return nil, err
+			}
+		}
+		return columns, nil
+	}
+
+	if fields, ok := q.table.ColumnGroups[name]; ok {
+		columns := make([]string, len(fields))
+		for i, f := range fields {
+			columns[i] = f.Name
+		}
+		return columns, nil
+	}
+
+	return nil, fmt.Errorf("bun: %s does not have column group %q", q.table.TypeName, name)
+}
@@ -20,14 +20,9 @@ type DeleteQuery struct {
 var _ Query = (*DeleteQuery)(nil)
 
 func NewDeleteQuery(db *DB) *DeleteQuery {
-	q := &DeleteQuery{
-		whereBaseQuery: whereBaseQuery{
-			baseQuery: baseQuery{
-				db:   db,
-				conn: db.DB,
-			},
-		},
-	}
+	q := deleteQueryPool.Get().(*DeleteQuery)
+	q.db = db
+	q.conn = db.DB
 	return q
 }
 
@@ -46,6 +41,26 @@ func (q *DeleteQuery) Err(err error) *DeleteQuery {
 	return q
 }
 
+// SkipHooks disables model hooks for the query, e.g. BeforeDelete and AfterDelete,
+// including hooks registered with DB.RegisterModelHook.
+func (q *DeleteQuery) SkipHooks() *DeleteQuery {
+	q.skipAllHooks()
+	return q
+}
+
+// SkipHook disables the model hooks of the given types for the query.
+func (q *DeleteQuery) SkipHook(hooks ...ModelHookType) *DeleteQuery {
+	q.skipHooks(hooks...)
+	return q
+}
+
+// Timeout sets the query timeout overriding the DB default set with WithQueryTimeout.
+// The timeout is applied as a context deadline. Zero disables the timeout.
+func (q *DeleteQuery) Timeout(d time.Duration) *DeleteQuery {
+	q.setTimeout(d)
+	return q
+}
+
 // Apply calls each function in fns, passing the DeleteQuery as an argument.
 func (q *DeleteQuery) Apply(fns ...func(*DeleteQuery) *DeleteQuery) *DeleteQuery {
 	for _, fn := range fns {
@@ -100,6 +115,20 @@ func (q *DeleteQuery) WhereOr(query string, args ...interface{}) *DeleteQuery {
 	return q
 }
 
+// WhereIn adds a condition with the slice as an IN list, which is always false (1 = 0)
+// if the slice is empty, see SelectQuery.WhereIn.
+func (q *DeleteQuery) WhereIn(query string, slice interface{}) *DeleteQuery {
+	q.addWhereIn(query, slice, false)
+	return q
+}
+
+// WhereNotIn is like WhereIn, but the condition is always true (1 = 1)
+// if the slice is empty.
+func (q *DeleteQuery) WhereNotIn(query string, slice interface{}) *DeleteQuery {
+	q.addWhereIn(query, slice, true)
+	return q
+}
+
 func (q *DeleteQuery) WhereGroup(sep string, fn func(*DeleteQuery) *DeleteQuery) *DeleteQuery {
 	saved := q.where
 	q.where = nil
@@ -124,6 +153,12 @@ func (q *DeleteQuery) WhereAllWithDeleted() *DeleteQuery {
 	return q
 }
 
+// Unscoped skips the default scopes of the model, see WithDefaultScope.
+func (q *DeleteQuery) Unscoped() *DeleteQuery {
+	q.flags = q.flags.Set(unscopedFlag)
+	return q
+}
+
 func (q *DeleteQuery) Order(orders ...string) *DeleteQuery {
 	if !q.hasFeature(feature.DeleteOrderLimit) {
 		q.err = errors.New("bun: order is not supported for current dialect")
@@ -249,7 +284,7 @@ func (q *DeleteQuery) AppendQuery(fmter schema.Formatter, b []byte) (_ []byte, e
 		return nil, err
 	}
 
-	if q.hasFeature(feature.Returning) && q.hasReturning() {
+	if q.hasFeature(feature.Returning|feature.DeleteReturning) && q.hasReturning() {
 		b = append(b, " RETURNING "...)
 		b, err = q.appendReturning(fmter, b)
 		if err != nil {
@@ -272,7 +307,7 @@ func (q *DeleteQuery) softDeleteSet(fmter schema.Formatter, tm time.Time) string
 	}
 	b = append(b, q.table.SoftDeleteField.SQLName...)
 	b = append(b, " = "...)
-	b = schema.Append(fmter, b, tm)
+	b = q.table.SoftDelete.AppendDeletedValue(fmter, b, tm)
 	return internal.String(b)
 }
 
@@ -306,12 +341,12 @@ func (q *DeleteQuery) scanOrExec(
 	}
 
 	// Generate the query before checking hasReturning.
-	queryBytes, err := q.AppendQuery(q.db.fmter, q.db.makeQueryBytes())
+	queryBytes, err := q.AppendQuery(q.db.formatter(ctx), q.db.makeQueryBytes())
 	if err != nil {
 		return nil, err
 	}
 
-	useScan := hasDest || (q.hasReturning() && q.hasFeature(feature.Returning|feature.Output))
+	useScan := hasDest || (q.hasReturning() && q.hasFeature(feature.Returning|feature.DeleteReturning|feature.Output))
 	var model Model
 
 	if useScan {
@@ -338,6 +373,10 @@ func (q *DeleteQuery) scanOrExec(
 		}
 	}
 
+	if err := q.invalidateEntities(ctx, q.isWherePK()); err != nil {
+		return nil, err
+	}
+
 	if q.table != nil {
 		if err := q.afterDeleteHook(ctx); err != nil {
 			return nil, err
@@ -348,21 +387,27 @@ func (q *DeleteQuery) scanOrExec(
 }
 
 func (q *DeleteQuery) beforeDeleteHook(ctx context.Context) error {
+	if q.isHookSkipped(BeforeDelete) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(BeforeDeleteHook); ok {
 		if err := hook.BeforeDelete(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, BeforeDelete, q.table, q)
 }
 
 func (q *DeleteQuery) afterDeleteHook(ctx context.Context) error {
+	if q.isHookSkipped(AfterDelete) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(AfterDeleteHook); ok {
 		if err := hook.AfterDelete(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, AfterDelete, q.table, q)
 }
 
 func (q *DeleteQuery) String() string {
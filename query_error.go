@@ -0,0 +1,117 @@
+package bun
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/uptrace/bun/internal"
+)
+
+// QueryErrorOptions configures query errors, see WithQueryErrors.
+type QueryErrorOptions struct {
+	// MaxQueryLen is the maximum length of QueryError.Query in bytes.
+	// Longer queries are truncated on a UTF-8 character boundary. Default is 1000.
+	MaxQueryLen int
+	// Sanitize is called with the query before it is truncated to remove secrets
+	// or personal data, because queries contain the inlined argument values.
+	// The default replaces string and numeric literals with ?. To keep the full
+	// query, use a func that returns the query as is.
+	Sanitize func(query string) string
+}
+
+// WithQueryErrors makes queries built with NewSelect, NewInsert, NewRaw, etc. return
+// a *QueryError that wraps the error with the query context, so it is clear which query failed.
+// sql.ErrNoRows is returned as is.
+func WithQueryErrors(opts QueryErrorOptions) DBOption {
+	return func(db *DB) {
+		if opts.MaxQueryLen == 0 {
+			opts.MaxQueryLen = 1000
+		}
+		if opts.Sanitize == nil {
+			opts.Sanitize = internal.SanitizeQuery
+		}
+		db.queryErrors = &opts
+	}
+}
+
+// QueryError is the error of a failed query, see WithQueryErrors.
+// Use errors.As and errors.Is to inspect the wrapped driver error.
+type QueryError struct {
+	// Operation is the query operation, e.g. SELECT.
+	Operation string
+	// Model is the name of the model, e.g. User, or empty.
+	Model string
+	// Table is the name of the table, e.g. users, or empty.
+	Table string
+	// Query is the sanitized and truncated query.
+	Query string
+	// Duration is the time elapsed until the query failed.
+	Duration time.Duration
+	Err      error
+}
+
+func (e *QueryError) Error() string {
+	name := e.Table
+	if name == "" {
+		name = "query"
+	}
+	return fmt.Sprintf("bun: %s %s failed after %s: %s (query: %q)",
+		e.Operation, name, e.Duration, e.Err, e.Query)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+func (db *DB) wrapsErrors() bool {
+	return db.queryErrors != nil || db.sqlErrors
+}
+
+// wrapQueryError classifies the error if WithSQLErrors is used
+// and replaces it with a *QueryError if WithQueryErrors is used.
+func (db *DB) wrapQueryError(errp *error, iquery Query, query string, start time.Time) {
+	err := *errp
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if db.sqlErrors {
+		err = ClassifyError(err)
+		*errp = err
+	}
+
+	opts := db.queryErrors
+	if opts == nil {
+		return
+	}
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		return
+	}
+
+	queryErr = &QueryError{
+		Operation: iquery.Operation(),
+		Table:     iquery.GetTableName(),
+		Query:     query,
+		Duration:  time.Since(start),
+		Err:       err,
+	}
+	if tm, ok := iquery.GetModel().(TableModel); ok {
+		queryErr.Model = tm.Table().TypeName
+	}
+	queryErr.Query = truncateQuery(opts.Sanitize(queryErr.Query), opts.MaxQueryLen)
+	*errp = queryErr
+}
+
+// truncateQuery truncates the query to at most n bytes without splitting a character.
+func truncateQuery(query string, n int) string {
+	if len(query) <= n {
+		return query
+	}
+	for n > 0 && !utf8.RuneStart(query[n]) {
+		n--
+	}
+	return query[:n] + "..."
+}
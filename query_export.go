@@ -0,0 +1,195 @@
+package bun
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// CSVOptions configures SelectQuery.ExportCSV.
+type CSVOptions struct {
+	// Comma is the field delimiter. Default is ','.
+	Comma rune
+	// NoHeader omits the header row with column names.
+	NoHeader bool
+	// Headers renames columns in the header row, e.g. {"created_at": "Created"}.
+	Headers map[string]string
+	// Null is written for NULL values. Default is an empty string.
+	Null string
+	// Encoders format the values of the columns, e.g. to format times or amounts.
+	// Encoders are not called for NULL values. Other columns use the default format.
+	Encoders map[string]func(value interface{}) (string, error)
+}
+
+// ExportCSV executes the query and writes the rows to w as CSV as they are read
+// from the database, so large results are not loaded into memory:
+//
+//	err := db.NewSelect().Model((*User)(nil)).Column("id", "email").
+//		ExportCSV(ctx, w, bun.CSVOptions{Headers: map[string]string{"email": "E-mail"}})
+//
+// Times are formatted as RFC 3339 and byte slices are written as strings.
+// Only columns selected by the query are exported, so relations are not loaded.
+func (q *SelectQuery) ExportCSV(ctx context.Context, w io.Writer, opts CSVOptions) error {
+	rows, err := q.Rows(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+
+	record := make([]string, len(columns))
+	if !opts.NoHeader {
+		for i, col := range columns {
+			if name, ok := opts.Headers[col]; ok {
+				record[i] = name
+			} else {
+				record[i] = col
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	encoders := make([]func(interface{}) (string, error), len(columns))
+	for i, col := range columns {
+		if fn, ok := opts.Encoders[col]; ok {
+			encoders[i] = fn
+		} else {
+			encoders[i] = formatCSVValue
+		}
+	}
+
+	values, dest := exportDest(len(columns))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i, value := range values {
+			if value == nil {
+				record[i] = opts.Null
+				continue
+			}
+			s, err := encoders[i](value)
+			if err != nil {
+				return fmt.Errorf("bun: can't export column %q: %w", columns[i], err)
+			}
+			record[i] = s
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportNDJSON executes the query and writes each row to w as a JSON object
+// on a separate line, streaming the rows as they are read from the database.
+// Keys are the column names in the order of the columns.
+//
+// Byte slices that are valid UTF-8 are written as strings, other byte slices
+// are encoded with base64.
+func (q *SelectQuery) ExportNDJSON(ctx context.Context, w io.Writer) error {
+	rows, err := q.Rows(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	keys := make([][]byte, len(columns))
+	for i, col := range columns {
+		if keys[i], err = json.Marshal(col); err != nil {
+			return err
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	values, dest := exportDest(len(columns))
+	var b []byte
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+
+		b = append(b[:0], '{')
+		for i, value := range values {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, keys[i]...)
+			b = append(b, ':')
+
+			if bs, ok := value.([]byte); ok && utf8.Valid(bs) {
+				value = string(bs)
+			}
+			js, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("bun: can't export column %q: %w", columns[i], err)
+			}
+			b = append(b, js...)
+		}
+		b = append(b, '}', '\n')
+
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func exportDest(n int) ([]interface{}, []interface{}) {
+	values := make([]interface{}, n)
+	dest := make([]interface{}, n)
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	return values, dest
+}
+
+func formatCSVValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case []byte:
+		return string(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case time.Time:
+		return value.Format(time.RFC3339Nano), nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
@@ -0,0 +1,237 @@
+package bun
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/uptrace/bun/schema"
+)
+
+// CSVImportOptions configures ImportCSV.
+type CSVImportOptions struct {
+	// Comma is the field delimiter. Default is ','.
+	Comma rune
+	// Header is used instead of the header row, in which case the first row
+	// is imported as data.
+	Header []string
+	// Columns maps headers to columns, e.g. {"E-mail": "email"}. Headers mapped to "-"
+	// are ignored. Other headers must match a column name or a struct field name.
+	Columns map[string]string
+	// ChunkSize is the number of rows inserted at once. Default is 1000.
+	ChunkSize int
+	// MaxErrors stops the import with an error after that many invalid rows.
+	// Default is 0, which means that all invalid rows are reported and skipped.
+	MaxErrors int
+	// Insert inserts a chunk of models, which is a *[]T. Default is an INSERT query.
+	// Use it to insert the rows with COPY or to upsert them.
+	Insert func(ctx context.Context, models interface{}) error
+}
+
+// ImportRowError describes a CSV row that was not imported.
+type ImportRowError struct {
+	// Line is the line number of the row in the CSV.
+	Line int
+	// Column is the column that has an invalid value, if any.
+	Column string
+	Err    error
+}
+
+func (e ImportRowError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e ImportRowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportResult describes the result of ImportCSV.
+type ImportResult struct {
+	// Rows is the number of inserted rows.
+	Rows int
+	// Errors are the rows that were not imported.
+	Errors []ImportRowError
+}
+
+// ImportCSV reads the CSV rows from r into models of type T and inserts them in chunks,
+// for example:
+//
+//	res, err := bun.ImportCSV[User](ctx, db, f, bun.CSVImportOptions{
+//		Columns: map[string]string{"E-mail": "email"},
+//	})
+//
+// Values are converted with the field scanners, so they use the same formats as values
+// returned by the database, and empty values are scanned as NULL. Rows that can't be
+// parsed, converted, or validated with ValidateHook are skipped and reported in
+// ImportResult.Errors. An error is returned when the CSV can't be read, a chunk can't
+// be inserted, or there are more than MaxErrors invalid rows; rows inserted before that
+// are not rolled back unless db is a transaction.
+func ImportCSV[T any](
+	ctx context.Context, db IDB, r io.Reader, opts CSVImportOptions,
+) (*ImportResult, error) {
+	table := db.Dialect().Tables().Get(reflect.TypeOf((*T)(nil)).Elem())
+
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 1000
+	}
+	if opts.Insert == nil {
+		opts.Insert = func(ctx context.Context, models interface{}) error {
+			_, err := db.NewInsert().Model(models).Exec(ctx)
+			return err
+		}
+	}
+
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.ReuseRecord = true
+
+	header := opts.Header
+	if header == nil {
+		record, err := cr.Read()
+		if err != nil {
+			if err == io.EOF {
+				return new(ImportResult), nil
+			}
+			return nil, err
+		}
+		header = append([]string(nil), record...)
+	}
+	cr.FieldsPerRecord = len(header)
+
+	fields, err := importFields(table, header, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(ImportResult)
+	models := make([]T, 0, opts.ChunkSize)
+
+	insert := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+		if err := opts.Insert(ctx, &models); err != nil {
+			return err
+		}
+		res.Rows += len(models)
+		models = models[:0]
+		return nil
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var rowErr ImportRowError
+		if err != nil {
+			var perr *csv.ParseError
+			if !errors.As(err, &perr) {
+				return nil, err
+			}
+			rowErr = ImportRowError{Line: perr.StartLine, Err: perr.Err}
+		} else {
+			line, _ := cr.FieldPos(0)
+			rowErr = importRow(ctx, table, fields, record, &models)
+			rowErr.Line = line
+		}
+
+		if rowErr.Err != nil {
+			res.Errors = append(res.Errors, rowErr)
+			if opts.MaxErrors > 0 && len(res.Errors) > opts.MaxErrors {
+				return res, fmt.Errorf("bun: ImportCSV stopped after %d invalid rows: %w",
+					len(res.Errors), rowErr)
+			}
+			continue
+		}
+
+		if len(models) == opts.ChunkSize {
+			if err := insert(); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	if err := insert(); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func importFields(
+	table *schema.Table, header []string, columns map[string]string,
+) ([]*schema.Field, error) {
+	fields := make([]*schema.Field, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if col, ok := columns[name]; ok {
+			if col == "-" {
+				continue
+			}
+			name = col
+		}
+
+		field := table.LookupField(name)
+		if field == nil {
+			field = importFieldByGoName(table, name)
+		}
+		if field == nil {
+			return nil, fmt.Errorf("bun: %s does not have column %q", table.TypeName, name)
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
+
+func importFieldByGoName(table *schema.Table, name string) *schema.Field {
+	for _, f := range table.Fields {
+		if f.GoName == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// importRow scans the record into a new model and appends it to models.
+// Column is set when the error is caused by a value.
+func importRow[T any](
+	ctx context.Context,
+	table *schema.Table,
+	fields []*schema.Field,
+	record []string,
+	models *[]T,
+) ImportRowError {
+	var model T
+	strct := reflect.ValueOf(&model).Elem()
+
+	for i, field := range fields {
+		if field == nil {
+			continue
+		}
+
+		var src interface{}
+		if record[i] != "" {
+			src = record[i]
+		}
+		if err := field.ScanValue(strct, src); err != nil {
+			return ImportRowError{Column: field.Name, Err: err}
+		}
+	}
+
+	if err := validateStruct(ctx, table, strct.Addr()); err != nil {
+		return ImportRowError{Err: err}
+	}
+
+	*models = append(*models, model)
+	return ImportRowError{}
+}
@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/uptrace/bun/dialect/feature"
 	"github.com/uptrace/bun/internal"
@@ -17,7 +20,8 @@ type InsertQuery struct {
 	returningQuery
 	customValueQuery
 
-	on schema.QueryWithArgs
+	on           schema.QueryWithArgs
+	onConstraint string
 	setQuery
 
 	ignore  bool
@@ -27,14 +31,9 @@ type InsertQuery struct {
 var _ Query = (*InsertQuery)(nil)
 
 func NewInsertQuery(db *DB) *InsertQuery {
-	q := &InsertQuery{
-		whereBaseQuery: whereBaseQuery{
-			baseQuery: baseQuery{
-				db:   db,
-				conn: db.DB,
-			},
-		},
-	}
+	q := insertQueryPool.Get().(*InsertQuery)
+	q.db = db
+	q.conn = db.DB
 	return q
 }
 
@@ -53,6 +52,26 @@ func (q *InsertQuery) Err(err error) *InsertQuery {
 	return q
 }
 
+// SkipHooks disables model hooks for the query, e.g. BeforeInsert and AfterInsert,
+// including hooks registered with DB.RegisterModelHook.
+func (q *InsertQuery) SkipHooks() *InsertQuery {
+	q.skipAllHooks()
+	return q
+}
+
+// SkipHook disables the model hooks of the given types for the query.
+func (q *InsertQuery) SkipHook(hooks ...ModelHookType) *InsertQuery {
+	q.skipHooks(hooks...)
+	return q
+}
+
+// Timeout sets the query timeout overriding the DB default set with WithQueryTimeout.
+// The timeout is applied as a context deadline. Zero disables the timeout.
+func (q *InsertQuery) Timeout(d time.Duration) *InsertQuery {
+	q.setTimeout(d)
+	return q
+}
+
 // Apply calls each function in fns, passing the InsertQuery as an argument.
 func (q *InsertQuery) Apply(fns ...func(*InsertQuery) *InsertQuery) *InsertQuery {
 	for _, fn := range fns {
@@ -204,6 +223,11 @@ func (q *InsertQuery) AppendQuery(fmter schema.Formatter, b []byte) (_ []byte, e
 		return nil, err
 	}
 
+	if q.useValuesAlias(fmter) {
+		b = append(b, " AS "...)
+		b = fmter.AppendIdent(b, valuesAlias)
+	}
+
 	b, err = q.appendOn(fmter, b)
 	if err != nil {
 		return nil, err
@@ -388,7 +412,11 @@ func (q *InsertQuery) getFields() ([]*schema.Field, error) {
 	hasIdentity := q.db.features.Has(feature.Identity)
 
 	if len(q.columns) > 0 || q.db.features.Has(feature.DefaultPlaceholder) && !hasIdentity {
-		return q.baseQuery.getFields()
+		fields, err := q.baseQuery.getFields()
+		if err != nil {
+			return nil, err
+		}
+		return withoutGeneratedFields(fields), nil
 	}
 
 	var strct reflect.Value
@@ -408,6 +436,9 @@ func (q *InsertQuery) getFields() ([]*schema.Field, error) {
 	fields := make([]*schema.Field, 0, len(q.table.Fields))
 
 	for _, f := range q.table.Fields {
+		if f.IsGenerated() {
+			continue
+		}
 		if hasIdentity && f.AutoIncrement {
 			q.addReturningField(f)
 			continue
@@ -422,6 +453,26 @@ func (q *InsertQuery) getFields() ([]*schema.Field, error) {
 	return fields, nil
 }
 
+// withoutGeneratedFields returns the fields except generated columns,
+// which can't be inserted or updated.
+func withoutGeneratedFields(fields []*schema.Field) []*schema.Field {
+	for i, f := range fields {
+		if !f.IsGenerated() {
+			continue
+		}
+
+		filtered := make([]*schema.Field, i, len(fields)-1)
+		copy(filtered, fields[:i])
+		for _, f := range fields[i+1:] {
+			if !f.IsGenerated() {
+				filtered = append(filtered, f)
+			}
+		}
+		return filtered
+	}
+	return fields
+}
+
 // marshalsToDefault checks if the value will be marshaled as DEFAULT or NULL (if DEFAULT placeholder is not supported)
 // when appending it to the VALUES clause in place of the given field.
 func (q InsertQuery) marshalsToDefault(f *schema.Field, v reflect.Value) bool {
@@ -446,6 +497,26 @@ func (q *InsertQuery) appendFields(
 
 func (q *InsertQuery) On(s string, args ...interface{}) *InsertQuery {
 	q.on = schema.SafeQuery(s, args)
+	q.onConstraint = ""
+	return q
+}
+
+// OnConflictConstraint uses the named constraint as the conflict target and generates
+// `ON CONFLICT ON CONSTRAINT "name"` followed by the action, for example:
+//
+//	db.NewInsert().
+//		Model(user).
+//		OnConflictConstraint("users_email_key", "DO UPDATE").
+//		Set("name = EXCLUDED.name")
+//
+// Like with On, the "DO UPDATE" action without Set updates all columns.
+// It is only supported by PostgreSQL.
+func (q *InsertQuery) OnConflictConstraint(
+	name string, action string, args ...interface{},
+) *InsertQuery {
+	args = append([]interface{}{Ident(name)}, args...)
+	q.on = schema.SafeQuery("CONFLICT ON CONSTRAINT ? "+action, args)
+	q.onConstraint = name
 	return q
 }
 
@@ -458,6 +529,10 @@ func (q *InsertQuery) appendOn(fmter schema.Formatter, b []byte) (_ []byte, err
 	if q.on.IsZero() {
 		return b, nil
 	}
+	if q.onConstraint != "" && !fmter.HasFeature(feature.OnConflictConstraint) {
+		return nil, fmt.Errorf("bun: ON CONFLICT ON CONSTRAINT is not supported by %s",
+			fmter.Dialect().Name())
+	}
 
 	b = append(b, " ON "...)
 	b, err = q.on.AppendQuery(fmter, b)
@@ -485,6 +560,7 @@ func (q *InsertQuery) appendOn(fmter schema.Formatter, b []byte) (_ []byte, err
 		if len(fields) == 0 {
 			fields = q.tableModel.Table().DataFields
 		}
+		fields = withoutGeneratedFields(fields)
 
 		b = q.appendSetExcluded(b, fields)
 	} else if q.onDuplicateKeyUpdate() {
@@ -496,8 +572,13 @@ func (q *InsertQuery) appendOn(fmter schema.Formatter, b []byte) (_ []byte, err
 		if len(fields) == 0 {
 			fields = q.tableModel.Table().DataFields
 		}
+		fields = withoutGeneratedFields(fields)
 
-		b = q.appendSetValues(b, fields)
+		if q.useValuesAlias(fmter) {
+			b = q.appendSetValuesAlias(fmter, b, fields)
+		} else {
+			b = q.appendSetValues(b, fields)
+		}
 	}
 
 	if len(q.where) > 0 {
@@ -533,6 +614,35 @@ func (q *InsertQuery) appendSetExcluded(b []byte, fields []*schema.Field) []byte
 	return b
 }
 
+const valuesAlias = "new"
+
+// useValuesAlias reports whether the auto-generated ON DUPLICATE KEY UPDATE clause
+// should reference the inserted values with a row alias, because the VALUES() function
+// is deprecated since MySQL 8.0.20 and MariaDB does not support row aliases.
+func (q *InsertQuery) useValuesAlias(fmter schema.Formatter) bool {
+	return fmter.HasFeature(feature.InsertValuesAlias) &&
+		q.onDuplicateKeyUpdate() &&
+		len(q.set) == 0 &&
+		!q.hasMultiTables()
+}
+
+func (q *InsertQuery) appendSetValuesAlias(
+	fmter schema.Formatter, b []byte, fields []*schema.Field,
+) []byte {
+	b = append(b, " "...)
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, f.SQLName...)
+		b = append(b, " = "...)
+		b = fmter.AppendIdent(b, valuesAlias)
+		b = append(b, '.')
+		b = append(b, f.SQLName...)
+	}
+	return b
+}
+
 func (q *InsertQuery) appendSetValues(b []byte, fields []*schema.Field) []byte {
 	b = append(b, " "...)
 	for i, f := range fields {
@@ -571,13 +681,17 @@ func (q *InsertQuery) scanOrExec(
 		}
 	}
 
+	if err := q.validateModel(ctx); err != nil {
+		return nil, err
+	}
+
 	// Run append model hooks before generating the query.
 	if err := q.beforeAppendModel(ctx, q); err != nil {
 		return nil, err
 	}
 
 	// Generate the query before checking hasReturning.
-	queryBytes, err := q.AppendQuery(q.db.fmter, q.db.makeQueryBytes())
+	queryBytes, err := q.AppendQuery(q.db.formatter(ctx), q.db.makeQueryBytes())
 	if err != nil {
 		return nil, err
 	}
@@ -607,7 +721,15 @@ func (q *InsertQuery) scanOrExec(
 			return nil, err
 		}
 
-		if err := q.tryLastInsertID(res, dest); err != nil {
+		if err := q.tryLastInsertID(ctx, res, dest); err != nil {
+			return nil, err
+		}
+	}
+
+	// Plain inserts don't change cached rows, but upserts can change any row
+	// that conflicts with the new ones.
+	if !q.on.IsZero() || q.replace {
+		if err := q.invalidateEntities(ctx, false); err != nil {
 			return nil, err
 		}
 	}
@@ -622,24 +744,30 @@ func (q *InsertQuery) scanOrExec(
 }
 
 func (q *InsertQuery) beforeInsertHook(ctx context.Context) error {
+	if q.isHookSkipped(BeforeInsert) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(BeforeInsertHook); ok {
 		if err := hook.BeforeInsert(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, BeforeInsert, q.table, q)
 }
 
 func (q *InsertQuery) afterInsertHook(ctx context.Context) error {
+	if q.isHookSkipped(AfterInsert) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(AfterInsertHook); ok {
 		if err := hook.AfterInsert(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, AfterInsert, q.table, q)
 }
 
-func (q *InsertQuery) tryLastInsertID(res sql.Result, dest []interface{}) error {
+func (q *InsertQuery) tryLastInsertID(ctx context.Context, res sql.Result, dest []interface{}) error {
 	if q.db.features.Has(feature.Returning) ||
 		q.db.features.Has(feature.Output) ||
 		q.table == nil ||
@@ -652,9 +780,6 @@ func (q *InsertQuery) tryLastInsertID(res sql.Result, dest []interface{}) error
 	if err != nil {
 		return err
 	}
-	if id == 0 {
-		return nil
-	}
 
 	model, err := q.getModel(dest)
 	if err != nil {
@@ -664,23 +789,154 @@ func (q *InsertQuery) tryLastInsertID(res sql.Result, dest []interface{}) error
 	pk := q.table.PKs[0]
 	switch model := model.(type) {
 	case *structTableModel:
+		if id == 0 {
+			return nil
+		}
 		if err := pk.ScanValue(model.strct, id); err != nil {
 			return err
 		}
 	case *sliceTableModel:
-		sliceLen := model.slice.Len()
-		for i := 0; i < sliceLen; i++ {
-			strct := indirect(model.slice.Index(i))
-			if err := pk.ScanValue(strct, id); err != nil {
-				return err
+		return q.scanLastInsertIDs(ctx, model, id)
+	}
+
+	return nil
+}
+
+// scanLastInsertIDs sets the auto-increment IDs of the inserted rows without a primary key.
+// The last insert ID is the ID of the first row, so the IDs are computed by adding
+// auto_increment_increment when the server generates consecutive IDs for all rows.
+// Otherwise, e.g. when some rows have IDs or conflict with existing rows, the IDs are
+// selected by a unique key of the model, see selectInsertedIDs. Without such a key,
+// the IDs are left unset rather than guessed.
+func (q *InsertQuery) scanLastInsertIDs(ctx context.Context, model *sliceTableModel, id int64) error {
+	pk := q.table.PKs[0]
+
+	var rows []int
+	sliceLen := model.slice.Len()
+	for i := 0; i < sliceLen; i++ {
+		if pk.HasZeroValue(indirect(model.slice.Index(i))) {
+			rows = append(rows, i)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	step, consecutive := int64(1), true
+	if d, ok := q.db.Dialect().(interface{ AutoIncrementStep() (int64, bool) }); ok {
+		step, consecutive = d.AutoIncrementStep()
+	}
+
+	simple := len(rows) == sliceLen && q.on.IsZero() && !q.ignore && !q.replace
+	if !simple || (!consecutive && len(rows) > 1) {
+		if fields := q.insertedRowsKey(model, rows); fields != nil {
+			return q.selectInsertedIDs(ctx, model, rows, fields)
+		}
+		// The IDs can't be computed.
+		return nil
+	}
+
+	if id == 0 {
+		return nil
+	}
+	for _, i := range rows {
+		if err := pk.ScanValue(indirect(model.slice.Index(i)), id); err != nil {
+			return err
+		}
+		id += step
+	}
+	return nil
+}
+
+// insertedRowsKey returns the fields of a unique key that are set in all the rows
+// or nil if there is no such key.
+func (q *InsertQuery) insertedRowsKey(model *sliceTableModel, rows []int) []*schema.Field {
+	names := make([]string, 0, len(q.table.Unique))
+	for name := range q.table.Unique {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fields := q.table.Unique[name]
+		if slices.Contains(fields, q.table.PKs[0]) {
+			continue
+		}
+		if allFieldsSet(model, rows, fields) {
+			return fields
+		}
+	}
+	return nil
+}
+
+func allFieldsSet(model *sliceTableModel, rows []int, fields []*schema.Field) bool {
+	for _, i := range rows {
+		strct := indirect(model.slice.Index(i))
+		for _, f := range fields {
+			if f.HasZeroValue(strct) {
+				return false
 			}
-			id++
 		}
 	}
+	return true
+}
+
+// selectInsertedIDs selects the IDs of the rows by the unique key fields.
+func (q *InsertQuery) selectInsertedIDs(
+	ctx context.Context, model *sliceTableModel, rows []int, fields []*schema.Field,
+) error {
+	pk := q.table.PKs[0]
+
+	// Select into copies of the rows, because scanning replaces the slice elements.
+	found := reflect.MakeSlice(reflect.SliceOf(q.table.Type), 0, len(rows))
+	for _, i := range rows {
+		found = reflect.Append(found, indirect(model.slice.Index(i)))
+	}
+	ptr := reflect.New(found.Type())
+	ptr.Elem().Set(found)
 
+	columns := make([]string, 0, len(fields)+1)
+	columns = append(columns, pk.Name)
+	for _, f := range fields {
+		columns = append(columns, f.Name)
+	}
+
+	if err := NewSelectQuery(q.db).
+		Conn(q.conn).
+		Model(ptr.Interface()).
+		Column(columns...).
+		WherePK(columns[1:]...).
+		Unscoped().
+		Scan(ctx); err != nil {
+		return err
+	}
+
+	fmter := q.db.Formatter()
+	ids := make(map[string]reflect.Value, ptr.Elem().Len())
+	for i := 0; i < ptr.Elem().Len(); i++ {
+		strct := ptr.Elem().Index(i)
+		ids[string(appendFieldsKey(fmter, nil, fields, strct))] = pk.Value(strct)
+	}
+
+	for _, i := range rows {
+		strct := indirect(model.slice.Index(i))
+		if id, ok := ids[string(appendFieldsKey(fmter, nil, fields, strct))]; ok {
+			pk.Value(strct).Set(id)
+		}
+	}
 	return nil
 }
 
+func appendFieldsKey(fmter schema.Formatter, b []byte, fields []*schema.Field, strct reflect.Value) []byte {
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = f.AppendValue(fmter, b, strct)
+	}
+	return b
+}
+
 func (q *InsertQuery) String() string {
 	buf, err := q.AppendQuery(q.db.Formatter(), nil)
 	if err != nil {
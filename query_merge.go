@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/uptrace/bun/dialect"
 	"github.com/uptrace/bun/dialect/feature"
@@ -50,6 +51,13 @@ func (q *MergeQuery) Err(err error) *MergeQuery {
 	return q
 }
 
+// Timeout sets the query timeout overriding the DB default set with WithQueryTimeout.
+// The timeout is applied as a context deadline. Zero disables the timeout.
+func (q *MergeQuery) Timeout(d time.Duration) *MergeQuery {
+	q.setTimeout(d)
+	return q
+}
+
 // Apply calls each function in fns, passing the MergeQuery as an argument.
 func (q *MergeQuery) Apply(fns ...func(*MergeQuery) *MergeQuery) *MergeQuery {
 	for _, fn := range fns {
@@ -234,7 +242,7 @@ func (q *MergeQuery) scanOrExec(
 	}
 
 	// Generate the query before checking hasReturning.
-	queryBytes, err := q.AppendQuery(q.db.fmter, q.db.makeQueryBytes())
+	queryBytes, err := q.AppendQuery(q.db.formatter(ctx), q.db.makeQueryBytes())
 	if err != nil {
 		return nil, err
 	}
@@ -265,6 +273,10 @@ func (q *MergeQuery) scanOrExec(
 		}
 	}
 
+	if err := q.invalidateEntities(ctx, false); err != nil {
+		return nil, err
+	}
+
 	return res, nil
 }
 
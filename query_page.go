@@ -0,0 +1,66 @@
+package bun
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Page selects a page of rows, see SelectQuery.Paginate.
+type Page struct {
+	// Number is the 1-based page number. Numbers below 1 select the first page.
+	Number int
+	// Size is the maximum number of rows on the page.
+	Size int
+	// Concurrent runs the page and count queries concurrently. Queries that use
+	// a transaction or a connection always run one by one.
+	Concurrent bool
+}
+
+// PageResult describes the selected page, see SelectQuery.Paginate.
+type PageResult struct {
+	// Number is the 1-based page number.
+	Number int
+	// Size is the maximum number of rows on the page.
+	Size int
+	// Total is the number of rows on all pages.
+	Total int
+	// Pages is the number of pages.
+	Pages int
+	// HasNext reports whether there are pages after this page.
+	HasNext bool
+}
+
+// Paginate scans the page of rows into dest and counts the rows on all pages
+// with a count query without ORDER BY, LIMIT, and OFFSET. It sets the limit
+// and the offset of the query.
+func (q *SelectQuery) Paginate(ctx context.Context, dest interface{}, page Page) (PageResult, error) {
+	if page.Size <= 0 {
+		return PageResult{}, errors.New("bun: Paginate requires a positive page size")
+	}
+	if page.Number < 1 {
+		page.Number = 1
+	}
+
+	q.Limit(page.Size).Offset((page.Number - 1) * page.Size)
+
+	var total int
+	var err error
+	if _, ok := q.resolveConn(ctx).(*sql.DB); ok && page.Concurrent {
+		total, err = q.scanAndCountConc(ctx, dest)
+	} else {
+		total, err = q.scanAndCountSeq(ctx, dest)
+	}
+	if err != nil {
+		return PageResult{}, err
+	}
+
+	res := PageResult{
+		Number: page.Number,
+		Size:   page.Size,
+		Total:  total,
+		Pages:  (total + page.Size - 1) / page.Size,
+	}
+	res.HasNext = res.Number < res.Pages
+	return res, nil
+}
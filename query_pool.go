@@ -0,0 +1,110 @@
+package bun
+
+import (
+	"reflect"
+	"sync"
+)
+
+// maxPooledSliceCap limits the capacity of slices that are kept by released queries,
+// so a single large query does not pin a large backing array in the pool.
+const maxPooledSliceCap = 64
+
+var (
+	selectQueryPool = sync.Pool{New: func() interface{} { return new(SelectQuery) }}
+	insertQueryPool = sync.Pool{New: func() interface{} { return new(InsertQuery) }}
+	updateQueryPool = sync.Pool{New: func() interface{} { return new(UpdateQuery) }}
+	deleteQueryPool = sync.Pool{New: func() interface{} { return new(DeleteQuery) }}
+)
+
+// Release resets the query and returns it to the pool, so the query and its slices
+// are reused by the next NewSelect instead of being allocated again.
+// Releasing queries is optional. The query must not be used after Release,
+// including as a subquery of another query.
+func (q *SelectQuery) Release() {
+	*q = SelectQuery{
+		whereBaseQuery: q.whereBaseQuery.reset(),
+		orderLimitOffsetQuery: orderLimitOffsetQuery{
+			order: resetSlice(q.order),
+		},
+		joins:  resetSlice(q.joins),
+		group:  resetSlice(q.group),
+		having: resetSlice(q.having),
+		union:  resetSlice(q.union),
+	}
+	selectQueryPool.Put(q)
+}
+
+// Release resets the query and returns it to the pool. See SelectQuery.Release.
+func (q *InsertQuery) Release() {
+	*q = InsertQuery{
+		whereBaseQuery: q.whereBaseQuery.reset(),
+		returningQuery: q.returningQuery.reset(),
+		customValueQuery: customValueQuery{
+			extraValues: resetSlice(q.extraValues),
+		},
+		setQuery: setQuery{
+			set: resetSlice(q.set),
+		},
+	}
+	insertQueryPool.Put(q)
+}
+
+// Release resets the query and returns it to the pool. See SelectQuery.Release.
+func (q *UpdateQuery) Release() {
+	*q = UpdateQuery{
+		whereBaseQuery: q.whereBaseQuery.reset(),
+		orderLimitOffsetQuery: orderLimitOffsetQuery{
+			order: resetSlice(q.order),
+		},
+		returningQuery: q.returningQuery.reset(),
+		customValueQuery: customValueQuery{
+			extraValues: resetSlice(q.extraValues),
+		},
+		setQuery: setQuery{
+			set: resetSlice(q.set),
+		},
+		joins:    resetSlice(q.joins),
+		original: reflect.Value{},
+	}
+	updateQueryPool.Put(q)
+}
+
+// Release resets the query and returns it to the pool. See SelectQuery.Release.
+func (q *DeleteQuery) Release() {
+	*q = DeleteQuery{
+		whereBaseQuery: q.whereBaseQuery.reset(),
+		orderLimitOffsetQuery: orderLimitOffsetQuery{
+			order: resetSlice(q.order),
+		},
+		returningQuery: q.returningQuery.reset(),
+	}
+	deleteQueryPool.Put(q)
+}
+
+// reset returns an empty query that reuses the slices of q.
+// Slices where nil has a special meaning, e.g. columns, are not reused.
+func (q *whereBaseQuery) reset() whereBaseQuery {
+	return whereBaseQuery{
+		baseQuery: baseQuery{
+			with:   resetSlice(q.with),
+			tables: resetSlice(q.tables),
+		},
+		where: resetSlice(q.where),
+	}
+}
+
+func (q *returningQuery) reset() returningQuery {
+	return returningQuery{
+		returning:       resetSlice(q.returning),
+		returningFields: resetSlice(q.returningFields),
+	}
+}
+
+// resetSlice clears the slice so it does not retain references and returns it with zero length.
+func resetSlice[T any](s []T) []T {
+	if cap(s) > maxPooledSliceCap {
+		return nil
+	}
+	clear(s)
+	return s[:0]
+}
@@ -3,6 +3,7 @@ package bun
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/uptrace/bun/schema"
 )
@@ -47,6 +48,13 @@ func (q *RawQuery) Err(err error) *RawQuery {
 	return q
 }
 
+// Timeout sets the query timeout overriding the DB default set with WithQueryTimeout.
+// The timeout is applied as a context deadline. Zero disables the timeout.
+func (q *RawQuery) Timeout(d time.Duration) *RawQuery {
+	q.setTimeout(d)
+	return q
+}
+
 func (q *RawQuery) Exec(ctx context.Context, dest ...interface{}) (sql.Result, error) {
 	return q.scanOrExec(ctx, dest, len(dest) > 0)
 }
@@ -73,7 +81,7 @@ func (q *RawQuery) scanOrExec(
 		}
 	}
 
-	query := q.db.format(q.query, q.args)
+	query := q.db.format(ctx, q.query, q.args)
 	var res sql.Result
 
 	if hasDest {
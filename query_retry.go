@@ -0,0 +1,134 @@
+package bun
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// QueryRetryPolicy configures retries of queries that fail with transient
+// connection errors, see WithQueryRetryPolicy.
+type QueryRetryPolicy struct {
+	// MaxRetries is the maximum number of retries. Default is 3.
+	MaxRetries int
+	// MinBackoff is the backoff before the first retry. It is doubled on every retry.
+	// Default is 100ms.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum backoff between retries. Default is 3s.
+	MaxBackoff time.Duration
+	// IsRetryable reports whether the query should be retried after the error.
+	// It can be used to classify dialect-specific error codes.
+	// Default is IsTransientConnError.
+	IsRetryable func(err error) bool
+	// IsIdempotent reports whether the query can be safely executed several times.
+	// By default only SELECT queries are retried. Raw queries are classified
+	// by the leading keyword of the formatted SQL, because RawQuery.Operation
+	// always reports SELECT.
+	IsIdempotent func(query Query) bool
+}
+
+var defaultQueryRetryPolicy = QueryRetryPolicy{
+	MaxRetries:  3,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  3 * time.Second,
+	IsRetryable: IsTransientConnError,
+}
+
+// WithQueryRetryPolicy enables retries with exponential backoff of idempotent queries
+// that fail with transient connection errors, e.g. when the connection is reset
+// or the database fails over to a replica. Zero fields use the default values.
+//
+// Queries executed in transactions or on a dedicated connection are never retried,
+// because the transaction state is lost with the connection.
+func WithQueryRetryPolicy(policy QueryRetryPolicy) DBOption {
+	return func(db *DB) {
+		if policy.MaxRetries == 0 {
+			policy.MaxRetries = defaultQueryRetryPolicy.MaxRetries
+		}
+		if policy.MinBackoff == 0 {
+			policy.MinBackoff = defaultQueryRetryPolicy.MinBackoff
+		}
+		if policy.MaxBackoff == 0 {
+			policy.MaxBackoff = defaultQueryRetryPolicy.MaxBackoff
+		}
+		if policy.IsRetryable == nil {
+			policy.IsRetryable = defaultQueryRetryPolicy.IsRetryable
+		}
+		db.queryRetry = &policy
+	}
+}
+
+// isIdempotent reports whether the query can be retried according to the policy.
+func (policy *QueryRetryPolicy) isIdempotent(iquery Query, query string) bool {
+	if policy.IsIdempotent != nil {
+		return policy.IsIdempotent(iquery)
+	}
+	return operationOf(iquery, query) == "SELECT"
+}
+
+// retryQuery calls fn until it succeeds or returns an error that can't be retried.
+func (db *DB) retryQuery(
+	ctx context.Context, conn IConn, iquery Query, query string, fn func() error,
+) error {
+	policy := db.queryRetry
+	if policy == nil || conn != IConn(db.DB) || iquery == nil || !policy.isIdempotent(iquery, query) {
+		return fn()
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= policy.MaxRetries || !policy.IsRetryable(err) {
+			return err
+		}
+
+		if err := sleep(ctx, retryBackoff(attempt, policy.MinBackoff, policy.MaxBackoff)); err != nil {
+			return err
+		}
+	}
+}
+
+// IsTransientConnError reports whether the error is caused by a broken connection
+// or a database that is temporarily unavailable, for example:
+//
+//   - driver.ErrBadConn, io.ErrUnexpectedEOF, connection reset and refused errors;
+//   - network timeouts;
+//   - PostgreSQL SQLSTATE class 08 (connection exception), 57P01-57P03 (shutdown);
+//   - MySQL errors 1053 (server shutdown), 2006 (server has gone away),
+//     and 2013 (lost connection).
+func IsTransientConnError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		switch state := stateErr.SQLState(); {
+		case strings.HasPrefix(state, "08"),
+			state == "57P01", state == "57P02", state == "57P03":
+			return true
+		}
+	}
+
+	// Fall back to error messages for drivers without typed errors,
+	// e.g. github.com/go-sql-driver/mysql.
+	msg := err.Error()
+	return strings.HasPrefix(msg, "Error 1053") ||
+		strings.HasPrefix(msg, "Error 2006") ||
+		strings.HasPrefix(msg, "Error 2013") ||
+		strings.Contains(msg, "invalid connection")
+}
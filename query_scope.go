@@ -0,0 +1,55 @@
+package bun
+
+import (
+	"reflect"
+
+	"github.com/uptrace/bun/schema"
+)
+
+// WithDefaultScope adds the condition to every SELECT, UPDATE, and DELETE query
+// for the model, like soft delete does for deleted rows, for example:
+//
+//	db := bun.NewDB(sqldb, dialect,
+//		bun.WithContextArg("current_tenant", currentTenant),
+//		bun.WithDefaultScope((*Order)(nil), "?TableAlias.tenant_id = ?current_tenant"),
+//		bun.WithDefaultScope((*Order)(nil), "?TableAlias.status != 'archived'"))
+//
+// The condition is combined with the other conditions using AND. It is not applied
+// to tables joined with has-one and belongs-to relations. Use Unscoped to skip it.
+func WithDefaultScope(model interface{}, query string, args ...interface{}) DBOption {
+	typ := reflect.TypeOf(model)
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return func(db *DB) {
+		if db.scopes == nil {
+			db.scopes = make(map[reflect.Type][]schema.QueryWithArgs)
+		}
+		db.scopes[typ] = append(db.scopes[typ], schema.SafeQuery(query, args))
+	}
+}
+
+// defaultScopes returns the conditions added with WithDefaultScope that apply to the query.
+func (q *baseQuery) defaultScopes() []schema.QueryWithArgs {
+	if q.table == nil || q.db == nil || q.flags.Has(unscopedFlag) {
+		return nil
+	}
+	return q.db.scopes[q.table.Type]
+}
+
+func appendDefaultScopes(
+	fmter schema.Formatter, b []byte, scopes []schema.QueryWithArgs,
+) (_ []byte, err error) {
+	for i := range scopes {
+		if i > 0 {
+			b = append(b, " AND "...)
+		}
+		b = append(b, '(')
+		b, err = scopes[i].AppendQuery(fmter, b)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, ')')
+	}
+	return b, nil
+}
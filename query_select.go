@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/uptrace/bun/dialect"
 
@@ -30,21 +32,22 @@ type SelectQuery struct {
 	group      []schema.QueryWithArgs
 	having     []schema.QueryWithArgs
 	selFor     schema.QueryWithArgs
+	asOf       schema.QueryWithArgs
+	sysTime    schema.QueryWithArgs
 
 	union []union
+
+	// relationSem limits the number of relation queries that run concurrently.
+	relationSem chan struct{}
 }
 
 var _ Query = (*SelectQuery)(nil)
 
 func NewSelectQuery(db *DB) *SelectQuery {
-	return &SelectQuery{
-		whereBaseQuery: whereBaseQuery{
-			baseQuery: baseQuery{
-				db:   db,
-				conn: db.DB,
-			},
-		},
-	}
+	q := selectQueryPool.Get().(*SelectQuery)
+	q.db = db
+	q.conn = db.DB
+	return q
 }
 
 func (q *SelectQuery) Conn(db IConn) *SelectQuery {
@@ -62,6 +65,27 @@ func (q *SelectQuery) Err(err error) *SelectQuery {
 	return q
 }
 
+// SkipHooks disables model hooks for the query, e.g. BeforeSelect and AfterSelect,
+// including hooks registered with DB.RegisterModelHook.
+func (q *SelectQuery) SkipHooks() *SelectQuery {
+	q.skipAllHooks()
+	return q
+}
+
+// SkipHook disables the model hooks of the given types for the query.
+func (q *SelectQuery) SkipHook(hooks ...ModelHookType) *SelectQuery {
+	q.skipHooks(hooks...)
+	return q
+}
+
+// Timeout sets the query timeout overriding the DB default set with WithQueryTimeout.
+// The timeout is applied as a context deadline and, with MySQL, as a MAX_EXECUTION_TIME
+// optimizer hint of the executed query, see WithQueryTimeout. Zero disables the timeout.
+func (q *SelectQuery) Timeout(d time.Duration) *SelectQuery {
+	q.setTimeout(d)
+	return q
+}
+
 // Apply calls each function in fns, passing the SelectQuery as an argument.
 func (q *SelectQuery) Apply(fns ...func(*SelectQuery) *SelectQuery) *SelectQuery {
 	for _, fn := range fns {
@@ -82,6 +106,18 @@ func (q *SelectQuery) WithRecursive(name string, query schema.QueryAppender) *Se
 	return q
 }
 
+// StrictScan is like bun.WithStrictScan, but only for this query.
+func (q *SelectQuery) StrictScan() *SelectQuery {
+	q.scanFlags = q.scanFlags.Set(strictScan)
+	return q
+}
+
+// StrictNullScan is like bun.WithStrictNullScan, but only for this query.
+func (q *SelectQuery) StrictNullScan() *SelectQuery {
+	q.scanFlags = q.scanFlags.Set(strictNullScan)
+	return q
+}
+
 func (q *SelectQuery) Distinct() *SelectQuery {
 	q.distinctOn = make([]schema.QueryWithArgs, 0)
 	return q
@@ -132,6 +168,10 @@ func (q *SelectQuery) ExcludeColumn(columns ...string) *SelectQuery {
 
 //------------------------------------------------------------------------------
 
+// WherePK adds a condition on the primary keys of the model, or on the columns if they
+// are specified, e.g. WherePK("email") to look up a row by a unique key. For slice models
+// it selects all rows in the slice with a single IN clause, e.g. (id, tenant_id) IN (...)
+// for composite keys, and skips duplicate keys.
 func (q *SelectQuery) WherePK(cols ...string) *SelectQuery {
 	q.addWhereCols(cols)
 	return q
@@ -147,6 +187,24 @@ func (q *SelectQuery) WhereOr(query string, args ...interface{}) *SelectQuery {
 	return q
 }
 
+// WhereIn adds a condition with the slice as an IN list, for example:
+//
+//	q.WhereIn("id IN (?)", ids)
+//
+// Unlike Where with bun.In, an empty slice makes the condition always false (1 = 0)
+// instead of producing invalid SQL.
+func (q *SelectQuery) WhereIn(query string, slice interface{}) *SelectQuery {
+	q.addWhereIn(query, slice, false)
+	return q
+}
+
+// WhereNotIn is like WhereIn, but the condition is always true (1 = 1)
+// if the slice is empty, e.g. WhereNotIn("id NOT IN (?)", ids).
+func (q *SelectQuery) WhereNotIn(query string, slice interface{}) *SelectQuery {
+	q.addWhereIn(query, slice, true)
+	return q
+}
+
 func (q *SelectQuery) WhereGroup(sep string, fn func(*SelectQuery) *SelectQuery) *SelectQuery {
 	saved := q.where
 	q.where = nil
@@ -171,6 +229,12 @@ func (q *SelectQuery) WhereAllWithDeleted() *SelectQuery {
 	return q
 }
 
+// Unscoped skips the default scopes of the model, see WithDefaultScope.
+func (q *SelectQuery) Unscoped() *SelectQuery {
+	q.flags = q.flags.Set(unscopedFlag)
+	return q
+}
+
 //------------------------------------------------------------------------------
 
 func (q *SelectQuery) UseIndex(indexes ...string) *SelectQuery {
@@ -301,6 +365,53 @@ func (q *SelectQuery) For(s string, args ...interface{}) *SelectQuery {
 	return q
 }
 
+// AsOfSystemTime adds the CockroachDB AS OF SYSTEM TIME clause, which reads historical data
+// without conflicting with writes, for example:
+//
+//	q.AsOfSystemTime("?", "-10s")
+//	q.AsOfSystemTime("follower_read_timestamp()")
+func (q *SelectQuery) AsOfSystemTime(query string, args ...interface{}) *SelectQuery {
+	q.asOf = schema.SafeQuery(query, args)
+	return q
+}
+
+// ForSystemTime adds the FOR SYSTEM_TIME clause to the model table of a system-versioned
+// table in MariaDB and SQL Server, for example:
+//
+//	q.ForSystemTime("AS OF TIMESTAMP ?", tm) // MariaDB
+//	q.ForSystemTime("BETWEEN ? AND ?", start, end) // SQL Server
+//	q.ForSystemTime("ALL")
+//
+// See AsOf for a portable API and feature.SystemVersioning.
+func (q *SelectQuery) ForSystemTime(query string, args ...interface{}) *SelectQuery {
+	q.sysTime = schema.SafeQuery(query, args)
+	return q
+}
+
+// AsOf selects the rows of the model table as they were at the time. It uses
+// FOR SYSTEM_TIME AS OF with system-versioned tables in MariaDB and SQL Server,
+// and AS OF SYSTEM TIME with CockroachDB. Other dialects make the query fail with an error.
+func (q *SelectQuery) AsOf(tm time.Time) *SelectQuery {
+	switch {
+	case q.db.HasFeature(feature.SystemVersioning):
+		if q.db.dialect.Name() == dialect.MySQL {
+			return q.ForSystemTime("AS OF TIMESTAMP ?", tm)
+		}
+		// SQL Server stores the period columns in UTC.
+		return q.ForSystemTime("AS OF ?", tm.UTC())
+	case isCockroachDB(q.db.dialect):
+		return q.AsOfSystemTime("?", tm)
+	default:
+		q.setErr(fmt.Errorf("bun: AsOf is not supported by %s", q.db.dialect.Name()))
+		return q
+	}
+}
+
+func isCockroachDB(d schema.Dialect) bool {
+	crdb, ok := d.(interface{ IsCockroachDB() bool })
+	return ok && crdb.IsCockroachDB()
+}
+
 //------------------------------------------------------------------------------
 
 func (q *SelectQuery) Union(other *SelectQuery) *SelectQuery {
@@ -364,8 +475,26 @@ func (q *SelectQuery) joinOn(cond string, args []interface{}, sep string) *Selec
 
 //------------------------------------------------------------------------------
 
-// Relation adds a relation to the query.
+// Relation adds a relation to the query. Has-one and belongs-to relations are joined
+// with LEFT JOIN unless the join_type:inner tag option is used.
 func (q *SelectQuery) Relation(name string, apply ...func(*SelectQuery) *SelectQuery) *SelectQuery {
+	return q.relation(name, "", apply)
+}
+
+// RelationWithJoinType is like Relation, but joins a has-one or belongs-to relation
+// with the join type, overriding the join_type tag option, e.g. InnerJoin
+// filters out rows without the related row:
+//
+//	db.NewSelect().Model(&users).RelationWithJoinType("Profile", bun.InnerJoin)
+func (q *SelectQuery) RelationWithJoinType(
+	name string, joinType JoinType, apply ...func(*SelectQuery) *SelectQuery,
+) *SelectQuery {
+	return q.relation(name, joinType, apply)
+}
+
+func (q *SelectQuery) relation(
+	name string, joinType JoinType, apply []func(*SelectQuery) *SelectQuery,
+) *SelectQuery {
 	if len(apply) > 1 {
 		panic("only one apply function is supported")
 	}
@@ -380,6 +509,9 @@ func (q *SelectQuery) Relation(name string, apply ...func(*SelectQuery) *SelectQ
 		q.setErr(fmt.Errorf("%s does not have relation=%q", q.table, name))
 		return q
 	}
+	if joinType != "" {
+		join.joinType = joinType
+	}
 
 	var apply1, apply2 func(*SelectQuery) *SelectQuery
 
@@ -434,28 +566,75 @@ func (q *SelectQuery) _forEachInlineRelJoin(fn func(*relationJoin) error, joins
 	return nil
 }
 
+// selectRelations selects has-many and many-to-many relations of the scanned models.
+// With WithRelationParallelism, relation queries run concurrently using the connection pool.
+func (q *SelectQuery) selectRelations(ctx context.Context, joins []relationJoin) error {
+	if n := q.db.relationParallelism; n > 1 {
+		// Transactions and connections can't run queries concurrently.
+		if _, ok := q.resolveConn(ctx).(*sql.DB); ok {
+			q.relationSem = make(chan struct{}, n)
+			defer func() {
+				q.relationSem = nil
+			}()
+		}
+	}
+	return q.selectJoins(ctx, joins)
+}
+
 func (q *SelectQuery) selectJoins(ctx context.Context, joins []relationJoin) error {
+	if q.relationSem != nil && len(joins) > 1 {
+		return q.selectJoinsParallel(ctx, joins)
+	}
+
+	for i := range joins {
+		if err := q.selectJoin(ctx, &joins[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectJoinsParallel selects independent relations concurrently and returns the first error.
+// Nested relations of a join are selected by the goroutine of the join after the join itself.
+func (q *SelectQuery) selectJoinsParallel(ctx context.Context, joins []relationJoin) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
 	for i := range joins {
 		j := &joins[i]
 
-		var err error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-		switch j.Relation.Type {
-		case schema.HasOneRelation, schema.BelongsToRelation:
-			err = q.selectJoins(ctx, j.JoinModel.getJoins())
-		case schema.HasManyRelation:
-			err = j.selectMany(ctx, q.db.NewSelect().Conn(q.conn))
-		case schema.ManyToManyRelation:
-			err = j.selectM2M(ctx, q.db.NewSelect().Conn(q.conn))
-		default:
-			panic("not reached")
-		}
+			if err := q.selectJoin(ctx, j); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
 
-		if err != nil {
-			return err
-		}
+	wg.Wait()
+	return firstErr
+}
+
+func (q *SelectQuery) selectJoin(ctx context.Context, j *relationJoin) error {
+	switch j.Relation.Type {
+	case schema.HasOneRelation, schema.BelongsToRelation:
+		return q.selectJoins(ctx, j.JoinModel.getJoins())
+	case schema.HasManyRelation:
+		return j.selectMany(ctx, q)
+	case schema.ManyToManyRelation:
+		return j.selectM2M(ctx, q)
+	default:
+		panic("not reached")
 	}
-	return nil
 }
 
 //------------------------------------------------------------------------------
@@ -475,6 +654,21 @@ func (q *SelectQuery) appendQuery(
 		return nil, q.err
 	}
 
+	if c := q.db.queryCache; c != nil && !fmter.IsNop() && fmter.ArgBinder() == nil {
+		if b, ok := c.appendSelect(fmter, b, q, count); ok {
+			return b, nil
+		}
+	}
+	return q.appendQueryNoCache(fmter, b, count)
+}
+
+func (q *SelectQuery) appendQueryNoCache(
+	fmter schema.Formatter, b []byte, count bool,
+) (_ []byte, err error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+
 	fmter = formatterWithModel(fmter, q)
 
 	cteCount := count && (len(q.group) > 0 || q.distinctOn != nil)
@@ -550,6 +744,14 @@ func (q *SelectQuery) appendQuery(
 		}
 	}
 
+	if !q.asOf.IsZero() {
+		b = append(b, " AS OF SYSTEM TIME "...)
+		b, err = q.asOf.AppendQuery(fmter, b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	b, err = q.appendWhere(fmter, b, true)
 	if err != nil {
 		return nil, err
@@ -727,12 +929,22 @@ func (q *SelectQuery) appendInlineRelColumns(
 
 func (q *SelectQuery) appendTables(fmter schema.Formatter, b []byte) (_ []byte, err error) {
 	b = append(b, " FROM "...)
-	return q.appendTablesWithAlias(fmter, b)
+	if q.sysTime.IsZero() {
+		return q.appendTablesWithAlias(fmter, b)
+	}
+
+	if !fmter.HasFeature(feature.SystemVersioning) {
+		return nil, fmt.Errorf("bun: FOR SYSTEM_TIME is not supported by %s", fmter.Dialect().Name())
+	}
+	if !q.modelHasTableName() || !q.modelTableName.IsZero() {
+		return nil, errors.New("bun: ForSystemTime requires a model table without ModelTableExpr")
+	}
+	return q._appendTables(fmter, b, true, q.sysTime)
 }
 
 //------------------------------------------------------------------------------
 
-func (q *SelectQuery) Rows(ctx context.Context) (*sql.Rows, error) {
+func (q *SelectQuery) Rows(ctx context.Context) (_ *sql.Rows, err error) {
 	if q.err != nil {
 		return nil, q.err
 	}
@@ -741,16 +953,32 @@ func (q *SelectQuery) Rows(ctx context.Context) (*sql.Rows, error) {
 		return nil, err
 	}
 
-	queryBytes, err := q.AppendQuery(q.db.fmter, q.db.makeQueryBytes())
+	queryBytes, err := q.AppendQuery(q.db.formatter(ctx), q.db.makeQueryBytes())
 	if err != nil {
 		return nil, err
 	}
 
-	query := internal.String(queryBytes)
+	query := internal.String(q.addTimeoutHint(queryBytes))
+	if q.db.wrapsErrors() {
+		defer q.db.wrapQueryError(&err, q, query, time.Now())
+	}
+
+	// The rows are read after Rows returns, so the deadline also limits reading
+	// and the context is released when the timeout expires.
+	ctx, cancel := q.withTimeout(ctx)
 
 	ctx, event := q.db.beforeQuery(ctx, q, query, nil, query, q.model)
-	rows, err := q.conn.QueryContext(ctx, query)
+	conn := q.resolveConn(ctx)
+
+	var rows *sql.Rows
+	err = q.db.retryQuery(ctx, conn, q, query, func() (err error) {
+		rows, err = conn.QueryContext(ctx, query)
+		return err
+	})
 	q.db.afterQuery(ctx, event, nil, err)
+	if err != nil {
+		cancel()
+	}
 	return rows, err
 }
 
@@ -762,12 +990,12 @@ func (q *SelectQuery) Exec(ctx context.Context, dest ...interface{}) (res sql.Re
 		return nil, err
 	}
 
-	queryBytes, err := q.AppendQuery(q.db.fmter, q.db.makeQueryBytes())
+	queryBytes, err := q.AppendQuery(q.db.formatter(ctx), q.db.makeQueryBytes())
 	if err != nil {
 		return nil, err
 	}
 
-	query := internal.String(queryBytes)
+	query := internal.String(q.addTimeoutHint(queryBytes))
 
 	if len(dest) > 0 {
 		model, err := q.getModel(dest)
@@ -814,21 +1042,44 @@ func (q *SelectQuery) scanResult(ctx context.Context, dest ...interface{}) (sql.
 		return nil, err
 	}
 
-	queryBytes, err := q.AppendQuery(q.db.fmter, q.db.makeQueryBytes())
+	cacheKey, cached := q.entityCacheKey(ctx, dest)
+	if cached {
+		if ok, err := q.scanEntityCache(ctx, cacheKey); err != nil {
+			return nil, err
+		} else if ok {
+			if err := q.afterSelectHook(ctx); err != nil {
+				return nil, err
+			}
+			return driver.RowsAffected(1), nil
+		}
+		model = &entityCacheModel{structTableModel: model.(*structTableModel)}
+	}
+
+	sq, err := q.projectColumns(model)
+	if err != nil {
+		return nil, err
+	}
+
+	queryBytes, err := sq.AppendQuery(q.db.formatter(ctx), q.db.makeQueryBytes())
 	if err != nil {
 		return nil, err
 	}
 
-	query := internal.String(queryBytes)
+	query := internal.String(q.addTimeoutHint(queryBytes))
 
 	res, err := q.scan(ctx, q, query, model, true)
 	if err != nil {
 		return nil, err
 	}
 
-	if n, _ := res.RowsAffected(); n > 0 {
+	if m, ok := model.(*entityCacheModel); ok && m.cacheable {
+		// Cache errors are ignored, the row is selected again next time.
+		_ = q.db.entityCache.store(ctx, q.table, cacheKey, &m.row)
+	}
+
+	if n, _ := res.RowsAffected(); n > 0 && !q.flags.Has(skipRelationsFlag) {
 		if tableModel, ok := model.(TableModel); ok {
-			if err := q.selectJoins(ctx, tableModel.getJoins()); err != nil {
+			if err := q.selectRelations(ctx, tableModel.getJoins()); err != nil {
 				return nil, err
 			}
 		}
@@ -843,41 +1094,101 @@ func (q *SelectQuery) scanResult(ctx context.Context, dest ...interface{}) (sql.
 	return res, nil
 }
 
+// projectColumns returns a copy of the query that selects only the model columns
+// that the destination struct has, so the rows can be scanned into a smaller struct,
+// for example:
+//
+//	type UserSummary struct {
+//		ID   int64
+//		Name string
+//	}
+//
+//	var summaries []UserSummary
+//	err := db.NewSelect().Model((*User)(nil)).Scan(ctx, &summaries)
+//
+// The fields are matched by the column name. Queries with explicit columns are not changed.
+func (q *SelectQuery) projectColumns(model Model) (*SelectQuery, error) {
+	destModel, ok := model.(TableModel)
+	if !ok || q.table == nil || q.columns != nil || len(q.union) > 0 {
+		return q, nil
+	}
+
+	destTable := destModel.Table()
+	if destTable.Type == q.table.Type {
+		return q, nil
+	}
+	if q.tableModel != nil && len(q.tableModel.getJoins()) > 0 {
+		return nil, fmt.Errorf("bun: Scan(%s) does not support the relations of %s",
+			destTable.TypeName, q.table.TypeName)
+	}
+
+	columns := make([]schema.QueryWithArgs, 0, len(destTable.Fields))
+	for _, f := range q.table.Fields {
+		if _, ok := destTable.FieldMap[f.Name]; ok {
+			columns = append(columns, schema.UnsafeIdent(f.Name))
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("bun: %s does not have any columns of %s",
+			destTable.TypeName, q.table.TypeName)
+	}
+
+	cp := *q
+	cp.columns = columns
+	return &cp, nil
+}
+
 func (q *SelectQuery) beforeSelectHook(ctx context.Context) error {
+	if q.isHookSkipped(BeforeSelect) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(BeforeSelectHook); ok {
 		if err := hook.BeforeSelect(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, BeforeSelect, q.table, q)
 }
 
 func (q *SelectQuery) afterSelectHook(ctx context.Context) error {
+	if q.isHookSkipped(AfterSelect) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(AfterSelectHook); ok {
 		if err := hook.AfterSelect(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, AfterSelect, q.table, q)
 }
 
-func (q *SelectQuery) Count(ctx context.Context) (int, error) {
+func (q *SelectQuery) Count(ctx context.Context) (_ int, err error) {
 	if q.err != nil {
 		return 0, q.err
 	}
 
 	qq := countQuery{q}
 
-	queryBytes, err := qq.AppendQuery(q.db.fmter, nil)
+	queryBytes, err := qq.AppendQuery(q.db.formatter(ctx), nil)
 	if err != nil {
 		return 0, err
 	}
 
-	query := internal.String(queryBytes)
+	query := internal.String(q.addTimeoutHint(queryBytes))
+	if q.db.wrapsErrors() {
+		defer q.db.wrapQueryError(&err, qq, query, time.Now())
+	}
+
+	ctx, cancel := q.withTimeout(ctx)
+	defer cancel()
+
 	ctx, event := q.db.beforeQuery(ctx, qq, query, nil, query, q.model)
 
 	var num int
-	err = q.conn.QueryRowContext(ctx, query).Scan(&num)
+	conn := q.resolveConn(ctx)
+	err = q.db.retryQuery(ctx, conn, qq, query, func() error {
+		return conn.QueryRowContext(ctx, query).Scan(&num)
+	})
 
 	q.db.afterQuery(ctx, event, nil, err)
 
@@ -967,19 +1278,29 @@ func (q *SelectQuery) Exists(ctx context.Context) (bool, error) {
 	return q.whereExists(ctx)
 }
 
-func (q *SelectQuery) selectExists(ctx context.Context) (bool, error) {
+func (q *SelectQuery) selectExists(ctx context.Context) (_ bool, err error) {
 	qq := selectExistsQuery{q}
 
-	queryBytes, err := qq.AppendQuery(q.db.fmter, nil)
+	queryBytes, err := qq.AppendQuery(q.db.formatter(ctx), nil)
 	if err != nil {
 		return false, err
 	}
 
-	query := internal.String(queryBytes)
+	query := internal.String(q.addTimeoutHint(queryBytes))
+	if q.db.wrapsErrors() {
+		defer q.db.wrapQueryError(&err, qq, query, time.Now())
+	}
+
+	ctx, cancel := q.withTimeout(ctx)
+	defer cancel()
+
 	ctx, event := q.db.beforeQuery(ctx, qq, query, nil, query, q.model)
 
 	var exists bool
-	err = q.conn.QueryRowContext(ctx, query).Scan(&exists)
+	conn := q.resolveConn(ctx)
+	err = q.db.retryQuery(ctx, conn, qq, query, func() error {
+		return conn.QueryRowContext(ctx, query).Scan(&exists)
+	})
 
 	q.db.afterQuery(ctx, event, nil, err)
 
@@ -989,12 +1310,12 @@ func (q *SelectQuery) selectExists(ctx context.Context) (bool, error) {
 func (q *SelectQuery) whereExists(ctx context.Context) (bool, error) {
 	qq := whereExistsQuery{q}
 
-	queryBytes, err := qq.AppendQuery(q.db.fmter, nil)
+	queryBytes, err := qq.AppendQuery(q.db.formatter(ctx), nil)
 	if err != nil {
 		return false, err
 	}
 
-	query := internal.String(queryBytes)
+	query := internal.String(q.addTimeoutHint(queryBytes))
 	res, err := q.exec(ctx, qq, query)
 	if err != nil {
 		return false, err
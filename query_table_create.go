@@ -32,6 +32,10 @@ type CreateTableQuery struct {
 	fks         []schema.QueryWithArgs
 	partitionBy schema.QueryWithArgs
 	tablespace  schema.QueryWithArgs
+
+	// SQLite table options.
+	strict       bool
+	withoutRowID bool
 }
 
 var _ Query = (*CreateTableQuery)(nil)
@@ -62,6 +66,19 @@ func (q *CreateTableQuery) Err(err error) *CreateTableQuery {
 	return q
 }
 
+// SkipHooks disables model hooks for the query, e.g. BeforeCreateTable and AfterCreateTable,
+// including hooks registered with DB.RegisterModelHook.
+func (q *CreateTableQuery) SkipHooks() *CreateTableQuery {
+	q.skipAllHooks()
+	return q
+}
+
+// SkipHook disables the model hooks of the given types for the query.
+func (q *CreateTableQuery) SkipHook(hooks ...ModelHookType) *CreateTableQuery {
+	q.skipHooks(hooks...)
+	return q
+}
+
 // ------------------------------------------------------------------------------
 
 func (q *CreateTableQuery) Table(tables ...string) *CreateTableQuery {
@@ -123,6 +140,21 @@ func (q *CreateTableQuery) TableSpace(tablespace string) *CreateTableQuery {
 	return q
 }
 
+// Strict creates a SQLite STRICT table, which rejects values that don't match
+// the column types. Column types are converted to the types allowed in STRICT tables
+// using the SQLite type affinity rules, e.g. VARCHAR becomes TEXT.
+func (q *CreateTableQuery) Strict() *CreateTableQuery {
+	q.strict = true
+	return q
+}
+
+// WithoutRowID creates a SQLite WITHOUT ROWID table. Such tables must have a primary key
+// and don't support autoincrement columns.
+func (q *CreateTableQuery) WithoutRowID() *CreateTableQuery {
+	q.withoutRowID = true
+	return q
+}
+
 // WithForeignKeys adds a FOREIGN KEY clause for each of the model's existing relations.
 func (q *CreateTableQuery) WithForeignKeys() *CreateTableQuery {
 	q.fksFromRel = true
@@ -143,6 +175,13 @@ func (q *CreateTableQuery) AppendQuery(fmter schema.Formatter, b []byte) (_ []by
 		return nil, errNilModel
 	}
 
+	if err := q.checkTableOptions(); err != nil {
+		return nil, err
+	}
+	if err := q.checkSystemVersioning(fmter); err != nil {
+		return nil, err
+	}
+
 	b = append(b, "CREATE "...)
 	if q.temp {
 		b = append(b, "TEMP "...)
@@ -165,11 +204,28 @@ func (q *CreateTableQuery) AppendQuery(fmter schema.Formatter, b []byte) (_ []by
 
 		b = append(b, field.SQLName...)
 		b = append(b, " "...)
+		if field == q.table.RowStartField || field == q.table.RowEndField {
+			b = q.appendPeriodColumn(b, field)
+			continue
+		}
+		start := len(b)
 		b = q.appendSQLType(b, field)
+		if q.strict {
+			sqlType := strictSQLType(string(b[start:]))
+			b = append(b[:start], sqlType...)
+		}
+		if field.Collation != "" {
+			b = appendCollation(fmter, b, field.Collation)
+		}
 		if field.NotNull && q.db.dialect.Name() != dialect.Oracle {
 			b = append(b, " NOT NULL"...)
 		}
 
+		if expr, ok := field.GeneratedExpr(); ok {
+			b = q.appendGenerated(b, field, expr)
+			continue
+		}
+
 		if (field.Identity && fmter.HasFeature(feature.GeneratedIdentity)) ||
 			(field.AutoIncrement && (fmter.HasFeature(feature.AutoIncrement) || fmter.HasFeature(feature.Identity))) {
 			b = q.db.dialect.AppendSequence(b, q.table, field)
@@ -194,13 +250,28 @@ func (q *CreateTableQuery) AppendQuery(fmter schema.Formatter, b []byte) (_ []by
 		}
 	}
 
+	if q.table.RowStartField != nil {
+		b = append(b, ", PERIOD FOR SYSTEM_TIME ("...)
+		b = append(b, q.table.RowStartField.SQLName...)
+		b = append(b, ", "...)
+		b = append(b, q.table.RowEndField.SQLName...)
+		b = append(b, ")"...)
+	}
+
 	// In SQLite AUTOINCREMENT is only valid for INTEGER PRIMARY KEY columns, so it might be that
 	// a primary key constraint has already been created in dialect.AppendSequence() call above.
 	// See sqldialect.Dialect.AppendSequence() for more details.
 	if len(q.table.PKs) > 0 && !bytes.Contains(b, []byte("PRIMARY KEY")) {
 		b = q.appendPKConstraint(b, q.table.PKs)
 	}
-	b = q.appendUniqueConstraints(fmter, b)
+	b, err = q.appendUniqueConstraints(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = q.appendExclusionConstraints(fmter, b)
+	if err != nil {
+		return nil, err
+	}
 
 	if q.fksFromRel {
 		b, err = q.appendFKConstraintsRel(fmter, b)
@@ -215,6 +286,23 @@ func (q *CreateTableQuery) AppendQuery(fmter schema.Formatter, b []byte) (_ []by
 
 	b = append(b, ")"...)
 
+	if q.table.RowStartField != nil {
+		if q.db.dialect.Name() == dialect.MSSQL {
+			b = append(b, " WITH (SYSTEM_VERSIONING = ON)"...)
+		} else {
+			b = append(b, " WITH SYSTEM VERSIONING"...)
+		}
+	}
+
+	switch {
+	case q.strict && q.withoutRowID:
+		b = append(b, " STRICT, WITHOUT ROWID"...)
+	case q.strict:
+		b = append(b, " STRICT"...)
+	case q.withoutRowID:
+		b = append(b, " WITHOUT ROWID"...)
+	}
+
 	if !q.partitionBy.IsZero() {
 		b = append(b, " PARTITION BY "...)
 		b, err = q.partitionBy.AppendQuery(fmter, b)
@@ -235,6 +323,11 @@ func (q *CreateTableQuery) AppendQuery(fmter schema.Formatter, b []byte) (_ []by
 }
 
 func (q *CreateTableQuery) appendSQLType(b []byte, field *schema.Field) []byte {
+	if cfg := q.db.fmter.TimeConfig(); cfg != nil && cfg.SQLType != "" &&
+		field.IndirectType == timeType && !field.Tag.HasOption("type") {
+		return append(b, cfg.SQLType...)
+	}
+
 	// Most of the time these two will match, but for the cases where DiscoveredSQLType is dialect-specific,
 	// e.g. pgdialect would change sqltype.SmallInt to pgTypeSmallSerial for columns that have `bun:",autoincrement"`
 	if !strings.EqualFold(field.CreateTableSQLType, field.DiscoveredSQLType) {
@@ -258,7 +351,113 @@ func (q *CreateTableQuery) appendSQLType(b []byte, field *schema.Field) []byte {
 	return b
 }
 
-func (q *CreateTableQuery) appendUniqueConstraints(fmter schema.Formatter, b []byte) []byte {
+func (q *CreateTableQuery) checkTableOptions() error {
+	if !q.strict && !q.withoutRowID {
+		return nil
+	}
+	if name := q.db.dialect.Name(); name != dialect.SQLite {
+		return fmt.Errorf("bun: STRICT and WITHOUT ROWID tables are not supported by %s", name)
+	}
+	if q.withoutRowID {
+		if len(q.table.PKs) == 0 {
+			return fmt.Errorf("bun: WITHOUT ROWID table %s must have a primary key", q.table.Name)
+		}
+		for _, f := range q.table.PKs {
+			if f.AutoIncrement {
+				return fmt.Errorf("bun: WITHOUT ROWID table %s can't have autoincrement column %s",
+					q.table.Name, f.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// checkSystemVersioning checks the row_start and row_end period columns
+// of system-versioned tables.
+func (q *CreateTableQuery) checkSystemVersioning(fmter schema.Formatter) error {
+	if q.table.RowStartField == nil && q.table.RowEndField == nil {
+		return nil
+	}
+	if q.table.RowStartField == nil || q.table.RowEndField == nil {
+		return fmt.Errorf("bun: system-versioned table %s must have row_start and row_end columns",
+			q.table.Name)
+	}
+	if !fmter.HasFeature(feature.SystemVersioning) {
+		return fmt.Errorf("bun: system-versioned tables are not supported by %s",
+			fmter.Dialect().Name())
+	}
+	return nil
+}
+
+// appendPeriodColumn appends a row_start or row_end column, which is generated
+// by the database when the row is inserted, updated, or deleted.
+func (q *CreateTableQuery) appendPeriodColumn(b []byte, field *schema.Field) []byte {
+	switch name := q.db.dialect.Name(); {
+	case field.Tag.HasOption("type"):
+		b = q.appendSQLType(b, field)
+	case name == dialect.MySQL:
+		// MariaDB requires TIMESTAMP(6) or BIGINT UNSIGNED period columns.
+		b = append(b, "TIMESTAMP(6)"...)
+	case name == dialect.MSSQL:
+		b = append(b, "DATETIME2"...)
+	default:
+		b = q.appendSQLType(b, field)
+	}
+
+	if field == q.table.RowStartField {
+		b = append(b, " GENERATED ALWAYS AS ROW START"...)
+	} else {
+		b = append(b, " GENERATED ALWAYS AS ROW END"...)
+	}
+	if q.db.dialect.Name() == dialect.MSSQL {
+		b = append(b, " NOT NULL"...)
+	}
+	return b
+}
+
+// strictSQLType converts the column type to one of the types allowed in SQLite STRICT tables
+// using the type affinity rules, see https://www.sqlite.org/datatype3.html#determination_of_column_affinity.
+func strictSQLType(sqlType string) string {
+	typ := strings.ToUpper(sqlType)
+	switch {
+	case strings.Contains(typ, "INT"):
+		return "INTEGER"
+	case strings.Contains(typ, "CHAR"), strings.Contains(typ, "CLOB"), strings.Contains(typ, "TEXT"):
+		return "TEXT"
+	case strings.Contains(typ, "BLOB"), typ == "":
+		return "BLOB"
+	case strings.Contains(typ, "REAL"), strings.Contains(typ, "FLOA"), strings.Contains(typ, "DOUB"):
+		return "REAL"
+	case typ == "ANY":
+		return typ
+	case strings.Contains(typ, "BOOL"):
+		return "INTEGER"
+	default:
+		// NUMERIC affinity, e.g. TIMESTAMP or DECIMAL, has no STRICT equivalent.
+		return "ANY"
+	}
+}
+
+// appendGenerated appends the GENERATED ALWAYS AS clause. PostgreSQL only supports
+// STORED generated columns, other dialects default to VIRTUAL columns.
+func (q *CreateTableQuery) appendGenerated(b []byte, field *schema.Field, expr string) []byte {
+	b = append(b, " GENERATED ALWAYS AS "...)
+	if strings.HasPrefix(expr, "(") {
+		b = append(b, expr...)
+	} else {
+		b = append(b, '(')
+		b = append(b, expr...)
+		b = append(b, ')')
+	}
+	if field.Tag.HasOption("stored") || q.db.dialect.Name() == dialect.PG {
+		b = append(b, " STORED"...)
+	}
+	return b
+}
+
+func (q *CreateTableQuery) appendUniqueConstraints(
+	fmter schema.Formatter, b []byte,
+) (_ []byte, err error) {
 	unique := q.table.Unique
 
 	keys := make([]string, 0, len(unique))
@@ -270,34 +469,95 @@ func (q *CreateTableQuery) appendUniqueConstraints(fmter schema.Formatter, b []b
 	for _, key := range keys {
 		if key == "" {
 			for _, field := range unique[key] {
-				b = q.appendUniqueConstraint(fmter, b, key, field)
+				b, err = q.appendUniqueConstraint(fmter, b, key, field)
+				if err != nil {
+					return nil, err
+				}
 			}
 			continue
 		}
-		b = q.appendUniqueConstraint(fmter, b, key, unique[key]...)
+		b, err = q.appendUniqueConstraint(fmter, b, key, unique[key]...)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return b
+	return b, nil
 }
 
 func (q *CreateTableQuery) appendUniqueConstraint(
 	fmter schema.Formatter, b []byte, name string, fields ...*schema.Field,
-) []byte {
+) ([]byte, error) {
+	opts := q.table.UniqueOptions[name]
+	if opts == nil {
+		opts = new(schema.UniqueOptions)
+	}
+	if opts.Deferrable && !fmter.HasFeature(feature.DeferrableUnique) {
+		return nil, fmt.Errorf("bun: deferrable unique constraint %s is not supported by %s",
+			name, fmter.Dialect().Name())
+	}
+	if opts.NullsNotDistinct && !fmter.HasFeature(feature.NullsNotDistinct) {
+		return nil, fmt.Errorf("bun: UNIQUE NULLS NOT DISTINCT constraint %s is not supported by %s",
+			name, fmter.Dialect().Name())
+	}
+
 	if name != "" {
 		b = append(b, ", CONSTRAINT "...)
 		b = fmter.AppendIdent(b, name)
 	} else {
 		b = append(b, ","...)
 	}
-	b = append(b, " UNIQUE ("...)
+	b = append(b, " UNIQUE"...)
+	if opts.NullsNotDistinct {
+		b = append(b, " NULLS NOT DISTINCT"...)
+	}
+	b = append(b, " ("...)
 	b = appendColumns(b, "", fields)
 	b = append(b, ")"...)
-	return b
+
+	if opts.InitiallyDeferred {
+		b = append(b, " DEFERRABLE INITIALLY DEFERRED"...)
+	} else if opts.Deferrable {
+		b = append(b, " DEFERRABLE"...)
+	}
+	return b, nil
+}
+
+// appendExclusionConstraints appends the exclusion constraints declared with the exclude
+// table tag option. Note that indexing scalar columns with gist requires the btree_gist extension.
+func (q *CreateTableQuery) appendExclusionConstraints(
+	fmter schema.Formatter, b []byte,
+) ([]byte, error) {
+	if len(q.table.Exclusions) == 0 {
+		return b, nil
+	}
+	if !fmter.HasFeature(feature.ExclusionConstraint) {
+		return nil, fmt.Errorf("bun: exclusion constraints are not supported by %s",
+			fmter.Dialect().Name())
+	}
+
+	for _, ex := range q.table.Exclusions {
+		b = append(b, ", EXCLUDE USING "...)
+		b = append(b, ex.Using...)
+		b = append(b, " ("...)
+		b = append(b, ex.Elements...)
+		b = append(b, ")"...)
+	}
+	return b, nil
 }
 
 // appendFKConstraintsRel appends a FOREIGN KEY clause for each of the model's existing relations.
 func (q *CreateTableQuery) appendFKConstraintsRel(fmter schema.Formatter, b []byte) (_ []byte, err error) {
-	for _, rel := range q.tableModel.Table().Relations {
+	relations := q.tableModel.Table().Relations
+
+	names := make([]string, 0, len(relations))
+	for name := range relations {
+		names = append(names, name)
+	}
+	sort.Strings(names) // keep the order stable across runs
+
+	for _, name := range names {
+		rel := relations[name]
 		if rel.References() {
 			b, err = q.appendFK(fmter, b, schema.QueryWithArgs{
 				Query: "(?) REFERENCES ? (?) ? ?",
@@ -347,7 +607,7 @@ func (q *CreateTableQuery) Exec(ctx context.Context, dest ...interface{}) (sql.R
 		return nil, err
 	}
 
-	queryBytes, err := q.AppendQuery(q.db.fmter, q.db.makeQueryBytes())
+	queryBytes, err := q.AppendQuery(q.db.formatter(ctx), q.db.makeQueryBytes())
 	if err != nil {
 		return nil, err
 	}
@@ -369,21 +629,27 @@ func (q *CreateTableQuery) Exec(ctx context.Context, dest ...interface{}) (sql.R
 }
 
 func (q *CreateTableQuery) beforeCreateTableHook(ctx context.Context) error {
+	if q.isHookSkipped(BeforeCreateTable) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(BeforeCreateTableHook); ok {
 		if err := hook.BeforeCreateTable(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, BeforeCreateTable, q.table, q)
 }
 
 func (q *CreateTableQuery) afterCreateTableHook(ctx context.Context) error {
+	if q.isHookSkipped(AfterCreateTable) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(AfterCreateTableHook); ok {
 		if err := hook.AfterCreateTable(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, AfterCreateTable, q.table, q)
 }
 
 func (q *CreateTableQuery) String() string {
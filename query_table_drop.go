@@ -42,6 +42,19 @@ func (q *DropTableQuery) Err(err error) *DropTableQuery {
 	return q
 }
 
+// SkipHooks disables model hooks for the query, e.g. BeforeDropTable and AfterDropTable,
+// including hooks registered with DB.RegisterModelHook.
+func (q *DropTableQuery) SkipHooks() *DropTableQuery {
+	q.skipAllHooks()
+	return q
+}
+
+// SkipHook disables the model hooks of the given types for the query.
+func (q *DropTableQuery) SkipHook(hooks ...ModelHookType) *DropTableQuery {
+	q.skipHooks(hooks...)
+	return q
+}
+
 //------------------------------------------------------------------------------
 
 func (q *DropTableQuery) Table(tables ...string) *DropTableQuery {
@@ -113,7 +126,7 @@ func (q *DropTableQuery) Exec(ctx context.Context, dest ...interface{}) (sql.Res
 		}
 	}
 
-	queryBytes, err := q.AppendQuery(q.db.fmter, q.db.makeQueryBytes())
+	queryBytes, err := q.AppendQuery(q.db.formatter(ctx), q.db.makeQueryBytes())
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +138,10 @@ func (q *DropTableQuery) Exec(ctx context.Context, dest ...interface{}) (sql.Res
 		return nil, err
 	}
 
+	if err := q.invalidateEntities(ctx, false); err != nil {
+		return nil, err
+	}
+
 	if q.table != nil {
 		if err := q.afterDropTableHook(ctx); err != nil {
 			return nil, err
@@ -135,19 +152,25 @@ func (q *DropTableQuery) Exec(ctx context.Context, dest ...interface{}) (sql.Res
 }
 
 func (q *DropTableQuery) beforeDropTableHook(ctx context.Context) error {
+	if q.isHookSkipped(BeforeDropTable) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(BeforeDropTableHook); ok {
 		if err := hook.BeforeDropTable(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, BeforeDropTable, q.table, q)
 }
 
 func (q *DropTableQuery) afterDropTableHook(ctx context.Context) error {
+	if q.isHookSkipped(AfterDropTable) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(AfterDropTableHook); ok {
 		if err := hook.AfterDropTable(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, AfterDropTable, q.table, q)
 }
@@ -0,0 +1,311 @@
+package bun
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/sqltype"
+	"github.com/uptrace/bun/schema"
+)
+
+// TableFunc is a set-returning function used as a table source, see SelectQuery.TableFunc.
+type TableFunc struct {
+	fn      func(fmter schema.Formatter, b []byte, f *TableFunc) ([]byte, error)
+	alias   string
+	columns []string
+}
+
+var _ schema.QueryAppender = (*TableFunc)(nil)
+
+// TableFuncExpr returns a table function with custom SQL, for example:
+//
+//	bun.TableFuncExpr("jsonb_to_recordset(?)", data).As("t", "id int", "name text")
+func TableFuncExpr(query string, args ...interface{}) *TableFunc {
+	return &TableFunc{
+		alias: "t",
+		fn: func(fmter schema.Formatter, b []byte, f *TableFunc) ([]byte, error) {
+			b = fmter.AppendQuery(b, query, args...)
+			return f.appendAlias(fmter, b, false), nil
+		},
+	}
+}
+
+// Unnest returns a table function that expands the slices into rows, one column per slice,
+// so Go values can be joined or selected like a table:
+//
+//	db.NewSelect().
+//		TableFunc(bun.Unnest(ids, names).As("t", "id", "name")).
+//		Join("LEFT JOIN users AS u ON u.id = t.id")
+//
+// It uses unnest on Postgres, JSON_TABLE on MySQL, and json_each on SQLite.
+// The slices must have the same length.
+func Unnest(slices ...interface{}) *TableFunc {
+	return &TableFunc{
+		alias: "t",
+		fn: func(fmter schema.Formatter, b []byte, f *TableFunc) ([]byte, error) {
+			return f.appendUnnest(fmter, b, slices)
+		},
+	}
+}
+
+// GenerateSeries returns a table function with a single column that contains the values
+// from start to stop with the step, for example, a calendar of days:
+//
+//	bun.GenerateSeries(start, end, 24*time.Hour).As("t", "day")
+//
+// Times and durations are supported on Postgres. Other dialects support integers
+// with a positive step and use a recursive CTE, which is limited to 1000 rows on MySQL
+// by default (see cte_max_recursion_depth).
+func GenerateSeries(start, stop, step interface{}) *TableFunc {
+	return &TableFunc{
+		alias: "t",
+		fn: func(fmter schema.Formatter, b []byte, f *TableFunc) ([]byte, error) {
+			return f.appendGenerateSeries(fmter, b, start, stop, step)
+		},
+	}
+}
+
+// As sets the alias of the function and the names of its columns.
+// The default alias is "t".
+func (f *TableFunc) As(alias string, columns ...string) *TableFunc {
+	f.alias = alias
+	f.columns = columns
+	return f
+}
+
+func (f *TableFunc) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	return f.fn(fmter, b, f)
+}
+
+func (f *TableFunc) appendAlias(fmter schema.Formatter, b []byte, quoteColumns bool) []byte {
+	b = append(b, " AS "...)
+	b = fmter.AppendIdent(b, f.alias)
+	if len(f.columns) > 0 {
+		b = append(b, '(')
+		for i, col := range f.columns {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			if quoteColumns {
+				b = fmter.AppendIdent(b, col)
+			} else {
+				b = append(b, col...)
+			}
+		}
+		b = append(b, ')')
+	}
+	return b
+}
+
+func (f *TableFunc) appendUnnest(
+	fmter schema.Formatter, b []byte, slices []interface{},
+) (_ []byte, err error) {
+	if len(slices) == 0 {
+		return nil, errors.New("bun: Unnest requires at least one slice")
+	}
+	if len(f.columns) != len(slices) {
+		return nil, fmt.Errorf("bun: Unnest got %d slices and %d columns (see TableFunc.As)",
+			len(slices), len(f.columns))
+	}
+
+	values := make([]reflect.Value, len(slices))
+	for i, slice := range slices {
+		v := reflect.Indirect(reflect.ValueOf(slice))
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("bun: Unnest(unsupported %T)", slice)
+		}
+		if i > 0 && v.Len() != values[0].Len() {
+			return nil, errors.New("bun: Unnest requires slices of the same length")
+		}
+		values[i] = v
+	}
+
+	switch fmter.Dialect().Name() {
+	case dialect.PG:
+		b = append(b, "unnest("...)
+		for i, v := range values {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b = append(b, "ARRAY["...)
+			for j := 0; j < v.Len(); j++ {
+				if j > 0 {
+					b = append(b, ", "...)
+				}
+				b = fmter.AppendArg(b, v.Index(j).Interface())
+			}
+			b = append(b, ']')
+			if typ := unnestSQLType(fmter, v.Type().Elem()); typ != "" {
+				b = append(b, "::"...)
+				b = append(b, typ...)
+				b = append(b, "[]"...)
+			}
+		}
+		b = append(b, ')')
+		return f.appendAlias(fmter, b, true), nil
+	case dialect.MySQL:
+		b = append(b, "JSON_TABLE("...)
+		if b, err = appendUnnestJSON(fmter, b, values); err != nil {
+			return nil, err
+		}
+		b = append(b, ", '$[*]' COLUMNS ("...)
+		for i, col := range f.columns {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b = fmter.AppendIdent(b, col)
+			b = append(b, ' ')
+			if typ := unnestSQLType(fmter, values[i].Type().Elem()); typ != "" {
+				b = append(b, typ...)
+			} else {
+				b = append(b, sqltype.JSON...)
+			}
+			b = append(b, " PATH '$["...)
+			b = strconv.AppendInt(b, int64(i), 10)
+			b = append(b, "]'"...)
+		}
+		b = append(b, "))"...)
+		f := *f
+		f.columns = nil
+		return f.appendAlias(fmter, b, true), nil
+	case dialect.SQLite:
+		b = append(b, "(SELECT "...)
+		for i, col := range f.columns {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b = append(b, "json_extract(value, '$["...)
+			b = strconv.AppendInt(b, int64(i), 10)
+			b = append(b, "]') AS "...)
+			b = fmter.AppendIdent(b, col)
+		}
+		b = append(b, " FROM json_each("...)
+		if b, err = appendUnnestJSON(fmter, b, values); err != nil {
+			return nil, err
+		}
+		b = append(b, "))"...)
+		f := *f
+		f.columns = nil
+		return f.appendAlias(fmter, b, true), nil
+	default:
+		return nil, fmt.Errorf("bun: Unnest is not supported by %s", fmter.Dialect().Name())
+	}
+}
+
+// appendUnnestJSON appends the slices as a JSON array of rows, e.g. [[1,"a"],[2,"b"]].
+func appendUnnestJSON(fmter schema.Formatter, b []byte, values []reflect.Value) ([]byte, error) {
+	rows := make([][]interface{}, values[0].Len())
+	for i := range rows {
+		row := make([]interface{}, len(values))
+		for j, v := range values {
+			row[j] = v.Index(i).Interface()
+		}
+		rows[i] = row
+	}
+
+	js, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+	return fmter.Dialect().AppendString(b, string(js)), nil
+}
+
+func unnestSQLType(fmter schema.Formatter, typ reflect.Type) string {
+	switch typ := schema.DiscoverSQLType(typ); typ {
+	case "":
+		return ""
+	case sqltype.Timestamp:
+		if fmter.Dialect().Name() == dialect.PG {
+			return "TIMESTAMPTZ"
+		}
+		return typ
+	case sqltype.VarChar:
+		if n := fmter.Dialect().DefaultVarcharLen(); n > 0 {
+			return typ + "(" + strconv.Itoa(n) + ")"
+		}
+		return typ
+	default:
+		return typ
+	}
+}
+
+func (f *TableFunc) appendGenerateSeries(
+	fmter schema.Formatter, b []byte, start, stop, step interface{},
+) ([]byte, error) {
+	if len(f.columns) > 1 {
+		return nil, errors.New("bun: GenerateSeries returns a single column")
+	}
+	column := "value"
+	if len(f.columns) == 1 {
+		column = f.columns[0]
+	}
+
+	switch fmter.Dialect().Name() {
+	case dialect.PG:
+		b = append(b, "generate_series("...)
+		for i, arg := range []interface{}{start, stop, step} {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			switch arg := arg.(type) {
+			case time.Time:
+				b = fmter.AppendArg(b, arg)
+				b = append(b, "::TIMESTAMPTZ"...)
+			case time.Duration:
+				b = append(b, '\'')
+				b = strconv.AppendInt(b, arg.Microseconds(), 10)
+				b = append(b, " microseconds'::INTERVAL"...)
+			default:
+				b = fmter.AppendArg(b, arg)
+			}
+		}
+		b = append(b, ')')
+		f := *f
+		f.columns = []string{column}
+		return f.appendAlias(fmter, b, true), nil
+	case dialect.MySQL, dialect.SQLite:
+		for _, arg := range []interface{}{start, stop, step} {
+			switch reflect.ValueOf(arg).Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			default:
+				return nil, fmt.Errorf("bun: GenerateSeries(%T) is not supported by %s",
+					arg, fmter.Dialect().Name())
+			}
+		}
+		if v := reflect.ValueOf(step); (v.CanInt() && v.Int() <= 0) || (v.CanUint() && v.Uint() == 0) {
+			return nil, errors.New("bun: GenerateSeries requires a positive step")
+		}
+
+		b = append(b, "(WITH RECURSIVE series (v) AS (SELECT "...)
+		b = fmter.AppendArg(b, start)
+		b = append(b, " UNION ALL SELECT v + "...)
+		b = fmter.AppendArg(b, step)
+		b = append(b, " FROM series WHERE v + "...)
+		b = fmter.AppendArg(b, step)
+		b = append(b, " <= "...)
+		b = fmter.AppendArg(b, stop)
+		b = append(b, ") SELECT v AS "...)
+		b = fmter.AppendIdent(b, column)
+		b = append(b, " FROM series WHERE v <= "...)
+		b = fmter.AppendArg(b, stop)
+		b = append(b, ')')
+		f := *f
+		f.columns = nil
+		return f.appendAlias(fmter, b, true), nil
+	default:
+		return nil, fmt.Errorf("bun: GenerateSeries is not supported by %s", fmter.Dialect().Name())
+	}
+}
+
+// TableFunc adds the set-returning function to the FROM clause,
+// see Unnest, GenerateSeries, and TableFuncExpr.
+func (q *SelectQuery) TableFunc(fn *TableFunc) *SelectQuery {
+	q.addTable(schema.SafeQuery("?", []interface{}{fn}))
+	return q
+}
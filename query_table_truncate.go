@@ -126,7 +126,7 @@ func (q *TruncateTableQuery) AppendQuery(
 //------------------------------------------------------------------------------
 
 func (q *TruncateTableQuery) Exec(ctx context.Context, dest ...interface{}) (sql.Result, error) {
-	queryBytes, err := q.AppendQuery(q.db.fmter, q.db.makeQueryBytes())
+	queryBytes, err := q.AppendQuery(q.db.formatter(ctx), q.db.makeQueryBytes())
 	if err != nil {
 		return nil, err
 	}
@@ -138,5 +138,9 @@ func (q *TruncateTableQuery) Exec(ctx context.Context, dest ...interface{}) (sql
 		return nil, err
 	}
 
+	if err := q.invalidateEntities(ctx, false); err != nil {
+		return nil, err
+	}
+
 	return res, nil
 }
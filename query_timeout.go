@@ -0,0 +1,74 @@
+package bun
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/uptrace/bun/dialect"
+)
+
+// WithQueryTimeout sets the default timeout for queries executed by the DB.
+// It can be overridden per query with the Timeout method, for example,
+// q.Timeout(0) disables the timeout.
+//
+// The timeout is applied as a context deadline. With MySQL, SELECT queries also get
+// a MAX_EXECUTION_TIME optimizer hint, so the server aborts them too. PostgreSQL
+// drivers such as pgdriver and pgx cancel the query on the server when the deadline
+// is exceeded, but bun does not set statement_timeout; use WithSessionVar or
+// the connection options to set it.
+func WithQueryTimeout(d time.Duration) DBOption {
+	return func(db *DB) {
+		db.queryTimeout = d
+	}
+}
+
+// queryTimeout returns the timeout set with Timeout or the DB default.
+func (q *baseQuery) queryTimeout() time.Duration {
+	if q.timeout != nil {
+		return *q.timeout
+	}
+	return q.db.queryTimeout
+}
+
+func (q *baseQuery) setTimeout(d time.Duration) {
+	q.timeout = &d
+}
+
+// withTimeout returns a context with the query timeout. An earlier deadline
+// of the parent context is kept.
+func (q *baseQuery) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d := q.queryTimeout(); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return ctx, func() {}
+}
+
+// addTimeoutHint adds an optimizer hint that makes the server abort the query
+// after the timeout. Only MySQL supports such hints, and only for SELECT statements.
+// The hint applies to the whole statement and must follow the first SELECT keyword,
+// so it is added to the executed query and not to subqueries or INSERT ... SELECT.
+func (q *baseQuery) addTimeoutHint(query []byte) []byte {
+	d := q.queryTimeout()
+	if d <= 0 || q.db.dialect.Name() != dialect.MySQL {
+		return query
+	}
+	i := bytes.Index(query, []byte("SELECT "))
+	if i == -1 {
+		return query
+	}
+	i += len("SELECT ")
+
+	ms := d.Milliseconds()
+	if ms == 0 {
+		ms = 1 // 0 disables the limit
+	}
+	b := make([]byte, 0, len(query)+32)
+	b = append(b, query[:i]...)
+	b = append(b, "/*+ MAX_EXECUTION_TIME("...)
+	b = strconv.AppendInt(b, ms, 10)
+	b = append(b, ") */ "...)
+	b = append(b, query[i:]...)
+	return b
+}
@@ -0,0 +1,115 @@
+package bun
+
+import (
+	"strconv"
+
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// toSQL formats the query with placeholders in the dialect style and returns the args.
+func toSQL(db *DB, q schema.QueryAppender) (string, []interface{}, error) {
+	binder := schema.NewArgBinder(placeholderAppender(db.Dialect().Name()))
+	b, err := q.AppendQuery(db.Formatter().WithArgBinder(binder), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(b), binder.Args(), nil
+}
+
+func placeholderAppender(name dialect.Name) func(b []byte, n int) []byte {
+	switch name {
+	case dialect.PG:
+		return func(b []byte, n int) []byte {
+			b = append(b, '$')
+			return strconv.AppendInt(b, int64(n), 10)
+		}
+	case dialect.MSSQL:
+		return func(b []byte, n int) []byte {
+			b = append(b, "@p"...)
+			return strconv.AppendInt(b, int64(n), 10)
+		}
+	case dialect.Oracle:
+		return func(b []byte, n int) []byte {
+			b = append(b, ':')
+			return strconv.AppendInt(b, int64(n), 10)
+		}
+	default:
+		return func(b []byte, n int) []byte {
+			return append(b, '?')
+		}
+	}
+}
+
+// ToSQL returns the query with placeholders, e.g. $1 for PostgreSQL and ? for MySQL,
+// in place of args and model values, and the args in placeholder order. Values with
+// basic Go types, time.Time, and driver.Valuer are bound; other values, e.g. slices
+// and JSON, are appended to the query like String does.
+func (q *SelectQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *InsertQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *UpdateQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *DeleteQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *MergeQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *RawQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *ValuesQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *CreateTableQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *DropTableQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *TruncateTableQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *CreateIndexQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *DropIndexQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *AddColumnQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
+
+// ToSQL returns the query with placeholders and the args. See SelectQuery.ToSQL.
+func (q *DropColumnQuery) ToSQL() (string, []interface{}, error) {
+	return toSQL(q.db, q)
+}
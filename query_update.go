@@ -1,10 +1,13 @@
 package bun
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/uptrace/bun/dialect"
 
@@ -23,19 +26,16 @@ type UpdateQuery struct {
 
 	joins    []joinQuery
 	omitZero bool
+
+	original reflect.Value
 }
 
 var _ Query = (*UpdateQuery)(nil)
 
 func NewUpdateQuery(db *DB) *UpdateQuery {
-	q := &UpdateQuery{
-		whereBaseQuery: whereBaseQuery{
-			baseQuery: baseQuery{
-				db:   db,
-				conn: db.DB,
-			},
-		},
-	}
+	q := updateQueryPool.Get().(*UpdateQuery)
+	q.db = db
+	q.conn = db.DB
 	return q
 }
 
@@ -54,6 +54,26 @@ func (q *UpdateQuery) Err(err error) *UpdateQuery {
 	return q
 }
 
+// SkipHooks disables model hooks for the query, e.g. BeforeUpdate and AfterUpdate,
+// including hooks registered with DB.RegisterModelHook.
+func (q *UpdateQuery) SkipHooks() *UpdateQuery {
+	q.skipAllHooks()
+	return q
+}
+
+// SkipHook disables the model hooks of the given types for the query.
+func (q *UpdateQuery) SkipHook(hooks ...ModelHookType) *UpdateQuery {
+	q.skipHooks(hooks...)
+	return q
+}
+
+// Timeout sets the query timeout overriding the DB default set with WithQueryTimeout.
+// The timeout is applied as a context deadline. Zero disables the timeout.
+func (q *UpdateQuery) Timeout(d time.Duration) *UpdateQuery {
+	q.setTimeout(d)
+	return q
+}
+
 // Apply calls each function in fns, passing the UpdateQuery as an argument.
 func (q *UpdateQuery) Apply(fns ...func(*UpdateQuery) *UpdateQuery) *UpdateQuery {
 	for _, fn := range fns {
@@ -179,6 +199,20 @@ func (q *UpdateQuery) WhereOr(query string, args ...interface{}) *UpdateQuery {
 	return q
 }
 
+// WhereIn adds a condition with the slice as an IN list, which is always false (1 = 0)
+// if the slice is empty, see SelectQuery.WhereIn.
+func (q *UpdateQuery) WhereIn(query string, slice interface{}) *UpdateQuery {
+	q.addWhereIn(query, slice, false)
+	return q
+}
+
+// WhereNotIn is like WhereIn, but the condition is always true (1 = 1)
+// if the slice is empty.
+func (q *UpdateQuery) WhereNotIn(query string, slice interface{}) *UpdateQuery {
+	q.addWhereIn(query, slice, true)
+	return q
+}
+
 func (q *UpdateQuery) WhereGroup(sep string, fn func(*UpdateQuery) *UpdateQuery) *UpdateQuery {
 	saved := q.where
 	q.where = nil
@@ -203,6 +237,12 @@ func (q *UpdateQuery) WhereAllWithDeleted() *UpdateQuery {
 	return q
 }
 
+// Unscoped skips the default scopes of the model, see WithDefaultScope.
+func (q *UpdateQuery) Unscoped() *UpdateQuery {
+	q.flags = q.flags.Set(unscopedFlag)
+	return q
+}
+
 // ------------------------------------------------------------------------------
 func (q *UpdateQuery) Order(orders ...string) *UpdateQuery {
 	if !q.hasFeature(feature.UpdateOrderLimit) {
@@ -541,13 +581,17 @@ func (q *UpdateQuery) scanOrExec(
 		}
 	}
 
+	if err := q.validateModel(ctx); err != nil {
+		return nil, err
+	}
+
 	// Run append model hooks before generating the query.
 	if err := q.beforeAppendModel(ctx, q); err != nil {
 		return nil, err
 	}
 
 	// Generate the query before checking hasReturning.
-	queryBytes, err := q.AppendQuery(q.db.fmter, q.db.makeQueryBytes())
+	queryBytes, err := q.AppendQuery(q.db.formatter(ctx), q.db.makeQueryBytes())
 	if err != nil {
 		return nil, err
 	}
@@ -579,6 +623,10 @@ func (q *UpdateQuery) scanOrExec(
 		}
 	}
 
+	if err := q.invalidateEntities(ctx, q.isWherePK()); err != nil {
+		return nil, err
+	}
+
 	if q.table != nil {
 		if err := q.afterUpdateHook(ctx); err != nil {
 			return nil, err
@@ -589,23 +637,175 @@ func (q *UpdateQuery) scanOrExec(
 }
 
 func (q *UpdateQuery) beforeUpdateHook(ctx context.Context) error {
+	if q.isHookSkipped(BeforeUpdate) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(BeforeUpdateHook); ok {
 		if err := hook.BeforeUpdate(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, BeforeUpdate, q.table, q)
 }
 
 func (q *UpdateQuery) afterUpdateHook(ctx context.Context) error {
+	if q.isHookSkipped(AfterUpdate) {
+		return nil
+	}
 	if hook, ok := q.table.ZeroIface.(AfterUpdateHook); ok {
 		if err := hook.AfterUpdate(ctx, q); err != nil {
 			return err
 		}
 	}
-	return nil
+	return q.db.runModelHooks(ctx, AfterUpdate, q.table, q)
+}
+
+//------------------------------------------------------------------------------
+
+// FieldChange describes a column that is changed by an update query.
+type FieldChange struct {
+	Field *schema.Field
+	Old   interface{}
+	New   interface{}
+}
+
+// Original loads the persisted version of the model using the primary keys
+// and the query connection, for example, in a BeforeUpdate hook.
+// It returns a pointer to a struct for struct models and a pointer to a slice for slice models.
+// The rows of slice models are loaded with a single query and returned in the order of the slice.
+// The result is cached, so the rows are loaded at most once.
+func (q *UpdateQuery) Original(ctx context.Context) (interface{}, error) {
+	if q.original.IsValid() {
+		return q.original.Interface(), nil
+	}
+	if q.table == nil {
+		return nil, errNilModel
+	}
+	if len(q.table.PKs) == 0 {
+		return nil, fmt.Errorf("bun: Original requires a model with primary keys: %s", q.table)
+	}
+
+	var rows []reflect.Value
+	switch model := q.tableModel.(type) {
+	case *structTableModel:
+		if !model.strct.IsValid() {
+			return nil, fmt.Errorf("bun: Original requires a model value: %s", q.table)
+		}
+		rows = []reflect.Value{model.strct}
+	case *sliceTableModel:
+		for i := 0; i < model.slice.Len(); i++ {
+			rows = append(rows, reflect.Indirect(model.slice.Index(i)))
+		}
+	default:
+		return nil, fmt.Errorf("bun: Original does not support %T", q.tableModel)
+	}
+
+	db := q.GetIDB()
+	if tx, ok := q.txFromContext(ctx); ok {
+		db = tx
+	}
+	slice := reflect.New(reflect.SliceOf(reflect.PointerTo(q.table.Type)))
+	for _, strct := range rows {
+		original := reflect.New(q.table.Type)
+		for _, pk := range q.table.PKs {
+			pk.Value(original.Elem()).Set(pk.Value(strct))
+		}
+		slice.Elem().Set(reflect.Append(slice.Elem(), original))
+	}
+
+	if _, ok := q.tableModel.(*structTableModel); ok {
+		original := slice.Elem().Index(0)
+		if err := q.selectOriginal(ctx, db, original.Interface()); err != nil {
+			return nil, err
+		}
+		q.original = original
+		return q.original.Interface(), nil
+	}
+
+	if len(rows) > 0 {
+		if err := q.selectOriginal(ctx, db, slice.Interface()); err != nil {
+			return nil, err
+		}
+	}
+
+	// The rows are selected in an arbitrary order, so they are sorted by the primary keys.
+	byPK := make(map[string]reflect.Value, slice.Elem().Len())
+	for i := 0; i < slice.Elem().Len(); i++ {
+		original := slice.Elem().Index(i)
+		byPK[string(q.appendPKs(nil, original.Elem()))] = original
+	}
+
+	originals := reflect.MakeSlice(slice.Elem().Type(), 0, len(rows))
+	for _, strct := range rows {
+		original, ok := byPK[string(q.appendPKs(nil, strct))]
+		if !ok {
+			return nil, sql.ErrNoRows
+		}
+		originals = reflect.Append(originals, original)
+	}
+	slice.Elem().Set(originals)
+
+	q.original = slice
+	return q.original.Interface(), nil
+}
+
+func (q *UpdateQuery) selectOriginal(ctx context.Context, db IDB, model interface{}) error {
+	sq := db.NewSelect().Model(model).WherePK()
+	if q.table.SoftDeleteField != nil {
+		sq = sq.WhereAllWithDeleted()
+	}
+	return sq.Scan(ctx)
+}
+
+func (q *UpdateQuery) appendPKs(b []byte, strct reflect.Value) []byte {
+	for _, pk := range q.table.PKs {
+		b = pk.AppendValue(q.db.fmter, b, strct)
+		b = append(b, ',')
+	}
+	return b
 }
 
+// Changes compares the model with the persisted version returned by Original
+// and returns the updated columns with different values. Slice models are not supported.
+func (q *UpdateQuery) Changes(ctx context.Context) ([]FieldChange, error) {
+	model, ok := q.tableModel.(*structTableModel)
+	if !ok {
+		return nil, errors.New("bun: Changes requires a struct model")
+	}
+
+	original, err := q.Original(ctx)
+	if err != nil {
+		return nil, err
+	}
+	old := reflect.ValueOf(original).Elem()
+
+	fields, err := q.getDataFields()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	var oldValue, newValue []byte
+	for _, f := range fields {
+		// Compare the SQL representation to ignore differences that are not persisted,
+		// e.g. the location of time.Time values.
+		oldValue = f.AppendValue(q.db.fmter, oldValue[:0], old)
+		newValue = f.AppendValue(q.db.fmter, newValue[:0], model.strct)
+		if bytes.Equal(oldValue, newValue) {
+			continue
+		}
+
+		changes = append(changes, FieldChange{
+			Field: f,
+			Old:   f.Value(old).Interface(),
+			New:   f.Value(model.strct).Interface(),
+		})
+	}
+	return changes, nil
+}
+
+//------------------------------------------------------------------------------
+
 // FQN returns a fully qualified column name, for example, table_name.column_name or
 // table_alias.column_alias.
 func (q *UpdateQuery) FQN(column string) Ident {
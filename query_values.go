@@ -1,6 +1,7 @@
 package bun
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -9,6 +10,8 @@ import (
 	"github.com/uptrace/bun/schema"
 )
 
+var errEmptyValues = errors.New("bun: Values requires at least one row")
+
 type ValuesQuery struct {
 	baseQuery
 	customValueQuery
@@ -197,30 +200,152 @@ func (q *ValuesQuery) appendQuery(
 func (q *ValuesQuery) appendValues(
 	fmter schema.Formatter, b []byte, fields []*schema.Field, strct reflect.Value,
 ) (_ []byte, err error) {
-	isTemplate := fmter.IsNop()
 	for i, f := range fields {
 		if i > 0 {
 			b = append(b, ", "...)
 		}
+		b, err = q.appendValue(fmter, b, f, strct)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
 
-		app, ok := q.modelValues[f.Name]
-		if ok {
-			b, err = app.AppendQuery(fmter, b)
-			if err != nil {
-				return nil, err
+func (q *ValuesQuery) appendValue(
+	fmter schema.Formatter, b []byte, f *schema.Field, strct reflect.Value,
+) (_ []byte, err error) {
+	if app, ok := q.modelValues[f.Name]; ok {
+		return app.AppendQuery(fmter, b)
+	}
+
+	if fmter.IsNop() {
+		b = append(b, '?')
+	} else {
+		b = f.AppendValue(fmter, b, indirect(strct))
+	}
+
+	if fmter.HasFeature(feature.DoubleColonCast) {
+		b = append(b, "::"...)
+		b = append(b, f.UserSQLType...)
+	}
+	return b, nil
+}
+
+// AsTable returns the VALUES list as a table source with the alias and the column names,
+// so it can be used with TableExpr and Join, or as the FROM source of an UPDATE query:
+//
+//	db.NewSelect().
+//		TableExpr("?", db.NewValues(&rows).AsTable("v")).
+//		Join("JOIN users AS u ON u.id = v.id")
+//
+// Dialects that can't name the columns of VALUES lists, see feature.ValuesTable, use
+// an equivalent SELECT ... UNION ALL SELECT ... subquery instead.
+func (q *ValuesQuery) AsTable(alias string) schema.QueryAppender {
+	return &valuesTable{query: q, alias: alias}
+}
+
+type valuesTable struct {
+	query *ValuesQuery
+	alias string
+}
+
+func (t *valuesTable) AppendQuery(fmter schema.Formatter, b []byte) (_ []byte, err error) {
+	b = append(b, '(')
+	if !fmter.HasFeature(feature.ValuesTable) {
+		b, err = t.query.appendSelectUnion(fmter, b)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, ") AS "...)
+		return fmter.AppendIdent(b, t.alias), nil
+	}
+
+	b, err = t.query.AppendQuery(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, ") AS "...)
+	b = fmter.AppendIdent(b, t.alias)
+	b = append(b, " ("...)
+	b, err = t.query.AppendColumns(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, ')'), nil
+}
+
+// appendSelectUnion appends the rows as SELECT ... UNION ALL SELECT ... with the column
+// names in the first SELECT, which works with dialects that don't support VALUES lists
+// as tables.
+func (q *ValuesQuery) appendSelectUnion(fmter schema.Formatter, b []byte) (_ []byte, err error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	if q.model == nil {
+		return nil, errNilModel
+	}
+
+	fmter = formatterWithModel(fmter, q)
+
+	if q.tableModel != nil {
+		fields, err := q.getFields()
+		if err != nil {
+			return nil, err
+		}
+
+		switch model := q.tableModel.(type) {
+		case *structTableModel:
+			return q.appendSelectRow(fmter, b, fields, model.strct, 0)
+		case *sliceTableModel:
+			sliceLen := model.slice.Len()
+			if sliceLen == 0 {
+				return nil, errEmptyValues
 			}
-			continue
+			for i := 0; i < sliceLen; i++ {
+				if i > 0 {
+					b = append(b, " UNION ALL "...)
+				}
+				b, err = q.appendSelectRow(fmter, b, fields, model.slice.Index(i), i)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return b, nil
 		}
+	}
 
-		if isTemplate {
-			b = append(b, '?')
-		} else {
-			b = f.AppendValue(fmter, b, indirect(strct))
+	switch model := q.model.(type) {
+	case *mapSliceModel:
+		return model.appendSelectUnion(fmter, b)
+	}
+
+	return nil, fmt.Errorf("bun: Values does not support %T", q.model)
+}
+
+func (q *ValuesQuery) appendSelectRow(
+	fmter schema.Formatter, b []byte, fields []*schema.Field, strct reflect.Value, index int,
+) (_ []byte, err error) {
+	b = append(b, "SELECT "...)
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b, err = q.appendValue(fmter, b, f, strct)
+		if err != nil {
+			return nil, err
 		}
+		if index == 0 {
+			b = append(b, " AS "...)
+			b = append(b, f.SQLName...)
+		}
+	}
 
-		if fmter.HasFeature(feature.DoubleColonCast) {
-			b = append(b, "::"...)
-			b = append(b, f.UserSQLType...)
+	if q.withOrder {
+		b = append(b, ", "...)
+		b = strconv.AppendInt(b, int64(index), 10)
+		if index == 0 {
+			b = append(b, " AS _order"...)
 		}
 	}
 	return b, nil
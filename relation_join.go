@@ -2,9 +2,12 @@ package bun
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"reflect"
-	"time"
+	"strings"
 
+	"github.com/uptrace/bun/dialect"
 	"github.com/uptrace/bun/dialect/feature"
 	"github.com/uptrace/bun/internal"
 	"github.com/uptrace/bun/schema"
@@ -16,8 +19,9 @@ type relationJoin struct {
 	JoinModel TableModel
 	Relation  *schema.Relation
 
-	apply   func(*SelectQuery) *SelectQuery
-	columns []schema.QueryWithArgs
+	apply    func(*SelectQuery) *SelectQuery
+	columns  []schema.QueryWithArgs
+	joinType JoinType // overrides Relation.InnerJoin, see RelationWithJoinType
 }
 
 func (j *relationJoin) applyTo(q *SelectQuery) {
@@ -46,30 +50,80 @@ func (j *relationJoin) Select(ctx context.Context, q *SelectQuery) error {
 }
 
 func (j *relationJoin) selectMany(ctx context.Context, q *SelectQuery) error {
-	q = j.manyQuery(q)
-	if q == nil {
+	hasManyModel := newHasManyModel(j)
+	if hasManyModel == nil {
 		return nil
 	}
-	return q.Scan(ctx)
+
+	values := relationValues(j.JoinModel.rootValue(), j.JoinModel.parentIndex(), j.Relation.BasePKs)
+	return j.selectChunks(ctx, q, hasManyModel, values, j.manyQuery)
 }
 
-func (j *relationJoin) manyQuery(q *SelectQuery) *SelectQuery {
-	hasManyModel := newHasManyModel(j)
-	if hasManyModel == nil {
-		return nil
+// selectChunks selects the relation for the base values in chunks of the size
+// configured with WithRelationChunkSize. Chunks are selected one by one into the same model,
+// which parks joined models to the base models, and nested relations are selected once at the end.
+func (j *relationJoin) selectChunks(
+	ctx context.Context,
+	q *SelectQuery,
+	model TableModel,
+	values []reflect.Value,
+	query func(q *SelectQuery, values []reflect.Value) *SelectQuery,
+) error {
+	size := q.db.relationChunkSize
+	if size <= 0 {
+		size = len(values)
+	}
+
+	var n int64
+	for len(values) > 0 {
+		chunk := values[:min(size, len(values))]
+		values = values[len(chunk):]
+
+		cq := q.db.NewSelect().Conn(q.conn).Model(model)
+		cq.flags = cq.flags.Set(skipRelationsFlag)
+
+		res, err := q.scanRelation(ctx, query(cq, chunk))
+		if err != nil {
+			return err
+		}
+		affected, _ := res.RowsAffected()
+		n += affected
 	}
 
-	q = q.Model(hasManyModel)
+	if n > 0 {
+		return q.selectJoins(ctx, model.getJoins())
+	}
+	return nil
+}
 
+// scanRelation scans the relation query, waiting for a free slot
+// when the number of concurrent relation queries is limited.
+func (q *SelectQuery) scanRelation(ctx context.Context, rq *SelectQuery) (sql.Result, error) {
+	if q.relationSem != nil {
+		select {
+		case q.relationSem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		defer func() {
+			<-q.relationSem
+		}()
+	}
+	return rq.scanResult(ctx)
+}
+
+func (j *relationJoin) manyQuery(q *SelectQuery, values []reflect.Value) *SelectQuery {
 	var where []byte
 
 	if q.db.dialect.Features().Has(feature.CompositeIn) {
-		return j.manyQueryCompositeIn(where, q)
+		return j.manyQueryCompositeIn(where, q, values)
 	}
-	return j.manyQueryMulti(where, q)
+	return j.manyQueryMulti(where, q, values)
 }
 
-func (j *relationJoin) manyQueryCompositeIn(where []byte, q *SelectQuery) *SelectQuery {
+func (j *relationJoin) manyQueryCompositeIn(
+	where []byte, q *SelectQuery, values []reflect.Value,
+) *SelectQuery {
 	if len(j.Relation.JoinPKs) > 1 {
 		where = append(where, '(')
 	}
@@ -78,13 +132,7 @@ func (j *relationJoin) manyQueryCompositeIn(where []byte, q *SelectQuery) *Selec
 		where = append(where, ')')
 	}
 	where = append(where, " IN ("...)
-	where = appendChildValues(
-		q.db.Formatter(),
-		where,
-		j.JoinModel.rootValue(),
-		j.JoinModel.parentIndex(),
-		j.Relation.BasePKs,
-	)
+	where = appendChildValues(q.db.Formatter(), where, values, j.Relation.BasePKs)
 	where = append(where, ")"...)
 	q = q.Where(internal.String(where))
 
@@ -98,12 +146,13 @@ func (j *relationJoin) manyQueryCompositeIn(where []byte, q *SelectQuery) *Selec
 	return q
 }
 
-func (j *relationJoin) manyQueryMulti(where []byte, q *SelectQuery) *SelectQuery {
+func (j *relationJoin) manyQueryMulti(
+	where []byte, q *SelectQuery, values []reflect.Value,
+) *SelectQuery {
 	where = appendMultiValues(
 		q.db.Formatter(),
 		where,
-		j.JoinModel.rootValue(),
-		j.JoinModel.parentIndex(),
+		values,
 		j.Relation.BasePKs,
 		j.Relation.JoinPKs,
 		j.JoinModel.Table().SQLAlias,
@@ -158,23 +207,17 @@ func (j *relationJoin) hasManyColumns(q *SelectQuery) *SelectQuery {
 }
 
 func (j *relationJoin) selectM2M(ctx context.Context, q *SelectQuery) error {
-	q = j.m2mQuery(q)
-	if q == nil {
-		return nil
-	}
-	return q.Scan(ctx)
-}
-
-func (j *relationJoin) m2mQuery(q *SelectQuery) *SelectQuery {
-	fmter := q.db.fmter
-
 	m2mModel := newM2MModel(j)
 	if m2mModel == nil {
 		return nil
 	}
-	q = q.Model(m2mModel)
 
-	index := j.JoinModel.parentIndex()
+	values := relationValues(j.BaseModel.rootValue(), j.JoinModel.parentIndex(), j.Relation.BasePKs)
+	return j.selectChunks(ctx, q, m2mModel, values, j.m2mQuery)
+}
+
+func (j *relationJoin) m2mQuery(q *SelectQuery, values []reflect.Value) *SelectQuery {
+	fmter := q.db.fmter
 
 	if j.Relation.M2MTable != nil {
 		// We only need base pks to park joined models to the base model.
@@ -202,7 +245,7 @@ func (j *relationJoin) m2mQuery(q *SelectQuery) *SelectQuery {
 		join = append(join, col.SQLName...)
 	}
 	join = append(join, ") IN ("...)
-	join = appendChildValues(fmter, join, j.BaseModel.rootValue(), index, j.Relation.BasePKs)
+	join = appendChildValues(fmter, join, values, j.Relation.BasePKs)
 	join = append(join, ")"...)
 	q = q.Join(internal.String(join))
 
@@ -234,7 +277,9 @@ func (j *relationJoin) appendAlias(fmter schema.Formatter, b []byte) []byte {
 	quote := fmter.IdentQuote()
 
 	b = append(b, quote)
+	start := len(b)
 	b = appendAlias(b, j)
+	b = truncateIdent(fmter, b, start)
 	b = append(b, quote)
 	return b
 }
@@ -243,21 +288,18 @@ func (j *relationJoin) appendAliasColumn(fmter schema.Formatter, b []byte, colum
 	quote := fmter.IdentQuote()
 
 	b = append(b, quote)
+	start := len(b)
 	b = appendAlias(b, j)
 	b = append(b, "__"...)
 	b = append(b, column...)
+	b = truncateIdent(fmter, b, start)
 	b = append(b, quote)
 	return b
 }
 
 func (j *relationJoin) appendBaseAlias(fmter schema.Formatter, b []byte) []byte {
-	quote := fmter.IdentQuote()
-
 	if j.hasParent() {
-		b = append(b, quote)
-		b = appendAlias(b, j.Parent)
-		b = append(b, quote)
-		return b
+		return j.Parent.appendAlias(fmter, b)
 	}
 	return append(b, j.BaseModel.Table().SQLAlias...)
 }
@@ -265,27 +307,48 @@ func (j *relationJoin) appendBaseAlias(fmter schema.Formatter, b []byte) []byte
 func (j *relationJoin) appendSoftDelete(
 	fmter schema.Formatter, b []byte, flags internal.Flag,
 ) []byte {
-	b = append(b, '.')
+	table := j.JoinModel.Table()
 
-	field := j.JoinModel.Table().SoftDeleteField
-	b = append(b, field.SQLName...)
+	column := j.appendAlias(fmter, nil)
+	column = append(column, '.')
+	column = append(column, table.SoftDeleteField.SQLName...)
 
-	if field.IsPtr || field.NullZero {
-		if flags.Has(deletedFlag) {
-			b = append(b, " IS NOT NULL"...)
-		} else {
-			b = append(b, " IS NULL"...)
-		}
-	} else {
-		if flags.Has(deletedFlag) {
-			b = append(b, " != "...)
-		} else {
-			b = append(b, " = "...)
+	return table.SoftDelete.AppendWhere(fmter, b, column, flags.Has(deletedFlag))
+}
+
+// appendRelationAlias appends the alias of the relation joined with the query,
+// e.g. "author__profile" for the Author.Profile path.
+func appendRelationAlias(fmter schema.Formatter, b []byte, model TableModel, path string) []byte {
+	var join *relationJoin
+	for _, name := range strings.Split(path, ".") {
+		join = model.getJoin(name)
+		if join == nil || !join.isInline() {
+			return dialect.AppendError(b, fmt.Errorf("bun: relation %q is not joined", path))
 		}
-		b = fmter.Dialect().AppendTime(b, time.Time{})
+		model = join.JoinModel
 	}
+	return join.appendAlias(fmter, b)
+}
 
-	return b
+// isInline reports whether the relation is joined with the base query
+// instead of being selected with a separate query.
+func (j *relationJoin) isInline() bool {
+	switch j.Relation.Type {
+	case schema.HasOneRelation, schema.BelongsToRelation:
+		return true
+	}
+	return false
+}
+
+// truncateIdent truncates the identifier that starts at b[start:] when it is longer
+// than the dialect allows, see schema.TruncateIdent.
+func truncateIdent(fmter schema.Formatter, b []byte, start int) []byte {
+	maxLen := schema.MaxIdentLen(fmter.Dialect())
+	if maxLen == 0 || len(b)-start <= maxLen {
+		return b
+	}
+	ident := schema.TruncateIdent(string(b[start:]), maxLen)
+	return append(b[:start], ident...)
 }
 
 func appendAlias(b []byte, j *relationJoin) []byte {
@@ -297,12 +360,23 @@ func appendAlias(b []byte, j *relationJoin) []byte {
 	return b
 }
 
+func (j *relationJoin) sqlJoinType() JoinType {
+	if j.joinType != "" {
+		return j.joinType
+	}
+	if j.Relation.InnerJoin {
+		return InnerJoin
+	}
+	return LeftJoin
+}
+
 func (j *relationJoin) appendHasOneJoin(
 	fmter schema.Formatter, b []byte, q *SelectQuery,
 ) (_ []byte, err error) {
 	isSoftDelete := j.JoinModel.Table().SoftDeleteField != nil && !q.flags.Has(allWithDeletedFlag)
 
-	b = append(b, "LEFT JOIN "...)
+	b = append(b, j.sqlJoinType()...)
+	b = append(b, ' ')
 	b = fmter.AppendQuery(b, string(j.JoinModel.Table().SQLNameForSelects))
 	b = append(b, " AS "...)
 	b = j.appendAlias(fmter, b)
@@ -326,20 +400,36 @@ func (j *relationJoin) appendHasOneJoin(
 
 	if isSoftDelete {
 		b = append(b, " AND "...)
-		b = j.appendAlias(fmter, b)
 		b = j.appendSoftDelete(fmter, b, q.flags)
 	}
 
 	return b, nil
 }
 
-func appendChildValues(
-	fmter schema.Formatter, b []byte, v reflect.Value, index []int, fields []*schema.Field,
-) []byte {
-	seen := make(map[string]struct{})
+// relationValues returns the base models of a relation, skipping models with duplicate keys.
+func relationValues(v reflect.Value, index []int, fields []*schema.Field) []reflect.Value {
+	var values []reflect.Value
+	seen := make(map[internal.MapKey]struct{})
+	key := make([]interface{}, 0, len(fields))
 	walk(v, index, func(v reflect.Value) {
-		start := len(b)
+		key = modelKey(key[:0], v, fields)
+		mapKey := internal.NewMapKey(key)
+		if _, ok := seen[mapKey]; ok {
+			return
+		}
+		seen[mapKey] = struct{}{}
+		values = append(values, v)
+	})
+	return values
+}
 
+func appendChildValues(
+	fmter schema.Formatter, b []byte, values []reflect.Value, fields []*schema.Field,
+) []byte {
+	for i, v := range values {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
 		if len(fields) > 1 {
 			b = append(b, '(')
 		}
@@ -352,16 +442,6 @@ func appendChildValues(
 		if len(fields) > 1 {
 			b = append(b, ')')
 		}
-		b = append(b, ", "...)
-
-		if _, ok := seen[string(b[start:])]; ok {
-			b = b[:start]
-		} else {
-			seen[string(b[start:])] = struct{}{}
-		}
-	})
-	if len(seen) > 0 {
-		b = b[:len(b)-2] // trim ", "
 	}
 	return b
 }
@@ -369,7 +449,7 @@ func appendChildValues(
 // appendMultiValues is an alternative to appendChildValues that doesn't use the sql keyword ID
 // but instead uses old style ((k1=v1) AND (k2=v2)) OR (...) conditions.
 func appendMultiValues(
-	fmter schema.Formatter, b []byte, v reflect.Value, index []int, baseFields, joinFields []*schema.Field, joinTable schema.Safe,
+	fmter schema.Formatter, b []byte, values []reflect.Value, baseFields, joinFields []*schema.Field, joinTable schema.Safe,
 ) []byte {
 	// This is based on a mix of appendChildValues and query_base.appendColumns
 
@@ -378,11 +458,11 @@ func appendMultiValues(
 		panic("not reached")
 	}
 
-	// walk the relations
 	b = append(b, '(')
-	seen := make(map[string]struct{})
-	walk(v, index, func(v reflect.Value) {
-		start := len(b)
+	for i, v := range values {
+		if i > 0 {
+			b = append(b, ") OR ("...)
+		}
 		for i, f := range baseFields {
 			if i > 0 {
 				b = append(b, " AND "...)
@@ -402,17 +482,6 @@ func appendMultiValues(
 				b = append(b, ')')
 			}
 		}
-
-		b = append(b, ") OR ("...)
-
-		if _, ok := seen[string(b[start:])]; ok {
-			b = b[:start]
-		} else {
-			seen[string(b[start:])] = struct{}{}
-		}
-	})
-	if len(seen) > 0 {
-		b = b[:len(b)-6] // trim ") OR ("
 	}
 	b = append(b, ')')
 	return b
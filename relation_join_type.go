@@ -0,0 +1,9 @@
+package bun
+
+// JoinType is the SQL join of a has-one or belongs-to relation, see RelationWithJoinType.
+type JoinType string
+
+const (
+	InnerJoin JoinType = "INNER JOIN"
+	LeftJoin  JoinType = "LEFT JOIN"
+)
@@ -0,0 +1,81 @@
+package bun
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrUnexpectedRowsAffected is the kind of RowsAffectedError, so the error can be checked
+// with errors.Is(err, bun.ErrUnexpectedRowsAffected).
+var ErrUnexpectedRowsAffected = errors.New("bun: unexpected number of rows affected")
+
+// RowsAffectedError is returned by MustAffect and ExecExpectingRows when the query
+// affected a different number of rows, e.g. because the row was updated or deleted
+// concurrently and its version no longer matches.
+type RowsAffectedError struct {
+	Expected int64
+	Actual   int64
+}
+
+func (e *RowsAffectedError) Error() string {
+	return fmt.Sprintf("bun: expected %d rows affected, got %d", e.Expected, e.Actual)
+}
+
+func (e *RowsAffectedError) Is(target error) bool {
+	return target == ErrUnexpectedRowsAffected
+}
+
+// MustAffect returns a *RowsAffectedError unless the result affected exactly n rows,
+// for example:
+//
+//	res, err := db.NewUpdate().Model(doc).WherePK().Where("version = ?", doc.Version).Exec(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	if err := bun.MustAffect(res, 1); err != nil {
+//		return err
+//	}
+//
+// Note that MySQL reports the number of changed rows, so rows that are updated with
+// the same values are not counted unless the clientFoundRows DSN parameter is set.
+func MustAffect(res sql.Result, n int64) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != n {
+		return &RowsAffectedError{Expected: n, Actual: affected}
+	}
+	return nil
+}
+
+// ExecExpectingRows executes the query like Exec and returns a *RowsAffectedError
+// unless it updated exactly n rows, see MustAffect. The changes are not rolled back,
+// so run the query in a transaction when they must be.
+func (q *UpdateQuery) ExecExpectingRows(
+	ctx context.Context, n int64, dest ...interface{},
+) (sql.Result, error) {
+	res, err := q.Exec(ctx, dest...)
+	return checkRowsAffected(res, err, n)
+}
+
+// ExecExpectingRows executes the query like Exec and returns a *RowsAffectedError
+// unless it deleted exactly n rows, see MustAffect. The changes are not rolled back,
+// so run the query in a transaction when they must be.
+func (q *DeleteQuery) ExecExpectingRows(
+	ctx context.Context, n int64, dest ...interface{},
+) (sql.Result, error) {
+	res, err := q.Exec(ctx, dest...)
+	return checkRowsAffected(res, err, n)
+}
+
+func checkRowsAffected(res sql.Result, err error, n int64) (sql.Result, error) {
+	// Queries with RETURNING and a single row destination return sql.ErrNoRows
+	// along with the result when no rows are affected.
+	if err != nil && (res == nil || !errors.Is(err, sql.ErrNoRows)) {
+		return res, err
+	}
+	return res, MustAffect(res, n)
+}
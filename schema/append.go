@@ -34,7 +34,7 @@ func Append(fmter Formatter, b []byte, v interface{}) []byte {
 	case string:
 		return fmter.Dialect().AppendString(b, v)
 	case time.Time:
-		return fmter.Dialect().AppendTime(b, v)
+		return fmter.AppendTime(b, v)
 	case []byte:
 		return fmter.Dialect().AppendBytes(b, v)
 	case QueryAppender:
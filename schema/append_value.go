@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"net"
+	"net/netip"
 	"reflect"
 	"strconv"
 	"strings"
@@ -12,7 +13,6 @@ import (
 	"github.com/puzpuzpuz/xsync/v3"
 	"github.com/uptrace/bun/dialect"
 	"github.com/uptrace/bun/dialect/sqltype"
-	"github.com/uptrace/bun/extra/bunjson"
 	"github.com/uptrace/bun/internal"
 	"github.com/vmihailenco/msgpack/v5"
 )
@@ -54,10 +54,27 @@ var appenders = []AppenderFunc{
 var appenderCache = xsync.NewMapOf[reflect.Type, AppenderFunc]()
 
 func FieldAppender(dialect Dialect, field *Field) AppenderFunc {
+	if mode, ok := field.Tag.Option("encrypted"); ok {
+		return encryptedAppender(mode == "deterministic")
+	}
 	if field.Tag.HasOption("msgpack") {
 		return appendMsgpack
 	}
 
+	if column, ok := field.Tag.Option("discriminator"); ok {
+		if column == "" {
+			return appendDiscriminatorEnvelope
+		}
+		return nilAwareAppender(AppendJSONValue)
+	}
+
+	if field.JSON && field.Tag.HasOption("json_omit_empty") {
+		return omitEmptyAppender(fieldAppender(dialect, field))
+	}
+	return fieldAppender(dialect, field)
+}
+
+func fieldAppender(dialect Dialect, field *Field) AppenderFunc {
 	fieldType := field.StructField.Type
 
 	switch strings.ToUpper(field.UserSQLType) {
@@ -73,6 +90,13 @@ func FieldAppender(dialect Dialect, field *Field) AppenderFunc {
 		}
 
 		return AppendJSONValue
+	case sqltype.UUID:
+		if isUUIDArray(fieldType) {
+			return appendUUIDValue
+		}
+		if fieldType.Kind() == reflect.Ptr && isUUIDArray(fieldType.Elem()) {
+			return PtrAppender(appendUUIDValue)
+		}
 	}
 
 	return Appender(dialect, fieldType)
@@ -92,6 +116,10 @@ func Appender(dialect Dialect, typ reflect.Type) AppenderFunc {
 }
 
 func appender(dialect Dialect, typ reflect.Type) AppenderFunc {
+	if fn := codecAppender(typ); fn != nil {
+		return fn
+	}
+
 	switch typ {
 	case bytesType:
 		return appendBytesValue
@@ -99,10 +127,16 @@ func appender(dialect Dialect, typ reflect.Type) AppenderFunc {
 		return appendTimeValue
 	case timePtrType:
 		return PtrAppender(appendTimeValue)
+	case ipType:
+		return appendIPValue
 	case ipNetType:
 		return appendIPNetValue
-	case ipType, netipPrefixType, netipAddrType:
-		return appendStringer
+	case netipAddrType:
+		return appendNetipAddrValue
+	case netipPrefixType:
+		return appendNetipPrefixValue
+	case hardwareAddrType:
+		return appendHardwareAddrValue
 	case jsonRawMessageType:
 		return appendJSONRawMessageValue
 	}
@@ -132,6 +166,13 @@ func appender(dialect Dialect, typ reflect.Type) AppenderFunc {
 		}
 	}
 
+	if IsUUIDType(typ) {
+		return appendUUIDValue
+	}
+	if kind == reflect.Ptr && IsUUIDType(typ.Elem()) {
+		return PtrAppender(appendUUIDValue)
+	}
+
 	switch kind {
 	case reflect.Interface:
 		return ifaceAppenderFunc
@@ -230,7 +271,7 @@ func AppendStringValue(fmter Formatter, b []byte, v reflect.Value) []byte {
 }
 
 func AppendJSONValue(fmter Formatter, b []byte, v reflect.Value) []byte {
-	bb, err := bunjson.Marshal(v.Interface())
+	bb, err := fmter.marshalJSON(v.Interface())
 	if err != nil {
 		return dialect.AppendError(b, err)
 	}
@@ -242,18 +283,72 @@ func AppendJSONValue(fmter Formatter, b []byte, v reflect.Value) []byte {
 	return fmter.Dialect().AppendJSON(b, bb)
 }
 
+// omitEmptyAppender appends NULL instead of empty JSON values such as null, {}, and [].
+func omitEmptyAppender(fn AppenderFunc) AppenderFunc {
+	return func(fmter Formatter, b []byte, v reflect.Value) []byte {
+		if isEmptyValue(v) {
+			return dialect.AppendNull(b)
+		}
+		return fn(fmter, b, v)
+	}
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+		return isEmptyValue(v.Elem())
+	}
+	return v.IsZero()
+}
+
 func appendTimeValue(fmter Formatter, b []byte, v reflect.Value) []byte {
 	tm := v.Interface().(time.Time)
-	return fmter.Dialect().AppendTime(b, tm)
+	return fmter.AppendTime(b, tm)
+}
+
+func appendIPValue(fmter Formatter, b []byte, v reflect.Value) []byte {
+	ip := v.Interface().(net.IP)
+	if ip == nil {
+		return dialect.AppendNull(b)
+	}
+	return fmter.Dialect().AppendString(b, ip.String())
 }
 
 func appendIPNetValue(fmter Formatter, b []byte, v reflect.Value) []byte {
 	ipnet := v.Interface().(net.IPNet)
+	if ipnet.IP == nil {
+		return dialect.AppendNull(b)
+	}
 	return fmter.Dialect().AppendString(b, ipnet.String())
 }
 
-func appendStringer(fmter Formatter, b []byte, v reflect.Value) []byte {
-	return fmter.Dialect().AppendString(b, v.Interface().(fmt.Stringer).String())
+func appendNetipAddrValue(fmter Formatter, b []byte, v reflect.Value) []byte {
+	addr := v.Interface().(netip.Addr)
+	if !addr.IsValid() {
+		return dialect.AppendNull(b)
+	}
+	return fmter.Dialect().AppendString(b, addr.String())
+}
+
+func appendNetipPrefixValue(fmter Formatter, b []byte, v reflect.Value) []byte {
+	prefix := v.Interface().(netip.Prefix)
+	if !prefix.IsValid() {
+		return dialect.AppendNull(b)
+	}
+	return fmter.Dialect().AppendString(b, prefix.String())
+}
+
+func appendHardwareAddrValue(fmter Formatter, b []byte, v reflect.Value) []byte {
+	mac := v.Interface().(net.HardwareAddr)
+	if mac == nil {
+		return dialect.AppendNull(b)
+	}
+	return fmter.Dialect().AppendString(b, mac.String())
 }
 
 func appendJSONRawMessageValue(fmter Formatter, b []byte, v reflect.Value) []byte {
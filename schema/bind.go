@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"reflect"
+	"time"
+)
+
+// ArgBinder collects the args of a query that are bound with placeholders
+// instead of being appended to the query. See Formatter.WithArgBinder.
+type ArgBinder struct {
+	placeholder func(b []byte, n int) []byte
+	args        []interface{}
+}
+
+// NewArgBinder returns a binder that appends placeholders using the func, which is
+// called with the 1-based position of the arg, e.g. to append $1 for PostgreSQL.
+func NewArgBinder(placeholder func(b []byte, n int) []byte) *ArgBinder {
+	return &ArgBinder{placeholder: placeholder}
+}
+
+// Args returns the bound args in placeholder order.
+func (ab *ArgBinder) Args() []interface{} {
+	return ab.args
+}
+
+func (ab *ArgBinder) bind(b []byte, arg interface{}) []byte {
+	ab.args = append(ab.args, arg)
+	return ab.placeholder(b, len(ab.args))
+}
+
+// WithArgBinder returns a formatter that appends placeholders for args and model values
+// with basic Go types, time.Time, or driver.Valuer and collects the values with the binder.
+// Other values, e.g. slices, JSON, and queries, are appended to the query as usual.
+func (f Formatter) WithArgBinder(binder *ArgBinder) Formatter {
+	f.binder = binder
+	return f
+}
+
+// ArgBinder returns the binder set by WithArgBinder or nil.
+func (f Formatter) ArgBinder() *ArgBinder {
+	return f.binder
+}
+
+// bindValue returns the value that is bound instead of appending v
+// and reports whether v can be bound.
+func (f Formatter) bindValue(v reflect.Value) (interface{}, bool) {
+	if !v.IsValid() {
+		return nil, true
+	}
+
+	typ := v.Type()
+	if codecAppender(typ) != nil || typ.Implements(queryAppenderType) {
+		return nil, false
+	}
+	if typ.Implements(driverValuerType) {
+		if typ.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, true
+		}
+		return v.Interface(), true
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if typ.Implements(jsonMarshalerType) {
+			return nil, false
+		}
+		if v.IsNil() {
+			return nil, true
+		}
+		return f.bindValue(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, true
+		}
+		return f.bindValue(v.Elem())
+	}
+
+	ptr := reflect.PointerTo(typ)
+	if ptr.Implements(queryAppenderType) || ptr.Implements(driverValuerType) {
+		return nil, false
+	}
+
+	if typ == timeType {
+		return f.time.Convert(v.Interface().(time.Time)), true
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return v.String(), true
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), true
+		}
+	}
+	return nil, false
+}
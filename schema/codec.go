@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"reflect"
+
+	"github.com/puzpuzpuz/xsync/v3"
+)
+
+type codec struct {
+	append AppenderFunc
+	scan   ScannerFunc
+}
+
+var codecs = xsync.NewMapOf[reflect.Type, codec]()
+
+// RegisterCodec associates the appender and scanner with the type across all models,
+// which allows using types that implement neither driver.Valuer nor sql.Scanner.
+// Pointers to the type are supported as well. The scanner must handle a nil src.
+//
+// RegisterCodec must be called before the models that use the type are registered or queried.
+func RegisterCodec(typ reflect.Type, appender AppenderFunc, scanner ScannerFunc) {
+	codecs.Store(typ, codec{
+		append: appender,
+		scan:   scanner,
+	})
+
+	ptrType := reflect.PointerTo(typ)
+	appenderCache.Delete(typ)
+	appenderCache.Delete(ptrType)
+	scannerCache.Delete(typ)
+	scannerCache.Delete(ptrType)
+}
+
+// LookupCodec returns the appender and scanner registered with RegisterCodec for the type.
+func LookupCodec(typ reflect.Type) (AppenderFunc, ScannerFunc, bool) {
+	c, ok := codecs.Load(typ)
+	return c.append, c.scan, ok
+}
+
+func codecAppender(typ reflect.Type) AppenderFunc {
+	if c, ok := codecs.Load(typ); ok && c.append != nil {
+		return c.append
+	}
+	if typ.Kind() == reflect.Ptr {
+		if c, ok := codecs.Load(typ.Elem()); ok && c.append != nil {
+			return PtrAppender(c.append)
+		}
+	}
+	return nil
+}
+
+func codecScanner(typ reflect.Type) ScannerFunc {
+	if c, ok := codecs.Load(typ); ok && c.scan != nil {
+		return c.scan
+	}
+	return nil
+}
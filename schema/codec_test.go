@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type codecMoney struct {
+	cents int64
+}
+
+func TestRegisterCodec(t *testing.T) {
+	typ := reflect.TypeOf(codecMoney{})
+	RegisterCodec(typ, func(fmter Formatter, b []byte, v reflect.Value) []byte {
+		return strconv.AppendInt(b, v.Interface().(codecMoney).cents, 10)
+	}, func(dest reflect.Value, src interface{}) error {
+		if src == nil {
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		}
+		dest.Set(reflect.ValueOf(codecMoney{cents: src.(int64)}))
+		return nil
+	})
+
+	fmter := NewFormatter(newNopDialect())
+
+	b := Appender(fmter.Dialect(), typ)(fmter, nil, reflect.ValueOf(codecMoney{cents: 150}))
+	require.Equal(t, "150", string(b))
+
+	var ptr *codecMoney
+	b = Appender(fmter.Dialect(), reflect.TypeOf(ptr))(fmter, nil, reflect.ValueOf(ptr))
+	require.Equal(t, "NULL", string(b))
+
+	var money codecMoney
+	err := Scanner(typ)(reflect.ValueOf(&money).Elem(), int64(42))
+	require.NoError(t, err)
+	require.Equal(t, int64(42), money.cents)
+
+	err = Scanner(reflect.TypeOf(ptr))(reflect.ValueOf(&ptr).Elem(), int64(7))
+	require.NoError(t, err)
+	require.Equal(t, int64(7), ptr.cents)
+}
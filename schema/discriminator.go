@@ -0,0 +1,214 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/puzpuzpuz/xsync/v3"
+
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/extra/bunjson"
+)
+
+var (
+	discriminatorTypes = xsync.NewMapOf[string, reflect.Type]()
+	discriminatorNames = xsync.NewMapOf[reflect.Type, string]()
+)
+
+// RegisterDiscriminator registers the type of the value under the name for interface
+// fields with the discriminator tag option, for example:
+//
+//	schema.RegisterDiscriminator("user_created", (*UserCreated)(nil))
+//
+//	type Event struct {
+//		ID      int64
+//		Payload EventPayload `bun:",discriminator"`
+//	}
+//
+// With the discriminator option the value is stored as JSON together with the type name,
+// e.g. {"type":"user_created","value":{...}}. With discriminator:column the value is stored
+// as plain JSON and the type name is stored in the string field with the column name,
+// which must be declared in the same struct as the interface field.
+func RegisterDiscriminator(name string, value interface{}) {
+	typ := reflect.TypeOf(value)
+	if typ == nil {
+		panic(fmt.Errorf("bun: RegisterDiscriminator(%q, nil)", name))
+	}
+	discriminatorTypes.Store(name, typ)
+	discriminatorNames.Store(typ, name)
+}
+
+type discriminatorEnvelope struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// initDiscriminators links interface fields that store the type name in a separate column
+// with the fields for those columns.
+func (t *Table) initDiscriminators() {
+	for _, field := range t.Fields {
+		if !field.Tag.HasOption("discriminator") {
+			continue
+		}
+
+		if field.IndirectType.Kind() != reflect.Interface {
+			panic(fmt.Errorf("bun: %s.%s: discriminator field must be an interface, got %s",
+				t.TypeName, field.GoName, field.IndirectType))
+		}
+
+		column := field.Tag.Options["discriminator"][0]
+		if column == "" {
+			continue
+		}
+
+		typeField, ok := t.FieldMap[column]
+		if !ok || !isSibling(field, typeField) || typeField.IndirectType.Kind() != reflect.String {
+			panic(fmt.Errorf("bun: %s.%s: discriminator column %q must be a string field "+
+				"declared in the same struct", t.TypeName, field.GoName, column))
+		}
+
+		field.typeField = typeField
+		typeField.valueField = field
+	}
+}
+
+// HasDiscriminatorColumn reports whether the field is an interface field that stores
+// the type name in a separate column with the discriminator:column tag option.
+// The column must be scanned before the field, so models defer scanning such fields
+// until the whole row is scanned.
+func (f *Field) HasDiscriminatorColumn() bool {
+	return f.typeField != nil
+}
+
+// isSibling reports whether the fields are declared in the same struct.
+func isSibling(f1, f2 *Field) bool {
+	if len(f1.Index) != len(f2.Index) {
+		return false
+	}
+	for i := 0; i < len(f1.Index)-1; i++ {
+		if f1.Index[i] != f2.Index[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// siblingIndex returns the index of a field declared in the same struct as f.
+func (f *Field) siblingIndex(sibling *Field) []int {
+	index := make([]int, len(f.Index))
+	copy(index, f.Index)
+	index[len(index)-1] = sibling.Index[len(sibling.Index)-1]
+	return index
+}
+
+func (f *Field) appendDiscriminator(fmter Formatter, b []byte, strct reflect.Value) []byte {
+	fv, ok := fieldByIndex(strct, f.siblingIndex(f.valueField))
+	if !ok || fv.IsNil() {
+		return dialect.AppendNull(b)
+	}
+
+	name, err := discriminatorName(fv)
+	if err != nil {
+		return dialect.AppendError(b, err)
+	}
+	return fmter.Dialect().AppendString(b, name)
+}
+
+func (f *Field) scanDiscriminated(strct reflect.Value, src interface{}) error {
+	tv, _ := fieldByIndex(strct, f.siblingIndex(f.typeField))
+	if !tv.IsValid() || tv.String() == "" {
+		return fmt.Errorf("bun: can't scan %s: discriminator column %s is empty",
+			f.GoName, f.typeField.Name)
+	}
+
+	b, err := toBytes(src)
+	if err != nil {
+		return err
+	}
+	return decodeDiscriminated(f.Value(strct), tv.String(), b)
+}
+
+func discriminatorName(v reflect.Value) (string, error) {
+	typ := v.Elem().Type()
+	name, ok := discriminatorNames.Load(typ)
+	if !ok {
+		return "", fmt.Errorf("bun: type %s is not registered with RegisterDiscriminator", typ)
+	}
+	return name, nil
+}
+
+func decodeDiscriminated(dest reflect.Value, name string, b []byte) error {
+	typ, ok := discriminatorTypes.Load(name)
+	if !ok {
+		return fmt.Errorf("bun: discriminator %q is not registered", name)
+	}
+	if !typ.AssignableTo(dest.Type()) {
+		return fmt.Errorf("bun: discriminator %q: %s is not assignable to %s", name, typ, dest.Type())
+	}
+
+	var ptr reflect.Value
+	if typ.Kind() == reflect.Ptr {
+		ptr = reflect.New(typ.Elem())
+	} else {
+		ptr = reflect.New(typ)
+	}
+	if err := bunjson.Unmarshal(b, ptr.Interface()); err != nil {
+		return err
+	}
+
+	if typ.Kind() == reflect.Ptr {
+		dest.Set(ptr)
+	} else {
+		dest.Set(ptr.Elem())
+	}
+	return nil
+}
+
+func appendDiscriminatorEnvelope(fmter Formatter, b []byte, v reflect.Value) []byte {
+	if v.IsNil() {
+		return dialect.AppendNull(b)
+	}
+
+	name, err := discriminatorName(v)
+	if err != nil {
+		return dialect.AppendError(b, err)
+	}
+
+	value, err := fmter.marshalJSON(v.Interface())
+	if err != nil {
+		return dialect.AppendError(b, err)
+	}
+
+	bb, err := bunjson.Marshal(discriminatorEnvelope{Type: name, Value: value})
+	if err != nil {
+		return dialect.AppendError(b, err)
+	}
+	return fmter.Dialect().AppendJSON(b, bb)
+}
+
+// scanDiscriminatorColumn only handles NULLs, because values are scanned by Field.ScanValue
+// which has access to the discriminator column.
+func scanDiscriminatorColumn(dest reflect.Value, src interface{}) error {
+	if src == nil {
+		return scanNull(dest)
+	}
+	return fmt.Errorf("bun: can't scan %s without the discriminator column", dest.Type())
+}
+
+func scanDiscriminatorEnvelope(dest reflect.Value, src interface{}) error {
+	if src == nil {
+		return scanNull(dest)
+	}
+
+	b, err := toBytes(src)
+	if err != nil {
+		return err
+	}
+
+	var env discriminatorEnvelope
+	if err := bunjson.Unmarshal(b, &env); err != nil {
+		return err
+	}
+	return decodeDiscriminated(dest, env.Type, env.Value)
+}
@@ -0,0 +1,275 @@
+package schema
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/uptrace/bun/dialect"
+)
+
+// Encryptor encrypts the values of fields with the encrypted tag option, e.g.
+// `bun:",encrypted"` or `bun:",encrypted:deterministic"`. Strings and byte slices are
+// encrypted as is and other values are encoded as JSON. Encrypted values are stored
+// as base64 strings in TEXT columns, so deterministic columns that must be indexed
+// on MySQL need an explicit type, e.g. `bun:",encrypted:deterministic,type:varchar(512)"`.
+//
+// Deterministic encryption returns the same ciphertext for the same value, which allows
+// equality lookups with Encrypted, but reveals which rows have equal values.
+//
+// The encryptor is set per DB with bun.WithEncryptor.
+type Encryptor interface {
+	Encrypt(plaintext []byte, deterministic bool) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+var errNoEncryptor = errors.New("bun: encryptor is not set, see bun.WithEncryptor")
+
+// KeyProvider provides the keys for AES-GCM encryption. Keys are identified by ids
+// that are stored with the encrypted values, so keys can be rotated: new values are
+// encrypted with the current key and existing values are decrypted with the key
+// they were encrypted with.
+//
+// Deterministic lookups with Encrypted only match values encrypted with the current key,
+// so after a rotation the rows encrypted with older keys must be re-encrypted, i.e.
+// selected and updated, before they can be found by value.
+type KeyProvider interface {
+	// CurrentKey returns the id and the key that encrypt new values.
+	CurrentKey() (id string, key []byte, err error)
+	// Key returns the key with the id.
+	Key(id string) ([]byte, error)
+}
+
+// StaticKey returns a key provider with a single key. The key must be 16, 24,
+// or 32 bytes long to select AES-128, AES-192, or AES-256.
+func StaticKey(id string, key []byte) KeyProvider {
+	return staticKey{id: id, key: key}
+}
+
+type staticKey struct {
+	id  string
+	key []byte
+}
+
+func (k staticKey) CurrentKey() (string, []byte, error) {
+	return k.id, k.key, nil
+}
+
+func (k staticKey) Key(id string) ([]byte, error) {
+	if id != k.id {
+		return nil, fmt.Errorf("bun: encryption key %q is not found", id)
+	}
+	return k.key, nil
+}
+
+// NewAESGCMEncryptor returns an encryptor that uses AES-GCM with the keys of the provider.
+// The ciphertext is an envelope that contains the key id, the nonce, and the sealed value.
+// Separate subkeys for AES-GCM and for the nonce are derived from each key with
+// HMAC-SHA256. Deterministic encryption derives the nonce from the value using
+// HMAC-SHA256 with the nonce subkey.
+func NewAESGCMEncryptor(keys KeyProvider) Encryptor {
+	return &aesGCMEncryptor{keys: keys}
+}
+
+type aesGCMEncryptor struct {
+	keys KeyProvider
+}
+
+const aesGCMEnvelopeVersion = 1
+
+func (e *aesGCMEncryptor) Encrypt(plaintext []byte, deterministic bool) ([]byte, error) {
+	id, key, err := e.keys.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+	if len(id) > 255 {
+		return nil, fmt.Errorf("bun: encryption key id %q is too long", id)
+	}
+
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if deterministic {
+		mac := hmac.New(sha256.New, deriveKey(key, "bun:nonce", sha256.Size))
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 0, 2+len(id)+len(nonce)+len(plaintext)+aead.Overhead())
+	b = append(b, aesGCMEnvelopeVersion, byte(len(id)))
+	b = append(b, id...)
+	b = append(b, nonce...)
+	// The header is authenticated, so the key id can't be swapped.
+	return aead.Seal(b, nonce, plaintext, b[:2+len(id)]), nil
+}
+
+func (e *aesGCMEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 2 || ciphertext[0] != aesGCMEnvelopeVersion {
+		return nil, errors.New("bun: unsupported encrypted value")
+	}
+	headerLen := 2 + int(ciphertext[1])
+	if len(ciphertext) < headerLen {
+		return nil, errors.New("bun: encrypted value is too short")
+	}
+
+	key, err := e.keys.Key(string(ciphertext[2:headerLen]))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < headerLen+aead.NonceSize() {
+		return nil, errors.New("bun: encrypted value is too short")
+	}
+	nonce := ciphertext[headerLen : headerLen+aead.NonceSize()]
+	sealed := ciphertext[headerLen+aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, ciphertext[:headerLen])
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, aes.KeySizeError(len(key))
+	}
+	block, err := aes.NewCipher(deriveKey(key, "bun:encryption", len(key)))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey derives a subkey of the size for the purpose from the key,
+// so the same key is never used both for AES and for HMAC.
+func deriveKey(key []byte, purpose string, size int) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)[:size]
+}
+
+//------------------------------------------------------------------------------
+
+// Encrypted returns a query appender that appends the value encrypted deterministically,
+// so it can be compared with a column with the encrypted:deterministic tag option:
+//
+//	db.NewSelect().Model(user).Where("email = ?", schema.Encrypted(email)).Scan(ctx)
+func Encrypted(value interface{}) QueryAppender {
+	return encryptedValue{value: value}
+}
+
+type encryptedValue struct {
+	value interface{}
+}
+
+func (v encryptedValue) AppendQuery(fmter Formatter, b []byte) ([]byte, error) {
+	return appendEncrypted(fmter, b, reflect.ValueOf(v.value), true), nil
+}
+
+func encryptedAppender(deterministic bool) AppenderFunc {
+	return func(fmter Formatter, b []byte, v reflect.Value) []byte {
+		return appendEncrypted(fmter, b, v, deterministic)
+	}
+}
+
+func appendEncrypted(fmter Formatter, b []byte, v reflect.Value, deterministic bool) []byte {
+	enc := fmter.Encryptor()
+	if enc == nil {
+		return dialect.AppendError(b, errNoEncryptor)
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return dialect.AppendNull(b)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return dialect.AppendNull(b)
+	}
+
+	var plaintext []byte
+	switch {
+	case v.Kind() == reflect.String:
+		plaintext = []byte(v.String())
+	case v.Type() == bytesType:
+		if v.IsNil() {
+			return dialect.AppendNull(b)
+		}
+		plaintext = v.Bytes()
+	default:
+		var err error
+		plaintext, err = json.Marshal(v.Interface())
+		if err != nil {
+			return dialect.AppendError(b, err)
+		}
+	}
+
+	ciphertext, err := enc.Encrypt(plaintext, deterministic)
+	if err != nil {
+		return dialect.AppendError(b, err)
+	}
+	return fmter.Dialect().AppendString(b, base64.StdEncoding.EncodeToString(ciphertext))
+}
+
+// scanEncryptedNoEncryptor is the scanner of encrypted fields that are scanned
+// without the encryptor of the DB, see Field.ScanEncryptedValue.
+func scanEncryptedNoEncryptor(dest reflect.Value, src interface{}) error {
+	return scanEncrypted(nil, dest, src)
+}
+
+func scanEncrypted(enc Encryptor, dest reflect.Value, src interface{}) error {
+	if src == nil {
+		return scanNull(dest)
+	}
+	if enc == nil {
+		return errNoEncryptor
+	}
+
+	b, err := toBytes(src)
+	if err != nil {
+		return err
+	}
+	ciphertext := make([]byte, base64.StdEncoding.DecodedLen(len(b)))
+	n, err := base64.StdEncoding.Decode(ciphertext, b)
+	if err != nil {
+		return fmt.Errorf("bun: can't decode encrypted value: %w", err)
+	}
+
+	plaintext, err := enc.Decrypt(ciphertext[:n])
+	if err != nil {
+		return err
+	}
+
+	if dest.Kind() == reflect.Ptr {
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+		dest = dest.Elem()
+	}
+
+	switch {
+	case dest.Kind() == reflect.String:
+		dest.SetString(string(plaintext))
+		return nil
+	case dest.Type() == bytesType:
+		dest.SetBytes(plaintext)
+		return nil
+	default:
+		return json.Unmarshal(plaintext, dest.Addr().Interface())
+	}
+}
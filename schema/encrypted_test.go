@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESGCMEncryptor(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	enc := NewAESGCMEncryptor(StaticKey("v1", key))
+
+	c1, err := enc.Encrypt([]byte("secret"), false)
+	require.NoError(t, err)
+	c2, err := enc.Encrypt([]byte("secret"), false)
+	require.NoError(t, err)
+	require.NotEqual(t, c1, c2)
+
+	d1, err := enc.Encrypt([]byte("secret"), true)
+	require.NoError(t, err)
+	d2, err := enc.Encrypt([]byte("secret"), true)
+	require.NoError(t, err)
+	require.Equal(t, d1, d2)
+
+	for _, ciphertext := range [][]byte{c1, d1} {
+		plaintext, err := enc.Decrypt(ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, "secret", string(plaintext))
+	}
+
+	// The value is sealed with a subkey derived from the key, not with the key itself.
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	header := 2 + len("v1")
+	_, err = aead.Open(nil, c1[header:header+aead.NonceSize()],
+		c1[header+aead.NonceSize():], c1[:header])
+	require.Error(t, err)
+
+	tampered := bytes.Clone(c1)
+	tampered[len(tampered)-1] ^= 1
+	_, err = enc.Decrypt(tampered)
+	require.Error(t, err)
+
+	rotated := NewAESGCMEncryptor(StaticKey("v2", bytes.Repeat([]byte{2}, 32)))
+	_, err = rotated.Decrypt(c1)
+	require.EqualError(t, err, `bun: encryption key "v1" is not found`)
+}
+
+func TestEncryptedField(t *testing.T) {
+	type Model struct {
+		Email string            `bun:",encrypted:deterministic"`
+		SSN   *string           `bun:",encrypted"`
+		Meta  map[string]string `bun:",encrypted"`
+	}
+
+	dialect := newNopDialect()
+	fmter := NewFormatter(dialect).
+		WithEncryptor(NewAESGCMEncryptor(StaticKey("v1", bytes.Repeat([]byte{1}, 32))))
+	table := dialect.Tables().Get(reflect.TypeOf((*Model)(nil)).Elem())
+
+	ssn := "123"
+	src := &Model{Email: "a@example.com", SSN: &ssn, Meta: map[string]string{"k": "v"}}
+	strct := reflect.ValueOf(src).Elem()
+
+	dest := new(Model)
+	destStrct := reflect.ValueOf(dest).Elem()
+	for _, field := range table.Fields {
+		b := field.AppendValue(fmter, nil, strct)
+		require.NotContains(t, string(b), "example")
+
+		value := string(b[1 : len(b)-1])
+		require.NoError(t, field.ScanEncryptedValue(destStrct, value, fmter.Encryptor()))
+	}
+	require.Equal(t, src, dest)
+
+	b := table.FieldMap["email"].AppendValue(fmter, nil, strct)
+	require.Equal(t, string(b), string(fmter.AppendArg(nil, Encrypted("a@example.com"))))
+	require.Equal(t, "TEXT", table.FieldMap["email"].DiscoveredSQLType)
+
+	dest.SSN = nil
+	require.NoError(t, table.FieldMap["ssn"].ScanValue(destStrct, nil))
+	require.Nil(t, dest.SSN)
+
+	b = table.FieldMap["email"].AppendValue(NewFormatter(dialect), nil, strct)
+	require.Contains(t, string(b), "encryptor is not set")
+	err := table.FieldMap["email"].ScanValue(destStrct, "x")
+	require.EqualError(t, err, "bun: encryptor is not set, see bun.WithEncryptor")
+}
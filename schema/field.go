@@ -1,10 +1,12 @@
 package schema
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
 
 	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/extra/bunjson"
 	"github.com/uptrace/bun/internal"
 	"github.com/uptrace/bun/internal/tagparser"
 )
@@ -34,10 +36,44 @@ type Field struct {
 	NullZero      bool
 	AutoIncrement bool
 	Identity      bool
+	// IdentityAlways is true for GENERATED ALWAYS AS IDENTITY columns, see the identity tag option.
+	// IdentityOptions are the sequence options, e.g. START WITH 1000 INCREMENT BY 10.
+	IdentityAlways  bool
+	IdentityOptions string
+	// Collation is the collation of the column, see the collate tag option.
+	Collation string
+	// RenamedFrom is the previous name of the column declared with the renamed_from tag option,
+	// so schema diffing tools can rename the column instead of dropping and adding it.
+	RenamedFrom string
+	// JSON is true for fields that are stored as JSON, e.g. maps, slices, and structs
+	// without a custom scanner or fields with the json and jsonb types. Dialects
+	// reset it for fields they encode themselves, e.g. PostgreSQL arrays and hstore.
+	JSON bool
+	// Encrypted is true for fields with the encrypted tag option.
+	Encrypted bool
 
 	Append AppenderFunc
 	Scan   ScannerFunc
 	IsZero IsZeroerFunc
+
+	// typeField is the discriminator column of an interface field
+	// and valueField is the interface field of a discriminator column.
+	typeField  *Field
+	valueField *Field
+
+	// gen contains the functions generated by bungen for the genType struct.
+	gen     *GeneratedField
+	genType reflect.Type
+
+	// setter scans values of basic fields at the offset in the setterType struct
+	// without reflection.
+	setter     fieldSetter
+	offset     uintptr
+	setterType reflect.Type
+
+	// bindable reports whether the value can be bound with a placeholder instead of
+	// being appended, because the field is appended by the appender of its Go type.
+	bindable bool
 }
 
 func (f *Field) String() string {
@@ -50,6 +86,9 @@ func (f *Field) WithIndex(path []int) *Field {
 	}
 	clone := *f
 	clone.Index = makeIndex(path, f.Index)
+	// Generated functions and setters are only valid for the original index.
+	clone.gen = nil
+	clone.setter = nil
 	return &clone
 }
 
@@ -81,6 +120,9 @@ func (f *Field) HasNilValue(v reflect.Value) bool {
 }
 
 func (f *Field) HasZeroValue(v reflect.Value) bool {
+	if gen, ok := f.generated(v); ok {
+		return gen.IsZero(v.Addr().Interface())
+	}
 	if len(f.Index) == 1 {
 		return f.IsZero(v.Field(f.Index[0]))
 	}
@@ -98,6 +140,28 @@ func (f *Field) HasZeroValue(v reflect.Value) bool {
 }
 
 func (f *Field) AppendValue(fmter Formatter, b []byte, strct reflect.Value) []byte {
+	if f.valueField != nil {
+		return f.appendDiscriminator(fmter, b, strct)
+	}
+
+	if fmter.binder != nil && f.bindable {
+		fv, ok := fieldByIndex(strct, f.Index)
+		if !ok || (f.IsPtr && fv.IsNil()) || (f.NullZero && f.IsZero(fv)) {
+			return fmter.binder.bind(b, nil)
+		}
+		if v, ok := fmter.bindValue(fv); ok {
+			return fmter.binder.bind(b, v)
+		}
+	}
+
+	if gen, ok := f.generated(strct); ok {
+		model := strct.Addr().Interface()
+		if f.NullZero && gen.IsZero(model) {
+			return dialect.AppendNull(b)
+		}
+		return gen.Append(fmter, b, model)
+	}
+
 	fv, ok := fieldByIndex(strct, f.Index)
 	if !ok {
 		return dialect.AppendNull(b)
@@ -113,6 +177,17 @@ func (f *Field) AppendValue(fmter Formatter, b []byte, strct reflect.Value) []by
 }
 
 func (f *Field) ScanValue(strct reflect.Value, src interface{}) error {
+	if f.typeField != nil && src != nil {
+		return f.scanDiscriminated(strct, src)
+	}
+
+	if gen, ok := f.generated(strct); ok {
+		return gen.Scan(strct.Addr().Interface(), src)
+	}
+	if ok, err := f.scanFast(strct, src); ok {
+		return err
+	}
+
 	if src == nil {
 		if fv, ok := fieldByIndex(strct, f.Index); ok {
 			return f.ScanWithCheck(fv, src)
@@ -124,6 +199,34 @@ func (f *Field) ScanValue(strct reflect.Value, src interface{}) error {
 	return f.ScanWithCheck(fv, src)
 }
 
+// ScanJSONValue is like ScanValue, but decodes the JSON field using the provider.
+func (f *Field) ScanJSONValue(strct reflect.Value, src interface{}, p bunjson.Provider) error {
+	if src == nil {
+		return f.ScanValue(strct, src)
+	}
+
+	b, err := toBytes(src)
+	if err != nil {
+		return err
+	}
+
+	fv := internal.FieldByIndexAlloc(strct, f.Index)
+	if f.Tag.HasOption("json_use_number") {
+		dec := p.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		return dec.Decode(fv.Addr().Interface())
+	}
+	return p.Unmarshal(b, fv.Addr().Interface())
+}
+
+// ScanEncryptedValue decrypts src with the encryptor and scans it into the field.
+func (f *Field) ScanEncryptedValue(strct reflect.Value, src interface{}, enc Encryptor) error {
+	if src == nil {
+		return f.ScanValue(strct, src)
+	}
+	return scanEncrypted(enc, internal.FieldByIndexAlloc(strct, f.Index), src)
+}
+
 func (f *Field) ScanWithCheck(fv reflect.Value, src interface{}) error {
 	if f.Scan == nil {
 		return fmt.Errorf("bun: Scan(unsupported %s)", f.IndirectType)
@@ -131,6 +234,39 @@ func (f *Field) ScanWithCheck(fv reflect.Value, src interface{}) error {
 	return f.Scan(fv, src)
 }
 
+// SkipUpdate reports whether the field is excluded from UPDATE queries,
+// i.e. it has the skipupdate option, is a generated column, or is a GENERATED ALWAYS
+// identity column.
 func (f *Field) SkipUpdate() bool {
-	return f.Tag.HasOption("skipupdate")
+	return f.Tag.HasOption("skipupdate") || f.IsGenerated() || f.IdentityAlways
+}
+
+// IsGenerated reports whether the column value is generated by the database, i.e. it is
+// a generated column or a row_start or row_end period column of a system-versioned table.
+// Such columns are excluded from INSERT and UPDATE queries.
+func (f *Field) IsGenerated() bool {
+	return f.Tag.HasOption("generated") || f.Tag.HasOption("row_start") || f.Tag.HasOption("row_end")
+}
+
+// GeneratedExpr returns the expression of a generated column declared with
+// the generated tag option, e.g. `bun:",generated:(price * quantity),stored"`.
+// Generated columns are excluded from INSERT and UPDATE queries.
+func (f *Field) GeneratedExpr() (string, bool) {
+	return f.Tag.Option("generated")
+}
+
+// AppendIdentity appends the GENERATED ... AS IDENTITY clause of the identity column
+// declared with the identity tag option, e.g. `bun:",pk,identity:always(START WITH 1000)"`.
+func (f *Field) AppendIdentity(b []byte) []byte {
+	if f.IdentityAlways {
+		b = append(b, " GENERATED ALWAYS AS IDENTITY"...)
+	} else {
+		b = append(b, " GENERATED BY DEFAULT AS IDENTITY"...)
+	}
+	if f.IdentityOptions != "" {
+		b = append(b, " ("...)
+		b = append(b, f.IdentityOptions...)
+		b = append(b, ')')
+	}
+	return b
 }
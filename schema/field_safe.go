@@ -0,0 +1,14 @@
+//go:build appengine
+// +build appengine
+
+package schema
+
+import "reflect"
+
+type fieldSetter func()
+
+func (t *Table) initFieldSetters() {}
+
+func (f *Field) scanFast(strct reflect.Value, src interface{}) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,146 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fieldStatus string
+
+type fieldEmbedded struct {
+	Level int8
+	Name  string
+}
+
+type fieldModel struct {
+	ID int64
+	fieldEmbedded
+	Status    fieldStatus
+	Active    bool
+	Count     uint16
+	Ratio     float32
+	Data      []byte
+	CreatedAt time.Time
+	Ptr       *string
+}
+
+func TestFieldScanValue(t *testing.T) {
+	tables := NewTables(newNopDialect())
+	table := tables.Get(reflect.TypeOf((*fieldModel)(nil)))
+
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	row := map[string]interface{}{
+		"id":         int64(42),
+		"level":      []byte("-3"),
+		"name":       "hello",
+		"status":     []byte("active"),
+		"active":     int64(1),
+		"count":      "7",
+		"ratio":      float64(0.5),
+		"data":       []byte("data"),
+		"created_at": tm,
+		"ptr":        "ptr",
+	}
+
+	var got fieldModel
+	strct := reflect.ValueOf(&got).Elem()
+	for column, src := range row {
+		field := table.FieldMap[column]
+		require.NoError(t, field.ScanValue(strct, src), column)
+	}
+
+	str := "ptr"
+	require.Equal(t, fieldModel{
+		ID:            42,
+		fieldEmbedded: fieldEmbedded{Level: -3, Name: "hello"},
+		Status:        "active",
+		Active:        true,
+		Count:         7,
+		Ratio:         0.5,
+		Data:          []byte("data"),
+		CreatedAt:     tm,
+		Ptr:           &str,
+	}, got)
+
+	got.Ptr = nil
+	for column := range row {
+		if column == "ptr" {
+			continue
+		}
+		field := table.FieldMap[column]
+		require.NoError(t, field.ScanValue(strct, nil), column)
+	}
+	require.Equal(t, fieldModel{}, got)
+
+	err := table.FieldMap["id"].ScanValue(strct, true)
+	require.EqualError(t, err, "bun: can't scan true (bool) into int64")
+}
+
+type wideTimestamps struct {
+	CreatedAt, UpdatedAt, DeletedAt time.Time
+}
+
+type wideAudit struct {
+	wideTimestamps
+	CreatedBy, UpdatedBy int64
+}
+
+type wideModel struct {
+	ID                                 int64
+	S1, S2, S3, S4, S5, S6, S7, S8, S9 string
+	I1, I2, I3, I4, I5, I6, I7, I8, I9 int64
+	F1, F2, F3, F4, F5, F6, F7, F8, F9 float64
+	B1, B2, B3, B4, B5, B6, B7, B8, B9 bool
+	U1, U2, U3, U4, U5, U6, U7, U8, U9 uint32
+	wideAudit
+}
+
+func BenchmarkScanWideStruct(b *testing.B) {
+	tables := NewTables(newNopDialect())
+	table := tables.Get(reflect.TypeOf((*wideModel)(nil)))
+
+	srcs := make([]interface{}, len(table.Fields))
+	for i, field := range table.Fields {
+		switch field.IndirectType.Kind() {
+		case reflect.String:
+			srcs[i] = "hello world"
+		case reflect.Int64, reflect.Uint32:
+			srcs[i] = int64(i)
+		case reflect.Float64:
+			srcs[i] = float64(i)
+		case reflect.Bool:
+			srcs[i] = int64(1)
+		case reflect.Struct:
+			srcs[i] = time.Now()
+		}
+	}
+
+	b.Run("ScanValue", func(b *testing.B) {
+		b.ReportAllocs()
+		var model wideModel
+		strct := reflect.ValueOf(&model).Elem()
+		for i := 0; i < b.N; i++ {
+			for j, field := range table.Fields {
+				if err := field.ScanValue(strct, srcs[j]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		var model wideModel
+		strct := reflect.ValueOf(&model).Elem()
+		for i := 0; i < b.N; i++ {
+			for j, field := range table.Fields {
+				if err := field.ScanWithCheck(field.Value(strct), srcs[j]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
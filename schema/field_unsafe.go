@@ -0,0 +1,210 @@
+//go:build !appengine
+// +build !appengine
+
+package schema
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+type fieldSetter func(ptr unsafe.Pointer, src interface{}) error
+
+// initFieldSetters precomputes offsets and typed setters for basic fields, so scanning
+// does not walk the field index and set values with reflection for every column and row.
+func (t *Table) initFieldSetters() {
+	for _, field := range t.allFields {
+		if !isBasicField(field) {
+			continue
+		}
+		offset, ok := fieldOffset(t.Type, field.Index)
+		if !ok {
+			continue
+		}
+		setter := newFieldSetter(field.StructField.Type)
+		if setter == nil {
+			continue
+		}
+		field.setter = setter
+		field.offset = offset
+		field.setterType = t.Type
+	}
+}
+
+// fieldOffset returns the offset of the field in the struct.
+// Fields of embedded struct pointers are not supported.
+func fieldOffset(typ reflect.Type, index []int) (uintptr, bool) {
+	var offset uintptr
+	for _, i := range index {
+		if typ.Kind() != reflect.Struct {
+			return 0, false
+		}
+		sf := typ.Field(i)
+		offset += sf.Offset
+		typ = sf.Type
+	}
+	return offset, true
+}
+
+func (f *Field) scanFast(strct reflect.Value, src interface{}) (bool, error) {
+	if f.setter == nil || strct.Type() != f.setterType || !strct.CanAddr() {
+		return false, nil
+	}
+	ptr := unsafe.Add(unsafe.Pointer(strct.UnsafeAddr()), f.offset)
+	return true, f.setter(ptr, src)
+}
+
+//nolint:gocyclo
+func newFieldSetter(typ reflect.Type) fieldSetter {
+	switch typ {
+	case timeType:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			v, err := scanTimeSrc(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*time.Time)(ptr) = v
+			return nil
+		}
+	case bytesType:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			v, err := scanBytesSrc(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*[]byte)(ptr) = v
+			return nil
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			v, err := scanBoolSrc(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*bool)(ptr) = v
+			return nil
+		}
+	case reflect.String:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			v, err := scanStringSrc(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*string)(ptr) = v
+			return nil
+		}
+	case reflect.Int:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			n, err := scanInt64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*int)(ptr) = int(n)
+			return nil
+		}
+	case reflect.Int8:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			n, err := scanInt64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*int8)(ptr) = int8(n)
+			return nil
+		}
+	case reflect.Int16:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			n, err := scanInt64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*int16)(ptr) = int16(n)
+			return nil
+		}
+	case reflect.Int32:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			n, err := scanInt64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*int32)(ptr) = int32(n)
+			return nil
+		}
+	case reflect.Int64:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			n, err := scanInt64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*int64)(ptr) = n
+			return nil
+		}
+	case reflect.Uint:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			n, err := scanUint64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*uint)(ptr) = uint(n)
+			return nil
+		}
+	case reflect.Uint8:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			n, err := scanUint64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*uint8)(ptr) = uint8(n)
+			return nil
+		}
+	case reflect.Uint16:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			n, err := scanUint64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*uint16)(ptr) = uint16(n)
+			return nil
+		}
+	case reflect.Uint32:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			n, err := scanUint64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*uint32)(ptr) = uint32(n)
+			return nil
+		}
+	case reflect.Uint64:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			n, err := scanUint64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*uint64)(ptr) = n
+			return nil
+		}
+	case reflect.Float32:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			v, err := scanFloat64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*float32)(ptr) = float32(v)
+			return nil
+		}
+	case reflect.Float64:
+		return func(ptr unsafe.Pointer, src interface{}) error {
+			v, err := scanFloat64Src(src, typ)
+			if err != nil {
+				return err
+			}
+			*(*float64)(ptr) = v
+			return nil
+		}
+	}
+	return nil
+}
@@ -4,9 +4,11 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/uptrace/bun/dialect"
 	"github.com/uptrace/bun/dialect/feature"
+	"github.com/uptrace/bun/extra/bunjson"
 	"github.com/uptrace/bun/internal"
 	"github.com/uptrace/bun/internal/parser"
 )
@@ -18,6 +20,10 @@ var nopFormatter = Formatter{
 type Formatter struct {
 	dialect Dialect
 	args    *namedArgList
+	time    *TimeConfig
+	json    bunjson.Provider
+	enc     Encryptor
+	binder  *ArgBinder
 }
 
 func NewFormatter(dialect Dialect) Formatter {
@@ -58,25 +64,69 @@ func (f Formatter) AppendValue(b []byte, v reflect.Value) []byte {
 	return appender(f, b, v)
 }
 
+// AppendTime converts the time using the TimeConfig and appends it using the dialect.
+func (f Formatter) AppendTime(b []byte, tm time.Time) []byte {
+	return f.dialect.AppendTime(b, f.time.Convert(tm))
+}
+
+// TimeConfig returns the config set by WithTimeConfig or nil.
+func (f Formatter) TimeConfig() *TimeConfig {
+	return f.time
+}
+
+func (f Formatter) WithTimeConfig(cfg *TimeConfig) Formatter {
+	f.time = cfg
+	return f
+}
+
+// JSONProvider returns the provider set by WithJSONProvider or nil
+// if the global bunjson provider is used.
+func (f Formatter) JSONProvider() bunjson.Provider {
+	return f.json
+}
+
+func (f Formatter) WithJSONProvider(p bunjson.Provider) Formatter {
+	f.json = p
+	return f
+}
+
+// Encryptor returns the encryptor set by WithEncryptor or nil.
+func (f Formatter) Encryptor() Encryptor {
+	return f.enc
+}
+
+func (f Formatter) WithEncryptor(e Encryptor) Formatter {
+	f.enc = e
+	return f
+}
+
+func (f Formatter) marshalJSON(v interface{}) ([]byte, error) {
+	if f.json != nil {
+		return f.json.Marshal(v)
+	}
+	return bunjson.Marshal(v)
+}
+
 func (f Formatter) HasFeature(feature feature.Feature) bool {
 	return f.dialect.Features().Has(feature)
 }
 
 func (f Formatter) WithArg(arg NamedArgAppender) Formatter {
-	return Formatter{
-		dialect: f.dialect,
-		args:    f.args.WithArg(arg),
-	}
+	f.args = f.args.WithArg(arg)
+	return f
 }
 
 func (f Formatter) WithNamedArg(name string, value interface{}) Formatter {
-	return Formatter{
-		dialect: f.dialect,
-		args:    f.args.WithArg(&namedArg{name: name, value: value}),
-	}
+	f.args = f.args.WithArg(&namedArg{name: name, value: value})
+	return f
 }
 
 func (f Formatter) FormatQuery(query string, args ...interface{}) string {
+	if len(args) == 1 && !f.IsNop() {
+		if params, ok := args[0].(NamedParams); ok {
+			return internal.String(f.appendNamedParams(nil, query, params))
+		}
+	}
 	if f.IsNop() || (args == nil && f.args == nil) || strings.IndexByte(query, '?') == -1 {
 		return query
 	}
@@ -84,6 +134,11 @@ func (f Formatter) FormatQuery(query string, args ...interface{}) string {
 }
 
 func (f Formatter) AppendQuery(dst []byte, query string, args ...interface{}) []byte {
+	if len(args) == 1 && !f.IsNop() {
+		if params, ok := args[0].(NamedParams); ok {
+			return f.appendNamedParams(dst, query, params)
+		}
+	}
 	if f.IsNop() || (args == nil && f.args == nil) || strings.IndexByte(query, '?') == -1 {
 		return append(dst, query...)
 	}
@@ -115,6 +170,12 @@ func (f Formatter) append(dst []byte, p *parser.Parser, args []interface{}) []by
 		dst = append(dst, b...)
 
 		name, numeric := p.ReadIdentifier()
+		if name != "" && !numeric {
+			// Named args can take arguments, e.g. ?RelationAlias("Author").
+			if call, ok := p.ReadCall(); ok {
+				name += call
+			}
+		}
 		if name != "" {
 			if numeric {
 				idx, err := strconv.Atoi(name)
@@ -162,7 +223,18 @@ func (f Formatter) append(dst []byte, p *parser.Parser, args []interface{}) []by
 	return dst
 }
 
+// AppendArg appends the arg like it is appended in place of a ? placeholder.
+func (f Formatter) AppendArg(b []byte, arg interface{}) []byte {
+	return f.appendArg(b, arg)
+}
+
 func (f Formatter) appendArg(b []byte, arg interface{}) []byte {
+	if f.binder != nil {
+		if v, ok := f.bindValue(reflect.ValueOf(arg)); ok {
+			return f.binder.bind(b, v)
+		}
+	}
+
 	switch arg := arg.(type) {
 	case QueryAppender:
 		bb, err := arg.AppendQuery(f, b)
@@ -203,6 +275,25 @@ func (l *namedArgList) AppendNamedArg(fmter Formatter, b []byte, name string) ([
 	return b, false
 }
 
+// TableAliasAppender is implemented by named args that know the alias of a table
+// in the query, for example, the query itself.
+type TableAliasAppender interface {
+	AppendTableAlias(fmter Formatter, b []byte, table *Table) ([]byte, bool)
+}
+
+// AppendTableAlias appends the alias of the table in the query being formatted.
+// It returns false when the formatter does not know the table.
+func (f Formatter) AppendTableAlias(b []byte, table *Table) ([]byte, bool) {
+	for l := f.args; l != nil && l.arg != nil; l = l.next {
+		if arg, ok := l.arg.(TableAliasAppender); ok {
+			if b, ok := arg.AppendTableAlias(f, b, table); ok {
+				return b, true
+			}
+		}
+	}
+	return b, false
+}
+
 //------------------------------------------------------------------------------
 
 type namedArg struct {
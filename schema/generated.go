@@ -0,0 +1,169 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v3"
+
+	"github.com/uptrace/bun/dialect/sqltype"
+)
+
+// GeneratedField contains the functions generated by bungen for a struct field.
+// The functions receive a pointer to the model, e.g. *User, and access the field
+// directly instead of using reflection.
+type GeneratedField struct {
+	Append func(fmter Formatter, b []byte, model interface{}) []byte
+	Scan   func(model interface{}, src interface{}) error
+	IsZero func(model interface{}) bool
+}
+
+var generatedModels = xsync.NewMapOf[reflect.Type, map[string]*GeneratedField]()
+
+var (
+	boolType    = reflect.TypeOf(false)
+	int64Type   = reflect.TypeOf(int64(0))
+	uint64Type  = reflect.TypeOf(uint64(0))
+	float64Type = reflect.TypeOf(float64(0))
+	stringType  = reflect.TypeOf("")
+)
+
+// RegisterGeneratedModel registers the functions generated by bungen for the model fields,
+// which are keyed by the Go field name. It is called from the init function of the generated
+// file and must be called before the model is used.
+//
+// Generated functions are only used for fields with basic Go types, []byte, and time.Time
+// that don't have custom appenders or scanners; other fields are still handled with reflection.
+func RegisterGeneratedModel(model interface{}, fields map[string]GeneratedField) {
+	typ := reflect.TypeOf(model)
+	if typ == nil {
+		panic(fmt.Errorf("bun: RegisterGeneratedModel(nil)"))
+	}
+	typ = indirectType(typ)
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Errorf("bun: RegisterGeneratedModel(unsupported %s)", typ))
+	}
+
+	m := make(map[string]*GeneratedField, len(fields))
+	for name, field := range fields {
+		field := field
+		m[name] = &field
+	}
+	generatedModels.Store(typ, m)
+}
+
+// initGenerated assigns the generated functions to the table fields.
+func (t *Table) initGenerated() {
+	fields, ok := generatedModels.Load(t.Type)
+	if !ok {
+		return
+	}
+	for _, field := range t.allFields {
+		gen, ok := fields[field.GoName]
+		if !ok || !canUseGenerated(field) {
+			continue
+		}
+		field.gen = gen
+		field.genType = t.Type
+	}
+}
+
+func canUseGenerated(f *Field) bool {
+	return len(f.Index) == 1 && isBasicField(f)
+}
+
+// isBasicField reports whether the field has a basic Go type, []byte, or time.Time
+// and uses the default appender and scanner for the type.
+func isBasicField(f *Field) bool {
+	if f.IsPtr || !hasTypeAppender(f) {
+		return false
+	}
+
+	typ := f.StructField.Type
+	if typ == timeType {
+		return true
+	}
+	if _, _, ok := LookupCodec(typ); ok {
+		return false
+	}
+	ptr := reflect.PointerTo(typ)
+	if ptr.Implements(scannerType) || ptr.Implements(driverValuerType) ||
+		ptr.Implements(queryAppenderType) || ptr.Implements(isZeroerType) {
+		return false
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return typ == bytesType
+}
+
+// hasTypeAppender reports whether the field is appended by the appender of its Go type,
+// i.e. its tag options and SQL type don't change how the value is appended.
+func hasTypeAppender(f *Field) bool {
+	if f.JSON {
+		return false
+	}
+	for _, opt := range []string{
+		"msgpack", "array", "hstore", "composite", "multirange", "discriminator",
+		"json_use_number", "json_omit_empty", "encrypted",
+	} {
+		if f.Tag.HasOption(opt) {
+			return false
+		}
+	}
+	switch strings.ToUpper(f.UserSQLType) {
+	case sqltype.JSON, sqltype.JSONB, sqltype.UUID, "VECTOR":
+		return false
+	}
+	return true
+}
+
+// generated returns the generated functions for the field of the struct.
+func (f *Field) generated(strct reflect.Value) (*GeneratedField, bool) {
+	if f.gen == nil || len(f.Index) != 1 || strct.Type() != f.genType || !strct.CanAddr() {
+		return nil, false
+	}
+	return f.gen, true
+}
+
+// ScanBool converts the src value returned by a driver to bool.
+func ScanBool(src interface{}) (bool, error) {
+	return scanBoolSrc(src, boolType)
+}
+
+// ScanInt64 converts the src value returned by a driver to int64.
+func ScanInt64(src interface{}) (int64, error) {
+	return scanInt64Src(src, int64Type)
+}
+
+// ScanUint64 converts the src value returned by a driver to uint64.
+func ScanUint64(src interface{}) (uint64, error) {
+	return scanUint64Src(src, uint64Type)
+}
+
+// ScanFloat64 converts the src value returned by a driver to float64.
+func ScanFloat64(src interface{}) (float64, error) {
+	return scanFloat64Src(src, float64Type)
+}
+
+// ScanString converts the src value returned by a driver to string.
+func ScanString(src interface{}) (string, error) {
+	return scanStringSrc(src, stringType)
+}
+
+// ScanBytes converts the src value returned by a driver to a copy of the bytes.
+func ScanBytes(src interface{}) ([]byte, error) {
+	return scanBytesSrc(src, bytesType)
+}
+
+// ScanTime converts the src value returned by a driver to time.Time.
+func ScanTime(src interface{}) (time.Time, error) {
+	return scanTimeSrc(src, timeType)
+}
@@ -28,6 +28,24 @@ var beforeAppendModelHookType = reflect.TypeOf((*BeforeAppendModelHook)(nil)).El
 
 //------------------------------------------------------------------------------
 
+// BeforeValidateHook is called by insert and update queries before ValidateHook,
+// for example, to normalize values.
+type BeforeValidateHook interface {
+	BeforeValidate(ctx context.Context) error
+}
+
+var beforeValidateHookType = reflect.TypeOf((*BeforeValidateHook)(nil)).Elem()
+
+// ValidateHook is called by insert and update queries for each model before the query
+// is generated. The query is not executed if Validate returns an error.
+type ValidateHook interface {
+	Validate(ctx context.Context) error
+}
+
+var validateHookType = reflect.TypeOf((*ValidateHook)(nil)).Elem()
+
+//------------------------------------------------------------------------------
+
 type BeforeScanRowHook interface {
 	BeforeScanRow(context.Context) error
 }
@@ -0,0 +1,53 @@
+package schema
+
+import (
+	"encoding/hex"
+	"hash/fnv"
+	"unicode/utf8"
+)
+
+// IdentLimiter is implemented by dialects that limit the length of identifiers,
+// e.g. Postgres truncates identifiers longer than 63 bytes.
+type IdentLimiter interface {
+	// MaxIdentLen returns the maximum length of identifiers in bytes.
+	MaxIdentLen() int
+}
+
+// MaxIdentLen returns the maximum length of identifiers in the dialect
+// or 0 if the length is not limited.
+func MaxIdentLen(d Dialect) int {
+	if d, ok := d.(IdentLimiter); ok {
+		return d.MaxIdentLen()
+	}
+	return 0
+}
+
+const identHashLen = 8
+
+// TruncateIdent returns the identifier as is when it fits in maxLen bytes or maxLen is 0.
+// Longer identifiers are truncated and suffixed with "_" and a hash of the whole identifier,
+// so different identifiers with a common prefix stay different and the same identifier
+// is always truncated the same way.
+func TruncateIdent(ident string, maxLen int) string {
+	if maxLen <= 0 || len(ident) <= maxLen {
+		return ident
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(ident))
+	sum := h.Sum(nil)
+
+	n := maxLen - identHashLen - 1
+	if n < 0 {
+		n = 0
+	}
+	for n > 0 && !utf8.RuneStart(ident[n]) {
+		n--
+	}
+
+	b := make([]byte, 0, n+1+identHashLen)
+	b = append(b, ident[:n]...)
+	b = append(b, '_')
+	b = hex.AppendEncode(b, sum)
+	return string(b)
+}
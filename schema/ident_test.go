@@ -0,0 +1,27 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateIdent(t *testing.T) {
+	require.Equal(t, "short", TruncateIdent("short", 63))
+	require.Equal(t, strings.Repeat("a", 80), TruncateIdent(strings.Repeat("a", 80), 0))
+
+	long1 := strings.Repeat("author__", 8) + "first_name"
+	long2 := strings.Repeat("author__", 8) + "last_name"
+
+	got1 := TruncateIdent(long1, 63)
+	require.Len(t, got1, 63)
+	require.True(t, strings.HasPrefix(got1, long1[:54]+"_"))
+	require.Equal(t, got1, TruncateIdent(long1, 63))
+	require.NotEqual(t, got1, TruncateIdent(long2, 63))
+
+	// Multi-byte characters are not split.
+	got := TruncateIdent(strings.Repeat("é", 40), 20)
+	require.LessOrEqual(t, len(got), 20)
+	require.True(t, strings.HasPrefix(got, strings.Repeat("é", 5)+"_"))
+}
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/uptrace/bun/dialect"
+)
+
+// NamedParams binds :name placeholders to the fields of a struct or the values of a map
+// with string keys. Struct fields are referenced by their column names, e.g. :user_id.
+// Slice values, except []byte, are expanded to comma-separated lists,
+// e.g. id IN (:ids) becomes id IN (1, 2, 3).
+//
+// When the params are the only arg of a query, the query is formatted with :name
+// placeholders instead of ? placeholders.
+type NamedParams struct {
+	value interface{}
+}
+
+func NewNamedParams(value interface{}) NamedParams {
+	return NamedParams{value: value}
+}
+
+func (f Formatter) appendNamedParams(b []byte, query string, params NamedParams) []byte {
+	lookup, err := params.lookup(f)
+	if err != nil {
+		return dialect.AppendError(b, err)
+	}
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch c {
+		case '\'', '"', '`':
+			// Copy quoted strings and identifiers as is.
+			end := i + 1
+			for end < len(query) && query[end] != c {
+				end++
+			}
+			if end == len(query) {
+				return append(b, query[i:]...)
+			}
+			b = append(b, query[i:end+1]...)
+			i = end
+			continue
+		case ':':
+			if i+1 < len(query) && query[i+1] == ':' {
+				// PostgreSQL casts, e.g. value::text.
+				b = append(b, "::"...)
+				i++
+				continue
+			}
+
+			end := i + 1
+			for end < len(query) && isNamedParamChar(query[end], end == i+1) {
+				end++
+			}
+			if end == i+1 {
+				break
+			}
+
+			name := query[i+1 : end]
+			v, ok := lookup(name)
+			if !ok {
+				return dialect.AppendError(b, fmt.Errorf("bun: named param %q is not found", name))
+			}
+			b = f.appendNamedParam(b, v)
+			i = end - 1
+			continue
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+func (f Formatter) appendNamedParam(b []byte, v reflect.Value) []byte {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return dialect.AppendNull(b)
+	}
+
+	if v.Kind() == reflect.Slice && v.Type() != bytesType {
+		if v.Len() == 0 {
+			return dialect.AppendNull(b)
+		}
+		return AppendQueryAppender(f, b, In(v.Interface()))
+	}
+	return f.appendArg(b, v.Interface())
+}
+
+// lookup returns a func that looks up the values of the params by name.
+func (p NamedParams) lookup(fmter Formatter) (func(name string) (reflect.Value, bool), error) {
+	v := reflect.Indirect(reflect.ValueOf(p.value))
+	switch v.Kind() {
+	case reflect.Struct:
+		table := fmter.Dialect().Tables().Get(v.Type())
+		return func(name string) (reflect.Value, bool) {
+			field, ok := table.FieldMap[name]
+			if !ok {
+				return reflect.Value{}, false
+			}
+			fv, ok := fieldByIndex(v, field.Index)
+			if !ok {
+				return reflect.Value{}, true
+			}
+			return fv, true
+		}, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("bun: named params must have string keys, got %s", v.Type())
+		}
+		return func(name string) (reflect.Value, bool) {
+			fv := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			return fv, fv.IsValid()
+		}, nil
+	default:
+		return nil, fmt.Errorf("bun: named params must be a struct or a map, got %T", p.value)
+	}
+}
+
+func isNamedParamChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	}
+	return false
+}
@@ -17,6 +17,7 @@ var (
 	ipNetType          = reflect.TypeOf((*net.IPNet)(nil)).Elem()
 	netipPrefixType    = reflect.TypeOf((*netip.Prefix)(nil)).Elem()
 	netipAddrType      = reflect.TypeOf((*netip.Addr)(nil)).Elem()
+	hardwareAddrType   = reflect.TypeOf((*net.HardwareAddr)(nil)).Elem()
 	jsonRawMessageType = reflect.TypeOf((*json.RawMessage)(nil)).Elem()
 
 	driverValuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
@@ -25,6 +25,9 @@ type Relation struct {
 	OnUpdate  string
 	OnDelete  string
 	Condition []string
+	// InnerJoin is set with the join_type:inner tag option to join has-one and belongs-to
+	// relations with INNER JOIN instead of LEFT JOIN.
+	InnerJoin bool
 
 	PolymorphicField *Field
 	PolymorphicValue string
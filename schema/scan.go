@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"net"
+	"net/netip"
 	"reflect"
 	"strconv"
 	"strings"
@@ -56,21 +57,83 @@ func init() {
 var scannerCache = xsync.NewMapOf[reflect.Type, ScannerFunc]()
 
 func FieldScanner(dialect Dialect, field *Field) ScannerFunc {
+	if field.Tag.HasOption("encrypted") {
+		return scanEncryptedNoEncryptor
+	}
 	if field.Tag.HasOption("msgpack") {
 		return scanMsgpack
 	}
 	if field.Tag.HasOption("json_use_number") {
 		return scanJSONUseNumber
 	}
+	if column, ok := field.Tag.Option("discriminator"); ok {
+		if column == "" {
+			return scanDiscriminatorEnvelope
+		}
+		return scanDiscriminatorColumn
+	}
 	if field.StructField.Type.Kind() == reflect.Interface {
 		switch strings.ToUpper(field.UserSQLType) {
 		case sqltype.JSON, sqltype.JSONB:
 			return scanJSONIntoInterface
 		}
 	}
+	if strings.EqualFold(field.UserSQLType, sqltype.UUID) {
+		fieldType := field.StructField.Type
+		if isUUIDArray(fieldType) {
+			return scanUUID
+		}
+		if fieldType.Kind() == reflect.Ptr && isUUIDArray(fieldType.Elem()) {
+			return PtrScanner(scanUUID)
+		}
+	}
 	return Scanner(field.StructField.Type)
 }
 
+func isJSONField(field *Field) bool {
+	if field.Tag.HasOption("msgpack") || field.Tag.HasOption("encrypted") {
+		return false
+	}
+	if field.Tag.HasOption("json_use_number") {
+		return true
+	}
+	switch strings.ToUpper(field.UserSQLType) {
+	case sqltype.JSON, sqltype.JSONB:
+		return !hasCustomScanner(field.IndirectType)
+	}
+	return isJSONType(field.IndirectType)
+}
+
+// isJSONType reports whether the scanner for the type decodes JSON.
+// It must be kept in sync with scanner.
+func isJSONType(typ reflect.Type) bool {
+	if hasCustomScanner(typ) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Map, reflect.Struct:
+		return true
+	case reflect.Slice:
+		return typ.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+func hasCustomScanner(typ reflect.Type) bool {
+	if codecScanner(typ) != nil {
+		return true
+	}
+	switch typ {
+	case bytesType, timeType, ipType, ipNetType, netipAddrType, netipPrefixType,
+		hardwareAddrType, jsonRawMessageType:
+		return true
+	}
+	if IsUUIDType(typ) {
+		return true
+	}
+	return typ.Implements(scannerType) || reflect.PointerTo(typ).Implements(scannerType)
+}
+
 func Scanner(typ reflect.Type) ScannerFunc {
 	if v, ok := scannerCache.Load(typ); ok {
 		return v
@@ -85,6 +148,10 @@ func Scanner(typ reflect.Type) ScannerFunc {
 }
 
 func scanner(typ reflect.Type) ScannerFunc {
+	if fn := codecScanner(typ); fn != nil {
+		return fn
+	}
+
 	kind := typ.Kind()
 
 	if kind == reflect.Ptr {
@@ -102,6 +169,12 @@ func scanner(typ reflect.Type) ScannerFunc {
 		return scanIP
 	case ipNetType:
 		return scanIPNet
+	case netipAddrType:
+		return scanNetipAddr
+	case netipPrefixType:
+		return scanNetipPrefix
+	case hardwareAddrType:
+		return scanHardwareAddr
 	case jsonRawMessageType:
 		return scanBytes
 	}
@@ -117,6 +190,10 @@ func scanner(typ reflect.Type) ScannerFunc {
 		}
 	}
 
+	if IsUUIDType(typ) {
+		return scanUUID
+	}
+
 	if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
 		return scanBytes
 	}
@@ -125,197 +202,183 @@ func scanner(typ reflect.Type) ScannerFunc {
 }
 
 func scanBool(dest reflect.Value, src interface{}) error {
+	v, err := scanBoolSrc(src, dest.Type())
+	if err != nil {
+		return err
+	}
+	dest.SetBool(v)
+	return nil
+}
+
+func scanBoolSrc(src interface{}, typ reflect.Type) (bool, error) {
 	switch src := src.(type) {
 	case nil:
-		dest.SetBool(false)
-		return nil
+		return false, nil
 	case bool:
-		dest.SetBool(src)
-		return nil
+		return src, nil
 	case int64:
-		dest.SetBool(src != 0)
-		return nil
+		return src != 0, nil
 	case []byte:
-		f, err := strconv.ParseBool(internal.String(src))
-		if err != nil {
-			return err
-		}
-		dest.SetBool(f)
-		return nil
+		return strconv.ParseBool(internal.String(src))
 	case string:
-		f, err := strconv.ParseBool(src)
-		if err != nil {
-			return err
-		}
-		dest.SetBool(f)
-		return nil
+		return strconv.ParseBool(src)
 	default:
-		return scanError(dest.Type(), src)
+		return false, scanError(typ, src)
 	}
 }
 
 func scanInt64(dest reflect.Value, src interface{}) error {
+	n, err := scanInt64Src(src, dest.Type())
+	if err != nil {
+		return err
+	}
+	dest.SetInt(n)
+	return nil
+}
+
+func scanInt64Src(src interface{}, typ reflect.Type) (int64, error) {
 	switch src := src.(type) {
 	case nil:
-		dest.SetInt(0)
-		return nil
+		return 0, nil
 	case int64:
-		dest.SetInt(src)
-		return nil
+		return src, nil
 	case uint64:
-		dest.SetInt(int64(src))
-		return nil
+		return int64(src), nil
 	case []byte:
-		n, err := strconv.ParseInt(internal.String(src), 10, 64)
-		if err != nil {
-			return err
-		}
-		dest.SetInt(n)
-		return nil
+		return strconv.ParseInt(internal.String(src), 10, 64)
 	case string:
-		n, err := strconv.ParseInt(src, 10, 64)
-		if err != nil {
-			return err
-		}
-		dest.SetInt(n)
-		return nil
+		return strconv.ParseInt(src, 10, 64)
 	default:
-		return scanError(dest.Type(), src)
+		return 0, scanError(typ, src)
 	}
 }
 
 func scanUint64(dest reflect.Value, src interface{}) error {
+	n, err := scanUint64Src(src, dest.Type())
+	if err != nil {
+		return err
+	}
+	dest.SetUint(n)
+	return nil
+}
+
+func scanUint64Src(src interface{}, typ reflect.Type) (uint64, error) {
 	switch src := src.(type) {
 	case nil:
-		dest.SetUint(0)
-		return nil
+		return 0, nil
 	case uint64:
-		dest.SetUint(src)
-		return nil
+		return src, nil
 	case int64:
-		dest.SetUint(uint64(src))
-		return nil
+		return uint64(src), nil
 	case []byte:
-		n, err := strconv.ParseUint(internal.String(src), 10, 64)
-		if err != nil {
-			return err
-		}
-		dest.SetUint(n)
-		return nil
+		return strconv.ParseUint(internal.String(src), 10, 64)
 	case string:
-		n, err := strconv.ParseUint(src, 10, 64)
-		if err != nil {
-			return err
-		}
-		dest.SetUint(n)
-		return nil
+		return strconv.ParseUint(src, 10, 64)
 	default:
-		return scanError(dest.Type(), src)
+		return 0, scanError(typ, src)
 	}
 }
 
 func scanFloat64(dest reflect.Value, src interface{}) error {
+	f, err := scanFloat64Src(src, dest.Type())
+	if err != nil {
+		return err
+	}
+	dest.SetFloat(f)
+	return nil
+}
+
+func scanFloat64Src(src interface{}, typ reflect.Type) (float64, error) {
 	switch src := src.(type) {
 	case nil:
-		dest.SetFloat(0)
-		return nil
+		return 0, nil
 	case float64:
-		dest.SetFloat(src)
-		return nil
+		return src, nil
 	case []byte:
-		f, err := strconv.ParseFloat(internal.String(src), 64)
-		if err != nil {
-			return err
-		}
-		dest.SetFloat(f)
-		return nil
+		return strconv.ParseFloat(internal.String(src), 64)
 	case string:
-		f, err := strconv.ParseFloat(src, 64)
-		if err != nil {
-			return err
-		}
-		dest.SetFloat(f)
-		return nil
+		return strconv.ParseFloat(src, 64)
 	default:
-		return scanError(dest.Type(), src)
+		return 0, scanError(typ, src)
 	}
 }
 
 func scanString(dest reflect.Value, src interface{}) error {
+	str, err := scanStringSrc(src, dest.Type())
+	if err != nil {
+		return err
+	}
+	dest.SetString(str)
+	return nil
+}
+
+func scanStringSrc(src interface{}, typ reflect.Type) (string, error) {
 	switch src := src.(type) {
 	case nil:
-		dest.SetString("")
-		return nil
+		return "", nil
 	case string:
-		dest.SetString(src)
-		return nil
+		return src, nil
 	case []byte:
-		dest.SetString(string(src))
-		return nil
+		return string(src), nil
 	case time.Time:
-		dest.SetString(src.Format(time.RFC3339Nano))
-		return nil
+		return src.Format(time.RFC3339Nano), nil
 	case int64:
-		dest.SetString(strconv.FormatInt(src, 10))
-		return nil
+		return strconv.FormatInt(src, 10), nil
 	case uint64:
-		dest.SetString(strconv.FormatUint(src, 10))
-		return nil
+		return strconv.FormatUint(src, 10), nil
 	case float64:
-		dest.SetString(strconv.FormatFloat(src, 'G', -1, 64))
-		return nil
+		return strconv.FormatFloat(src, 'G', -1, 64), nil
 	default:
-		return scanError(dest.Type(), src)
+		return "", scanError(typ, src)
 	}
 }
 
 func scanBytes(dest reflect.Value, src interface{}) error {
+	b, err := scanBytesSrc(src, dest.Type())
+	if err != nil {
+		return err
+	}
+	dest.SetBytes(b)
+	return nil
+}
+
+func scanBytesSrc(src interface{}, typ reflect.Type) ([]byte, error) {
 	switch src := src.(type) {
 	case nil:
-		dest.SetBytes(nil)
-		return nil
+		return nil, nil
 	case string:
-		dest.SetBytes([]byte(src))
-		return nil
+		return []byte(src), nil
 	case []byte:
 		clone := make([]byte, len(src))
 		copy(clone, src)
-
-		dest.SetBytes(clone)
-		return nil
+		return clone, nil
 	default:
-		return scanError(dest.Type(), src)
+		return nil, scanError(typ, src)
 	}
 }
 
 func scanTime(dest reflect.Value, src interface{}) error {
+	tm, err := scanTimeSrc(src, dest.Type())
+	if err != nil {
+		return err
+	}
+	destTime := dest.Addr().Interface().(*time.Time)
+	*destTime = tm
+	return nil
+}
+
+func scanTimeSrc(src interface{}, typ reflect.Type) (time.Time, error) {
 	switch src := src.(type) {
 	case nil:
-		destTime := dest.Addr().Interface().(*time.Time)
-		*destTime = time.Time{}
-		return nil
+		return time.Time{}, nil
 	case time.Time:
-		destTime := dest.Addr().Interface().(*time.Time)
-		*destTime = src
-		return nil
+		return src, nil
 	case string:
-		srcTime, err := internal.ParseTime(src)
-		if err != nil {
-			return err
-		}
-		destTime := dest.Addr().Interface().(*time.Time)
-		*destTime = srcTime
-		return nil
+		return internal.ParseTime(src)
 	case []byte:
-		srcTime, err := internal.ParseTime(internal.String(src))
-		if err != nil {
-			return err
-		}
-		destTime := dest.Addr().Interface().(*time.Time)
-		*destTime = srcTime
-		return nil
+		return internal.ParseTime(internal.String(src))
 	default:
-		return scanError(dest.Type(), src)
+		return time.Time{}, scanError(typ, src)
 	}
 }
 
@@ -378,7 +441,13 @@ func scanIP(dest reflect.Value, src interface{}) error {
 		return err
 	}
 
-	ip := net.ParseIP(internal.String(b))
+	s := internal.String(b)
+	// PostgreSQL inet columns may include a netmask, e.g. 192.168.0.1/24.
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		s = s[:i]
+	}
+
+	ip := net.ParseIP(s)
 	if ip == nil {
 		return fmt.Errorf("bun: invalid ip: %q", b)
 	}
@@ -410,6 +479,74 @@ func scanIPNet(dest reflect.Value, src interface{}) error {
 	return nil
 }
 
+func scanNetipAddr(dest reflect.Value, src interface{}) error {
+	if src == nil {
+		return scanNull(dest)
+	}
+
+	b, err := toBytes(src)
+	if err != nil {
+		return err
+	}
+
+	s := internal.String(b)
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		s = s[:i]
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return err
+	}
+
+	ptr := dest.Addr().Interface().(*netip.Addr)
+	*ptr = addr
+
+	return nil
+}
+
+func scanNetipPrefix(dest reflect.Value, src interface{}) error {
+	if src == nil {
+		return scanNull(dest)
+	}
+
+	b, err := toBytes(src)
+	if err != nil {
+		return err
+	}
+
+	prefix, err := netip.ParsePrefix(internal.String(b))
+	if err != nil {
+		return err
+	}
+
+	ptr := dest.Addr().Interface().(*netip.Prefix)
+	*ptr = prefix
+
+	return nil
+}
+
+func scanHardwareAddr(dest reflect.Value, src interface{}) error {
+	if src == nil {
+		return scanNull(dest)
+	}
+
+	b, err := toBytes(src)
+	if err != nil {
+		return err
+	}
+
+	mac, err := net.ParseMAC(internal.String(b))
+	if err != nil {
+		return err
+	}
+
+	ptr := dest.Addr().Interface().(*net.HardwareAddr)
+	*ptr = mac
+
+	return nil
+}
+
 func addrScanner(fn ScannerFunc) ScannerFunc {
 	return func(dest reflect.Value, src interface{}) error {
 		if !dest.CanAddr() {
@@ -0,0 +1,156 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// SoftDelete defines how a soft delete column marks rows as deleted.
+//
+// The soft_delete tag option selects a built-in representation based on the field type:
+//
+//   - time.Time, sql.NullTime, and int64 fields store the time of deletion, and rows
+//     that are not deleted have NULL or a zero time;
+//   - bool fields, e.g. `bun:",soft_delete"` or `bun:",soft_delete:flag"`, are true for deleted rows;
+//   - string fields with a status in the tag, e.g. `bun:",soft_delete:archived"`, store
+//     the status for deleted rows and any other status for rows that are not deleted.
+//
+// Models can implement SoftDeleteModel to use a custom representation.
+type SoftDelete interface {
+	// AppendDeletedValue appends the value that marks a row as deleted at the time.
+	AppendDeletedValue(fmter Formatter, b []byte, tm time.Time) []byte
+	// AppendWhere appends the condition on the column that matches the rows that
+	// are not deleted, or the rows that are deleted if deleted is true.
+	AppendWhere(fmter Formatter, b []byte, column []byte, deleted bool) []byte
+	// SetDeleted sets the field value that marks a row as deleted at the time.
+	SetDeleted(fv reflect.Value, tm time.Time) error
+}
+
+// SoftDeleteModel is implemented by models that use a custom soft delete representation.
+type SoftDeleteModel interface {
+	SoftDeleteStrategy(field *Field) SoftDelete
+}
+
+func newSoftDelete(field *Field) (SoftDelete, error) {
+	value, _ := field.Tag.Option("soft_delete")
+
+	switch field.IndirectType.Kind() {
+	case reflect.Bool:
+		if value != "" && value != "flag" {
+			return nil, fmt.Errorf("bun: %s soft_delete option must be empty or flag, got %q",
+				field.GoName, value)
+		}
+		return &flagSoftDelete{field: field}, nil
+	case reflect.String:
+		// Without a status, strings store the time of deletion.
+		if value != "" && value != "time" {
+			return &statusSoftDelete{field: field, status: value}, nil
+		}
+	}
+
+	if value != "" && value != "time" {
+		return nil, fmt.Errorf("bun: %s soft_delete option must be empty or time, got %q",
+			field.GoName, value)
+	}
+	return &timeSoftDelete{field: field, update: softDeleteFieldUpdater(field)}, nil
+}
+
+//------------------------------------------------------------------------------
+
+type timeSoftDelete struct {
+	field  *Field
+	update func(fv reflect.Value, tm time.Time) error
+}
+
+func (s *timeSoftDelete) AppendDeletedValue(fmter Formatter, b []byte, tm time.Time) []byte {
+	return Append(fmter, b, tm)
+}
+
+func (s *timeSoftDelete) AppendWhere(fmter Formatter, b []byte, column []byte, deleted bool) []byte {
+	b = append(b, column...)
+	if s.field.IsPtr || s.field.NullZero {
+		if deleted {
+			return append(b, " IS NOT NULL"...)
+		}
+		return append(b, " IS NULL"...)
+	}
+
+	if deleted {
+		b = append(b, " != "...)
+	} else {
+		b = append(b, " = "...)
+	}
+	return fmter.Dialect().AppendTime(b, time.Time{})
+}
+
+func (s *timeSoftDelete) SetDeleted(fv reflect.Value, tm time.Time) error {
+	return s.update(fv, tm)
+}
+
+//------------------------------------------------------------------------------
+
+type flagSoftDelete struct {
+	field *Field
+}
+
+func (s *flagSoftDelete) AppendDeletedValue(fmter Formatter, b []byte, tm time.Time) []byte {
+	return fmter.Dialect().AppendBool(b, true)
+}
+
+func (s *flagSoftDelete) AppendWhere(fmter Formatter, b []byte, column []byte, deleted bool) []byte {
+	if deleted {
+		b = append(b, column...)
+		b = append(b, " = "...)
+		return fmter.Dialect().AppendBool(b, true)
+	}
+
+	nullable := s.field.IsPtr || s.field.NullZero
+	if nullable {
+		b = append(b, '(')
+	}
+	b = append(b, column...)
+	b = append(b, " = "...)
+	b = fmter.Dialect().AppendBool(b, false)
+	if nullable {
+		b = append(b, " OR "...)
+		b = append(b, column...)
+		b = append(b, " IS NULL)"...)
+	}
+	return b
+}
+
+func (s *flagSoftDelete) SetDeleted(fv reflect.Value, tm time.Time) error {
+	return s.field.ScanWithCheck(fv, true)
+}
+
+//------------------------------------------------------------------------------
+
+type statusSoftDelete struct {
+	field  *Field
+	status string
+}
+
+func (s *statusSoftDelete) AppendDeletedValue(fmter Formatter, b []byte, tm time.Time) []byte {
+	return fmter.Dialect().AppendString(b, s.status)
+}
+
+func (s *statusSoftDelete) AppendWhere(fmter Formatter, b []byte, column []byte, deleted bool) []byte {
+	if deleted {
+		b = append(b, column...)
+		b = append(b, " = "...)
+		return fmter.Dialect().AppendString(b, s.status)
+	}
+
+	b = append(b, '(')
+	b = append(b, column...)
+	b = append(b, " != "...)
+	b = fmter.Dialect().AppendString(b, s.status)
+	b = append(b, " OR "...)
+	b = append(b, column...)
+	return append(b, " IS NULL)"...)
+}
+
+func (s *statusSoftDelete) SetDeleted(fv reflect.Value, tm time.Time) error {
+	return s.field.ScanWithCheck(fv, s.status)
+}
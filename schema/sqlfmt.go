@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"reflect"
 	"strings"
 
 	"github.com/uptrace/bun/internal"
@@ -50,6 +51,54 @@ func (s Ident) AppendQuery(fmter Formatter, b []byte) ([]byte, error) {
 
 //------------------------------------------------------------------------------
 
+// ModelColumn represents a column of a model table. It is appended qualified
+// with the table alias, for example, "user"."email", so it can be used as an arg in
+// Where, OrderExpr, and ColumnExpr. When the model is joined as a relation,
+// the alias of the relation is used instead, for example, "author"."email". Column constants generated by bungen use it,
+// so renaming or removing a field breaks the build instead of the queries.
+type ModelColumn struct {
+	typ  reflect.Type
+	name string
+}
+
+var _ QueryAppender = ModelColumn{}
+
+// NewModelColumn returns the column with the name of the model, e.g.
+// NewModelColumn((*User)(nil), "email").
+func NewModelColumn(model interface{}, name string) ModelColumn {
+	return ModelColumn{
+		typ:  indirectType(reflect.TypeOf(model)),
+		name: name,
+	}
+}
+
+// String returns the column name, e.g. for Column and Order.
+func (c ModelColumn) String() string {
+	return c.name
+}
+
+// Name returns the column name without the table alias, e.g. for Set.
+func (c ModelColumn) Name() Name {
+	return Name(c.name)
+}
+
+func (c ModelColumn) AppendQuery(fmter Formatter, b []byte) ([]byte, error) {
+	table := fmter.Dialect().Tables().Get(c.typ)
+	field, err := table.Field(c.name)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := fmter.AppendTableAlias(b, table)
+	if !ok {
+		b = append(b, table.SQLAlias...)
+	}
+	b = append(b, '.')
+	b = append(b, field.SQLName...)
+	return b, nil
+}
+
+//------------------------------------------------------------------------------
+
 type QueryWithArgs struct {
 	Query string
 	Args  []interface{}
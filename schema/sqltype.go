@@ -47,7 +47,31 @@ var sqlTypes = []string{
 	reflect.Struct:     sqltype.VarChar,
 }
 
+// NullWrapper is implemented by generic nullable wrappers such as bun.Null[T]
+// so the SQL type is discovered from the wrapped type.
+type NullWrapper interface {
+	NullValueType() reflect.Type
+}
+
+var nullWrapperType = reflect.TypeOf((*NullWrapper)(nil)).Elem()
+
+// UnwrapNullType returns the type wrapped by a NullWrapper or nil.
+func UnwrapNullType(typ reflect.Type) reflect.Type {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return nil
+	}
+	if typ.Implements(nullWrapperType) {
+		return reflect.Zero(typ).Interface().(NullWrapper).NullValueType()
+	}
+	return nil
+}
+
 func DiscoverSQLType(typ reflect.Type) string {
+	if elemType := UnwrapNullType(typ); elemType != nil {
+		return DiscoverSQLType(elemType)
+	}
+
 	switch typ {
 	case timeType, nullTimeType, bunNullTimeType:
 		return sqltype.Timestamp
@@ -61,6 +85,12 @@ func DiscoverSQLType(typ reflect.Type) string {
 		return sqltype.VarChar
 	case jsonRawMessageType:
 		return sqltype.JSON
+	case ipType, ipNetType, netipAddrType, netipPrefixType, hardwareAddrType:
+		return sqltype.VarChar
+	}
+
+	if IsUUIDType(typ) {
+		return sqltype.UUID
 	}
 
 	switch typ.Kind() {
@@ -108,7 +138,7 @@ func (tm NullTime) AppendQuery(fmter Formatter, b []byte) ([]byte, error) {
 	if tm.IsZero() {
 		return dialect.AppendNull(b), nil
 	}
-	return fmter.Dialect().AppendTime(b, tm.Time), nil
+	return fmter.AppendTime(b, tm.Time), nil
 }
 
 func (tm *NullTime) Scan(src interface{}) error {
@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/puzpuzpuz/xsync/v3"
+)
+
+var globalSQLTypes = xsync.NewMapOf[reflect.Type, string]()
+
+// RegisterSQLType sets the SQL type of fields with the Go type of the value for all dialects,
+// so custom column types can be declared once instead of using the type tag option
+// on every field, for example:
+//
+//	schema.RegisterSQLType((*Point)(nil), "geometry(Point, 4326)")
+//
+// Types registered with Tables.RegisterSQLType take precedence. The type tag option
+// takes precedence over both. Types must be registered before the models are used.
+func RegisterSQLType(value interface{}, sqlType string) {
+	globalSQLTypes.Store(sqlTypeKey(value), sqlType)
+}
+
+// RegisterSQLType sets the SQL type of fields with the Go type of the value
+// for the dialect that owns the tables. See the package-level RegisterSQLType.
+// Tables that are already built are not changed.
+func (t *Tables) RegisterSQLType(value interface{}, sqlType string) {
+	t.sqlTypes.Store(sqlTypeKey(value), sqlType)
+}
+
+func sqlTypeKey(value interface{}) reflect.Type {
+	typ := reflect.TypeOf(value)
+	if typ == nil {
+		panic(fmt.Errorf("bun: RegisterSQLType(nil)"))
+	}
+	return indirectType(typ)
+}
+
+// registeredSQLType returns the SQL type registered for the Go type.
+func registeredSQLType(dialect Dialect, typ reflect.Type) (string, bool) {
+	if tables := dialect.Tables(); tables != nil {
+		if s, ok := tables.sqlTypes.Load(typ); ok {
+			return s, true
+		}
+	}
+	return globalSQLTypes.Load(typ)
+}
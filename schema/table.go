@@ -4,11 +4,13 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/jinzhu/inflection"
 
+	"github.com/uptrace/bun/dialect/sqltype"
 	"github.com/uptrace/bun/internal"
 	"github.com/uptrace/bun/internal/tagparser"
 )
@@ -19,6 +21,8 @@ const (
 	afterScanHookFlag
 	beforeScanRowHookFlag
 	afterScanRowHookFlag
+	beforeValidateHookFlag
+	validateHookFlag
 )
 
 var (
@@ -63,9 +67,39 @@ type Table struct {
 	Relations map[string]*Relation
 	Unique    map[string][]*Field
 
+	// UniqueOptions are the options of the named unique constraints,
+	// e.g. unique:name(deferred,nulls_not_distinct).
+	UniqueOptions map[string]*UniqueOptions
+
+	// Exclusions are the exclusion constraints of the table,
+	// e.g. exclude:gist(room_id WITH =, during WITH &&).
+	Exclusions []Exclusion
+
+	// ColumnGroups are the named groups of fields selected together, see the group tag option.
+	ColumnGroups map[string][]*Field
+
 	SoftDeleteField       *Field
+	SoftDelete            SoftDelete
 	UpdateSoftDeleteField func(fv reflect.Value, tm time.Time) error
 
+	// RowStartField and RowEndField are the period columns of a system-versioned table,
+	// see the row_start and row_end tag options.
+	RowStartField *Field
+	RowEndField   *Field
+
+	// ExtrasField receives selected columns that are not mapped to other fields.
+	ExtrasField *Field
+
+	// EntityCache reports whether the rows selected by primary key are cached,
+	// see the cache table tag option. A non-zero EntityCacheTTL overrides the default TTL.
+	EntityCache    bool
+	EntityCacheTTL time.Duration
+
+	// RenamedFrom is the previous name of the table declared with the renamed_from
+	// table tag option, so schema diffing tools can rename the table instead of
+	// dropping and creating it.
+	RenamedFrom string
+
 	flags internal.Flag
 }
 
@@ -89,6 +123,8 @@ func (table *Table) init(dialect Dialect, typ reflect.Type, canAddr bool) {
 	table.Fields = make([]*Field, 0, typ.NumField())
 	table.FieldMap = make(map[string]*Field, typ.NumField())
 	table.processFields(typ, canAddr)
+	table.initDiscriminators()
+	table.initSoftDelete()
 
 	hooks := []struct {
 		typ  reflect.Type
@@ -98,6 +134,9 @@ func (table *Table) init(dialect Dialect, typ reflect.Type, canAddr bool) {
 
 		{beforeScanRowHookType, beforeScanRowHookFlag},
 		{afterScanRowHookType, afterScanRowHookFlag},
+
+		{beforeValidateHookType, beforeValidateHookFlag},
+		{validateHookType, validateHookFlag},
 	}
 
 	typ = reflect.PointerTo(table.Type)
@@ -108,6 +147,24 @@ func (table *Table) init(dialect Dialect, typ reflect.Type, canAddr bool) {
 	}
 }
 
+func (t *Table) initSoftDelete() {
+	field := t.SoftDeleteField
+	if field == nil {
+		return
+	}
+
+	if m, ok := t.ZeroIface.(SoftDeleteModel); ok {
+		t.SoftDelete = m.SoftDeleteStrategy(field)
+	} else {
+		softDelete, err := newSoftDelete(field)
+		if err != nil {
+			panic(fmt.Errorf("bun: %s: %w", t.TypeName, err))
+		}
+		t.SoftDelete = softDelete
+	}
+	t.UpdateSoftDeleteField = t.SoftDelete.SetDeleted
+}
+
 func (t *Table) processFields(typ reflect.Type, canAddr bool) {
 	type embeddedField struct {
 		prefix     string
@@ -268,6 +325,42 @@ func (t *Table) CheckPKs() error {
 	return nil
 }
 
+// IsUniqueKey reports whether the fields are the primary keys or the columns
+// of a unique constraint, i.e. they identify at most one row.
+func (t *Table) IsUniqueKey(fields []*Field) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	if sameFields(fields, t.PKs) {
+		return true
+	}
+	for name, unique := range t.Unique {
+		if name == "" {
+			// Unnamed unique options create a constraint per column.
+			if len(fields) == 1 && slices.Contains(unique, fields[0]) {
+				return true
+			}
+			continue
+		}
+		if sameFields(fields, unique) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameFields(a, b []*Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, f := range a {
+		if !slices.Contains(b, f) {
+			return false
+		}
+	}
+	return true
+}
+
 func (t *Table) addField(field *Field) {
 	t.allFields = append(t.allFields, field)
 
@@ -276,8 +369,20 @@ func (t *Table) addField(field *Field) {
 		return
 	}
 
+	if field.Tag.HasOption("extras") {
+		// Scanned values have arbitrary types, so the map values must be empty interfaces.
+		if typ := field.StructField.Type; typ.Kind() != reflect.Map ||
+			typ.Key() != stringType ||
+			typ.Elem().Kind() != reflect.Interface || typ.Elem().NumMethod() > 0 {
+			panic(fmt.Errorf("bun: %s.%s: extras field must be map[string]interface{}, got %s",
+				t.TypeName, field.GoName, field.StructField.Type))
+		}
+		t.ExtrasField = field
+		return
+	}
+
 	if field.Tag.HasOption("join") {
-		internal.Warn.Printf(
+		t.dialect.Tables().warnf(
 			`%s.%s "join" option must come together with "rel" option`,
 			t.TypeName, field.GoName,
 		)
@@ -294,7 +399,12 @@ func (t *Table) addField(field *Field) {
 
 	if _, ok := field.Tag.Options["soft_delete"]; ok {
 		t.SoftDeleteField = field
-		t.UpdateSoftDeleteField = softDeleteFieldUpdater(field)
+	}
+	if field.Tag.HasOption("row_start") {
+		t.RowStartField = field
+	}
+	if field.Tag.HasOption("row_end") {
+		t.RowEndField = field
 	}
 
 	t.Fields = append(t.Fields, field)
@@ -358,7 +468,7 @@ func (t *Table) processBaseModelField(f reflect.StructField) {
 	tag := tagparser.Parse(f.Tag.Get("bun"))
 
 	if isKnownTableOption(tag.Name) {
-		internal.Warn.Printf(
+		t.dialect.Tables().warnf(
 			"%s.%s tag name %q is also an option name, is it a mistake? Try table:%s.",
 			t.TypeName, f.Name, tag.Name, tag.Name,
 		)
@@ -366,7 +476,7 @@ func (t *Table) processBaseModelField(f reflect.StructField) {
 
 	for name := range tag.Options {
 		if !isKnownTableOption(name) {
-			internal.Warn.Printf("%s.%s has unknown tag option: %q", t.TypeName, f.Name, name)
+			t.dialect.Tables().warnf("%s.%s has unknown tag option: %q", t.TypeName, f.Name, name)
 		}
 	}
 
@@ -386,6 +496,43 @@ func (t *Table) processBaseModelField(f reflect.StructField) {
 		t.Alias = s
 		t.SQLAlias = t.quoteIdent(s)
 	}
+
+	if s, ok := tag.Option("cache"); ok {
+		t.EntityCache = true
+		if s != "" {
+			ttl, err := time.ParseDuration(s)
+			if err != nil {
+				panic(fmt.Errorf("bun: %s has invalid cache TTL %q: %w", t.TypeName, s, err))
+			}
+			t.EntityCacheTTL = ttl
+		}
+	}
+
+	if s, ok := tag.Option("renamed_from"); ok {
+		t.RenamedFrom = s
+	}
+
+	for _, s := range tag.Options["exclude"] {
+		t.Exclusions = append(t.Exclusions, t.parseExclusion(s))
+	}
+}
+
+// parseExclusion parses the exclusion constraint, e.g. gist(room_id WITH =, during WITH &&).
+func (t *Table) parseExclusion(s string) Exclusion {
+	i := strings.IndexByte(s, '(')
+	if i == -1 || !strings.HasSuffix(s, ")") {
+		panic(fmt.Errorf("bun: %s has invalid exclusion constraint %q "+
+			"(expected exclude:method(element WITH operator, ...))", t.TypeName, s))
+	}
+
+	ex := Exclusion{
+		Using:    strings.TrimSpace(s[:i]),
+		Elements: strings.TrimSpace(s[i+1 : len(s)-1]),
+	}
+	if ex.Using == "" {
+		ex.Using = "gist"
+	}
+	return ex
 }
 
 // nolint
@@ -393,7 +540,7 @@ func (t *Table) newField(sf reflect.StructField, tag tagparser.Tag) *Field {
 	sqlName := internal.Underscore(sf.Name)
 	if tag.Name != "" && tag.Name != sqlName {
 		if isKnownFieldOption(tag.Name) {
-			internal.Warn.Printf(
+			t.dialect.Tables().warnf(
 				"%s.%s tag name %q is also an option name, is it a mistake? Try column:%s.",
 				t.TypeName, sf.Name, tag.Name, tag.Name,
 			)
@@ -407,7 +554,7 @@ func (t *Table) newField(sf reflect.StructField, tag tagparser.Tag) *Field {
 
 	for name := range tag.Options {
 		if !isKnownFieldOption(name) {
-			internal.Warn.Printf("%s.%s has unknown tag option: %q", t.TypeName, sf.Name, name)
+			t.dialect.Tables().warnf("%s.%s has unknown tag option: %q", t.TypeName, sf.Name, name)
 		}
 	}
 
@@ -434,8 +581,11 @@ func (t *Table) newField(sf reflect.StructField, tag tagparser.Tag) *Field {
 		field.AutoIncrement = true
 		field.NullZero = true
 	}
-	if tag.HasOption("identity") {
+	if s, ok := tag.Option("identity"); ok {
+		// Like autoincrement, zero values are inserted as DEFAULT.
 		field.Identity = true
+		field.NullZero = true
+		t.parseIdentity(field, s)
 	}
 
 	if v, ok := tag.Options["unique"]; ok {
@@ -444,25 +594,57 @@ func (t *Table) newField(sf reflect.StructField, tag tagparser.Tag) *Field {
 			// Split the value by comma, this will allow multiple names to be specified.
 			// We can use this to create multiple named unique constraints where a single column
 			// might be included in multiple constraints.
-			names = strings.Split(v[0], ",")
+			names = splitUniqueNames(v[0])
 		} else {
 			names = v
 		}
 
 		for _, uniqueName := range names {
+			uniqueName = t.parseUniqueOptions(field, uniqueName)
 			if t.Unique == nil {
 				t.Unique = make(map[string][]*Field)
 			}
 			t.Unique[uniqueName] = append(t.Unique[uniqueName], field)
 		}
 	}
+	if v, ok := tag.Options["group"]; ok {
+		names := v
+		if len(v) == 1 {
+			// Like unique, group:"list,detail" adds the field to multiple groups.
+			names = strings.Split(v[0], ",")
+		}
+
+		for _, name := range names {
+			if t.ColumnGroups == nil {
+				t.ColumnGroups = make(map[string][]*Field)
+			}
+			t.ColumnGroups[name] = append(t.ColumnGroups[name], field)
+		}
+	}
 	if s, ok := tag.Option("default"); ok {
 		field.SQLDefault = s
 	}
+	if s, ok := tag.Option("collate"); ok {
+		field.Collation = s
+	}
+	if s, ok := tag.Option("renamed_from"); ok {
+		field.RenamedFrom = s
+	}
 	if s, ok := field.Tag.Option("type"); ok {
 		field.UserSQLType = s
+	} else if s, ok := registeredSQLType(t.dialect, field.IndirectType); ok {
+		field.UserSQLType = s
 	}
 	field.DiscoveredSQLType = DiscoverSQLType(field.IndirectType)
+	if tag.HasOption("discriminator") {
+		field.DiscoveredSQLType = sqltype.JSON
+	}
+	if tag.HasOption("encrypted") {
+		// Encrypted values are stored as base64 strings that are longer than the values.
+		field.Encrypted = true
+		field.DiscoveredSQLType = sqltype.Text
+	}
+	field.JSON = isJSONField(field)
 	field.Append = FieldAppender(t.dialect, field)
 	field.Scan = FieldScanner(t.dialect, field)
 	field.IsZero = zeroChecker(field.StructField.Type)
@@ -517,10 +699,6 @@ func (t *Table) addRelation(rel *Relation) {
 
 func (t *Table) belongsToRelation(field *Field) *Relation {
 	joinTable := t.dialect.Tables().InProgress(field.IndirectType)
-	if err := joinTable.CheckPKs(); err != nil {
-		panic(err)
-	}
-
 	rel := &Relation{
 		Type:      BelongsToRelation,
 		Field:     field,
@@ -530,6 +708,7 @@ func (t *Table) belongsToRelation(field *Field) *Relation {
 	if field.Tag.HasOption("join_on") {
 		rel.Condition = field.Tag.Options["join_on"]
 	}
+	rel.InnerJoin = t.relationInnerJoin(field, "belongs-to")
 
 	rel.OnUpdate = "ON UPDATE NO ACTION"
 	if onUpdate, ok := field.Tag.Options["on_update"]; ok {
@@ -539,7 +718,7 @@ func (t *Table) belongsToRelation(field *Field) *Relation {
 
 		rule := strings.ToUpper(onUpdate[0])
 		if !isKnownFKRule(rule) {
-			internal.Warn.Printf("bun: %s belongs-to %s: unknown on_update rule %s", t.TypeName, field.GoName, rule)
+			t.dialect.Tables().warnf("bun: %s belongs-to %s: unknown on_update rule %s", t.TypeName, field.GoName, rule)
 		}
 
 		s := fmt.Sprintf("ON UPDATE %s", rule)
@@ -554,7 +733,7 @@ func (t *Table) belongsToRelation(field *Field) *Relation {
 
 		rule := strings.ToUpper(onDelete[0])
 		if !isKnownFKRule(rule) {
-			internal.Warn.Printf("bun: %s belongs-to %s: unknown on_delete rule %s", t.TypeName, field.GoName, rule)
+			t.dialect.Tables().warnf("bun: %s belongs-to %s: unknown on_delete rule %s", t.TypeName, field.GoName, rule)
 		}
 		s := fmt.Sprintf("ON DELETE %s", rule)
 		rel.OnDelete = s
@@ -583,9 +762,21 @@ func (t *Table) belongsToRelation(field *Field) *Relation {
 				))
 			}
 		}
+
+		// Lookup tables can be joined by a unique natural key instead of the primary key.
+		if len(joinTable.PKs) == 0 && !joinTable.IsUniqueKey(rel.JoinPKs) {
+			panic(fmt.Errorf(
+				"bun: %s belongs-to %s: %s does not have primary keys or a unique key on the join columns",
+				t.TypeName, field.GoName, joinTable.TypeName,
+			))
+		}
 		return rel
 	}
 
+	if err := joinTable.CheckPKs(); err != nil {
+		panic(err)
+	}
+
 	rel.JoinPKs = joinTable.PKs
 	fkPrefix := internal.Underscore(field.GoName) + "_"
 	for _, joinPK := range joinTable.PKs {
@@ -610,10 +801,6 @@ func (t *Table) belongsToRelation(field *Field) *Relation {
 }
 
 func (t *Table) hasOneRelation(field *Field) *Relation {
-	if err := t.CheckPKs(); err != nil {
-		panic(err)
-	}
-
 	joinTable := t.dialect.Tables().InProgress(field.IndirectType)
 	rel := &Relation{
 		Type:      HasOneRelation,
@@ -624,6 +811,7 @@ func (t *Table) hasOneRelation(field *Field) *Relation {
 	if field.Tag.HasOption("join_on") {
 		rel.Condition = field.Tag.Options["join_on"]
 	}
+	rel.InnerJoin = t.relationInnerJoin(field, "has-one")
 
 	if join, ok := field.Tag.Options["join"]; ok {
 		baseColumns, joinColumns := parseRelationJoin(join)
@@ -647,9 +835,20 @@ func (t *Table) hasOneRelation(field *Field) *Relation {
 				))
 			}
 		}
+
+		if len(t.PKs) == 0 && !t.IsUniqueKey(rel.BasePKs) {
+			panic(fmt.Errorf(
+				"bun: %s has-one %s: %s does not have primary keys or a unique key on the join columns",
+				t.TypeName, field.GoName, t.TypeName,
+			))
+		}
 		return rel
 	}
 
+	if err := t.CheckPKs(); err != nil {
+		panic(err)
+	}
+
 	rel.BasePKs = t.PKs
 	fkPrefix := internal.Underscore(t.ModelName) + "_"
 	for _, pk := range t.PKs {
@@ -866,6 +1065,9 @@ func (t *Table) HasAfterScanHook() bool { return t.flags.Has(afterScanHookFlag)
 func (t *Table) HasBeforeScanRowHook() bool { return t.flags.Has(beforeScanRowHookFlag) }
 func (t *Table) HasAfterScanRowHook() bool  { return t.flags.Has(afterScanRowHookFlag) }
 
+func (t *Table) HasBeforeValidateHook() bool { return t.flags.Has(beforeValidateHookFlag) }
+func (t *Table) HasValidateHook() bool       { return t.flags.Has(validateHookFlag) }
+
 //------------------------------------------------------------------------------
 
 func (t *Table) AppendNamedArg(
@@ -891,9 +1093,111 @@ func (t *Table) quoteIdent(s string) Safe {
 	return Safe(NewFormatter(t.dialect).AppendIdent(nil, s))
 }
 
+// UniqueOptions are the options of a unique constraint.
+type UniqueOptions struct {
+	// Deferrable creates a DEFERRABLE constraint, which can be deferred
+	// with SET CONSTRAINTS ... DEFERRED.
+	Deferrable bool
+	// InitiallyDeferred creates a DEFERRABLE INITIALLY DEFERRED constraint,
+	// which is checked when the transaction is committed.
+	InitiallyDeferred bool
+	// NullsNotDistinct creates a UNIQUE NULLS NOT DISTINCT constraint,
+	// which treats NULL values as equal.
+	NullsNotDistinct bool
+}
+
+// parseIdentity parses the identity options, e.g. always(START WITH 1000 INCREMENT BY 10).
+func (t *Table) parseIdentity(field *Field, s string) {
+	if i := strings.IndexByte(s, '('); i >= 0 && strings.HasSuffix(s, ")") {
+		field.IdentityOptions = strings.TrimSpace(s[i+1 : len(s)-1])
+		s = s[:i]
+	}
+
+	switch s {
+	case "", "default":
+	case "always":
+		field.IdentityAlways = true
+	default:
+		panic(fmt.Errorf("bun: %s.%s: unknown identity option %q (expected always or default)",
+			t.TypeName, field.GoName, s))
+	}
+}
+
+// Exclusion is an exclusion constraint, which guarantees that no two rows
+// match all the element operators, e.g. overlapping bookings of the same room.
+type Exclusion struct {
+	// Using is the index method, e.g. gist.
+	Using string
+	// Elements are the elements and the operators, e.g. room_id WITH =, during WITH &&.
+	Elements string
+}
+
+// splitUniqueNames splits the names by comma, keeping the commas
+// in the options, e.g. "a(deferred,nulls_not_distinct),b".
+func splitUniqueNames(s string) []string {
+	var names []string
+	var lvl, start int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			lvl++
+		case ')':
+			lvl--
+		case ',':
+			if lvl == 0 {
+				names = append(names, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(names, s[start:])
+}
+
+// parseUniqueOptions parses the options of the unique constraint,
+// e.g. name(deferred nulls_not_distinct), and returns the constraint name.
+func (t *Table) parseUniqueOptions(field *Field, s string) string {
+	i := strings.IndexByte(s, '(')
+	if i == -1 || !strings.HasSuffix(s, ")") {
+		return s
+	}
+
+	name := s[:i]
+	if name == "" {
+		panic(fmt.Errorf("bun: %s.%s: unique options require a constraint name, e.g. unique:name%s",
+			t.TypeName, field.GoName, s))
+	}
+
+	opts := t.UniqueOptions[name]
+	if opts == nil {
+		opts = new(UniqueOptions)
+		if t.UniqueOptions == nil {
+			t.UniqueOptions = make(map[string]*UniqueOptions)
+		}
+		t.UniqueOptions[name] = opts
+	}
+
+	for _, opt := range strings.FieldsFunc(s[i+1:len(s)-1], func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		switch opt {
+		case "deferrable":
+			opts.Deferrable = true
+		case "deferred":
+			opts.Deferrable = true
+			opts.InitiallyDeferred = true
+		case "nulls_not_distinct":
+			opts.NullsNotDistinct = true
+		default:
+			panic(fmt.Errorf("bun: %s.%s: unknown unique option %q (expected deferrable, deferred, or nulls_not_distinct)",
+				t.TypeName, field.GoName, opt))
+		}
+	}
+	return name
+}
+
 func isKnownTableOption(name string) bool {
 	switch name {
-	case "table", "alias", "select":
+	case "table", "alias", "select", "cache", "exclude", "renamed_from":
 		return true
 	}
 	return false
@@ -909,14 +1213,25 @@ func isKnownFieldOption(name string) bool {
 		"composite",
 		"multirange",
 		"json_use_number",
+		"json_omit_empty",
 		"msgpack",
+		"encrypted",
 		"notnull",
 		"nullzero",
 		"default",
+		"collate",
+		"renamed_from",
 		"unique",
+		"group",
 		"soft_delete",
 		"scanonly",
 		"skipupdate",
+		"generated",
+		"stored",
+		"row_start",
+		"row_end",
+		"extras",
+		"discriminator",
 
 		"pk",
 		"autoincrement",
@@ -933,6 +1248,23 @@ func isKnownFieldOption(name string) bool {
 	return false
 }
 
+// relationInnerJoin parses the join_type tag option, which is either inner or left.
+func (t *Table) relationInnerJoin(field *Field, relType string) bool {
+	joinType, ok := field.Tag.Option("join_type")
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(joinType) {
+	case "inner":
+		return true
+	case "left":
+		return false
+	default:
+		panic(fmt.Errorf("bun: %s %s %s: unknown join_type %q (expected inner or left)",
+			t.TypeName, relType, field.GoName, joinType))
+	}
+}
+
 func isKnownFKRule(name string) bool {
 	switch name {
 	case "CASCADE",
@@ -1,8 +1,10 @@
 package schema
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -245,4 +247,242 @@ func TestTable(t *testing.T) {
 
 		require.Equal(t, table.FieldMap["foo"].SQLName, table.FieldMap["alt_name"].SQLName)
 	})
+
+	t.Run("join type", func(t *testing.T) {
+		type Profile struct {
+			ID int64 `bun:",pk"`
+		}
+		type User struct {
+			ID        int64 `bun:",pk"`
+			ProfileID int64
+			Profile   *Profile `bun:"rel:belongs-to,join_type:inner"`
+			Manager   *Profile `bun:"rel:belongs-to,join:profile_id=id"`
+		}
+
+		table := tables.Get(reflect.TypeOf((*User)(nil)))
+		require.True(t, table.Relations["Profile"].InnerJoin)
+		require.False(t, table.Relations["Manager"].InnerJoin)
+
+		type BadUser struct {
+			ID        int64 `bun:",pk"`
+			ProfileID int64
+			Profile   *Profile `bun:"rel:belongs-to,join_type:outer"`
+		}
+
+		require.PanicsWithError(t,
+			`bun: BadUser belongs-to Profile: unknown join_type "outer" (expected inner or left)`,
+			func() { tables.Get(reflect.TypeOf((*BadUser)(nil))) })
+	})
+
+	t.Run("extras", func(t *testing.T) {
+		type Model struct {
+			ID     int64                  `bun:",pk"`
+			Extras map[string]interface{} `bun:",extras"`
+		}
+
+		table := tables.Get(reflect.TypeOf((*Model)(nil)))
+		require.Equal(t, "Extras", table.ExtrasField.GoName)
+
+		type BadModel struct {
+			ID     int64                   `bun:",pk"`
+			Extras map[string]fmt.Stringer `bun:",extras"`
+		}
+
+		require.PanicsWithError(t,
+			"bun: BadModel.Extras: extras field must be map[string]interface{}, got map[string]fmt.Stringer",
+			func() { tables.Get(reflect.TypeOf((*BadModel)(nil))) })
+	})
+
+	t.Run("entity cache", func(t *testing.T) {
+		type User struct {
+			BaseModel `bun:"table:users,cache"`
+			ID        int64 `bun:",pk"`
+		}
+		type Session struct {
+			BaseModel `bun:"cache:30s"`
+			ID        int64 `bun:",pk"`
+		}
+
+		table := tables.Get(reflect.TypeOf((*User)(nil)))
+		require.True(t, table.EntityCache)
+		require.Zero(t, table.EntityCacheTTL)
+
+		table = tables.Get(reflect.TypeOf((*Session)(nil)))
+		require.True(t, table.EntityCache)
+		require.Equal(t, 30*time.Second, table.EntityCacheTTL)
+
+		type BadSession struct {
+			BaseModel `bun:"cache:1x"`
+			ID        int64 `bun:",pk"`
+		}
+
+		require.Panics(t, func() { tables.Get(reflect.TypeOf((*BadSession)(nil))) })
+	})
+
+	t.Run("relation by unique key", func(t *testing.T) {
+		type Country struct {
+			Code string `bun:",unique"`
+			Name string
+		}
+		type Currency struct {
+			Code        string `bun:",unique:currency_code"`
+			CountryCode string
+			Country     *Country `bun:"rel:belongs-to,join:country_code=code"`
+		}
+		type Address struct {
+			ID          int64 `bun:",pk"`
+			CountryCode string
+			Country     *Country  `bun:"rel:belongs-to,join:country_code=code"`
+			Currency    *Currency `bun:"rel:has-one,join:country_code=country_code"`
+		}
+
+		table := tables.Get(reflect.TypeOf((*Currency)(nil)))
+		require.True(t, table.IsUniqueKey([]*Field{table.FieldMap["code"]}))
+		require.False(t, table.IsUniqueKey([]*Field{table.FieldMap["country_code"]}))
+
+		rel := table.Relations["Country"]
+		require.Equal(t, "code", rel.JoinPKs[0].Name)
+
+		table = tables.Get(reflect.TypeOf((*Address)(nil)))
+		require.Equal(t, HasOneRelation, table.Relations["Currency"].Type)
+
+		type Rate struct {
+			CountryCode string
+			Currency    *Currency `bun:"rel:has-one,join:country_code=country_code"`
+		}
+
+		require.PanicsWithError(t,
+			"bun: Rate has-one Currency: Rate does not have primary keys or a unique key on the join columns",
+			func() { tables.Get(reflect.TypeOf((*Rate)(nil))) })
+
+		type Region struct {
+			Name string
+		}
+		type City struct {
+			ID         int64 `bun:",pk"`
+			RegionName string
+			Region     *Region `bun:"rel:belongs-to,join:region_name=name"`
+		}
+
+		require.PanicsWithError(t,
+			"bun: City belongs-to Region: Region does not have primary keys or a unique key on the join columns",
+			func() { tables.Get(reflect.TypeOf((*City)(nil))) })
+	})
+
+	t.Run("unique options", func(t *testing.T) {
+		type Booking struct {
+			RoomID   int64  `bun:",unique:\"slot(deferred,nulls_not_distinct),room\""`
+			StartsAt int64  `bun:",unique:slot"`
+			Code     string `bun:",unique:code(deferrable)"`
+			Email    string `bun:",unique"`
+		}
+
+		table := tables.Get(reflect.TypeOf((*Booking)(nil)))
+		require.Len(t, table.Unique["slot"], 2)
+		require.Len(t, table.Unique["room"], 1)
+		require.Len(t, table.Unique["code"], 1)
+		require.Equal(t, &UniqueOptions{
+			Deferrable:        true,
+			InitiallyDeferred: true,
+			NullsNotDistinct:  true,
+		}, table.UniqueOptions["slot"])
+		require.Equal(t, &UniqueOptions{Deferrable: true}, table.UniqueOptions["code"])
+		require.Nil(t, table.UniqueOptions["room"])
+
+		type BadBooking struct {
+			RoomID int64 `bun:",unique:slot(immediate)"`
+		}
+
+		require.Panics(t, func() { tables.Get(reflect.TypeOf((*BadBooking)(nil))) })
+	})
+
+	t.Run("exclusion constraints", func(t *testing.T) {
+		type Booking struct {
+			BaseModel `bun:"table:bookings,exclude:gist(room_id WITH =, during WITH &&),exclude:(code WITH =)"`
+			RoomID    int64
+			During    string
+			Code      string
+		}
+
+		table := tables.Get(reflect.TypeOf((*Booking)(nil)))
+		require.Equal(t, []Exclusion{
+			{Using: "gist", Elements: "room_id WITH =, during WITH &&"},
+			{Using: "gist", Elements: "code WITH ="},
+		}, table.Exclusions)
+
+		type BadBooking struct {
+			BaseModel `bun:"exclude:gist"`
+			RoomID    int64
+		}
+
+		require.Panics(t, func() { tables.Get(reflect.TypeOf((*BadBooking)(nil))) })
+	})
+
+	t.Run("identity", func(t *testing.T) {
+		type Invoice struct {
+			ID     int64 `bun:",pk,identity:always(START WITH 1000)"`
+			Number int64 `bun:",identity"`
+			Code   int64 `bun:",identity:default"`
+		}
+
+		table := tables.Get(reflect.TypeOf((*Invoice)(nil)))
+
+		id := table.FieldMap["id"]
+		require.True(t, id.Identity)
+		require.True(t, id.IdentityAlways)
+		require.True(t, id.NullZero)
+		require.True(t, id.SkipUpdate())
+		require.Equal(t, "START WITH 1000", id.IdentityOptions)
+		require.Equal(t, " GENERATED ALWAYS AS IDENTITY (START WITH 1000)", string(id.AppendIdentity(nil)))
+
+		number := table.FieldMap["number"]
+		require.True(t, number.Identity)
+		require.False(t, number.IdentityAlways)
+		require.False(t, number.SkipUpdate())
+		require.Equal(t, " GENERATED BY DEFAULT AS IDENTITY", string(number.AppendIdentity(nil)))
+
+		require.False(t, table.FieldMap["code"].IdentityAlways)
+
+		type BadInvoice struct {
+			ID int64 `bun:",pk,identity:sometimes"`
+		}
+
+		require.Panics(t, func() { tables.Get(reflect.TypeOf((*BadInvoice)(nil))) })
+	})
+
+	t.Run("renamed from", func(t *testing.T) {
+		type Account struct {
+			BaseModel `bun:"table:accounts,renamed_from:users"`
+			ID        int64  `bun:",pk"`
+			Email     string `bun:",renamed_from:email_address"`
+			Name      string
+		}
+
+		table := tables.Get(reflect.TypeOf((*Account)(nil)))
+		require.Equal(t, "users", table.RenamedFrom)
+		require.Equal(t, "email_address", table.FieldMap["email"].RenamedFrom)
+		require.Empty(t, table.FieldMap["name"].RenamedFrom)
+	})
+
+	t.Run("column groups", func(t *testing.T) {
+		type Article struct {
+			ID    int64  `bun:",pk,group:\"list,detail\""`
+			Title string `bun:",group:list,group:detail"`
+			Body  string `bun:",group:detail"`
+			Data  []byte
+		}
+
+		table := tables.Get(reflect.TypeOf((*Article)(nil)))
+		require.Len(t, table.ColumnGroups, 2)
+
+		names := func(fields []*Field) []string {
+			var names []string
+			for _, f := range fields {
+				names = append(names, f.Name)
+			}
+			return names
+		}
+		require.Equal(t, []string{"id", "title"}, names(table.ColumnGroups["list"]))
+		require.Equal(t, []string{"id", "title", "body"}, names(table.ColumnGroups["detail"]))
+	})
 }
@@ -1,34 +1,91 @@
 package schema
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"sync"
+	"sync/atomic"
 
 	"github.com/puzpuzpuz/xsync/v3"
+
+	"github.com/uptrace/bun/internal"
 )
 
+// Tables is a registry of tables created from Go structs. It is safe for concurrent use.
+//
+// Tables are built once per type and published only after they are fully initialized.
+// Published tables are never modified, so looking up a table, which is done for every
+// query, does not lock. Building tables is serialized by a single lock, because tables
+// can reference each other through relations and must be built together.
+//
+// Tables reachable through relations and embedded structs of a published table,
+// e.g. Relation.JoinTable, can still be in progress until they are passed to Get.
 type Tables struct {
 	dialect Dialect
 
-	mu     sync.Mutex
-	tables *xsync.MapOf[reflect.Type, *Table]
-
+	// mu serializes building tables.
+	mu         sync.Mutex
 	inProgress map[reflect.Type]*Table
+	warnings   *[]error // collects warnings instead of logging them, see Validate
+
+	tables  *xsync.MapOf[reflect.Type, *Table]
+	byName  *xsync.MapOf[string, *Table]
+	byModel *xsync.MapOf[string, *Table]
+
+	// registeredMu serializes Register calls; Registered loads the slice without locking.
+	registeredMu sync.Mutex
+	registered   atomic.Pointer[[]*Table] // copy on write
+
+	sqlTypes *xsync.MapOf[reflect.Type, string]
 }
 
 func NewTables(dialect Dialect) *Tables {
 	return &Tables{
 		dialect:    dialect,
-		tables:     xsync.NewMapOf[reflect.Type, *Table](),
 		inProgress: make(map[reflect.Type]*Table),
+		tables:     xsync.NewMapOf[reflect.Type, *Table](),
+		byName:     xsync.NewMapOf[string, *Table](),
+		byModel:    xsync.NewMapOf[string, *Table](),
+		sqlTypes:   xsync.NewMapOf[reflect.Type, string](),
 	}
 }
 
 func (t *Tables) Register(models ...interface{}) {
 	for _, model := range models {
-		_ = t.Get(reflect.TypeOf(model).Elem())
+		table := t.Get(reflect.TypeOf(model).Elem())
+
+		t.registeredMu.Lock()
+		var registered []*Table
+		if p := t.registered.Load(); p != nil {
+			registered = *p
+		}
+		if !containsTable(registered, table) {
+			registered = append(registered[:len(registered):len(registered)], table)
+			t.registered.Store(&registered)
+		}
+		t.registeredMu.Unlock()
+	}
+}
+
+// Registered returns tables for the models passed to Register in registration order.
+func (t *Tables) Registered() []*Table {
+	p := t.registered.Load()
+	if p == nil {
+		return []*Table{}
+	}
+	tables := make([]*Table, len(*p))
+	copy(tables, *p)
+	return tables
+}
+
+func containsTable(tables []*Table, table *Table) bool {
+	for _, t := range tables {
+		if t == table {
+			return true
+		}
 	}
+	return false
 }
 
 func (t *Tables) Get(typ reflect.Type) *Table {
@@ -50,7 +107,12 @@ func (t *Tables) Get(typ reflect.Type) *Table {
 
 	table := t.InProgress(typ)
 	table.initRelations()
+	t.publish(table)
+	return table
+}
 
+// publish finishes building the table and makes it available to Get, ByName, and ByModel.
+func (t *Tables) publish(table *Table) {
 	t.dialect.OnTable(table)
 	for _, field := range table.FieldMap {
 		if field.UserSQLType == "" {
@@ -59,12 +121,128 @@ func (t *Tables) Get(typ reflect.Type) *Table {
 		if field.CreateTableSQLType == "" {
 			field.CreateTableSQLType = field.UserSQLType
 		}
+		field.bindable = hasTypeAppender(field)
 	}
+	table.initGenerated()
+	table.initFieldSetters()
 
-	t.tables.Store(typ, table)
-	return table
+	t.byName.LoadOrStore(table.Name, table)
+	t.byModel.LoadOrStore(table.TypeName, table)
+	t.tables.Store(table.Type, table)
+}
+
+// Validate builds the tables for the models and the tables reachable through their
+// relations like Get, but returns the errors found in the struct tags and relations
+// instead of panicking, so invalid models can be detected at startup or in tests.
+// Warnings that are otherwise logged, e.g. unknown tag options, are returned as errors.
+//
+// Tables that are already built are not validated again. Tables with errors are
+// not published, so Get panics with the first error as usual.
+func (t *Tables) Validate(models ...interface{}) error {
+	var errs []error
+	seen := make(map[reflect.Type]bool)
+	queue := make([]reflect.Type, 0, len(models))
+	for _, model := range models {
+		queue = append(queue, indirectType(reflect.TypeOf(model)))
+	}
+
+	for len(queue) > 0 {
+		typ := queue[0]
+		queue = queue[1:]
+		if seen[typ] {
+			continue
+		}
+		seen[typ] = true
+
+		table, tableErrs := t.validate(typ)
+		errs = append(errs, tableErrs...)
+		if table == nil {
+			continue
+		}
+		for _, rel := range table.Relations {
+			queue = append(queue, rel.JoinTable.Type)
+			if rel.M2MTable != nil {
+				queue = append(queue, rel.M2MTable.Type)
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
+// validate builds and publishes the table for the type and returns it,
+// or returns the errors if the table can't be built.
+func (t *Tables) validate(typ reflect.Type) (*Table, []error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, []error{fmt.Errorf("bun: got %s, wanted %s", typ.Kind(), reflect.Struct)}
+	}
+	if table, ok := t.tables.Load(typ); ok {
+		return table, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if table, ok := t.tables.Load(typ); ok {
+		return table, nil
+	}
+
+	var errs []error
+	t.warnings = &errs
+	defer func() {
+		t.warnings = nil
+	}()
+
+	var table *Table
+	if err := catchPanic(func() {
+		table = t.InProgress(typ)
+	}); err != nil {
+		return nil, append(errs, err)
+	}
+
+	for _, field := range table.relFields {
+		if err := catchPanic(func() {
+			table.processRelation(field)
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		// Build the table from scratch next time, because relations are partially initialized.
+		delete(t.inProgress, typ)
+		return nil, errs
+	}
+
+	table.relFields = nil
+	t.publish(table)
+	return table, nil
+}
+
+// warnf logs the warning about the table definition,
+// or collects it as an error if the tables are being validated.
+func (t *Tables) warnf(format string, args ...interface{}) {
+	if t.warnings != nil {
+		*t.warnings = append(*t.warnings, fmt.Errorf(format, args...))
+		return
+	}
+	internal.Warn.Printf(format, args...)
+}
+
+func catchPanic(fn func()) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			if e, ok := v.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("bun: %v", v)
+			}
+		}
+	}()
+	fn()
+	return nil
+}
+
+// InProgress returns the table for the type that is being built. It must only be called
+// while building tables, i.e. by Get and by tables that are being initialized.
 func (t *Tables) InProgress(typ reflect.Type) *Table {
 	if table, ok := t.inProgress[typ]; ok {
 		return table
@@ -72,31 +250,28 @@ func (t *Tables) InProgress(typ reflect.Type) *Table {
 
 	table := new(Table)
 	t.inProgress[typ] = table
+
+	ok := false
+	defer func() {
+		if !ok {
+			// Don't return a partially initialized table when the struct is invalid.
+			delete(t.inProgress, typ)
+		}
+	}()
 	table.init(t.dialect, typ, false)
+	ok = true
 
 	return table
 }
 
+// ByModel returns the published table with the model name, e.g. "User", or nil.
 func (t *Tables) ByModel(name string) *Table {
-	var found *Table
-	t.tables.Range(func(typ reflect.Type, table *Table) bool {
-		if table.TypeName == name {
-			found = table
-			return false
-		}
-		return true
-	})
-	return found
+	table, _ := t.byModel.Load(name)
+	return table
 }
 
+// ByName returns the published table with the SQL table name, e.g. "users", or nil.
 func (t *Tables) ByName(name string) *Table {
-	var found *Table
-	t.tables.Range(func(typ reflect.Type, table *Table) bool {
-		if table.Name == name {
-			found = table
-			return false
-		}
-		return true
-	})
-	return found
+	table, _ := t.byName.Load(name)
+	return table
 }
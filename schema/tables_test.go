@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tablesAuthor struct {
+	ID    int64 `bun:",pk"`
+	Name  string
+	Books []*tablesBook `bun:"rel:has-many,join:id=author_id"`
+}
+
+type tablesBook struct {
+	ID       int64 `bun:",pk"`
+	AuthorID int64
+	Author   *tablesAuthor `bun:"rel:belongs-to,join:author_id=id"`
+}
+
+type tablesTag struct {
+	ID   int64 `bun:",pk"`
+	Name string
+}
+
+func TestTablesConcurrentGet(t *testing.T) {
+	tables := newNopDialect().Tables()
+	types := []reflect.Type{
+		reflect.TypeOf((*tablesAuthor)(nil)),
+		reflect.TypeOf((*tablesBook)(nil)),
+		reflect.TypeOf((*tablesTag)(nil)),
+	}
+
+	const numGoroutines = 8
+	got := make([][]*Table, numGoroutines)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := range types {
+				typ := types[(i+j)%len(types)]
+				got[i] = append(got[i], tables.Get(typ))
+			}
+			tables.Register((*tablesTag)(nil), (*tablesAuthor)(nil))
+			_ = tables.Registered()
+			_ = tables.ByName("tables_books")
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range got {
+		require.ElementsMatch(t, got[0], got[i])
+	}
+
+	author := tables.Get(types[0])
+	book := tables.Get(types[1])
+	require.Same(t, book, author.Relations["Books"].JoinTable)
+	require.Same(t, author, book.Relations["Author"].JoinTable)
+	require.Same(t, book, tables.ByName("tables_books"))
+	require.Same(t, author, tables.ByModel("TablesAuthor"))
+	require.Equal(t, []*Table{tables.Get(types[2]), author}, tables.Registered())
+}
+
+type validateAuthor struct {
+	ID      int64             `bun:",pk,unknown_option"`
+	Books   []*validateBook   `bun:"rel:has-many,join:id=no_such_column"`
+	Profile *validateProfile  `bun:"rel:has-one,join:id=author_id"`
+	Reviews []*validateReview `bun:"rel:has-few"`
+}
+
+type validateBook struct {
+	ID       int64 `bun:",pk"`
+	AuthorID int64
+}
+
+type validateProfile struct {
+	ID       int64 `bun:",pk"`
+	AuthorID int64
+}
+
+type validateReview struct {
+	ID int64 `bun:",pk"`
+}
+
+func TestTablesValidate(t *testing.T) {
+	tables := newNopDialect().Tables()
+
+	err := tables.Validate((*validateAuthor)(nil), (*validateProfile)(nil))
+	require.Error(t, err)
+	msg := err.Error()
+	require.Contains(t, msg, `has unknown tag option: "unknown_option"`)
+	require.Contains(t, msg, "no_such_column")
+	require.Contains(t, msg, "unknown relation=has-few")
+	require.NotContains(t, msg, "Profile")
+
+	require.NotNil(t, tables.ByModel("ValidateProfile"))
+	require.Nil(t, tables.ByModel("ValidateAuthor"))
+	require.Panics(t, func() {
+		tables.Get(reflect.TypeOf((*validateAuthor)(nil)))
+	})
+
+	require.NoError(t, tables.Validate((*tablesAuthor)(nil)))
+	require.NotNil(t, tables.ByModel("TablesBook"))
+}
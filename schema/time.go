@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"time"
+)
+
+// TimeConfig controls how time.Time values are appended to queries and scanned from rows.
+// The zero value keeps the hard-coded behavior of the dialect.
+type TimeConfig struct {
+	// Location is used to convert appended and scanned times, e.g. time.UTC.
+	// Nil keeps the location of the value.
+	//
+	// Dialects that append the UTC offset, for example, PostgreSQL and SQLite,
+	// still refer to the same instant, but MySQL and MSSQL write the wall clock
+	// in the Location.
+	Location *time.Location
+
+	// Precision truncates appended and scanned times, e.g. time.Microsecond
+	// to match the precision of PostgreSQL timestamps. Zero disables truncation.
+	Precision time.Duration
+
+	// SQLType is the column type used by CreateTable for time.Time fields
+	// without an explicit type, e.g. "TIMESTAMP" or "TIMESTAMPTZ".
+	// Empty uses the type chosen by the dialect.
+	SQLType string
+}
+
+// Convert applies the location and precision to the time.
+// The zero time is returned unchanged so it is still treated as NULL.
+func (c *TimeConfig) Convert(tm time.Time) time.Time {
+	if c == nil || tm.IsZero() {
+		return tm
+	}
+	if c.Location != nil {
+		tm = tm.In(c.Location)
+	}
+	if c.Precision > 0 {
+		tm = tm.Truncate(c.Precision)
+	}
+	return tm
+}
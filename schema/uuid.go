@@ -0,0 +1,103 @@
+package schema
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	"github.com/uptrace/bun/internal"
+)
+
+// UUIDAppender is implemented by dialects that store UUIDs in a custom format,
+// for example, as BINARY(16) in MySQL. By default UUIDs are appended as strings.
+type UUIDAppender interface {
+	AppendUUID(b []byte, uuid [16]byte) []byte
+}
+
+// IsUUIDType reports whether the type is a UUID type, i.e. a [16]byte type named UUID
+// such as github.com/google/uuid.UUID. Plain [16]byte fields are treated as UUIDs
+// only when they have the type:uuid tag option.
+func IsUUIDType(typ reflect.Type) bool {
+	return typ.Name() == "UUID" && isUUIDArray(typ)
+}
+
+func isUUIDArray(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Array && typ.Len() == 16 && typ.Elem().Kind() == reflect.Uint8
+}
+
+// AppendUUID appends the UUID as a quoted string in the canonical 8-4-4-4-12 format.
+func AppendUUID(b []byte, uuid [16]byte) []byte {
+	b = append(b, '\'')
+	b = appendUUIDString(b, uuid)
+	return append(b, '\'')
+}
+
+func appendUUIDString(b []byte, uuid [16]byte) []byte {
+	s := len(b)
+	b = append(b, make([]byte, 36)...)
+	dst := b[s:]
+
+	hex.Encode(dst, uuid[:4])
+	dst[8] = '-'
+	hex.Encode(dst[9:13], uuid[4:6])
+	dst[13] = '-'
+	hex.Encode(dst[14:18], uuid[6:8])
+	dst[18] = '-'
+	hex.Encode(dst[19:23], uuid[8:10])
+	dst[23] = '-'
+	hex.Encode(dst[24:], uuid[10:])
+
+	return b
+}
+
+func appendUUIDValue(fmter Formatter, b []byte, v reflect.Value) []byte {
+	var uuid [16]byte
+	reflect.Copy(reflect.ValueOf(uuid[:]), v)
+
+	if d, ok := fmter.Dialect().(UUIDAppender); ok {
+		return d.AppendUUID(b, uuid)
+	}
+	return AppendUUID(b, uuid)
+}
+
+func scanUUID(dest reflect.Value, src interface{}) error {
+	if src == nil {
+		return scanNull(dest)
+	}
+
+	var uuid [16]byte
+	switch src := src.(type) {
+	case []byte:
+		if len(src) == len(uuid) {
+			copy(uuid[:], src)
+			break
+		}
+		if err := parseUUID(uuid[:], internal.String(src)); err != nil {
+			return err
+		}
+	case string:
+		if err := parseUUID(uuid[:], src); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("bun: can't scan %#v into %s", src, dest.Type())
+	}
+
+	reflect.Copy(dest, reflect.ValueOf(uuid[:]))
+	return nil
+}
+
+// parseUUID parses a UUID in the 8-4-4-4-12 format or as 32 hex digits.
+func parseUUID(dst []byte, src string) error {
+	s := src
+	if len(s) == 36 && s[8] == '-' && s[13] == '-' && s[18] == '-' && s[23] == '-' {
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	}
+	if len(s) != 32 {
+		return fmt.Errorf("bun: invalid UUID: %q", src)
+	}
+	if _, err := hex.Decode(dst, internal.Bytes(s)); err != nil {
+		return fmt.Errorf("bun: invalid UUID: %q", src)
+	}
+	return nil
+}
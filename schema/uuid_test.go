@@ -0,0 +1,42 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type UUID [16]byte
+
+func TestUUID(t *testing.T) {
+	typ := reflect.TypeOf(UUID{})
+	require.True(t, IsUUIDType(typ))
+	require.False(t, IsUUIDType(reflect.TypeOf([16]byte{})))
+
+	id := UUID{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	fmter := NewFormatter(newNopDialect())
+
+	b := Appender(fmter.Dialect(), typ)(fmter, nil, reflect.ValueOf(id))
+	require.Equal(t, "'123e4567-e89b-12d3-a456-426614174000'", string(b))
+
+	var ptr *UUID
+	b = Appender(fmter.Dialect(), reflect.TypeOf(ptr))(fmter, nil, reflect.ValueOf(ptr))
+	require.Equal(t, "NULL", string(b))
+
+	for _, src := range []interface{}{
+		"123e4567-e89b-12d3-a456-426614174000",
+		"123e4567e89b12d3a456426614174000",
+		[]byte("123e4567-e89b-12d3-a456-426614174000"),
+		id[:],
+	} {
+		var got UUID
+		err := Scanner(typ)(reflect.ValueOf(&got).Elem(), src)
+		require.NoError(t, err)
+		require.Equal(t, id, got)
+	}
+
+	var got UUID
+	err := Scanner(typ)(reflect.ValueOf(&got).Elem(), "not-a-uuid")
+	require.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package schema_test
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/uptrace/bun/schema"
+)
+
+// UUID is a UUID type with its own Value and Scan methods,
+// e.g. github.com/google/uuid.UUID.
+type UUID [16]byte
+
+func (u UUID) Value() (driver.Value, error) {
+	return "valuer", nil
+}
+
+func (u *UUID) Scan(src interface{}) error {
+	u[0] = 1
+	return nil
+}
+
+func TestUUIDValuerAndScanner(t *testing.T) {
+	typ := reflect.TypeOf(UUID{})
+	require.True(t, schema.IsUUIDType(typ))
+
+	fmter := schema.NewNopFormatter()
+	b := schema.Appender(fmter.Dialect(), typ)(fmter, nil, reflect.ValueOf(UUID{}))
+	require.Equal(t, "'valuer'", string(b))
+
+	var got UUID
+	err := schema.Scanner(typ)(reflect.ValueOf(&got).Elem(), "123e4567-e89b-12d3-a456-426614174000")
+	require.NoError(t, err)
+	require.Equal(t, UUID{1}, got)
+}
@@ -0,0 +1,104 @@
+package bun
+
+import (
+	"context"
+	"database/sql"
+)
+
+// WithSessionVar registers the PostgreSQL setting, e.g. app.current_tenant, that is set
+// to the value returned by the func for queries and transactions, so row-level security
+// policies can use it, for example:
+//
+//	db := bun.NewDB(sqldb, pgdialect.New(), bun.WithSessionVar("app.current_tenant",
+//		func(ctx context.Context) (string, bool) {
+//			id, ok := ctx.Value(tenantKey{}).(string)
+//			return id, ok
+//		}))
+//
+//	CREATE POLICY tenant_isolation ON orders
+//		USING (tenant_id = current_setting('app.current_tenant')::bigint)
+//
+// Settings are set with set_config(name, value, true), which works like SET LOCAL, when
+// a transaction is started with BeginTx or RunInTx. Queries built with NewSelect,
+// NewInsert, NewRaw, etc. outside of transactions run in a transaction that sets them.
+// Settings are not set if the func returns false.
+func WithSessionVar(name string, fn func(ctx context.Context) (string, bool)) DBOption {
+	return func(db *DB) {
+		db.sessionVars = append(db.sessionVars, sessionVar{name: name, fn: fn})
+	}
+}
+
+type sessionVar struct {
+	name string
+	fn   func(ctx context.Context) (string, bool)
+}
+
+// sessionVarsQuery returns the query that sets the session vars from the context,
+// or an empty string if there are no vars to set.
+func (db *DB) sessionVarsQuery(ctx context.Context) string {
+	var b []byte
+	for _, v := range db.sessionVars {
+		value, ok := v.fn(ctx)
+		if !ok {
+			continue
+		}
+		if b == nil {
+			b = append(b, "SELECT "...)
+		} else {
+			b = append(b, ", "...)
+		}
+		b = db.fmter.AppendQuery(b, "set_config(?, ?, true)", v.name, value)
+	}
+	return string(b)
+}
+
+func (db *DB) setSessionVars(ctx context.Context, tx *sql.Tx) error {
+	if len(db.sessionVars) == 0 {
+		return nil
+	}
+	query := db.sessionVarsQuery(ctx)
+	if query == "" {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// withSessionVars starts a transaction that sets the session vars for a query that
+// is executed outside of transactions. The returned func commits the transaction
+// if the query succeeds and rolls it back otherwise.
+func (db *DB) withSessionVars(ctx context.Context, conn IConn) (IConn, func(err *error), error) {
+	noop := func(*error) {}
+	if len(db.sessionVars) == 0 {
+		return conn, noop, nil
+	}
+
+	beginner, ok := conn.(interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return conn, noop, nil
+	}
+
+	query := db.sessionVarsQuery(ctx)
+	if query == "" {
+		return conn, noop, nil
+	}
+
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		_ = tx.Rollback()
+		return nil, nil, err
+	}
+
+	return tx, func(err *error) {
+		if *err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		*err = tx.Commit()
+	}, nil
+}
@@ -0,0 +1,299 @@
+package bun
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Portable kinds of database errors, see SQLError and ClassifyError.
+var (
+	ErrUniqueViolation      = errors.New("bun: unique violation")
+	ErrForeignKeyViolation  = errors.New("bun: foreign key violation")
+	ErrCheckViolation       = errors.New("bun: check violation")
+	ErrNotNullViolation     = errors.New("bun: not null violation")
+	ErrSerializationFailure = errors.New("bun: serialization failure")
+)
+
+// WithSQLErrors makes queries built with NewSelect, NewInsert, NewRaw, etc. return
+// a *SQLError for constraint violations and serialization failures, so they can be
+// checked with errors.Is(err, bun.ErrUniqueViolation) regardless of the dialect and driver.
+// See ClassifyError.
+func WithSQLErrors() DBOption {
+	return func(db *DB) {
+		db.sqlErrors = true
+	}
+}
+
+// SQLError is a driver error classified into one of ErrUniqueViolation,
+// ErrForeignKeyViolation, ErrCheckViolation, ErrNotNullViolation,
+// or ErrSerializationFailure. Use errors.As to inspect the wrapped driver error.
+type SQLError struct {
+	// Kind is the kind of the error, e.g. ErrUniqueViolation.
+	Kind error
+	// Constraint is the name of the violated constraint or index, if it is reported.
+	Constraint string
+	// Table is the name of the table, if it is reported.
+	Table string
+	// Column is the name of the column, if it is reported.
+	Column string
+	Err    error
+}
+
+func (e *SQLError) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *SQLError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *SQLError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError returns a *SQLError that wraps the error if it is a constraint violation
+// or a serialization failure, and the error as is otherwise. It recognizes:
+//
+//   - PostgreSQL and CockroachDB SQLSTATE 23505, 23503, 23514, 23502, 40001, and 40P01;
+//   - MySQL errors 1062, 1451, 1452, 3819, 1048, and 1213;
+//   - MSSQL errors 2627, 2601, 547, 515, 1205, and 3960;
+//   - SQLite UNIQUE, PRIMARY KEY, FOREIGN KEY, CHECK, and NOT NULL constraint errors;
+//   - Oracle errors ORA-00001, ORA-02291, ORA-02292, ORA-02290, ORA-01400, and ORA-08177.
+//
+// Deadlocks are classified as serialization failures, because the transaction
+// can be retried in both cases.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var sqlErr *SQLError
+	if errors.As(err, &sqlErr) {
+		return err
+	}
+
+	if sqlErr = classifyError(err); sqlErr != nil {
+		sqlErr.Err = err
+		return sqlErr
+	}
+	return err
+}
+
+var (
+	pgConstraintRE     = regexp.MustCompile(`constraint "([^"]+)"`)
+	pgKeyRE            = regexp.MustCompile(`^Key \(([^)]+)\)`)
+	mysqlKeyRE         = regexp.MustCompile(`for key '([^']+)'`)
+	mysqlConstraintRE  = regexp.MustCompile("CONSTRAINT `([^`]+)`")
+	mysqlCheckRE       = regexp.MustCompile(`Check constraint '([^']+)'`)
+	mysqlColumnRE      = regexp.MustCompile(`Column '([^']+)'`)
+	mssqlConstraintRE  = regexp.MustCompile(`constraint ["']([^"']+)["']`)
+	mssqlIndexRE       = regexp.MustCompile(`unique index '([^']+)'`)
+	mssqlObjectRE      = regexp.MustCompile(`object '([^']+)'`)
+	mssqlColumnRE      = regexp.MustCompile(`column '([^']+)'`)
+	oracleConstraintRE = regexp.MustCompile(`constraint \(([^)]+)\)`)
+	oracleColumnRE     = regexp.MustCompile(`NULL into \(([^)]+)\)`)
+	sqliteErrorRE      = regexp.MustCompile(`(UNIQUE|PRIMARY KEY|FOREIGN KEY|CHECK|NOT NULL) constraint failed(?:: ([^()]+))?`)
+)
+
+func classifyError(err error) *SQLError {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return classifyPGError(stateErr.(error), stateErr.SQLState())
+	}
+
+	var numErr interface{ SQLErrorNumber() int32 }
+	if errors.As(err, &numErr) {
+		return classifyMSSQLError(numErr.(error).Error(), numErr.SQLErrorNumber())
+	}
+
+	// Fall back to error messages for drivers without typed errors,
+	// e.g. github.com/go-sql-driver/mysql and SQLite drivers.
+	msg := err.Error()
+	if code, ok := mysqlErrorNumber(msg); ok {
+		return classifyMySQLError(msg, code)
+	}
+	if code, ok := oracleErrorNumber(msg); ok {
+		return classifyOracleError(msg, code)
+	}
+	if m := sqliteErrorRE.FindStringSubmatch(msg); m != nil {
+		// modernc.org/sqlite appends the error code, e.g. (2067).
+		return classifySQLiteError(m[1], strings.TrimSpace(m[2]))
+	}
+	return nil
+}
+
+func classifyPGError(err error, state string) *SQLError {
+	var e SQLError
+	switch state {
+	case "23505":
+		e.Kind = ErrUniqueViolation
+	case "23503":
+		e.Kind = ErrForeignKeyViolation
+	case "23514":
+		e.Kind = ErrCheckViolation
+	case "23502":
+		e.Kind = ErrNotNullViolation
+	case "40001", "40P01":
+		e.Kind = ErrSerializationFailure
+		return &e
+	default:
+		return nil
+	}
+
+	// pgdriver.Error exposes error fields, e.g. the constraint name.
+	if fieldErr, ok := err.(interface{ Field(k byte) string }); ok {
+		e.Constraint = fieldErr.Field('n')
+		e.Table = fieldErr.Field('t')
+		e.Column = fieldErr.Field('c')
+		if e.Column == "" {
+			e.Column = submatch(pgKeyRE, fieldErr.Field('D'))
+		}
+	}
+	if e.Constraint == "" {
+		e.Constraint = submatch(pgConstraintRE, err.Error())
+	}
+	return &e
+}
+
+func classifyMySQLError(msg string, code int) *SQLError {
+	var e SQLError
+	switch code {
+	case 1062, 1586:
+		e.Kind = ErrUniqueViolation
+		e.Constraint = submatch(mysqlKeyRE, msg)
+		// MySQL 8.0 qualifies the key with the table name.
+		if i := strings.LastIndexByte(e.Constraint, '.'); i >= 0 {
+			e.Table = e.Constraint[:i]
+			e.Constraint = e.Constraint[i+1:]
+		}
+	case 1451, 1452, 1216, 1217:
+		e.Kind = ErrForeignKeyViolation
+		e.Constraint = submatch(mysqlConstraintRE, msg)
+	case 3819:
+		e.Kind = ErrCheckViolation
+		e.Constraint = submatch(mysqlCheckRE, msg)
+	case 1048:
+		e.Kind = ErrNotNullViolation
+		e.Column = submatch(mysqlColumnRE, msg)
+	case 1213:
+		e.Kind = ErrSerializationFailure
+	default:
+		return nil
+	}
+	return &e
+}
+
+func classifyMSSQLError(msg string, code int32) *SQLError {
+	var e SQLError
+	switch code {
+	case 2627:
+		e.Kind = ErrUniqueViolation
+		e.Constraint = submatch(mssqlConstraintRE, msg)
+		e.Table = submatch(mssqlObjectRE, msg)
+	case 2601:
+		e.Kind = ErrUniqueViolation
+		e.Constraint = submatch(mssqlIndexRE, msg)
+		e.Table = submatch(mssqlObjectRE, msg)
+	case 547:
+		switch {
+		case strings.Contains(msg, "FOREIGN KEY"), strings.Contains(msg, "REFERENCE"):
+			e.Kind = ErrForeignKeyViolation
+		case strings.Contains(msg, "CHECK"):
+			e.Kind = ErrCheckViolation
+		default:
+			return nil
+		}
+		e.Constraint = submatch(mssqlConstraintRE, msg)
+		e.Column = submatch(mssqlColumnRE, msg)
+	case 515:
+		e.Kind = ErrNotNullViolation
+		e.Column = submatch(mssqlColumnRE, msg)
+	case 1205, 3960:
+		e.Kind = ErrSerializationFailure
+	default:
+		return nil
+	}
+	return &e
+}
+
+func classifyOracleError(msg string, code int) *SQLError {
+	var e SQLError
+	switch code {
+	case 1:
+		e.Kind = ErrUniqueViolation
+	case 2291, 2292:
+		e.Kind = ErrForeignKeyViolation
+	case 2290:
+		e.Kind = ErrCheckViolation
+	case 1400:
+		e.Kind = ErrNotNullViolation
+		e.Column = submatch(oracleColumnRE, msg)
+		return &e
+	case 8177, 60:
+		e.Kind = ErrSerializationFailure
+		return &e
+	default:
+		return nil
+	}
+	e.Constraint = submatch(oracleConstraintRE, msg)
+	return &e
+}
+
+func classifySQLiteError(kind, detail string) *SQLError {
+	var e SQLError
+	switch kind {
+	case "UNIQUE", "PRIMARY KEY":
+		e.Kind = ErrUniqueViolation
+	case "FOREIGN KEY":
+		e.Kind = ErrForeignKeyViolation
+		return &e
+	case "CHECK":
+		e.Kind = ErrCheckViolation
+		e.Constraint = detail
+		return &e
+	case "NOT NULL":
+		e.Kind = ErrNotNullViolation
+	}
+
+	// The detail lists the columns as table.column, e.g. users.email.
+	// Only single column violations are reported.
+	if detail != "" && !strings.Contains(detail, ",") {
+		if i := strings.IndexByte(detail, '.'); i >= 0 {
+			e.Table = detail[:i]
+			e.Column = detail[i+1:]
+		}
+	}
+	return &e
+}
+
+// mysqlErrorNumber parses errors formatted as "Error 1062 (23000): ..." or "Error 1062: ...".
+func mysqlErrorNumber(msg string) (int, bool) {
+	msg, ok := strings.CutPrefix(msg, "Error ")
+	if !ok {
+		return 0, false
+	}
+	i := strings.IndexAny(msg, " :")
+	if i == -1 {
+		return 0, false
+	}
+	code, err := strconv.Atoi(msg[:i])
+	return code, err == nil
+}
+
+// oracleErrorNumber parses errors that contain "ORA-00001: ...".
+func oracleErrorNumber(msg string) (int, bool) {
+	i := strings.Index(msg, "ORA-")
+	if i == -1 || len(msg) < i+9 {
+		return 0, false
+	}
+	code, err := strconv.Atoi(msg[i+4 : i+9])
+	return code, err == nil
+}
+
+func submatch(re *regexp.Regexp, s string) string {
+	if m := re.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return ""
+}
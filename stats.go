@@ -0,0 +1,93 @@
+package bun
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// Stats contains the connection pool stats of sql.DB and the counters of the DB.
+// The pool stats are embedded, so stats.OpenConnections and other fields
+// work like with sql.DB.Stats.
+type Stats struct {
+	sql.DBStats
+
+	Counters DBStats
+}
+
+// PoolStats returns the connection pool stats and the query counters of the DB.
+// Use db.Stats for the pool stats alone and db.DBStats for the counters alone.
+func (db *DB) PoolStats() Stats {
+	return Stats{
+		DBStats:  db.DB.Stats(),
+		Counters: db.DBStats(),
+	}
+}
+
+// CollectStats calls fn with the stats every interval until the context is canceled.
+// The err argument is the result of pinging the database, which can be used as
+// a health check. The ping times out after the interval. CollectStats blocks,
+// so run it in a goroutine:
+//
+//	go db.CollectStats(ctx, 10*time.Second, func(stats bun.Stats, err error) {
+//		if err != nil {
+//			log.Printf("database is unhealthy: %s", err)
+//		}
+//		log.Printf("open connections: %d", stats.OpenConnections)
+//	})
+func (db *DB) CollectStats(
+	ctx context.Context, interval time.Duration, fn func(stats Stats, err error),
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := db.PingContext(pingCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+		fn(db.PoolStats(), err)
+	}
+}
+
+// PublishExpvar publishes the stats of the DB as an expvar variable with the name,
+// so they are served by the /debug/vars handler. The stats are collected on each request.
+// Like expvar.Publish, it panics if the name is already used.
+func (db *DB) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return db.PoolStats()
+	}))
+}
+
+func (s *DBStats) countQuery(iquery Query) {
+	atomic.AddUint32(&s.Queries, 1)
+
+	var op string
+	if _, ok := iquery.(*RawQuery); !ok && iquery != nil {
+		op = iquery.Operation()
+	}
+
+	switch op {
+	case "SELECT":
+		atomic.AddUint32(&s.Selects, 1)
+	case "INSERT":
+		atomic.AddUint32(&s.Inserts, 1)
+	case "UPDATE":
+		atomic.AddUint32(&s.Updates, 1)
+	case "DELETE":
+		atomic.AddUint32(&s.Deletes, 1)
+	default:
+		atomic.AddUint32(&s.OtherQueries, 1)
+	}
+}
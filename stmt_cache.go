@@ -0,0 +1,244 @@
+package bun
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// WithStmtCache enables a cache of prepared statements keyed by the generated SQL.
+// Up to size least recently used statements are kept prepared and reused by queries
+// with the same SQL, which avoids parsing and planning hot queries again.
+//
+// Bun inlines query arguments into the SQL, so the cache key includes the argument
+// values and only queries with exactly the same SQL, e.g. the same WHERE values, reuse
+// a statement. Every distinct value prepares a statement of its own and evicts another
+// one, which costs an extra round trip, so the cache only pays off for hot queries
+// that repeat verbatim.
+//
+// Only SELECT, INSERT, UPDATE, and DELETE queries that are executed using the DB
+// are cached; queries in transactions are not. The cache is reset after
+// CREATE, ALTER, DROP, and TRUNCATE queries, including queries executed in
+// transactions and on connections, because they can invalidate the statements.
+func WithStmtCache(size int) DBOption {
+	return func(db *DB) {
+		if size > 0 {
+			db.stmtCache = newStmtCache(db.DB, size)
+		}
+	}
+}
+
+// ResetStmtCache closes the statements cached with WithStmtCache,
+// e.g. after changing the schema outside of Bun.
+func (db *DB) ResetStmtCache() {
+	if db.stmtCache != nil {
+		db.stmtCache.reset()
+	}
+}
+
+type stmtCache struct {
+	db   *sql.DB
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List // of *stmtCacheEntry, the most recently used first
+	items map[string]*list.Element
+}
+
+type stmtCacheEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(db *sql.DB, size int) *stmtCache {
+	return &stmtCache{
+		db:    db,
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get returns a prepared statement for the query. The caller must call release
+// when the statement and the rows returned by it are no longer used.
+func (c *stmtCache) get(ctx context.Context, query string) (*stmtCacheEntry, error) {
+	if e := c.lookup(query); e != nil {
+		return e, nil
+	}
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[query]; ok {
+		// Another goroutine prepared the same query.
+		_ = stmt.Close()
+		e := el.Value.(*stmtCacheEntry)
+		c.ll.MoveToFront(el)
+		e.refs++
+		return e, nil
+	}
+
+	e := &stmtCacheEntry{query: query, stmt: stmt, refs: 1}
+	c.items[query] = c.ll.PushFront(e)
+	for c.ll.Len() > c.size {
+		c.evict(c.ll.Back())
+	}
+	return e, nil
+}
+
+func (c *stmtCache) lookup(query string) *stmtCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[query]
+	if !ok {
+		return nil
+	}
+	c.ll.MoveToFront(el)
+	e := el.Value.(*stmtCacheEntry)
+	e.refs++
+	return e
+}
+
+func (c *stmtCache) release(e *stmtCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e.refs--
+	if e.evicted && e.refs == 0 {
+		_ = e.stmt.Close()
+	}
+}
+
+// evict removes the statement from the cache and closes it once it is released.
+func (c *stmtCache) evict(el *list.Element) {
+	e := c.ll.Remove(el).(*stmtCacheEntry)
+	delete(c.items, e.query)
+	e.evicted = true
+	if e.refs == 0 {
+		_ = e.stmt.Close()
+	}
+}
+
+func (c *stmtCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for el := c.ll.Front(); el != nil; el = c.ll.Front() {
+		c.evict(el)
+	}
+}
+
+// cachedStmt returns a cached prepared statement for the query or nil
+// if the query should not be prepared.
+func (db *DB) cachedStmt(
+	ctx context.Context, conn IConn, operation, query string,
+) (*stmtCacheEntry, error) {
+	if db.stmtCache == nil || conn != IConn(db.DB) {
+		return nil, nil
+	}
+	switch operation {
+	case "SELECT", "INSERT", "UPDATE", "DELETE":
+		return db.stmtCache.get(ctx, query)
+	default:
+		return nil, nil
+	}
+}
+
+// invalidateStmtCache resets the statement cache after queries that change the schema.
+func (db *DB) invalidateStmtCache(operation string) {
+	if db.stmtCache != nil && isSchemaOperation(operation) {
+		db.stmtCache.reset()
+	}
+}
+
+// invalidateStmtCache resets the statement cache after queries that change the schema
+// and again when the transaction commits, because until then other connections
+// can prepare statements for the old schema.
+func (tx Tx) invalidateStmtCache(operation string) {
+	c := tx.db.stmtCache
+	if c == nil || !isSchemaOperation(operation) {
+		return
+	}
+	c.reset()
+	if tx.callbacks != nil {
+		tx.OnCommit(func(context.Context) {
+			c.reset()
+		})
+	}
+}
+
+// invalidateStmtCache resets the statement cache after queries that change the schema,
+// taking into account the transaction that executes the query.
+func (q *baseQuery) invalidateStmtCache(ctx context.Context, operation string) {
+	if tx, ok := q.txFromContext(ctx); ok {
+		tx.invalidateStmtCache(operation)
+		return
+	}
+	if tx, ok := q.idb.(Tx); ok {
+		tx.invalidateStmtCache(operation)
+		return
+	}
+	q.db.invalidateStmtCache(operation)
+}
+
+func isSchemaOperation(operation string) bool {
+	operation = strings.ToUpper(operation)
+	for _, prefix := range []string{"CREATE", "ALTER", "DROP", "TRUNCATE"} {
+		if strings.HasPrefix(operation, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// operationOf returns the operation of the query. Raw queries are classified
+// by the leading keyword of the SQL, because RawQuery.Operation always reports SELECT.
+func operationOf(iquery Query, query string) string {
+	if _, ok := iquery.(*RawQuery); ok || iquery == nil {
+		return sqlOperation(query)
+	}
+	return iquery.Operation()
+}
+
+// sqlOperation returns the leading keyword of the query in upper case,
+// skipping whitespace, comments, and opening parentheses.
+func sqlOperation(query string) string {
+	for {
+		query = strings.TrimLeftFunc(query, func(r rune) bool {
+			return unicode.IsSpace(r) || r == '('
+		})
+		switch {
+		case strings.HasPrefix(query, "--"):
+			idx := strings.IndexByte(query, '\n')
+			if idx == -1 {
+				return ""
+			}
+			query = query[idx+1:]
+		case strings.HasPrefix(query, "/*"):
+			idx := strings.Index(query, "*/")
+			if idx == -1 {
+				return ""
+			}
+			query = query[idx+2:]
+		default:
+			end := strings.IndexFunc(query, func(r rune) bool {
+				return !unicode.IsLetter(r)
+			})
+			if end == -1 {
+				end = len(query)
+			}
+			return strings.ToUpper(query[:end])
+		}
+	}
+}
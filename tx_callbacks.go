@@ -0,0 +1,70 @@
+package bun
+
+import (
+	"context"
+	"sync"
+)
+
+// txCallbacks holds the functions registered with Tx.OnCommit and Tx.OnRollback.
+// Savepoints have their own callbacks that are moved to the parent on release.
+type txCallbacks struct {
+	mu        sync.Mutex
+	parent    *txCallbacks
+	commits   []func(ctx context.Context)
+	rollbacks []func(ctx context.Context)
+}
+
+func (c *txCallbacks) onCommit(fn func(ctx context.Context)) {
+	c.mu.Lock()
+	c.commits = append(c.commits, fn)
+	c.mu.Unlock()
+}
+
+func (c *txCallbacks) onRollback(fn func(ctx context.Context)) {
+	c.mu.Lock()
+	c.rollbacks = append(c.rollbacks, fn)
+	c.mu.Unlock()
+}
+
+func (c *txCallbacks) take() (commits, rollbacks []func(ctx context.Context)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	commits, rollbacks = c.commits, c.rollbacks
+	c.commits, c.rollbacks = nil, nil
+	return commits, rollbacks
+}
+
+// release moves the callbacks of a released savepoint to the parent transaction.
+func (c *txCallbacks) release() {
+	if c == nil || c.parent == nil {
+		return
+	}
+
+	commits, rollbacks := c.take()
+
+	c.parent.mu.Lock()
+	c.parent.commits = append(c.parent.commits, commits...)
+	c.parent.rollbacks = append(c.parent.rollbacks, rollbacks...)
+	c.parent.mu.Unlock()
+}
+
+func (c *txCallbacks) commit(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	commits, _ := c.take()
+	for _, fn := range commits {
+		fn(ctx)
+	}
+}
+
+func (c *txCallbacks) rollback(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	_, rollbacks := c.take()
+	for _, fn := range rollbacks {
+		fn(ctx)
+	}
+}
@@ -0,0 +1,136 @@
+package bun
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// TxRetryPolicy configures DB.RunInTxRetry.
+type TxRetryPolicy struct {
+	// MaxRetries is the maximum number of retries. Default is 3.
+	MaxRetries int
+	// MinBackoff is the backoff before the first retry. It is doubled on every retry.
+	// Default is 10ms.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum backoff between retries. Default is 1s.
+	MaxBackoff time.Duration
+	// IsRetryable reports whether the transaction should be retried after the error.
+	// Default is IsRetryableTxError.
+	IsRetryable func(err error) bool
+}
+
+var defaultTxRetryPolicy = TxRetryPolicy{
+	MaxRetries:  3,
+	MinBackoff:  10 * time.Millisecond,
+	MaxBackoff:  time.Second,
+	IsRetryable: IsRetryableTxError,
+}
+
+// WithTxRetryPolicy configures the retries of DB.RunInTxRetry.
+// Zero fields use the default values.
+func WithTxRetryPolicy(policy TxRetryPolicy) DBOption {
+	return func(db *DB) {
+		if policy.MaxRetries == 0 {
+			policy.MaxRetries = defaultTxRetryPolicy.MaxRetries
+		}
+		if policy.MinBackoff == 0 {
+			policy.MinBackoff = defaultTxRetryPolicy.MinBackoff
+		}
+		if policy.MaxBackoff == 0 {
+			policy.MaxBackoff = defaultTxRetryPolicy.MaxBackoff
+		}
+		if policy.IsRetryable == nil {
+			policy.IsRetryable = defaultTxRetryPolicy.IsRetryable
+		}
+		db.txRetry = &policy
+	}
+}
+
+// RunInTxRetry runs the function in a transaction like RunInTx and re-runs the whole
+// transaction with exponential backoff when it fails with a serialization failure or
+// a deadlock. The function must be safe to run several times, e.g. it should not have
+// side effects outside of the transaction.
+//
+// When called in a transaction started by RunInTx, the function runs in a savepoint
+// without retries, because the outer transaction must be retried instead.
+func (db *DB) RunInTxRetry(
+	ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context, tx Tx) error,
+) error {
+	if tx, ok := TxFromContext(ctx); ok && tx.db.DB == db.DB {
+		return tx.RunInTx(ctx, opts, fn)
+	}
+
+	policy := db.txRetry
+	if policy == nil {
+		policy = &defaultTxRetryPolicy
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = db.RunInTx(ctx, opts, fn)
+		if err == nil || attempt >= policy.MaxRetries || !policy.IsRetryable(err) {
+			return err
+		}
+
+		if err := sleep(ctx, retryBackoff(attempt, policy.MinBackoff, policy.MaxBackoff)); err != nil {
+			return err
+		}
+	}
+}
+
+func retryBackoff(attempt int, minBackoff, maxBackoff time.Duration) time.Duration {
+	d := minBackoff << uint(attempt)
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	// Add jitter so concurrent transactions don't retry at the same time.
+	if d > 1 {
+		d = d/2 + time.Duration(rand.Int63n(int64(d/2)))
+	}
+	return d
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// IsRetryableTxError reports whether the transaction that failed with the error
+// can succeed when retried, i.e. the error is a serialization failure or a deadlock:
+//
+//   - PostgreSQL and CockroachDB SQLSTATE 40001 and 40P01;
+//   - MySQL error 1213;
+//   - MSSQL error 1205.
+func IsRetryableTxError(err error) bool {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		switch stateErr.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+	}
+
+	var numErr interface{ SQLErrorNumber() int32 }
+	if errors.As(err, &numErr) && numErr.SQLErrorNumber() == 1205 {
+		return true
+	}
+
+	// Fall back to error messages for drivers without typed errors,
+	// e.g. github.com/go-sql-driver/mysql.
+	msg := err.Error()
+	return strings.HasPrefix(msg, "Error 1213") ||
+		strings.Contains(msg, "SQLSTATE 40001") ||
+		strings.Contains(msg, "SQLSTATE=40001") ||
+		strings.Contains(msg, "restart transaction")
+}
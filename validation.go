@@ -0,0 +1,130 @@
+package bun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/uptrace/bun/schema"
+)
+
+// FieldError describes an invalid field value.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError aggregates field errors returned by ValidateHook, for example:
+//
+//	func (u *User) Validate(ctx context.Context) error {
+//		verr := new(bun.ValidationError)
+//		if u.Name == "" {
+//			verr.Add("name", "is required")
+//		}
+//		return verr.Err()
+//	}
+//
+// When a slice of models is validated, errors of all models are aggregated
+// and field names are prefixed with the model index, e.g. "[1].name".
+// Nil models in the slice are reported as "[1]: is nil".
+type ValidationError struct {
+	Errors []FieldError
+}
+
+var _ error = (*ValidationError)(nil)
+
+// Add adds an error for the field.
+func (e *ValidationError) Add(field, message string) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: message})
+}
+
+// Err returns nil when there are no field errors and e otherwise.
+func (e *ValidationError) Err() error {
+	if e == nil || len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 0 {
+		return "bun: validation failed"
+	}
+
+	var b strings.Builder
+	b.WriteString("bun: validation failed: ")
+	for i, err := range e.Errors {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+func validateStruct(ctx context.Context, table *schema.Table, strct reflect.Value) error {
+	if table.HasBeforeValidateHook() {
+		if err := strct.Interface().(schema.BeforeValidateHook).BeforeValidate(ctx); err != nil {
+			return err
+		}
+	}
+	if table.HasValidateHook() {
+		return strct.Interface().(schema.ValidateHook).Validate(ctx)
+	}
+	return nil
+}
+
+func (m *structTableModel) validate(ctx context.Context) error {
+	if !m.hasValidateHooks() || !m.strct.IsValid() {
+		return nil
+	}
+	return validateStruct(ctx, m.table, m.strct.Addr())
+}
+
+func (m *structTableModel) hasValidateHooks() bool {
+	return m.table.HasBeforeValidateHook() || m.table.HasValidateHook()
+}
+
+func (m *sliceTableModel) validate(ctx context.Context) error {
+	if !m.hasValidateHooks() || !m.slice.IsValid() {
+		return nil
+	}
+
+	var verr *ValidationError
+	for i := 0; i < m.slice.Len(); i++ {
+		strct := m.slice.Index(i)
+		if !m.sliceOfPtr {
+			strct = strct.Addr()
+		} else if strct.IsNil() {
+			if verr == nil {
+				verr = new(ValidationError)
+			}
+			verr.Add(fmt.Sprintf("[%d]", i), "is nil")
+			continue
+		}
+
+		err := validateStruct(ctx, m.table, strct)
+		if err == nil {
+			continue
+		}
+
+		var elemErr *ValidationError
+		if !errors.As(err, &elemErr) {
+			return err
+		}
+
+		if verr == nil {
+			verr = new(ValidationError)
+		}
+		for _, fe := range elemErr.Errors {
+			verr.Add(fmt.Sprintf("[%d].%s", i, fe.Field), fe.Message)
+		}
+	}
+	return verr.Err()
+}